@@ -20,6 +20,8 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
+
 	"github.com/polynetwork/poly/cmd/utils"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
@@ -121,6 +123,10 @@ func setCommonConfig(ctx *cli.Context, cfg *config.CommonConfig) {
 	cfg.LogLevel = ctx.Uint(utils.GetFlagName(utils.LogLevelFlag))
 	cfg.EnableEventLog = !ctx.Bool(utils.GetFlagName(utils.DisableEventLogFlag))
 	cfg.DataDir = ctx.String(utils.GetFlagName(utils.DataDirFlag))
+	cfg.PruneKeepBlocks = ctx.Uint(utils.GetFlagName(utils.PruneKeepBlocksFlag))
+	if cfg.PruneKeepBlocks != 0 && cfg.PruneKeepBlocks < config.MIN_PRUNE_KEEP_BLOCKS {
+		cfg.PruneKeepBlocks = config.MIN_PRUNE_KEEP_BLOCKS
+	}
 }
 
 func setConsensusConfig(ctx *cli.Context, cfg *config.ConsensusConfig) {
@@ -140,6 +146,16 @@ func setP2PNodeConfig(ctx *cli.Context, cfg *config.P2PNodeConfig) {
 	cfg.MaxConnInBound = ctx.Uint(utils.GetFlagName(utils.MaxConnInBoundFlag))
 	cfg.MaxConnOutBound = ctx.Uint(utils.GetFlagName(utils.MaxConnOutBoundFlag))
 	cfg.MaxConnInBoundForSingleIP = ctx.Uint(utils.GetFlagName(utils.MaxConnInBoundForSingleIPFlag))
+	cfg.RequireSignedVersion = ctx.Bool(utils.GetFlagName(utils.RequireSignedVersionFlag))
+	cfg.SeedAnnounceAddr = ctx.String(utils.GetFlagName(utils.SeedAnnounceFlag))
+	for _, key := range strings.Split(ctx.String(utils.GetFlagName(utils.SeedPubKeysFlag)), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			cfg.SeedPubKeys = append(cfg.SeedPubKeys, key)
+		}
+	}
+
+	cfg.SnapshotSyncHeight = uint32(ctx.Uint(utils.GetFlagName(utils.SnapshotSyncHeightFlag)))
+	cfg.SnapshotSyncStateHash = ctx.String(utils.GetFlagName(utils.SnapshotSyncStateHashFlag))
 
 	rsvfile := ctx.String(utils.GetFlagName(utils.ReservedPeersFileFlag))
 	if cfg.ReservedPeersOnly {
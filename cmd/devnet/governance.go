@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package devnet
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/core/types"
+	ontErrors "github.com/polynetwork/poly/errors"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	sutils "github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
+)
+
+const (
+	txTimeout = time.Minute
+	gasLimit  = 100 * config.DEFAULT_GAS_LIMIT
+)
+
+// RegisterSideChains registers the side chains proposed by the first validator and approves them
+func (this *Network) RegisterSideChains(sideChains []*side_chain_manager.RegisterSideChainParam) error {
+	proposer := this.Nodes[0].Account
+	for _, param := range sideChains {
+		param.Address = proposer.Address
+		sink := common.NewZeroCopySink(nil)
+		if err := param.Serialization(sink); err != nil {
+			return fmt.Errorf("serialize side chain %d error: %s", param.ChainId, err)
+		}
+		if _, err := this.Invoke(sutils.SideChainManagerContractAddress, side_chain_manager.REGISTER_SIDE_CHAIN,
+			sink.Bytes(), proposer); err != nil {
+			return fmt.Errorf("register side chain %d error: %s", param.ChainId, err)
+		}
+		chainId := param.ChainId
+		err := this.approve(sutils.SideChainManagerContractAddress, side_chain_manager.APPROVE_REGISTER_SIDE_CHAIN,
+			func(signer common.Address) []byte {
+				sink := common.NewZeroCopySink(nil)
+				(&side_chain_manager.ChainidParam{Chainid: chainId, Address: signer}).Serialization(sink)
+				return sink.Bytes()
+			})
+		if err != nil {
+			return fmt.Errorf("approve side chain %d error: %s", chainId, err)
+		}
+	}
+	return nil
+}
+
+// RegisterRelayers registers the relayers proposed by the first validator and approves them
+func (this *Network) RegisterRelayers(relayers []common.Address) error {
+	if len(relayers) == 0 {
+		return nil
+	}
+	proposer := this.Nodes[0].Account
+	sink := common.NewZeroCopySink(nil)
+	(&relayer_manager.RelayerListParam{AddressList: relayers, Address: proposer.Address}).Serialization(sink)
+	notify, err := this.Invoke(sutils.RelayerManagerContractAddress, relayer_manager.REGISTER_RELAYER, sink.Bytes(), proposer)
+	if err != nil {
+		return fmt.Errorf("register relayers error: %s", err)
+	}
+	applyID, err := relayerApplyID(notify)
+	if err != nil {
+		return err
+	}
+	err = this.approve(sutils.RelayerManagerContractAddress, relayer_manager.APPROVE_REGISTER_RELAYER,
+		func(signer common.Address) []byte {
+			sink := common.NewZeroCopySink(nil)
+			(&relayer_manager.ApproveRelayerParam{ID: applyID, Address: signer}).Serialization(sink)
+			return sink.Bytes()
+		})
+	if err != nil {
+		return fmt.Errorf("approve relayers error: %s", err)
+	}
+	return nil
+}
+
+func relayerApplyID(notify *event.ExecuteNotify) (uint64, error) {
+	for _, n := range notify.Notify {
+		states, ok := n.States.([]interface{})
+		if !ok || len(states) != 2 || states[0] != "putRelayerApply" {
+			continue
+		}
+		switch id := states[1].(type) {
+		case uint64:
+			return id, nil
+		case float64:
+			return uint64(id), nil
+		}
+	}
+	return 0, fmt.Errorf("relayer apply id not found in events of %s", notify.TxHash.ToHexString())
+}
+
+// approve sends the approval of method from just enough validators to pass the consensus signs
+func (this *Network) approve(contract common.Address, method string, args func(common.Address) []byte) error {
+	quorum := (2*len(this.Nodes) + 2) / 3
+	txHashes := make([]common.Uint256, 0, quorum)
+	for _, node := range this.Nodes[:quorum] {
+		txHash, err := this.SendTransaction(contract, method, args(node.Account.Address), node.Account)
+		if err != nil {
+			return fmt.Errorf("validator %d: %s", node.Index, err)
+		}
+		txHashes = append(txHashes, txHash)
+	}
+	for _, txHash := range txHashes {
+		if _, err := this.WaitTransaction(txHash, txTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invoke calls method of the native contract signed by signer and waits for the tx to be packed
+func (this *Network) Invoke(contract common.Address, method string, args []byte, signer *account.Account) (*event.ExecuteNotify, error) {
+	txHash, err := this.SendTransaction(contract, method, args, signer)
+	if err != nil {
+		return nil, err
+	}
+	return this.WaitTransaction(txHash, txTimeout)
+}
+
+// SendTransaction adds the call of method of the native contract signed by signer to the tx pool
+func (this *Network) SendTransaction(contract common.Address, method string, args []byte, signer *account.Account) (common.Uint256, error) {
+	invokeCode := common.NewZeroCopySink(nil)
+	(&states.ContractInvokeParam{Address: contract, Method: method, Args: args}).Serialization(invokeCode)
+	mutTx := &types.Transaction{
+		Version:  types.TX_VERSION_LEGACY,
+		TxType:   types.Invoke,
+		Payload:  &payload.InvokeCode{Code: invokeCode.Bytes()},
+		Nonce:    rand.Uint32(),
+		ChainID:  config.GetChainIdByNetId(this.NetworkId),
+		GasLimit: gasLimit,
+		GasPrice: config.DefConfig.Common.GasPrice,
+	}
+	sink := common.NewZeroCopySink(nil)
+	if err := mutTx.Serialization(sink); err != nil {
+		return common.UINT256_EMPTY, fmt.Errorf("tx serialization error: %s", err)
+	}
+	tx, err := types.TransactionFromRawBytes(sink.Bytes())
+	if err != nil {
+		return common.UINT256_EMPTY, fmt.Errorf("TransactionFromRawBytes error: %s", err)
+	}
+	if err := utils.SignTransaction(signer, tx); err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	sink = common.NewZeroCopySink(nil)
+	if err := tx.Serialization(sink); err != nil {
+		return common.UINT256_EMPTY, fmt.Errorf("tx serialization error: %s", err)
+	}
+	if tx, err = types.TransactionFromRawBytes(sink.Bytes()); err != nil {
+		return common.UINT256_EMPTY, fmt.Errorf("TransactionFromRawBytes error: %s", err)
+	}
+	txHash := tx.Hash()
+	if errCode := this.txs.append(tx, this.Nodes[0].ledger); errCode != ontErrors.ErrNoError {
+		return common.UINT256_EMPTY, fmt.Errorf("append tx %s error: %s", txHash.ToHexString(), errCode.Error())
+	}
+	return txHash, nil
+}
+
+// WaitTransaction waits until the first validator saves the tx and returns its events
+func (this *Network) WaitTransaction(txHash common.Uint256, timeout time.Duration) (*event.ExecuteNotify, error) {
+	ld := this.Nodes[0].ledger
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if exist, _ := ld.IsContainTransaction(txHash); !exist {
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		notify, err := ld.GetEventNotifyByTx(txHash)
+		if err != nil {
+			return nil, fmt.Errorf("get events of %s error: %s", txHash.ToHexString(), err)
+		}
+		if notify.State != event.CONTRACT_STATE_SUCCESS {
+			return nil, fmt.Errorf("tx %s failed", txHash.ToHexString())
+		}
+		return notify, nil
+	}
+	return nil, fmt.Errorf("tx %s is not packed in %s", txHash.ToHexString(), timeout)
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package devnet
+
+import (
+	"sync"
+
+	"github.com/ontio/ontology-eventbus/actor"
+	"github.com/polynetwork/poly/common/log"
+	p2pactor "github.com/polynetwork/poly/p2pserver/actor/server"
+	ptypes "github.com/polynetwork/poly/p2pserver/message/types"
+)
+
+// links routes the consensus messages between the validators in the process,
+// a validator is known to the others by its index as the p2p id
+type links struct {
+	sync.RWMutex
+	servers map[uint64]*actor.PID
+}
+
+func newLinks() *links {
+	return &links{servers: make(map[uint64]*actor.PID)}
+}
+
+func (this *links) add(id uint64, server *actor.PID) {
+	this.Lock()
+	defer this.Unlock()
+	this.servers[id] = server
+}
+
+func (this *links) remove(id uint64) {
+	this.Lock()
+	defer this.Unlock()
+	delete(this.servers, id)
+}
+
+// deliver hands a copy of payload to the validator of id as sent by the validator of from
+func (this *links) deliver(from, id uint64, payload *ptypes.ConsensusPayload) {
+	this.RLock()
+	server, ok := this.servers[id]
+	this.RUnlock()
+	if !ok {
+		return
+	}
+	msg := *payload
+	msg.PeerId = from
+	server.Tell(&msg)
+}
+
+func (this *links) broadcast(from uint64, payload *ptypes.ConsensusPayload) {
+	this.RLock()
+	ids := make([]uint64, 0, len(this.servers))
+	for id := range this.servers {
+		if id != from {
+			ids = append(ids, id)
+		}
+	}
+	this.RUnlock()
+	for _, id := range ids {
+		this.deliver(from, id, payload)
+	}
+}
+
+// linkActor takes the place of the p2p actor of a validator
+type linkActor struct {
+	links *links
+	from  uint64
+}
+
+func (this *linkActor) Receive(ctx actor.Context) {
+	switch msg := ctx.Message().(type) {
+	case *ptypes.ConsensusPayload:
+		if err := msg.Verify(); err != nil {
+			log.Errorf("devnet validator %d: invalid consensus payload: %s", this.from, err)
+			return
+		}
+		this.links.broadcast(this.from, msg)
+	case *p2pactor.TransmitConsensusMsgReq:
+		cons, ok := msg.Msg.(*ptypes.Consensus)
+		if !ok {
+			return
+		}
+		if err := cons.Cons.Verify(); err != nil {
+			log.Errorf("devnet validator %d: invalid consensus payload: %s", this.from, err)
+			return
+		}
+		this.links.deliver(this.from, msg.Target, &cons.Cons)
+	}
+}
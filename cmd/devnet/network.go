@@ -0,0 +1,322 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package devnet runs a local multi-validator VBFT network in one process, the validators
+// keep their own ledgers and exchange consensus messages over in-memory links
+package devnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology-crypto/signature"
+	"github.com/ontio/ontology-eventbus/actor"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	actorTypes "github.com/polynetwork/poly/consensus/actor"
+	"github.com/polynetwork/poly/consensus/vbft"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/ledger"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/events"
+	_ "github.com/polynetwork/poly/native/service"
+)
+
+const (
+	Password      = "devnet"
+	GenesisFile   = "genesis.json"
+	WalletFile    = "wallet.dat"
+	ChainDir      = "Chain"
+	stopTimeout   = 10 * time.Second
+	blockMsgDelay = 5000 // the lowest delays accepted by the node manager
+	hashMsgDelay  = 5000
+)
+
+// Node is a validator of the devnet
+type Node struct {
+	Index   int
+	Dir     string
+	Account *account.Account
+
+	ledger *ledger.Ledger
+	pool   *actor.PID
+	link   *actor.PID
+	server *vbft.Server
+}
+
+// Network is a devnet kept in a directory, wallets, genesis and ledgers are reused when the
+// network is opened again
+type Network struct {
+	Dir       string
+	NetworkId uint32
+	Nodes     []*Node
+	Created   bool
+
+	genesis *config.GenesisConfig
+	txs     *txPool
+	links   *links
+}
+
+// NewNetwork opens the devnet in dir, a network of num validators is created if dir holds none.
+// num is ignored if it is 0 and dir holds a network
+func NewNetwork(dir string, networkId uint32, num int) (*Network, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid devnet dir: %s", err)
+	}
+	genesisFile := filepath.Join(dir, GenesisFile)
+	created := !common.FileExisted(genesisFile)
+	var genesisConfig *config.GenesisConfig
+	if !created {
+		genesisConfig = config.NewGenesisConfig()
+		data, err := ioutil.ReadFile(genesisFile)
+		if err != nil {
+			return nil, fmt.Errorf("read devnet genesis error: %s", err)
+		}
+		if err := json.Unmarshal(data, genesisConfig); err != nil {
+			return nil, fmt.Errorf("load devnet genesis error: %s", err)
+		}
+		if genesisConfig.VBFT == nil {
+			return nil, fmt.Errorf("devnet genesis in %s has no vbft config", dir)
+		}
+		if num != 0 && num != len(genesisConfig.VBFT.Peers) {
+			return nil, fmt.Errorf("devnet in %s has %d validators, remove it to start a devnet of %d validators",
+				dir, len(genesisConfig.VBFT.Peers), num)
+		}
+		num = len(genesisConfig.VBFT.Peers)
+	}
+	if num < config.VBFT_MIN_NODE_NUM {
+		return nil, fmt.Errorf("devnet at least need %d validators", config.VBFT_MIN_NODE_NUM)
+	}
+
+	network := &Network{
+		Dir:       dir,
+		NetworkId: networkId,
+		Nodes:     make([]*Node, 0, num),
+		Created:   created,
+		genesis:   genesisConfig,
+	}
+	for i := 0; i < num; i++ {
+		node, err := loadNode(dir, i)
+		if err != nil {
+			return nil, err
+		}
+		network.Nodes = append(network.Nodes, node)
+	}
+	if created {
+		network.genesis = newGenesis(network.Nodes)
+		data, err := json.MarshalIndent(network.genesis, "", "\t")
+		if err != nil {
+			return nil, fmt.Errorf("json.Marshal devnet genesis error: %s", err)
+		}
+		if err := ioutil.WriteFile(genesisFile, data, 0644); err != nil {
+			return nil, fmt.Errorf("write devnet genesis error: %s", err)
+		}
+	}
+	return network, nil
+}
+
+// loadNode opens the wallet of the index-th validator, the validator account is created if absent
+func loadNode(dir string, index int) (*Node, error) {
+	nodeDir := filepath.Join(dir, fmt.Sprintf("node%d", index+1))
+	if err := os.MkdirAll(nodeDir, 0755); err != nil {
+		return nil, fmt.Errorf("create devnet node dir error: %s", err)
+	}
+	wallet, err := account.Open(filepath.Join(nodeDir, WalletFile))
+	if err != nil {
+		return nil, fmt.Errorf("open devnet wallet error: %s", err)
+	}
+	var acc *account.Account
+	if wallet.GetAccountNum() == 0 {
+		acc, err = wallet.NewAccount("", keypair.PK_ECDSA, keypair.SECP256K1, signature.SHA256withECDSA, []byte(Password))
+	} else {
+		acc, err = wallet.GetDefaultAccount([]byte(Password))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get account of devnet validator %d error: %s", index+1, err)
+	}
+	return &Node{
+		Index:   index + 1,
+		Dir:     nodeDir,
+		Account: acc,
+	}, nil
+}
+
+func newGenesis(nodes []*Node) *config.GenesisConfig {
+	genesisConfig := config.NewGenesisConfig()
+	genesisConfig.ConsensusType = config.CONSENSUS_TYPE_VBFT
+	genesisConfig.VBFT = &config.VBFTConfig{
+		BlockMsgDelay:        blockMsgDelay,
+		HashMsgDelay:         hashMsgDelay,
+		PeerHandshakeTimeout: config.PolarisConfig.VBFT.PeerHandshakeTimeout,
+		MaxBlockChangeView:   config.PolarisConfig.VBFT.MaxBlockChangeView,
+		VrfValue:             config.PolarisConfig.VBFT.VrfValue,
+		VrfProof:             config.PolarisConfig.VBFT.VrfProof,
+	}
+	for _, node := range nodes {
+		genesisConfig.VBFT.Peers = append(genesisConfig.VBFT.Peers, &config.VBFTPeerInfo{
+			Index:      uint32(node.Index),
+			PeerPubkey: vconfig.PubkeyID(node.Account.PublicKey),
+			Address:    node.Account.Address.ToBase58(),
+		})
+	}
+	// the devnet runs every feature from genesis
+	genesisConfig.ActivationHeights = make(map[string]uint32)
+	for feature := range config.ACTIVATION_HEIGHTS {
+		genesisConfig.ActivationHeights[string(feature)] = 0
+	}
+	return genesisConfig
+}
+
+// Start opens the ledgers and starts the consensus of every validator. The validators share the
+// process wide config, so only one network can run in a process
+func (this *Network) Start() error {
+	config.DefConfig.P2PNode.NetworkId = this.NetworkId
+	config.DefConfig.Genesis = this.genesis
+	config.DefConfig.Common.EnableEventLog = true
+	// the side chain params read the height of the default ledger, which none of the validators is
+	config.EXTRA_INFO_HEIGHT_FORK_CHECK = false
+	if events.DefActorPublisher == nil {
+		events.Init()
+	}
+
+	bookkeepers, err := config.DefConfig.GetBookkeepers()
+	if err != nil {
+		return fmt.Errorf("GetBookkeepers error: %s", err)
+	}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookkeepers, this.genesis)
+	if err != nil {
+		return fmt.Errorf("genesisBlock error: %s", err)
+	}
+
+	this.txs = newTxPool()
+	this.links = newLinks()
+	for _, node := range this.Nodes {
+		if err := this.startNode(node, bookkeepers, genesisBlock); err != nil {
+			this.Stop()
+			return err
+		}
+	}
+	for _, node := range this.Nodes {
+		if err := node.server.Start(); err != nil {
+			this.Stop()
+			return fmt.Errorf("start consensus of devnet validator %d error: %s", node.Index, err)
+		}
+	}
+	return nil
+}
+
+func (this *Network) startNode(node *Node, bookkeepers []keypair.PublicKey, genesisBlock *types.Block) error {
+	chainDir := filepath.Join(node.Dir, ChainDir)
+	ld, err := ledger.NewLedger(chainDir)
+	if err != nil {
+		return fmt.Errorf("NewLedger of devnet validator %d error: %s", node.Index, err)
+	}
+	node.ledger = ld
+	if err := ld.Init(bookkeepers, genesisBlock); err != nil {
+		return fmt.Errorf("Init ledger of devnet validator %d error: %s", node.Index, err)
+	}
+	this.txs.addLedger(ld)
+	node.pool = actor.Spawn(actor.FromProducer(func() actor.Actor {
+		return &poolActor{txs: this.txs, ledger: ld}
+	}))
+	node.link = actor.Spawn(actor.FromProducer(func() actor.Actor {
+		return &linkActor{links: this.links, from: uint64(node.Index)}
+	}))
+	node.server, err = vbft.NewLocalVbftServer(node.Account, node.pool, node.link, ld, chainDir)
+	if err != nil {
+		return fmt.Errorf("create consensus of devnet validator %d error: %s", node.Index, err)
+	}
+	this.links.add(uint64(node.Index), node.server.GetPID())
+	return nil
+}
+
+// Stop stops the consensus of every validator and closes the ledgers
+func (this *Network) Stop() {
+	for _, node := range this.Nodes {
+		if node.server != nil {
+			this.links.remove(uint64(node.Index))
+			node.server.GetPID().RequestFuture(&actorTypes.StopConsensus{}, stopTimeout).Wait()
+			node.server.GetPID().Stop()
+			node.server = nil
+		}
+		if node.link != nil {
+			node.link.Stop()
+			node.link = nil
+		}
+		if node.pool != nil {
+			node.pool.Stop()
+			node.pool = nil
+		}
+		if node.ledger != nil {
+			node.ledger.Close()
+			node.ledger = nil
+		}
+	}
+}
+
+// Ledger returns the ledger of the validator, nil if the network is not started
+func (this *Node) Ledger() *ledger.Ledger {
+	return this.ledger
+}
+
+// TxPool returns the pid of the tx pool actor of the validator, nil if the network is not started
+func (this *Node) TxPool() *actor.PID {
+	return this.pool
+}
+
+// Server returns the consensus of the validator, nil if the network is not started
+func (this *Node) Server() *vbft.Server {
+	return this.server
+}
+
+// WaitHeight waits until every validator saves the block of height
+func (this *Network) WaitHeight(height uint32, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		lowest := this.Height()
+		if lowest >= height {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("devnet is at block %d after %s, %d expected", lowest, timeout, height)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// Height returns the lowest block height among the validators
+func (this *Network) Height() uint32 {
+	var lowest uint32
+	for i, node := range this.Nodes {
+		if node.ledger == nil {
+			return 0
+		}
+		if height := node.ledger.GetCurrentBlockHeight(); i == 0 || height < lowest {
+			lowest = height
+		}
+	}
+	return lowest
+}
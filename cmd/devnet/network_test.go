@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package devnet
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetwork(t *testing.T) {
+	if testing.Short() {
+		t.Skip("devnet seals a block every few seconds")
+	}
+	dir, err := ioutil.TempDir("", "devnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	network, err := NewNetwork(dir, 1000, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, network.Created)
+	assert.Equal(t, 4, len(network.Nodes))
+	if err := network.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer network.Stop()
+
+	if err := network.WaitHeight(2, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range network.Nodes[1:] {
+		assert.Equal(t, network.Nodes[0].Ledger().GetBlockHash(2), node.Ledger().GetBlockHash(2))
+	}
+
+	err = network.RegisterSideChains([]*side_chain_manager.RegisterSideChainParam{{
+		ChainId:      8,
+		Router:       utils.ETH_ROUTER,
+		Name:         "devnet-8",
+		BlocksToWait: 1,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := network.WaitHeight(network.Nodes[0].Ledger().GetCurrentBlockHeight(), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range network.Nodes {
+		data, err := node.Ledger().GetStorageItem(utils.SideChainManagerContractAddress,
+			append([]byte(side_chain_manager.SIDE_CHAIN), utils.GetUint64Bytes(8)...))
+		if err != nil {
+			t.Fatalf("side chain of validator %d: %s", node.Index, err)
+		}
+		sideChain := new(side_chain_manager.SideChain)
+		if err := sideChain.Deserialization(common.NewZeroCopySource(data)); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "devnet-8", sideChain.Name)
+		assert.Equal(t, utils.ETH_ROUTER, sideChain.Router)
+	}
+
+	relayer := network.Nodes[3].Account.Address
+	if err := network.RegisterRelayers([]common.Address{relayer}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := network.Nodes[0].Ledger().GetStorageItem(utils.RelayerManagerContractAddress,
+		append([]byte(relayer_manager.RELAYER), relayer[:]...))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, data)
+}
+
+func TestReopenNetwork(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	network, err := NewNetwork(dir, 1000, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := NewNetwork(dir, 1000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, reopened.Created)
+	assert.Equal(t, len(network.Nodes), len(reopened.Nodes))
+	for i, node := range network.Nodes {
+		assert.Equal(t, node.Account.Address, reopened.Nodes[i].Account.Address)
+	}
+	_, err = NewNetwork(dir, 1000, 7)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package devnet
+
+import (
+	"sync"
+
+	"github.com/ontio/ontology-eventbus/actor"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/ledger"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/core/validation"
+	ontErrors "github.com/polynetwork/poly/errors"
+	tc "github.com/polynetwork/poly/txnpool/common"
+)
+
+// txPool keeps the pending txs of the network. The validators propose from the same pool,
+// each of them skips the txs already saved in its ledger
+type txPool struct {
+	sync.RWMutex
+	txs     []*types.Transaction
+	ledgers []*ledger.Ledger
+}
+
+func newTxPool() *txPool {
+	return &txPool{}
+}
+
+func (this *txPool) addLedger(ld *ledger.Ledger) {
+	this.Lock()
+	defer this.Unlock()
+	this.ledgers = append(this.ledgers, ld)
+}
+
+// append adds a verified tx to the pool, ld is the ledger of the validator receiving the tx
+func (this *txPool) append(tx *types.Transaction, ld *ledger.Ledger) ontErrors.ErrCode {
+	if errCode := validation.VerifyTransaction(tx); errCode != ontErrors.ErrNoError {
+		return errCode
+	}
+	if exist, _ := ld.IsContainTransaction(tx.Hash()); exist {
+		return ontErrors.ErrDuplicatedTx
+	}
+	this.Lock()
+	defer this.Unlock()
+	for _, pending := range this.txs {
+		if pending.Hash() == tx.Hash() {
+			return ontErrors.ErrDuplicatedTx
+		}
+	}
+	this.txs = append(this.txs, tx)
+	return ontErrors.ErrNoError
+}
+
+// pending returns the txs not saved in ld, txs saved by every validator are dropped
+func (this *txPool) pending(ld *ledger.Ledger) []*types.Transaction {
+	this.Lock()
+	defer this.Unlock()
+	txs := make([]*types.Transaction, 0, len(this.txs))
+	kept := this.txs[:0]
+	for _, tx := range this.txs {
+		hash := tx.Hash()
+		if exist, _ := ld.IsContainTransaction(hash); !exist {
+			txs = append(txs, tx)
+		}
+		if !this.savedByAll(hash) {
+			kept = append(kept, tx)
+		}
+	}
+	this.txs = kept
+	return txs
+}
+
+func (this *txPool) savedByAll(hash common.Uint256) bool {
+	for _, ld := range this.ledgers {
+		if exist, _ := ld.IsContainTransaction(hash); !exist {
+			return false
+		}
+	}
+	return true
+}
+
+func (this *txPool) get(hash common.Uint256) *types.Transaction {
+	this.RLock()
+	defer this.RUnlock()
+	for _, tx := range this.txs {
+		if tx.Hash() == hash {
+			return tx
+		}
+	}
+	return nil
+}
+
+func (this *txPool) count() int {
+	this.RLock()
+	defer this.RUnlock()
+	return len(this.txs)
+}
+
+// poolActor takes the place of the tx pool actor of a validator
+type poolActor struct {
+	txs    *txPool
+	ledger *ledger.Ledger
+}
+
+func (this *poolActor) Receive(ctx actor.Context) {
+	switch msg := ctx.Message().(type) {
+	case *tc.TxReq:
+		errCode := this.txs.append(msg.Tx, this.ledger)
+		if msg.TxResultCh != nil {
+			msg.TxResultCh <- &tc.TxResult{Err: errCode, Hash: msg.Tx.Hash(), Desc: errCode.Error()}
+		}
+	case *tc.GetTxnPoolReq:
+		txs := this.txs.pending(this.ledger)
+		entries := make([]*tc.TXEntry, 0, len(txs))
+		for _, tx := range txs {
+			entries = append(entries, &tc.TXEntry{Tx: tx})
+		}
+		ctx.Respond(&tc.GetTxnPoolRsp{TxnPool: entries})
+	case *tc.VerifyBlockReq:
+		results := make([]*tc.VerifyTxResult, 0, len(msg.Txs))
+		for _, tx := range msg.Txs {
+			errCode := validation.VerifyTransaction(tx)
+			if exist, _ := this.ledger.IsContainTransaction(tx.Hash()); exist {
+				errCode = ontErrors.ErrDuplicatedTx
+			}
+			results = append(results, &tc.VerifyTxResult{Height: msg.Height, Tx: tx, ErrCode: errCode})
+		}
+		ctx.Respond(&tc.VerifyBlockRsp{TxnPool: results})
+	case *tc.GetTxnReq:
+		ctx.Respond(&tc.GetTxnRsp{Txn: this.txs.get(msg.Hash)})
+	case *tc.GetTxnStatusReq:
+		ctx.Respond(&tc.GetTxnStatusRsp{Hash: msg.Hash})
+	case *tc.GetTxnCountReq:
+		ctx.Respond(&tc.GetTxnCountRsp{Count: []uint32{uint32(this.txs.count())}})
+	}
+}
@@ -0,0 +1,179 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/polynetwork/poly/cmd/devnet"
+	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/core/ledger"
+	hserver "github.com/polynetwork/poly/http/base/actor"
+	"github.com/polynetwork/poly/http/jsonrpc"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/urfave/cli"
+)
+
+var DevnetCommand = cli.Command{
+	Action:    startDevnet,
+	Name:      "devnet",
+	Usage:     "Run a local multi-validator VBFT network",
+	ArgsUsage: "[command options]",
+	Description: `Generate the validator wallets and the VBFT genesis of a local network in the devnet directory, run every
+validator in this process over in-memory links and register the given side chains and relayers when the network is
+created. The first validator serves the json rpc. Wallets and ledgers are kept in the directory, so running the command
+again restarts the same network. Remove the directory to start over.`,
+	Flags: []cli.Flag{
+		utils.DevnetNodeNumFlag,
+		utils.DevnetDirFlag,
+		utils.DevnetNetworkIdFlag,
+		utils.DevnetRPCPortFlag,
+		utils.DevnetSideChainFlag,
+		utils.DevnetRelayerFlag,
+		utils.LogLevelFlag,
+	},
+}
+
+const devnetStartTimeout = 2 * time.Minute
+
+func startDevnet(ctx *cli.Context) error {
+	sideChains, err := parseDevnetSideChains(ctx.StringSlice(utils.GetFlagName(utils.DevnetSideChainFlag)))
+	if err != nil {
+		return err
+	}
+	relayers, err := parseDevnetRelayers(ctx.StringSlice(utils.GetFlagName(utils.DevnetRelayerFlag)))
+	if err != nil {
+		return err
+	}
+	dir := ctx.String(utils.GetFlagName(utils.DevnetDirFlag))
+	networkId := uint32(ctx.Uint(utils.GetFlagName(utils.DevnetNetworkIdFlag)))
+	num := int(ctx.Uint(utils.GetFlagName(utils.DevnetNodeNumFlag)))
+	if !ctx.IsSet(utils.GetFlagName(utils.DevnetNodeNumFlag)) && common.FileExisted(filepath.Join(dir, devnet.GenesisFile)) {
+		// an existing devnet keeps its validators
+		num = 0
+	}
+	network, err := devnet.NewNetwork(dir, networkId, num)
+	if err != nil {
+		return err
+	}
+	// the validators log into the devnet directory, keeping the console for the devnet messages
+	log.InitLog(int(ctx.Uint(utils.GetFlagName(utils.LogLevelFlag))),
+		filepath.Join(network.Dir, log.PATH)+string(os.PathSeparator))
+	if err := network.Start(); err != nil {
+		return err
+	}
+	defer network.Stop()
+
+	entry := network.Nodes[0]
+	ledger.DefLedger = entry.Ledger()
+	hserver.SetTxnPoolPid(entry.TxPool())
+	hserver.SetTxPid(entry.TxPool())
+	hserver.SetConsensusMonitor(entry.Server())
+	config.DefConfig.Rpc.HttpJsonPort = ctx.Uint(utils.GetFlagName(utils.DevnetRPCPortFlag))
+	exit := make(chan error, 1)
+	go func() {
+		exit <- jsonrpc.StartRPCServer()
+	}()
+
+	PrintInfoMsg("Devnet %d started in %s, rpc http://127.0.0.1:%d", networkId, network.Dir,
+		config.DefConfig.Rpc.HttpJsonPort)
+	for _, node := range network.Nodes {
+		PrintInfoMsg("  Validator %d: address %s", node.Index, node.Account.Address.ToBase58())
+	}
+
+	if network.Created && (len(sideChains) > 0 || len(relayers) > 0) {
+		if err := network.WaitHeight(1, devnetStartTimeout); err != nil {
+			return err
+		}
+		if err := network.RegisterSideChains(sideChains); err != nil {
+			return err
+		}
+		for _, param := range sideChains {
+			PrintInfoMsg("Side chain %d (%s) registered", param.ChainId, param.Name)
+		}
+		if err := network.RegisterRelayers(relayers); err != nil {
+			return err
+		}
+		if len(relayers) > 0 {
+			PrintInfoMsg("%d relayers registered", len(relayers))
+		}
+	}
+
+	PrintInfoMsg("Devnet is running, press Ctrl+C to stop.")
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	select {
+	case <-sc:
+		return nil
+	case err := <-exit:
+		return fmt.Errorf("devnet rpc server exited: %v", err)
+	}
+}
+
+// parseDevnetSideChains parses side chains in the format of chainId:router[:name]
+func parseDevnetSideChains(values []string) ([]*side_chain_manager.RegisterSideChainParam, error) {
+	params := make([]*side_chain_manager.RegisterSideChainParam, 0, len(values))
+	for _, value := range values {
+		fields := strings.SplitN(value, ":", 3)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid side chain %s, chainId:router[:name] expected", value)
+		}
+		chainId, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain id of side chain %s: %s", value, err)
+		}
+		router, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid router of side chain %s: %s", value, err)
+		}
+		name := fmt.Sprintf("devnet-%d", chainId)
+		if len(fields) == 3 && fields[2] != "" {
+			name = fields[2]
+		}
+		params = append(params, &side_chain_manager.RegisterSideChainParam{
+			ChainId:      chainId,
+			Router:       router,
+			Name:         name,
+			BlocksToWait: 1,
+		})
+	}
+	return params, nil
+}
+
+func parseDevnetRelayers(values []string) ([]common.Address, error) {
+	relayers := make([]common.Address, 0, len(values))
+	for _, value := range values {
+		addr, err := common.AddressFromBase58(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid relayer address %s: %s", value, err)
+		}
+		relayers = append(relayers, addr)
+	}
+	return relayers, nil
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/ledger"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/urfave/cli"
+)
+
+var SnapshotCommand = cli.Command{
+	Action:    cli.ShowSubcommandHelp,
+	Name:      "snapshot",
+	Usage:     "Export or import the state snapshot of ledger",
+	ArgsUsage: "[arguments...]",
+	Description: `A snapshot holds the block hashes, the latest blocks and all the states at a height.
+A new node imports a snapshot instead of executing all blocks from genesis. Node should be stopped while running these commands.`,
+	Subcommands: []cli.Command{
+		{
+			Action:    exportSnapshot,
+			Name:      "export",
+			Usage:     "Export the snapshot of current block",
+			ArgsUsage: "[sub-command options]",
+			Flags: []cli.Flag{
+				utils.SnapshotDirFlag,
+				utils.DataDirFlag,
+				utils.ConfigFlag,
+				utils.NetworkIdFlag,
+			},
+		},
+		{
+			Action:    importSnapshot,
+			Name:      "import",
+			Usage:     "Import a snapshot to an empty ledger",
+			ArgsUsage: "[sub-command options]",
+			Flags: []cli.Flag{
+				utils.SnapshotDirFlag,
+				utils.SnapshotTrustedStateHashFlag,
+				utils.DataDirFlag,
+				utils.ConfigFlag,
+				utils.NetworkIdFlag,
+			},
+		},
+	},
+}
+
+func openSnapshotLedger(ctx *cli.Context) (string, error) {
+	log.InitLog(log.InfoLog)
+	cfg, err := SetOntologyConfig(ctx)
+	if err != nil {
+		return "", fmt.Errorf("SetOntologyConfig error:%s", err)
+	}
+	dbDir := utils.GetStoreDirPath(cfg.Common.DataDir, cfg.P2PNode.NetworkName)
+	ledger.DefLedger, err = ledger.NewLedger(dbDir)
+	if err != nil {
+		return "", fmt.Errorf("NewLedger error:%s", err)
+	}
+	return dbDir, nil
+}
+
+func initSnapshotLedger() error {
+	bookKeepers, err := config.DefConfig.GetBookkeepers()
+	if err != nil {
+		return fmt.Errorf("GetBookkeepers error:%s", err)
+	}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookKeepers, config.DefConfig.Genesis)
+	if err != nil {
+		return fmt.Errorf("BuildGenesisBlock error %s", err)
+	}
+	err = ledger.DefLedger.Init(bookKeepers, genesisBlock)
+	if err != nil {
+		return fmt.Errorf("init ledger error:%s", err)
+	}
+	return nil
+}
+
+func exportSnapshot(ctx *cli.Context) error {
+	dbDir, err := openSnapshotLedger(ctx)
+	if err != nil {
+		return err
+	}
+	defer ledger.DefLedger.Close()
+	if err = initSnapshotLedger(); err != nil {
+		return err
+	}
+	dir := ctx.String(utils.GetFlagName(utils.SnapshotDirFlag))
+	if dir == "" {
+		dir = scom.GetSnapshotDir(filepath.Join(dbDir, scom.SNAPSHOT_BASE_DIR), ledger.DefLedger.GetCurrentBlockHeight())
+	}
+	PrintInfoMsg("Start export snapshot.")
+	manifest, err := ledger.DefLedger.ExportSnapshot(dir)
+	if err != nil {
+		return fmt.Errorf("ExportSnapshot error:%s", err)
+	}
+	manifestHash := manifest.Hash()
+	PrintInfoMsg("Export snapshot successfully.")
+	PrintInfoMsg("Height:%d", manifest.Height)
+	PrintInfoMsg("BlockHash:%s", manifest.BlockHash.ToHexString())
+	PrintInfoMsg("StateHash:%s", manifest.StateHash.ToHexString())
+	PrintInfoMsg("ManifestHash:%s", manifestHash.ToHexString())
+	PrintInfoMsg("Snapshot dir:%s", dir)
+	return nil
+}
+
+func importSnapshot(ctx *cli.Context) error {
+	dir := ctx.String(utils.GetFlagName(utils.SnapshotDirFlag))
+	if dir == "" {
+		PrintErrorMsg("Missing %s argument.", utils.SnapshotDirFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	hash := ctx.String(utils.GetFlagName(utils.SnapshotTrustedStateHashFlag))
+	if hash == "" {
+		PrintErrorMsg("Missing %s argument.", utils.SnapshotTrustedStateHashFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	trustedStateHash, err := common.Uint256FromHexString(hash)
+	if err != nil {
+		return fmt.Errorf("invalid trusted state hash:%s", err)
+	}
+	_, err = openSnapshotLedger(ctx)
+	if err != nil {
+		return err
+	}
+	defer ledger.DefLedger.Close()
+
+	PrintInfoMsg("Start import snapshot.")
+	manifest, err := ledger.DefLedger.ImportSnapshot(dir, trustedStateHash)
+	if err != nil {
+		return fmt.Errorf("ImportSnapshot error:%s", err)
+	}
+	if err = initSnapshotLedger(); err != nil {
+		return err
+	}
+	PrintInfoMsg("Import snapshot completed, current block height:%d, hash:%s.", manifest.Height, manifest.BlockHash.ToHexString())
+	return nil
+}
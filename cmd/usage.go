@@ -101,6 +101,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.LogLevelFlag,
 			utils.DisableEventLogFlag,
 			utils.DataDirFlag,
+			utils.PruneKeepBlocksFlag,
 		},
 	},
 	{
@@ -154,6 +155,9 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.MaxConnInBoundFlag,
 			utils.MaxConnOutBoundFlag,
 			utils.MaxConnInBoundForSingleIPFlag,
+			utils.SeedAnnounceFlag,
+			utils.SeedPubKeysFlag,
+			utils.RequireSignedVersionFlag,
 		},
 	},
 	{
@@ -245,6 +249,26 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.ImportEndHeightFlag,
 		},
 	},
+	{
+		Name: "SNAPSHOT",
+		Flags: []cli.Flag{
+			utils.SnapshotDirFlag,
+			utils.SnapshotTrustedStateHashFlag,
+			utils.SnapshotSyncHeightFlag,
+			utils.SnapshotSyncStateHashFlag,
+		},
+	},
+	{
+		Name: "DEVNET",
+		Flags: []cli.Flag{
+			utils.DevnetNodeNumFlag,
+			utils.DevnetDirFlag,
+			utils.DevnetNetworkIdFlag,
+			utils.DevnetRPCPortFlag,
+			utils.DevnetSideChainFlag,
+			utils.DevnetRelayerFlag,
+		},
+	},
 	{
 		Name: "MISC",
 	},
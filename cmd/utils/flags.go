@@ -30,6 +30,10 @@ const (
 	DEFAULT_ABI_PATH      = "./abi"
 	DEFAULT_EXPORT_HEIGHT = 0
 	DEFAULT_WALLET_PATH   = "./wallet_data"
+
+	DEFAULT_DEVNET_DIR        = "./devnet"
+	DEFAULT_DEVNET_NETWORK_ID = 100
+	DEFAULT_DEVNET_RPC_PORT   = 30000
 )
 
 var (
@@ -67,6 +71,11 @@ var (
 		Usage: "Block data storage `<path>`",
 		Value: config.DEFAULT_DATA_DIR,
 	}
+	PruneKeepBlocksFlag = cli.UintFlag{
+		Name:  "prune-keep-blocks",
+		Usage: "Keep block bodies, events and cross states of the latest `<number>` blocks only, 0 keeps all. The minimum is 1024",
+		Value: config.DEFAULT_PRUNE_KEEP_BLOCKS,
+	}
 
 	//Consensus setting
 	EnableConsensusFlag = cli.BoolFlag{
@@ -139,6 +148,18 @@ var (
 		Usage: "Max connection `<number>` in bound for single ip",
 		Value: config.DEFAULT_MAX_CONN_IN_BOUND_FOR_SINGLE_IP,
 	}
+	SeedAnnounceFlag = cli.StringFlag{
+		Name:  "seed-announce",
+		Usage: "Announce the node as a seed with the public `<host:port>`. Announcements are signed with the node key",
+	}
+	SeedPubKeysFlag = cli.StringFlag{
+		Name:  "seed-pubkeys",
+		Usage: "Trust the seed announcements signed with the comma separated hex node `<keys>`",
+	}
+	RequireSignedVersionFlag = cli.BoolFlag{
+		Name:  "require-signed-version",
+		Usage: "Reject the peers not signing their versions with their node keys.",
+	}
 	// RPC settings
 	RPCDisabledFlag = cli.BoolFlag{
 		Name:  "disable-rpc",
@@ -434,6 +455,54 @@ var (
 		Value: "m",
 	}
 
+	//Snapshot setting
+	SnapshotDirFlag = cli.StringFlag{
+		Name:  "snapshot-dir",
+		Usage: "Snapshot directory `<path>`, default is snapshot/<height> of the ledger data dir",
+	}
+	SnapshotTrustedStateHashFlag = cli.StringFlag{
+		Name:  "trusted-state-hash",
+		Usage: "State `<hash>` of the snapshot printed by the export on a trusted node, required by import",
+	}
+	SnapshotSyncHeightFlag = cli.UintFlag{
+		Name:  "snapshot-sync-height",
+		Usage: "Download the snapshot at `<height>` from peers, used with --snapshot-sync-state-hash",
+	}
+	SnapshotSyncStateHashFlag = cli.StringFlag{
+		Name:  "snapshot-sync-state-hash",
+		Usage: "Download the snapshot of the trusted state `<hash>` from peers to the snapshot-download dir",
+	}
+
+	//Devnet setting
+	DevnetNodeNumFlag = cli.UintFlag{
+		Name:  "nodes",
+		Usage: "Number of devnet validators `<number>`",
+		Value: config.VBFT_MIN_NODE_NUM,
+	}
+	DevnetDirFlag = cli.StringFlag{
+		Name:  "dir",
+		Usage: "Devnet directory `<path>` holding genesis, wallets and ledgers of the validators",
+		Value: DEFAULT_DEVNET_DIR,
+	}
+	DevnetNetworkIdFlag = cli.UintFlag{
+		Name:  "networkid",
+		Usage: "Devnet network `<id>`",
+		Value: DEFAULT_DEVNET_NETWORK_ID,
+	}
+	DevnetRPCPortFlag = cli.UintFlag{
+		Name:  "rpcport",
+		Usage: "Json rpc port `<number>` of the devnet, served by the first validator",
+		Value: DEFAULT_DEVNET_RPC_PORT,
+	}
+	DevnetSideChainFlag = cli.StringSliceFlag{
+		Name:  "sidechain",
+		Usage: "Side chain `<chainId:router:name>` registered when the devnet is created, repeatable",
+	}
+	DevnetRelayerFlag = cli.StringSliceFlag{
+		Name:  "relayer",
+		Usage: "Relayer `<address>` registered when the devnet is created, repeatable",
+	}
+
 	//PreExecute switcher
 	TxpoolPreExecDisableFlag = cli.BoolFlag{
 		Name:  "disable-tx-pool-pre-exec",
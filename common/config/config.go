@@ -67,6 +67,8 @@ const (
 	DEFUALT_CLI_RPC_ADDRESS                 = "127.0.0.1"
 	DEFAULT_GAS_LIMIT                       = 20000
 	DEFAULT_GAS_PRICE                       = 500
+	DEFAULT_PRUNE_KEEP_BLOCKS               = uint(0)
+	MIN_PRUNE_KEEP_BLOCKS                   = uint(1024)
 
 	DEFAULT_DATA_DIR      = "./Chain"
 	DEFAULT_RESERVED_FILE = "./peers.rsv"
@@ -109,6 +111,45 @@ var ETH1559_HEIGHT = map[uint32]uint64{
 	NETWORK_ID_TEST_NET: constants.ETH1559_HEIGHT_TESTNET,
 }
 
+// Feature is a protocol change activated from a scheduled height of each network
+type Feature string
+
+const (
+	FEATURE_GAS_METERING        Feature = "GasMetering"        //transactions are limited by their gas limit and charged
+	FEATURE_SIDE_HEADER_PRUNE   Feature = "SideHeaderPrune"    //superseded side chain headers are pruned by header sync
+	FEATURE_BATCH_IMPORT        Feature = "BatchImport"        //cross chain txs could be imported in batch
+	FEATURE_FINALITY_POLICY     Feature = "FinalityPolicy"     //side chains could set their finality policy
+	FEATURE_CROSS_CHAIN_PAUSE   Feature = "CrossChainPause"    //cross chain routes could be paused
+	FEATURE_EQUIVOCATION_SLASH  Feature = "EquivocationSlash"  //consensus peers could be slashed with equivocation evidence
+	FEATURE_GOVERNANCE_QUERY    Feature = "GovernanceQuery"    //governance contracts could be enumerated
+	FEATURE_GOVERNANCE_PROPOSAL Feature = "GovernanceProposal" //consensus approvals are tracked as governance proposals
+	FEATURE_RELAYER_QUOTA       Feature = "RelayerQuota"       //relayer submissions are limited by quotas
+	FEATURE_RELAYER_REWARD      Feature = "RelayerReward"      //relayers are credited for their work
+	FEATURE_TX_EXPIRY           Feature = "TxExpiry"           //transactions of version 1 with a validity window are accepted
+)
+
+// ActivationHeight is the height a feature activates from on the main net, the test net
+// and the other networks
+type ActivationHeight struct {
+	MainNet uint32
+	TestNet uint32
+	Other   uint32
+}
+
+var ACTIVATION_HEIGHTS = map[Feature]ActivationHeight{
+	FEATURE_GAS_METERING:        {constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT},
+	FEATURE_SIDE_HEADER_PRUNE:   {constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT},
+	FEATURE_BATCH_IMPORT:        {constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT},
+	FEATURE_FINALITY_POLICY:     {constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT},
+	FEATURE_CROSS_CHAIN_PAUSE:   {constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT},
+	FEATURE_EQUIVOCATION_SLASH:  {constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT},
+	FEATURE_GOVERNANCE_QUERY:    {constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT},
+	FEATURE_GOVERNANCE_PROPOSAL: {constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT},
+	FEATURE_RELAYER_QUOTA:       {constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT},
+	FEATURE_RELAYER_REWARD:      {constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT},
+	FEATURE_TX_EXPIRY:           {constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT, constants.UNSCHEDULED_HEIGHT},
+}
+
 var POLYGON_SNAP_CHAINID = map[uint32]uint32{
 	NETWORK_ID_MAIN_NET: constants.POLYGON_SNAP_CHAINID_MAINNET,
 }
@@ -142,6 +183,28 @@ func GetExtraInfoHeight(id uint32) uint32 {
 	return EXTRA_INFO_HEIGHT[id]
 }
 
+// GetActivationHeight returns the height from which the feature is active on the network.
+// Networks other than the main net and the test net only activate the features scheduled
+// in their genesis config
+func GetActivationHeight(feature Feature, id uint32) uint32 {
+	heights, ok := ACTIVATION_HEIGHTS[feature]
+	if !ok {
+		return constants.UNSCHEDULED_HEIGHT
+	}
+	switch id {
+	case NETWORK_ID_MAIN_NET:
+		return heights.MainNet
+	case NETWORK_ID_TEST_NET:
+		return heights.TestNet
+	}
+	if DefConfig.Genesis != nil {
+		if height, ok := DefConfig.Genesis.ActivationHeights[string(feature)]; ok {
+			return height
+		}
+	}
+	return heights.Other
+}
+
 func GetNetworkName(id uint32) string {
 	name, ok := NETWORK_NAME[id]
 	if ok {
@@ -261,11 +324,12 @@ var MainNetConfig = &GenesisConfig{
 var DefConfig = NewOntologyConfig()
 
 type GenesisConfig struct {
-	SeedList      []string
-	ConsensusType string
-	VBFT          *VBFTConfig
-	DBFT          *DBFTConfig
-	SOLO          *SOLOConfig
+	SeedList          []string
+	ConsensusType     string
+	VBFT              *VBFTConfig
+	DBFT              *DBFTConfig
+	SOLO              *SOLOConfig
+	ActivationHeights map[string]uint32 //Feature => activation height, for networks other than main net and test net
 }
 
 func NewGenesisConfig() *GenesisConfig {
@@ -404,13 +468,14 @@ type SOLOConfig struct {
 }
 
 type CommonConfig struct {
-	LogLevel       uint
-	NodeType       string
-	EnableEventLog bool
-	SystemFee      map[string]int64
-	GasLimit       uint64
-	GasPrice       uint64
-	DataDir        string
+	LogLevel        uint
+	NodeType        string
+	EnableEventLog  bool
+	SystemFee       map[string]int64
+	GasLimit        uint64
+	GasPrice        uint64
+	DataDir         string
+	PruneKeepBlocks uint
 }
 
 type ConsensusConfig struct {
@@ -441,6 +506,11 @@ type P2PNodeConfig struct {
 	MaxConnInBound            uint
 	MaxConnOutBound           uint
 	MaxConnInBoundForSingleIP uint
+	SeedAnnounceAddr          string   //public address to announce as a seed node, empty for non seed nodes
+	SeedPubKeys               []string //hex encoded node keys of the trusted seed nodes
+	SnapshotSyncHeight        uint32   //height of the snapshot to download from peers
+	SnapshotSyncStateHash     string   //hex encoded trusted state hash of the snapshot to download, empty to disable
+	RequireSignedVersion      bool     //reject the unsigned versions of legacy peers
 }
 
 type RpcConfig struct {
@@ -478,11 +548,12 @@ func NewOntologyConfig() *OntologyConfig {
 	return &OntologyConfig{
 		Genesis: MainNetConfig,
 		Common: &CommonConfig{
-			LogLevel:       DEFAULT_LOG_LEVEL,
-			EnableEventLog: DEFAULT_ENABLE_EVENT_LOG,
-			SystemFee:      make(map[string]int64),
-			GasLimit:       DEFAULT_GAS_LIMIT,
-			DataDir:        DEFAULT_DATA_DIR,
+			LogLevel:        DEFAULT_LOG_LEVEL,
+			EnableEventLog:  DEFAULT_ENABLE_EVENT_LOG,
+			SystemFee:       make(map[string]int64),
+			GasLimit:        DEFAULT_GAS_LIMIT,
+			DataDir:         DEFAULT_DATA_DIR,
+			PruneKeepBlocks: DEFAULT_PRUNE_KEEP_BLOCKS,
 		},
 		Consensus: &ConsensusConfig{
 			EnableConsensus: true,
@@ -49,3 +49,7 @@ const ETH1559_HEIGHT_MAINNET = 12965000
 const ETH1559_HEIGHT_TESTNET = 10499401
 
 const POLYGON_SNAP_CHAINID_MAINNET = 16
+
+// activation height of the features not scheduled yet, see config.ACTIVATION_HEIGHTS
+// TODO: modify the activation heights when the features are scheduled
+const UNSCHEDULED_HEIGHT = 0xffffffff
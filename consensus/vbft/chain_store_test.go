@@ -20,6 +20,7 @@ package vbft
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"testing"
 
@@ -40,7 +41,12 @@ func newChainStore() *ChainStore {
 		os.Exit(1)
 	}
 
-	db, err := ledger.NewLedger(config.DEFAULT_DATA_DIR)
+	dataDir, err := ioutil.TempDir("", "vbft_chain_store")
+	if err != nil {
+		log.Fatalf("TempDir error %s", err)
+		os.Exit(1)
+	}
+	db, err := ledger.NewLedger(dataDir)
 	if err != nil {
 		log.Fatalf("NewLedger error %s", err)
 		os.Exit(1)
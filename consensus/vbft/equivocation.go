@@ -0,0 +1,311 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"encoding/hex"
+	"sync"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology-crypto/signature"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
+)
+
+const (
+	MAX_EVIDENCE_PER_BLOCK = 4    // max number of equivocation evidences packed into one proposal
+	MAX_EVIDENCE_PENDING   = 1024 // blocks to keep an evidence not reported on chain
+)
+
+// EquivocationPool keeps the block headers signed by each peer for recent rounds,
+// and builds evidences for peers signing more headers of one round than an honest peer does.
+type EquivocationPool struct {
+	lock       sync.Mutex
+	server     *Server
+	historyLen uint32
+
+	headers   map[uint32]map[common.Uint256]*types.Header              // block num -> proposed headers
+	sigs      map[uint32]map[uint32]map[common.Uint256][]byte          // block num -> peer -> header hash -> sig
+	evidences map[uint32]map[uint32]*node_manager.EquivocationEvidence // block num -> peer -> evidence
+}
+
+func newEquivocationPool(server *Server, historyLen uint32) *EquivocationPool {
+	return &EquivocationPool{
+		server:     server,
+		historyLen: historyLen,
+		headers:    make(map[uint32]map[common.Uint256]*types.Header),
+		sigs:       make(map[uint32]map[uint32]map[common.Uint256][]byte),
+		evidences:  make(map[uint32]map[uint32]*node_manager.EquivocationEvidence),
+	}
+}
+
+func (pool *EquivocationPool) AddMsg(msg ConsensusMsg) {
+	blkNum := msg.GetBlockNum()
+	if blkNum > pool.server.GetCurrentBlockNo()+pool.historyLen ||
+		blkNum+pool.historyLen < pool.server.GetCommittedBlockNo() {
+		return
+	}
+
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	// headers are checked against the previous block, peers of future blocks are checked once it is sealed
+	check := blkNum <= pool.server.GetCurrentBlockNo()
+	switch msg.Type() {
+	case BlockProposalMessage:
+		pMsg := msg.(*blockProposalMsg)
+		proposer := pMsg.Block.getProposer()
+		for _, blk := range []*types.Block{pMsg.Block.Block, pMsg.Block.EmptyBlock} {
+			if blk == nil || len(blk.Header.SigData) == 0 {
+				continue
+			}
+			hash := pool.addHeaderLocked(blk.Header)
+			pool.addSigLocked(blkNum, proposer, hash, blk.Header.SigData[0])
+		}
+		// headers of the proposal may complete evidences of other peers
+		if check {
+			for peerIdx := range pool.sigs[blkNum] {
+				pool.checkPeerLocked(blkNum, peerIdx)
+			}
+		}
+	case BlockEndorseMessage:
+		pMsg := msg.(*blockEndorseMsg)
+		pool.addSigLocked(blkNum, pMsg.Endorser, pMsg.EndorsedBlockHash, pMsg.EndorserSig)
+		if check {
+			pool.checkPeerLocked(blkNum, pMsg.Endorser)
+		}
+	case BlockCommitMessage:
+		pMsg := msg.(*blockCommitMsg)
+		pool.addSigLocked(blkNum, pMsg.Committer, pMsg.CommitBlockHash, pMsg.CommitterSig)
+		for endorser, sig := range pMsg.EndorsersSig {
+			pool.addSigLocked(blkNum, endorser, pMsg.CommitBlockHash, sig)
+		}
+		if check {
+			pool.checkPeerLocked(blkNum, pMsg.Committer)
+			for endorser := range pMsg.EndorsersSig {
+				pool.checkPeerLocked(blkNum, endorser)
+			}
+		}
+	}
+}
+
+func (pool *EquivocationPool) addHeaderLocked(header *types.Header) common.Uint256 {
+	hash := header.Hash()
+	if _, present := pool.headers[header.Height]; !present {
+		pool.headers[header.Height] = make(map[common.Uint256]*types.Header)
+	}
+	if _, present := pool.headers[header.Height][hash]; !present {
+		// signatures are kept per peer, strip them to keep evidences small
+		h := *header
+		h.Bookkeepers = nil
+		h.SigData = nil
+		pool.headers[header.Height][hash] = &h
+	}
+	return hash
+}
+
+func (pool *EquivocationPool) addSigLocked(blkNum, peerIdx uint32, hash common.Uint256, sigData []byte) {
+	peerSigs := pool.sigs[blkNum]
+	if peerSigs == nil {
+		peerSigs = make(map[uint32]map[common.Uint256][]byte)
+		pool.sigs[blkNum] = peerSigs
+	}
+	if _, present := peerSigs[peerIdx][hash]; present {
+		return
+	}
+
+	pk := pool.server.peerPool.GetPeerPubKey(peerIdx)
+	if pk == nil {
+		return
+	}
+	sig, err := signature.Deserialize(sigData)
+	if err != nil || !signature.Verify(pk, hash[:], sig) {
+		log.Errorf("equivocation pool: invalid sig of peer %d on block %d (%s)", peerIdx, blkNum, hash.ToHexString())
+		return
+	}
+	if peerSigs[peerIdx] == nil {
+		peerSigs[peerIdx] = make(map[common.Uint256][]byte)
+	}
+	peerSigs[peerIdx][hash] = sigData
+}
+
+func (pool *EquivocationPool) checkPeerLocked(blkNum, peerIdx uint32) {
+	if _, present := pool.evidences[blkNum][peerIdx]; present {
+		return
+	}
+	pk := pool.server.peerPool.GetPeerPubKey(peerIdx)
+	if pk == nil {
+		return
+	}
+
+	//headers of different proposers are never conflicting, group them by proposer
+	proposals := make(map[uint32][]*node_manager.SignedHeader)
+	for hash, sig := range pool.sigs[blkNum][peerIdx] {
+		header := pool.headers[blkNum][hash]
+		if header == nil {
+			continue
+		}
+		blkInfo, err := vconfig.VbftBlock(header)
+		if err != nil {
+			continue
+		}
+		proposals[blkInfo.Proposer] = append(proposals[blkInfo.Proposer],
+			&node_manager.SignedHeader{Header: header, Sig: sig})
+	}
+
+	chainID := config.GetChainIdByNetId(config.DefConfig.P2PNode.NetworkId)
+	evidence := pool.findEquivocation(hex.EncodeToString(keypair.SerializePublicKey(pk)), proposals, chainID)
+	if evidence == nil {
+		return
+	}
+
+	log.Warnf("server %d detected equivocation of peer %d on block %d, signed headers: %d",
+		pool.server.Index, peerIdx, blkNum, len(evidence.Headers))
+	if _, present := pool.evidences[blkNum]; !present {
+		pool.evidences[blkNum] = make(map[uint32]*node_manager.EquivocationEvidence)
+	}
+	pool.evidences[blkNum][peerIdx] = evidence
+}
+
+// findEquivocation returns the evidence of the first pair of conflicting headers of one proposer
+// signed by the peer, or nil if the peer signed none
+func (pool *EquivocationPool) findEquivocation(peerPubkey string,
+	proposals map[uint32][]*node_manager.SignedHeader, chainID uint64) *node_manager.EquivocationEvidence {
+	for _, headers := range proposals {
+		for i := 0; i < len(headers); i++ {
+			for j := i + 1; j < len(headers); j++ {
+				evidence := &node_manager.EquivocationEvidence{
+					PeerPubkey: peerPubkey,
+					Headers:    []*node_manager.SignedHeader{headers[i], headers[j]},
+				}
+				if _, err := node_manager.VerifyEquivocation(evidence, chainID, pool.getSealedBlockHash); err == nil {
+					return evidence
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (pool *EquivocationPool) getSealedBlockHash(blkNum uint32) common.Uint256 {
+	_, hash := pool.server.blockPool.getSealedBlock(blkNum)
+	return hash
+}
+
+// GetFaultyReports returns reports of peers proposing conflicting blocks of blkNum if proposal is true,
+// or of peers endorsing or committing conflicting blocks otherwise
+func (pool *EquivocationPool) GetFaultyReports(blkNum uint32, proposal bool) []*FaultyReport {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	reports := make([]*FaultyReport, 0)
+	for peerIdx, evidence := range pool.evidences[blkNum] {
+		blkInfo, err := vconfig.VbftBlock(evidence.Headers[0].Header)
+		if err != nil || (blkInfo.Proposer == peerIdx) != proposal {
+			continue
+		}
+		reports = append(reports, &FaultyReport{
+			FaultyID:      peerIdx,
+			FaultyMsgHash: evidence.Headers[0].Header.Hash(),
+		})
+	}
+	return reports
+}
+
+// GetEvidences returns at most maxCount evidences not yet reported on chain
+func (pool *EquivocationPool) GetEvidences(maxCount int) []*node_manager.EquivocationEvidence {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	evidences := make([]*node_manager.EquivocationEvidence, 0)
+	for _, peerEvidences := range pool.evidences {
+		for _, evidence := range peerEvidences {
+			if len(evidences) >= maxCount {
+				return evidences
+			}
+			evidences = append(evidences, evidence)
+		}
+	}
+	return evidences
+}
+
+func (pool *EquivocationPool) onBlockSealed(blk *types.Block) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	// drop evidences reported in the block
+	for _, tx := range blk.Transactions {
+		evidence := getReportedEquivocation(tx)
+		if evidence == nil {
+			continue
+		}
+		for blkNum, peerEvidences := range pool.evidences {
+			for peerIdx, e := range peerEvidences {
+				if e.PeerPubkey == evidence.PeerPubkey && evidence.Headers[0].Header.Height == blkNum {
+					delete(peerEvidences, peerIdx)
+				}
+			}
+		}
+	}
+
+	blockNum := blk.Header.Height
+	for peerIdx := range pool.sigs[blockNum+1] {
+		pool.checkPeerLocked(blockNum+1, peerIdx)
+	}
+	if blockNum <= pool.historyLen {
+		return
+	}
+	for n := range pool.sigs {
+		if n < blockNum-pool.historyLen {
+			delete(pool.headers, n)
+			delete(pool.sigs, n)
+		}
+	}
+	for n, peerEvidences := range pool.evidences {
+		if n+MAX_EVIDENCE_PENDING < blockNum || len(peerEvidences) == 0 {
+			delete(pool.evidences, n)
+		}
+	}
+}
+
+func getReportedEquivocation(tx *types.Transaction) *node_manager.EquivocationEvidence {
+	invoke, ok := tx.Payload.(*payload.InvokeCode)
+	if !ok {
+		return nil
+	}
+	param := new(states.ContractInvokeParam)
+	if err := param.Deserialization(common.NewZeroCopySource(invoke.Code)); err != nil {
+		return nil
+	}
+	if param.Address != utils.NodeManagerContractAddress || param.Method != node_manager.REPORT_EQUIVOCATION {
+		return nil
+	}
+	evidence := new(node_manager.EquivocationEvidence)
+	if err := evidence.Deserialization(common.NewZeroCopySource(param.Args)); err != nil || len(evidence.Headers) == 0 {
+		return nil
+	}
+	return evidence
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+)
+
+// constructEquivocationServer returns a server at block 10 with peers 1..n signing by accts
+func constructEquivocationServer(t *testing.T, n int) (*Server, []*account.Account) {
+	accts := make([]*account.Account, n+1)
+	peers := make(map[uint32]*Peer)
+	for i := 1; i <= n; i++ {
+		accts[i] = account.NewAccount("")
+		peers[uint32(i)] = &Peer{Index: uint32(i), PubKey: accts[i].PublicKey}
+	}
+	chainStore := &ChainStore{chainedBlockNum: 9}
+	server := &Server{
+		Index:           1,
+		currentBlockNum: 10,
+		chainStore:      chainStore,
+		peerPool: &PeerPool{
+			configs: make(map[uint32]*vconfig.PeerConfig),
+			IDMap:   make(map[string]uint32),
+			peers:   peers,
+		},
+	}
+	server.blockPool = &BlockPool{
+		server:          server,
+		chainStore:      chainStore,
+		candidateBlocks: make(map[uint32]*CandidateInfo),
+	}
+	sealEquivocationTestBlock(t, server, 9)
+	return server, accts
+}
+
+func sealEquivocationTestBlock(t *testing.T, server *Server, blkNum uint32) *types.Block {
+	blk := &types.Block{
+		Header: &types.Header{
+			ChainID:       config.GetChainIdByNetId(config.DefConfig.P2PNode.NetworkId),
+			PrevBlockHash: common.Uint256{byte(blkNum)},
+			Height:        blkNum,
+		},
+	}
+	server.blockPool.candidateBlocks[blkNum] = &CandidateInfo{SealedBlock: &Block{Block: blk}}
+	return blk
+}
+
+func signEquivocationTestHeader(t *testing.T, acct *account.Account, header *types.Header) *types.Header {
+	hash := header.Hash()
+	sig, err := signature.Sign(acct, hash[:])
+	if err != nil {
+		t.Fatalf("sign header: %s", err)
+	}
+	header.SigData = [][]byte{sig}
+	return header
+}
+
+func constructEquivocationProposal(t *testing.T, server *Server, acct *account.Account, proposer, blkNum, timestamp uint32) *blockProposalMsg {
+	info := &vconfig.VbftBlockInfo{Proposer: proposer}
+	payload, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("marshal block info: %s", err)
+	}
+	_, prevHash := server.blockPool.getSealedBlock(blkNum - 1)
+	newHeader := func(txRoot common.Uint256) *types.Header {
+		return signEquivocationTestHeader(t, acct, &types.Header{
+			ChainID:          config.GetChainIdByNetId(config.DefConfig.P2PNode.NetworkId),
+			PrevBlockHash:    prevHash,
+			TransactionsRoot: txRoot,
+			Timestamp:        timestamp,
+			Height:           blkNum,
+			ConsensusPayload: payload,
+		})
+	}
+	return &blockProposalMsg{
+		Block: &Block{
+			Block:      &types.Block{Header: newHeader(common.Uint256{1})},
+			EmptyBlock: &types.Block{Header: newHeader(common.Uint256{2})},
+			Info:       info,
+		},
+	}
+}
+
+func constructEquivocationEndorse(t *testing.T, acct *account.Account, endorser uint32, proposal *blockProposalMsg) *blockEndorseMsg {
+	hash := proposal.Block.Block.Hash()
+	sig, err := signature.Sign(acct, hash[:])
+	if err != nil {
+		t.Fatalf("sign endorsement: %s", err)
+	}
+	return &blockEndorseMsg{
+		Endorser:          endorser,
+		EndorsedProposer:  proposal.Block.getProposer(),
+		BlockNum:          proposal.GetBlockNum(),
+		EndorsedBlockHash: hash,
+		EndorserSig:       sig,
+	}
+}
+
+func TestEquivocationPoolProposals(t *testing.T) {
+	server, accts := constructEquivocationServer(t, 4)
+	pool := newEquivocationPool(server, 64)
+
+	pool.AddMsg(constructEquivocationProposal(t, server, accts[2], 2, 10, 100))
+	if evidences := pool.GetEvidences(MAX_EVIDENCE_PER_BLOCK); len(evidences) != 0 {
+		t.Fatalf("evidence of one proposal: %d", len(evidences))
+	}
+
+	pool.AddMsg(constructEquivocationProposal(t, server, accts[2], 2, 10, 101))
+	evidences := pool.GetEvidences(MAX_EVIDENCE_PER_BLOCK)
+	if len(evidences) != 1 {
+		t.Fatalf("evidences of two proposals: %d", len(evidences))
+	}
+	reports := pool.GetFaultyReports(10, true)
+	if len(reports) != 1 || reports[0].FaultyID != 2 {
+		t.Fatalf("faulty proposal reports: %v", reports)
+	}
+	if reports := pool.GetFaultyReports(10, false); len(reports) != 0 {
+		t.Fatalf("faulty verify reports: %v", reports)
+	}
+
+	// evidence is dropped once reported on chain
+	tx := server.createEquivocationTransaction(evidences[0], 11)
+	pool.onBlockSealed(&types.Block{Header: &types.Header{Height: 11}, Transactions: []*types.Transaction{tx}})
+	if evidences := pool.GetEvidences(MAX_EVIDENCE_PER_BLOCK); len(evidences) != 0 {
+		t.Fatalf("evidences after reported: %d", len(evidences))
+	}
+}
+
+func TestEquivocationPoolEndorsements(t *testing.T) {
+	server, accts := constructEquivocationServer(t, 4)
+	pool := newEquivocationPool(server, 64)
+
+	// endorser 3 endorses the proposal of proposer 2, then the proposals of proposers 4 and 1
+	// as the rounds of the height time out
+	for i, proposer := range []uint32{2, 4, 1} {
+		proposal := constructEquivocationProposal(t, server, accts[proposer], proposer, 10, 100+uint32(i))
+		pool.AddMsg(proposal)
+		pool.AddMsg(constructEquivocationEndorse(t, accts[3], 3, proposal))
+	}
+	if reports := pool.GetFaultyReports(10, false); len(reports) != 0 {
+		t.Fatalf("faulty verify reports of honest endorser: %v", reports)
+	}
+	if evidences := pool.GetEvidences(MAX_EVIDENCE_PER_BLOCK); len(evidences) != 0 {
+		t.Fatalf("evidences of honest endorser: %d", len(evidences))
+	}
+
+	// endorsing a conflicting proposal of proposer 4
+	proposal := constructEquivocationProposal(t, server, accts[4], 4, 10, 200)
+	pool.AddMsg(proposal)
+	pool.AddMsg(constructEquivocationEndorse(t, accts[3], 3, proposal))
+	reports := pool.GetFaultyReports(10, false)
+	if len(reports) != 1 || reports[0].FaultyID != 3 {
+		t.Fatalf("faulty verify reports: %v", reports)
+	}
+}
+
+func TestEquivocationPoolForkedHeaders(t *testing.T) {
+	server, accts := constructEquivocationServer(t, 4)
+	pool := newEquivocationPool(server, 64)
+
+	// proposals not following the sealed block can't be reported on chain
+	sealEquivocationTestBlock(t, server, 9).Header.PrevBlockHash = common.Uint256{0xff}
+	pool.AddMsg(constructEquivocationProposal(t, server, accts[2], 2, 10, 100))
+	sealEquivocationTestBlock(t, server, 9)
+	pool.AddMsg(constructEquivocationProposal(t, server, accts[2], 2, 10, 101))
+	if evidences := pool.GetEvidences(MAX_EVIDENCE_PER_BLOCK); len(evidences) != 0 {
+		t.Fatalf("evidences of forked headers: %d", len(evidences))
+	}
+}
+
+func TestEquivocationPoolFutureBlock(t *testing.T) {
+	server, accts := constructEquivocationServer(t, 4)
+	pool := newEquivocationPool(server, 64)
+
+	blk10 := sealEquivocationTestBlock(t, server, 10)
+	pool.AddMsg(constructEquivocationProposal(t, server, accts[2], 2, 11, 100))
+	pool.AddMsg(constructEquivocationProposal(t, server, accts[2], 2, 11, 101))
+	if evidences := pool.GetEvidences(MAX_EVIDENCE_PER_BLOCK); len(evidences) != 0 {
+		t.Fatalf("evidences before previous block sealed: %d", len(evidences))
+	}
+
+	pool.onBlockSealed(blk10)
+	if evidences := pool.GetEvidences(MAX_EVIDENCE_PER_BLOCK); len(evidences) != 1 {
+		t.Fatalf("evidences after previous block sealed: %d", len(evidences))
+	}
+}
@@ -0,0 +1,343 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+var serverStateNames = map[ServerState]string{
+	Init:             "init",
+	LocalConfigured:  "local_configured",
+	Configured:       "configured",
+	Syncing:          "syncing",
+	WaitNetworkReady: "wait_network_ready",
+	SyncReady:        "sync_ready",
+	Synced:           "synced",
+	SyncingCheck:     "syncing_check",
+}
+
+var timerEventNames = map[TimerEventType]string{
+	EventProposeBlockTimeout:      "propose_block_timeout",
+	EventProposalBackoff:          "proposal_backoff",
+	EventRandomBackoff:            "random_backoff",
+	EventPropose2ndBlockTimeout:   "propose_2nd_block_timeout",
+	EventEndorseBlockTimeout:      "endorse_block_timeout",
+	EventEndorseEmptyBlockTimeout: "endorse_empty_block_timeout",
+	EventCommitBlockTimeout:       "commit_block_timeout",
+	EventPeerHeartbeat:            "peer_heartbeat",
+	EventTxPool:                   "tx_pool",
+	EventTxBlockTimeout:           "tx_block_timeout",
+}
+
+// PeerStatus is the consensus status of a peer seen by this node
+type PeerStatus struct {
+	Index           uint32
+	Connected       bool
+	LastSeenHeight  uint32
+	LastSeenTime    int64
+	MissedProposals uint64
+}
+
+// ConsensusStatus is a snapshot of the consensus state of this node
+type ConsensusStatus struct {
+	State             string
+	Ready             bool
+	CurrentBlock      uint32
+	CommittedBlock    uint32
+	ChainConfigView   uint32
+	ViewChanges       uint64
+	Rounds            uint64
+	RoundStartTime    int64
+	LastRoundSeconds  float64
+	AvgRoundSeconds   float64
+	RoundProposals    uint32
+	RoundEndorsements uint32
+	RoundCommits      uint32
+	EmptyBlocks       uint64
+	MissedProposals   uint64
+	TimerExpiries     map[string]uint64
+	Peers             []PeerStatus
+}
+
+type consensusMetrics struct {
+	sync.RWMutex
+	state             ServerState
+	currentBlock      uint32
+	committedBlock    uint32
+	chainConfigView   uint32
+	viewChanges       uint64
+	roundStart        time.Time
+	rounds            uint64
+	roundSecondsSum   float64
+	lastRoundSeconds  float64
+	roundProposals    uint32
+	roundEndorsements uint32
+	roundCommits      uint32
+	emptyBlocks       uint64
+	missedProposals   uint64
+	timerExpiries     map[TimerEventType]uint64
+	peers             map[uint32]*PeerStatus
+}
+
+func newConsensusMetrics() *consensusMetrics {
+	return &consensusMetrics{
+		timerExpiries: make(map[TimerEventType]uint64),
+		peers:         make(map[uint32]*PeerStatus),
+	}
+}
+
+func (m *consensusMetrics) setState(state ServerState) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.state = state
+}
+
+func (m *consensusMetrics) setChainConfigView(view uint32) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.chainConfigView != 0 && m.chainConfigView != view {
+		m.viewChanges++
+	}
+	m.chainConfigView = view
+}
+
+func (m *consensusMetrics) newRound(blockNum uint32) {
+	m.Lock()
+	defer m.Unlock()
+
+	if blockNum == m.currentBlock && !m.roundStart.IsZero() {
+		// round restarted on the same block, keep its start time
+		return
+	}
+	m.currentBlock = blockNum
+	m.roundStart = time.Now()
+	m.roundProposals = 0
+	m.roundEndorsements = 0
+	m.roundCommits = 0
+}
+
+func (m *consensusMetrics) onRoundMsg(blockNum uint32, msgType MsgType) {
+	m.Lock()
+	defer m.Unlock()
+
+	if blockNum != m.currentBlock {
+		return
+	}
+	switch msgType {
+	case BlockProposalMessage:
+		m.roundProposals++
+	case BlockEndorseMessage:
+		m.roundEndorsements++
+	case BlockCommitMessage:
+		m.roundCommits++
+	}
+}
+
+func (m *consensusMetrics) onBlockSealed(blockNum uint32, empty bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	if empty {
+		m.emptyBlocks++
+	}
+	if blockNum > m.committedBlock {
+		m.committedBlock = blockNum
+	}
+	// blocks sealed by syncing have no round
+	if blockNum == m.currentBlock && !m.roundStart.IsZero() {
+		m.lastRoundSeconds = time.Since(m.roundStart).Seconds()
+		m.roundSecondsSum += m.lastRoundSeconds
+		m.rounds++
+		m.roundStart = time.Time{}
+	}
+}
+
+func (m *consensusMetrics) onTimerEvent(evtType TimerEventType) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.timerExpiries[evtType]++
+}
+
+func (m *consensusMetrics) onMissedProposal(peerIdx uint32) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.missedProposals++
+	m.getPeer(peerIdx).MissedProposals++
+}
+
+func (m *consensusMetrics) onPeerSeen(peerIdx uint32, committedBlockNum uint32) {
+	m.Lock()
+	defer m.Unlock()
+
+	p := m.getPeer(peerIdx)
+	p.Connected = true
+	p.LastSeenHeight = committedBlockNum
+	p.LastSeenTime = time.Now().Unix()
+}
+
+func (m *consensusMetrics) onPeerDisconnected(peerIdx uint32) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.getPeer(peerIdx).Connected = false
+}
+
+// getPeer should be called with m locked
+func (m *consensusMetrics) getPeer(peerIdx uint32) *PeerStatus {
+	p, present := m.peers[peerIdx]
+	if !present {
+		p = &PeerStatus{Index: peerIdx}
+		m.peers[peerIdx] = p
+	}
+	return p
+}
+
+func (m *consensusMetrics) snapshot() ConsensusStatus {
+	m.RLock()
+	defer m.RUnlock()
+
+	status := ConsensusStatus{
+		State:             serverStateNames[m.state],
+		Ready:             isReady(m.state),
+		CurrentBlock:      m.currentBlock,
+		CommittedBlock:    m.committedBlock,
+		ChainConfigView:   m.chainConfigView,
+		ViewChanges:       m.viewChanges,
+		Rounds:            m.rounds,
+		LastRoundSeconds:  m.lastRoundSeconds,
+		RoundProposals:    m.roundProposals,
+		RoundEndorsements: m.roundEndorsements,
+		RoundCommits:      m.roundCommits,
+		EmptyBlocks:       m.emptyBlocks,
+		MissedProposals:   m.missedProposals,
+		TimerExpiries:     make(map[string]uint64),
+		Peers:             make([]PeerStatus, 0, len(m.peers)),
+	}
+	if !m.roundStart.IsZero() {
+		status.RoundStartTime = m.roundStart.Unix()
+	}
+	if m.rounds > 0 {
+		status.AvgRoundSeconds = m.roundSecondsSum / float64(m.rounds)
+	}
+	for evtType, n := range m.timerExpiries {
+		status.TimerExpiries[timerEventNames[evtType]] = n
+	}
+	for _, p := range m.peers {
+		status.Peers = append(status.Peers, *p)
+	}
+	sort.Slice(status.Peers, func(i, j int) bool {
+		return status.Peers[i].Index < status.Peers[j].Index
+	})
+	return status
+}
+
+func (m *consensusMetrics) writeMetrics(out io.Writer) error {
+	status := m.snapshot()
+	m.RLock()
+	roundSecondsSum := m.roundSecondsSum
+	m.RUnlock()
+
+	w := new(bytes.Buffer)
+
+	boolValue := func(v bool) int {
+		if v {
+			return 1
+		}
+		return 0
+	}
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("vbft_ready", "gauge", "Whether the node is synced and processing consensus messages.")
+	fmt.Fprintf(w, "vbft_ready %d\n", boolValue(status.Ready))
+	metric("vbft_state", "gauge", "Consensus state of the node.")
+	for state := Init; state <= SyncingCheck; state++ {
+		name := serverStateNames[state]
+		fmt.Fprintf(w, "vbft_state{state=%q} %d\n", name, boolValue(name == status.State))
+	}
+	metric("vbft_current_block_height", "gauge", "Block number of the current consensus round.")
+	fmt.Fprintf(w, "vbft_current_block_height %d\n", status.CurrentBlock)
+	metric("vbft_committed_block_height", "gauge", "Block number last sealed by consensus.")
+	fmt.Fprintf(w, "vbft_committed_block_height %d\n", status.CommittedBlock)
+	metric("vbft_chain_config_view", "gauge", "View of the current chain config.")
+	fmt.Fprintf(w, "vbft_chain_config_view %d\n", status.ChainConfigView)
+	metric("vbft_view_changes_total", "counter", "Chain config view changes seen since start.")
+	fmt.Fprintf(w, "vbft_view_changes_total %d\n", status.ViewChanges)
+	metric("vbft_round_duration_seconds", "summary", "Time from the start of a round to its block being sealed.")
+	fmt.Fprintf(w, "vbft_round_duration_seconds_sum %g\n", roundSecondsSum)
+	fmt.Fprintf(w, "vbft_round_duration_seconds_count %d\n", status.Rounds)
+	metric("vbft_last_round_duration_seconds", "gauge", "Duration of the last completed round.")
+	fmt.Fprintf(w, "vbft_last_round_duration_seconds %g\n", status.LastRoundSeconds)
+	metric("vbft_round_messages", "gauge", "Consensus messages received in the current round.")
+	fmt.Fprintf(w, "vbft_round_messages{type=\"proposal\"} %d\n", status.RoundProposals)
+	fmt.Fprintf(w, "vbft_round_messages{type=\"endorsement\"} %d\n", status.RoundEndorsements)
+	fmt.Fprintf(w, "vbft_round_messages{type=\"commit\"} %d\n", status.RoundCommits)
+	metric("vbft_empty_blocks_total", "counter", "Empty blocks sealed since start.")
+	fmt.Fprintf(w, "vbft_empty_blocks_total %d\n", status.EmptyBlocks)
+	metric("vbft_missed_proposals_total", "counter", "Rounds whose leader proposal did not arrive before the proposal timeout.")
+	fmt.Fprintf(w, "vbft_missed_proposals_total %d\n", status.MissedProposals)
+	metric("vbft_timer_expiries_total", "counter", "Consensus timer expiries by event.")
+	names := make([]string, 0, len(status.TimerExpiries))
+	for name := range status.TimerExpiries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "vbft_timer_expiries_total{event=%q} %d\n", name, status.TimerExpiries[name])
+	}
+	metric("vbft_peer_connected", "gauge", "Whether the consensus peer is connected.")
+	for _, p := range status.Peers {
+		fmt.Fprintf(w, "vbft_peer_connected{peer=\"%d\"} %d\n", p.Index, boolValue(p.Connected))
+	}
+	metric("vbft_peer_last_seen_height", "gauge", "Committed block number last announced by the peer.")
+	for _, p := range status.Peers {
+		fmt.Fprintf(w, "vbft_peer_last_seen_height{peer=\"%d\"} %d\n", p.Index, p.LastSeenHeight)
+	}
+	metric("vbft_peer_last_seen_timestamp_seconds", "gauge", "Unix time the peer was last heard from.")
+	for _, p := range status.Peers {
+		fmt.Fprintf(w, "vbft_peer_last_seen_timestamp_seconds{peer=\"%d\"} %d\n", p.Index, p.LastSeenTime)
+	}
+	metric("vbft_peer_missed_proposals_total", "counter", "Leader proposals of the peer that did not arrive in time.")
+	for _, p := range status.Peers {
+		fmt.Fprintf(w, "vbft_peer_missed_proposals_total{peer=\"%d\"} %d\n", p.Index, p.MissedProposals)
+	}
+	_, err := out.Write(w.Bytes())
+	return err
+}
+
+// GetConsensusStatus returns the consensus status of this node
+func (self *Server) GetConsensusStatus() ConsensusStatus {
+	return self.metrics.snapshot()
+}
+
+// WriteMetrics writes the consensus metrics in prometheus text format
+func (self *Server) WriteMetrics(w io.Writer) error {
+	return self.metrics.writeMetrics(w)
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConsensusStatus(t *testing.T) {
+	server := &Server{metrics: newConsensusMetrics()}
+	m := server.metrics
+
+	m.setState(Syncing)
+	m.setChainConfigView(1)
+	m.setChainConfigView(1)
+	m.setChainConfigView(2)
+	m.newRound(10)
+	m.onRoundMsg(10, BlockProposalMessage)
+	m.onRoundMsg(10, BlockEndorseMessage)
+	m.onRoundMsg(10, BlockEndorseMessage)
+	m.onRoundMsg(10, BlockCommitMessage)
+	m.onRoundMsg(9, BlockCommitMessage)
+	m.onTimerEvent(EventProposeBlockTimeout)
+	m.onTimerEvent(EventProposeBlockTimeout)
+	m.onMissedProposal(2)
+	m.onPeerSeen(1, 9)
+	m.onPeerSeen(3, 8)
+	m.onPeerDisconnected(3)
+
+	status := server.GetConsensusStatus()
+	if status.State != "syncing" || status.Ready {
+		t.Fatalf("state %s, ready %t", status.State, status.Ready)
+	}
+	if status.CurrentBlock != 10 || status.ChainConfigView != 2 || status.ViewChanges != 1 {
+		t.Fatalf("current block %d, view %d, view changes %d", status.CurrentBlock, status.ChainConfigView, status.ViewChanges)
+	}
+	if status.RoundProposals != 1 || status.RoundEndorsements != 2 || status.RoundCommits != 1 {
+		t.Fatalf("round msgs: %d %d %d", status.RoundProposals, status.RoundEndorsements, status.RoundCommits)
+	}
+	if status.RoundStartTime == 0 || status.Rounds != 0 {
+		t.Fatalf("round start %d, rounds %d", status.RoundStartTime, status.Rounds)
+	}
+	if status.TimerExpiries["propose_block_timeout"] != 2 || status.MissedProposals != 1 {
+		t.Fatalf("timer expiries %v, missed proposals %d", status.TimerExpiries, status.MissedProposals)
+	}
+	if len(status.Peers) != 3 {
+		t.Fatalf("peers: %v", status.Peers)
+	}
+	if p := status.Peers[0]; p.Index != 1 || !p.Connected || p.LastSeenHeight != 9 || p.LastSeenTime == 0 {
+		t.Fatalf("peer 1: %+v", p)
+	}
+	if p := status.Peers[1]; p.Index != 2 || p.MissedProposals != 1 {
+		t.Fatalf("peer 2: %+v", p)
+	}
+	if p := status.Peers[2]; p.Index != 3 || p.Connected || p.LastSeenHeight != 8 {
+		t.Fatalf("peer 3: %+v", p)
+	}
+
+	// sealing the round block completes the round and starts counting the next one
+	m.setState(Synced)
+	m.onBlockSealed(10, true)
+	m.newRound(11)
+	m.onBlockSealed(5, false)
+	status = server.GetConsensusStatus()
+	if status.State != "synced" || !status.Ready {
+		t.Fatalf("state %s, ready %t", status.State, status.Ready)
+	}
+	if status.CommittedBlock != 10 || status.CurrentBlock != 11 || status.EmptyBlocks != 1 || status.Rounds != 1 {
+		t.Fatalf("committed %d, current %d, empty blocks %d, rounds %d", status.CommittedBlock, status.CurrentBlock,
+			status.EmptyBlocks, status.Rounds)
+	}
+	if status.RoundProposals != 0 || status.RoundEndorsements != 0 || status.RoundCommits != 0 {
+		t.Fatalf("round msgs of new round: %d %d %d", status.RoundProposals, status.RoundEndorsements, status.RoundCommits)
+	}
+	if status.LastRoundSeconds < 0 || status.AvgRoundSeconds != status.LastRoundSeconds {
+		t.Fatalf("last round %g, avg round %g", status.LastRoundSeconds, status.AvgRoundSeconds)
+	}
+
+	// servers keep their own metrics
+	if other := (&Server{metrics: newConsensusMetrics()}).GetConsensusStatus(); other.CurrentBlock != 0 || len(other.Peers) != 0 {
+		t.Fatalf("metrics shared between servers: %+v", other)
+	}
+}
+
+func TestConsensusMetricsOutput(t *testing.T) {
+	server := &Server{metrics: newConsensusMetrics()}
+	m := server.metrics
+	m.setState(Synced)
+	m.setChainConfigView(3)
+	m.newRound(7)
+	m.onRoundMsg(7, BlockEndorseMessage)
+	m.onBlockSealed(7, true)
+	m.onTimerEvent(EventEndorseBlockTimeout)
+	m.onTimerEvent(EventCommitBlockTimeout)
+	m.onMissedProposal(4)
+	m.onPeerSeen(2, 6)
+
+	buf := new(bytes.Buffer)
+	if err := server.WriteMetrics(buf); err != nil {
+		t.Fatalf("write metrics: %s", err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"# HELP vbft_ready Whether the node is synced and processing consensus messages.",
+		"# TYPE vbft_ready gauge",
+		"vbft_ready 1",
+		`vbft_state{state="synced"} 1`,
+		`vbft_state{state="syncing"} 0`,
+		"vbft_current_block_height 7",
+		"vbft_committed_block_height 7",
+		"vbft_chain_config_view 3",
+		"vbft_view_changes_total 0",
+		"# TYPE vbft_round_duration_seconds summary",
+		"vbft_round_duration_seconds_count 1",
+		`vbft_round_messages{type="endorsement"} 1`,
+		"vbft_empty_blocks_total 1",
+		"vbft_missed_proposals_total 1",
+		`vbft_timer_expiries_total{event="commit_block_timeout"} 1`,
+		`vbft_timer_expiries_total{event="endorse_block_timeout"} 1`,
+		`vbft_peer_connected{peer="2"} 1`,
+		`vbft_peer_connected{peer="4"} 0`,
+		`vbft_peer_last_seen_height{peer="2"} 6`,
+		`vbft_peer_missed_proposals_total{peer="4"} 1`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Fatalf("metrics miss %q:\n%s", line, out)
+		}
+	}
+	// timer expiries are sorted by event
+	if strings.Index(out, `event="commit_block_timeout"`) > strings.Index(out, `event="endorse_block_timeout"`) {
+		t.Fatalf("timer expiries not sorted:\n%s", out)
+	}
+	// every sample belongs to a described metric
+	described := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if strings.HasPrefix(line, "# TYPE ") {
+			described[strings.Fields(line)[2]] = true
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := strings.FieldsFunc(line, func(r rune) bool { return r == '{' || r == ' ' })[0]
+		name = strings.TrimSuffix(strings.TrimSuffix(name, "_sum"), "_count")
+		if !described[name] {
+			t.Fatalf("sample %q of undescribed metric", line)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/ontio/ontology-crypto/keypair"
@@ -29,18 +30,81 @@ import (
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
-	"github.com/polynetwork/poly/core/ledger"
 	"github.com/polynetwork/poly/core/signature"
 	"github.com/polynetwork/poly/core/types"
 )
 
+// consensus msg encodings, peers announce the highest one they decode in handshake
+const (
+	MsgVersionJSON   uint32 = 0
+	MsgVersionBinary uint32 = 1
+
+	MsgVersion = MsgVersionBinary
+)
+
 type ConsensusMsgPayload struct {
 	Type    MsgType `json:"type"`
 	Len     uint32  `json:"len"`
 	Payload []byte  `json:"payload"`
 }
 
+func newConsensusMsg(msgType MsgType) (ConsensusMsg, error) {
+	switch msgType {
+	case BlockProposalMessage:
+		return &blockProposalMsg{}, nil
+	case BlockEndorseMessage:
+		return &blockEndorseMsg{}, nil
+	case BlockCommitMessage:
+		return &blockCommitMsg{}, nil
+	case PeerHandshakeMessage:
+		return &peerHandshakeMsg{}, nil
+	case PeerHeartbeatMessage:
+		return &peerHeartbeatMsg{}, nil
+	case BlockInfoFetchMessage:
+		return &BlockInfoFetchMsg{}, nil
+	case BlockInfoFetchRespMessage:
+		return &BlockInfoFetchRespMsg{}, nil
+	case BlockFetchMessage:
+		return &blockFetchMsg{}, nil
+	case BlockFetchRespMessage:
+		return &BlockFetchRespMsg{}, nil
+	case ProposalFetchMessage:
+		return &proposalFetchMsg{}, nil
+	}
+	return nil, fmt.Errorf("unknown msg type: %d", msgType)
+}
+
+// binary msgs start with their version byte, json msgs always start with '{'
+func deserializeBinaryVbftMsg(msgPayload []byte) (ConsensusMsg, error) {
+	source := common.NewZeroCopySource(msgPayload)
+	version, eof := source.NextUint8()
+	if eof {
+		return nil, fmt.Errorf("read msg version: %s", io.ErrUnexpectedEOF)
+	}
+	if uint32(version) != MsgVersionBinary {
+		return nil, fmt.Errorf("unsupported msg version: %d", version)
+	}
+	msgType, eof := source.NextUint8()
+	if eof {
+		return nil, fmt.Errorf("read msg type: %s", io.ErrUnexpectedEOF)
+	}
+	msg, err := newConsensusMsg(MsgType(msgType))
+	if err != nil {
+		return nil, err
+	}
+	if err := msg.Deserialization(source); err != nil {
+		return nil, fmt.Errorf("failed to deserialize msg (type: %d): %s", msgType, err)
+	}
+	if source.Len() != 0 {
+		return nil, fmt.Errorf("failed to deserialize msg (type: %d): %d trailing bytes", msgType, source.Len())
+	}
+	return msg, nil
+}
+
 func DeserializeVbftMsg(msgPayload []byte) (ConsensusMsg, error) {
+	if len(msgPayload) > 0 && msgPayload[0] != '{' {
+		return deserializeBinaryVbftMsg(msgPayload)
+	}
 
 	m := &ConsensusMsgPayload{}
 	if err := json.Unmarshal(msgPayload, m); err != nil {
@@ -130,6 +194,21 @@ func SerializeVbftMsg(msg ConsensusMsg) ([]byte, error) {
 	})
 }
 
+// SerializeVbftMsgWithVersion encodes msg in the encoding of version, falling back to json
+// for peers not announcing a binary one
+func SerializeVbftMsgWithVersion(msg ConsensusMsg, version uint32) ([]byte, error) {
+	if version < MsgVersionBinary {
+		return SerializeVbftMsg(msg)
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint8(uint8(MsgVersionBinary))
+	sink.WriteUint8(uint8(msg.Type()))
+	if err := msg.Serialization(sink); err != nil {
+		return nil, err
+	}
+	return sink.Bytes(), nil
+}
+
 func (self *Server) constructHandshakeMsg() (*peerHandshakeMsg, error) {
 
 	blkNum := self.GetCurrentBlockNo() - 1
@@ -142,6 +221,7 @@ func (self *Server) constructHandshakeMsg() (*peerHandshakeMsg, error) {
 		CommittedBlockHash:   blockhash,
 		CommittedBlockLeader: block.getProposer(),
 		ChainConfig:          self.config,
+		MsgVersion:           MsgVersion,
 	}
 
 	return msg, nil
@@ -192,7 +272,7 @@ func (self *Server) constructBlock(blkNum uint32, prevBlkHash common.Uint256, tx
 	}
 	txRoot := common.ComputeMerkleRoot(txHash)
 
-	blockRoot := ledger.DefLedger.GetBlockRootWithPreBlockHashes(blkNum-1, []common.Uint256{lastBlock.Block.Header.PrevBlockHash, prevBlkHash})
+	blockRoot := self.ledger.GetBlockRootWithPreBlockHashes(blkNum-1, []common.Uint256{lastBlock.Block.Header.PrevBlockHash, prevBlkHash})
 	crossStateRoot, err := self.blockPool.getCrossStatesRoot(blkNum - 1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to GetCrossStatesRoot: %s,blkNum:%d", err, (blkNum - 1))
@@ -287,6 +367,10 @@ func (self *Server) constructProposalMsg(blkNum uint32, sysTxs, userTxs []*types
 	if err != nil {
 		return nil, fmt.Errorf("failed to constuct blk: %s", err)
 	}
+	// an honest proposer never signs two proposals of one block, even after restarting
+	if err := self.signRecord.signProposal(blkNum, blk.Hash(), emptyBlk.Hash()); err != nil {
+		return nil, fmt.Errorf("failed to record proposal: %s", err)
+	}
 
 	msg := &blockProposalMsg{
 		Block: &Block{
@@ -301,8 +385,6 @@ func (self *Server) constructProposalMsg(blkNum uint32, sysTxs, userTxs []*types
 
 func (self *Server) constructEndorseMsg(proposal *blockProposalMsg, forEmpty bool) (*blockEndorseMsg, error) {
 
-	// TODO, support faultyMsg reporting
-
 	var proposerSig, endorserSig []byte
 	var blkHash common.Uint256
 	var err error
@@ -323,6 +405,9 @@ func (self *Server) constructEndorseMsg(proposal *blockProposalMsg, forEmpty boo
 	if err != nil {
 		return nil, fmt.Errorf("endorser failed to sign block. hash:%x, err: %s", blkHash, err)
 	}
+	if err := self.signRecord.signEndorsement(proposal.Block.getBlockNum(), blkHash, forEmpty); err != nil {
+		return nil, fmt.Errorf("failed to record endorsement: %s", err)
+	}
 
 	msg := &blockEndorseMsg{
 		Endorser:          self.Index,
@@ -330,6 +415,7 @@ func (self *Server) constructEndorseMsg(proposal *blockProposalMsg, forEmpty boo
 		BlockNum:          proposal.Block.getBlockNum(),
 		EndorsedBlockHash: blkHash,
 		EndorseForEmpty:   forEmpty,
+		FaultyProposals:   self.equivPool.GetFaultyReports(proposal.Block.getBlockNum(), true),
 		ProposerSig:       proposerSig,
 		EndorserSig:       endorserSig,
 	}
@@ -339,8 +425,6 @@ func (self *Server) constructEndorseMsg(proposal *blockProposalMsg, forEmpty boo
 
 func (self *Server) constructCommitMsg(proposal *blockProposalMsg, endorses []*blockEndorseMsg, forEmpty bool) (*blockCommitMsg, error) {
 
-	// TODO, support faultyMsg reporting
-
 	var proposerSig, committerSig []byte
 	var blkHash common.Uint256
 	var err error
@@ -361,6 +445,9 @@ func (self *Server) constructCommitMsg(proposal *blockProposalMsg, endorses []*b
 	if err != nil {
 		return nil, fmt.Errorf("endorser failed to sign block. hash:%x, caused by: %s", blkHash, err)
 	}
+	if err := self.signRecord.signCommitment(proposal.Block.getBlockNum(), blkHash); err != nil {
+		return nil, fmt.Errorf("failed to record commitment: %s", err)
+	}
 
 	endorsersSig := make(map[uint32][]byte)
 	for _, e := range endorses {
@@ -373,6 +460,7 @@ func (self *Server) constructCommitMsg(proposal *blockProposalMsg, endorses []*b
 		BlockNum:        proposal.Block.getBlockNum(),
 		CommitBlockHash: blkHash,
 		CommitForEmpty:  forEmpty,
+		FaultyVerifies:  self.equivPool.GetFaultyReports(proposal.Block.getBlockNum(), false),
 		ProposerSig:     proposerSig,
 		EndorsersSig:    endorsersSig,
 		CommitterSig:    committerSig,
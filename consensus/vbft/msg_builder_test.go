@@ -19,10 +19,13 @@
 package vbft
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
 )
 
 func constructMsg() *blockProposalMsg {
@@ -58,3 +61,117 @@ func TestDeserializeVbftMsg(t *testing.T) {
 	}
 	t.Logf("TestDeserializeVbftMsg succ")
 }
+
+func TestSerializeVbftMsgWithVersion(t *testing.T) {
+	acc := account.NewAccount("SHA256withECDSA")
+	for _, msg := range constructBinaryTestMsgs(acc) {
+		jsonData, err := SerializeVbftMsgWithVersion(msg, MsgVersionJSON)
+		if err != nil {
+			t.Fatalf("serialize json msg (type: %d): %s", msg.Type(), err)
+		}
+		if jsonData[0] != '{' {
+			t.Fatalf("json msg (type: %d) starts with %d", msg.Type(), jsonData[0])
+		}
+		binData, err := SerializeVbftMsgWithVersion(msg, MsgVersionBinary)
+		if err != nil {
+			t.Fatalf("serialize binary msg (type: %d): %s", msg.Type(), err)
+		}
+		if uint32(binData[0]) != MsgVersionBinary || MsgType(binData[1]) != msg.Type() {
+			t.Fatalf("binary msg (type: %d) header: %v", msg.Type(), binData[:2])
+		}
+
+		// a receiver decodes both encodings to the same msg
+		fromJSON, err := DeserializeVbftMsg(jsonData)
+		if err != nil {
+			t.Fatalf("deserialize json msg (type: %d): %s", msg.Type(), err)
+		}
+		fromBin, err := DeserializeVbftMsg(binData)
+		if err != nil {
+			t.Fatalf("deserialize binary msg (type: %d): %s", msg.Type(), err)
+		}
+		if fromJSON.Type() != msg.Type() || fromBin.Type() != msg.Type() {
+			t.Fatalf("msg type %d decoded as %d and %d", msg.Type(), fromJSON.Type(), fromBin.Type())
+		}
+		sink1, sink2 := common.NewZeroCopySink(nil), common.NewZeroCopySink(nil)
+		if err := fromJSON.Serialization(sink1); err != nil {
+			t.Fatalf("serialize msg (type: %d) from json: %s", msg.Type(), err)
+		}
+		if err := fromBin.Serialization(sink2); err != nil {
+			t.Fatalf("serialize msg (type: %d) from binary: %s", msg.Type(), err)
+		}
+		if !bytes.Equal(sink1.Bytes(), sink2.Bytes()) {
+			t.Fatalf("msg (type: %d) decoded differently from json and binary", msg.Type())
+		}
+	}
+}
+
+func TestDeserializeVbftMsgVersions(t *testing.T) {
+	msg := &blockFetchMsg{BlockNum: 10}
+	data, err := SerializeVbftMsgWithVersion(msg, MsgVersionBinary)
+	if err != nil {
+		t.Fatalf("serialize msg: %s", err)
+	}
+	unknown := append([]byte{}, data...)
+	unknown[0] = uint8(MsgVersion + 1)
+	if _, err := DeserializeVbftMsg(unknown); err == nil {
+		t.Fatalf("deserialized msg of unknown version")
+	}
+	if _, err := DeserializeVbftMsg(append(data, 0)); err == nil {
+		t.Fatalf("deserialized msg with trailing bytes")
+	}
+	if _, err := DeserializeVbftMsg(data[:1]); err == nil {
+		t.Fatalf("deserialized msg without type")
+	}
+
+	// handshakes of peers before binary msgs carry no msg version
+	handshake := &peerHandshakeMsg{CommittedBlockNumber: 1, CommittedBlockLeader: 3}
+	payload, err := json.Marshal(&struct {
+		CommittedBlockNumber uint32         `json:"committed_block_number"`
+		CommittedBlockHash   common.Uint256 `json:"committed_block_hash"`
+		CommittedBlockLeader uint32         `json:"committed_block_leader"`
+	}{handshake.CommittedBlockNumber, handshake.CommittedBlockHash, handshake.CommittedBlockLeader})
+	if err != nil {
+		t.Fatalf("marshal old handshake: %s", err)
+	}
+	data, err = json.Marshal(&ConsensusMsgPayload{Type: PeerHandshakeMessage, Len: uint32(len(payload)), Payload: payload})
+	if err != nil {
+		t.Fatalf("marshal old handshake payload: %s", err)
+	}
+	decoded, err := DeserializeVbftMsg(data)
+	if err != nil {
+		t.Fatalf("deserialize old handshake: %s", err)
+	}
+	if decoded.(*peerHandshakeMsg).MsgVersion != MsgVersionJSON {
+		t.Fatalf("old handshake msg version: %d", decoded.(*peerHandshakeMsg).MsgVersion)
+	}
+}
+
+func TestPeerMsgVersion(t *testing.T) {
+	pool := NewPeerPool(4, nil)
+	pool.peers[1] = &Peer{Index: 1, connected: true, handShake: &peerHandshakeMsg{MsgVersion: MsgVersionBinary}}
+	pool.peers[2] = &Peer{Index: 2, connected: true, handShake: &peerHandshakeMsg{MsgVersion: MsgVersion + 1}}
+	pool.peers[3] = &Peer{Index: 3, connected: false}
+
+	if v := pool.getPeerMsgVersion(1); v != MsgVersionBinary {
+		t.Fatalf("peer 1 msg version: %d", v)
+	}
+	if v := pool.getPeerMsgVersion(2); v != MsgVersion {
+		t.Fatalf("newer peer msg version: %d", v)
+	}
+	if v := pool.getPeerMsgVersion(3); v != MsgVersionJSON {
+		t.Fatalf("peer without handshake msg version: %d", v)
+	}
+	if v := pool.getPeerMsgVersion(4); v != MsgVersionJSON {
+		t.Fatalf("unknown peer msg version: %d", v)
+	}
+	if v := pool.getBroadcastMsgVersion(); v != MsgVersionBinary {
+		t.Fatalf("broadcast msg version: %d", v)
+	}
+
+	// broadcast falls back to json once an old peer connects
+	pool.peers[3].connected = true
+	pool.peers[3].handShake = &peerHandshakeMsg{}
+	if v := pool.getBroadcastMsgVersion(); v != MsgVersionJSON {
+		t.Fatalf("broadcast msg version with old peer: %d", v)
+	}
+}
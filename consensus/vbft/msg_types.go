@@ -23,6 +23,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"sort"
 
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology-crypto/signature"
@@ -53,6 +55,8 @@ type ConsensusMsg interface {
 	Verify(pub keypair.PublicKey) error
 	GetBlockNum() uint32
 	Serialize() ([]byte, error)
+	Serialization(sink *common.ZeroCopySink) error
+	Deserialization(source *common.ZeroCopySource) error
 }
 
 type blockProposalMsg struct {
@@ -106,6 +110,23 @@ func (msg *blockProposalMsg) Serialize() ([]byte, error) {
 	return msg.Block.Serialize()
 }
 
+func (msg *blockProposalMsg) Serialization(sink *common.ZeroCopySink) error {
+	data, err := msg.Block.Serialize()
+	if err != nil {
+		return err
+	}
+	sink.WriteVarBytes(data)
+	return nil
+}
+
+func (msg *blockProposalMsg) Deserialization(source *common.ZeroCopySource) error {
+	data, eof := source.NextVarBytes()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return msg.UnmarshalJSON(data)
+}
+
 func (msg *blockProposalMsg) UnmarshalJSON(data []byte) error {
 	blk := &Block{}
 	if err := blk.Deserialize(data); err != nil {
@@ -125,6 +146,90 @@ type FaultyReport struct {
 	FaultyMsgHash common.Uint256 `json:"faulty_block_hash"`
 }
 
+func serializeFaultyReports(sink *common.ZeroCopySink, reports []*FaultyReport) {
+	sink.WriteVarUint(uint64(len(reports)))
+	for _, r := range reports {
+		sink.WriteUint32(r.FaultyID)
+		sink.WriteHash(r.FaultyMsgHash)
+	}
+}
+
+func deserializeFaultyReports(source *common.ZeroCopySource) ([]*FaultyReport, error) {
+	n, eof := source.NextVarUint()
+	if eof {
+		return nil, io.ErrUnexpectedEOF
+	}
+	reports := make([]*FaultyReport, 0)
+	for i := uint64(0); i < n; i++ {
+		r := &FaultyReport{}
+		if r.FaultyID, eof = source.NextUint32(); eof {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if r.FaultyMsgHash, eof = source.NextHash(); eof {
+			return nil, io.ErrUnexpectedEOF
+		}
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+// serializeSigs writes the signatures ordered by peer index, to keep the encoding deterministic
+func serializeSigs(sink *common.ZeroCopySink, sigs map[uint32][]byte) {
+	peers := make([]uint32, 0, len(sigs))
+	for peerIdx := range sigs {
+		peers = append(peers, peerIdx)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i] < peers[j] })
+	sink.WriteVarUint(uint64(len(peers)))
+	for _, peerIdx := range peers {
+		sink.WriteUint32(peerIdx)
+		sink.WriteVarBytes(sigs[peerIdx])
+	}
+}
+
+func deserializeSigs(source *common.ZeroCopySource) (map[uint32][]byte, error) {
+	n, eof := source.NextVarUint()
+	if eof {
+		return nil, io.ErrUnexpectedEOF
+	}
+	sigs := make(map[uint32][]byte)
+	for i := uint64(0); i < n; i++ {
+		peerIdx, eof := source.NextUint32()
+		if eof {
+			return nil, io.ErrUnexpectedEOF
+		}
+		sig, eof := source.NextVarBytes()
+		if eof {
+			return nil, io.ErrUnexpectedEOF
+		}
+		sigs[peerIdx] = sig
+	}
+	return sigs, nil
+}
+
+func serializeBytesList(sink *common.ZeroCopySink, list [][]byte) {
+	sink.WriteVarUint(uint64(len(list)))
+	for _, v := range list {
+		sink.WriteVarBytes(v)
+	}
+}
+
+func deserializeBytesList(source *common.ZeroCopySource) ([][]byte, error) {
+	n, eof := source.NextVarUint()
+	if eof {
+		return nil, io.ErrUnexpectedEOF
+	}
+	list := make([][]byte, 0)
+	for i := uint64(0); i < n; i++ {
+		v, eof := source.NextVarBytes()
+		if eof {
+			return nil, io.ErrUnexpectedEOF
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
 type blockEndorseMsg struct {
 	Endorser          uint32          `json:"endorser"`
 	EndorsedProposer  uint32          `json:"endorsed_proposer"`
@@ -160,6 +265,49 @@ func (msg *blockEndorseMsg) Serialize() ([]byte, error) {
 	return json.Marshal(msg)
 }
 
+func (msg *blockEndorseMsg) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteUint32(msg.Endorser)
+	sink.WriteUint32(msg.EndorsedProposer)
+	sink.WriteUint32(msg.BlockNum)
+	sink.WriteHash(msg.EndorsedBlockHash)
+	sink.WriteBool(msg.EndorseForEmpty)
+	serializeFaultyReports(sink, msg.FaultyProposals)
+	sink.WriteVarBytes(msg.ProposerSig)
+	sink.WriteVarBytes(msg.EndorserSig)
+	return nil
+}
+
+func (msg *blockEndorseMsg) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if msg.Endorser, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.EndorsedProposer, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.BlockNum, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.EndorsedBlockHash, eof = source.NextHash(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.EndorseForEmpty, eof = source.NextBool(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	reports, err := deserializeFaultyReports(source)
+	if err != nil {
+		return fmt.Errorf("deserialize faulty proposals: %s", err)
+	}
+	msg.FaultyProposals = reports
+	if msg.ProposerSig, eof = source.NextVarBytes(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.EndorserSig, eof = source.NextVarBytes(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
 type blockCommitMsg struct {
 	Committer       uint32            `json:"committer"`
 	BlockProposer   uint32            `json:"block_proposer"`
@@ -197,11 +345,61 @@ func (msg *blockCommitMsg) Serialize() ([]byte, error) {
 	return json.Marshal(msg)
 }
 
+func (msg *blockCommitMsg) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteUint32(msg.Committer)
+	sink.WriteUint32(msg.BlockProposer)
+	sink.WriteUint32(msg.BlockNum)
+	sink.WriteHash(msg.CommitBlockHash)
+	sink.WriteBool(msg.CommitForEmpty)
+	serializeFaultyReports(sink, msg.FaultyVerifies)
+	sink.WriteVarBytes(msg.ProposerSig)
+	serializeSigs(sink, msg.EndorsersSig)
+	sink.WriteVarBytes(msg.CommitterSig)
+	return nil
+}
+
+func (msg *blockCommitMsg) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if msg.Committer, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.BlockProposer, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.BlockNum, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.CommitBlockHash, eof = source.NextHash(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.CommitForEmpty, eof = source.NextBool(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	reports, err := deserializeFaultyReports(source)
+	if err != nil {
+		return fmt.Errorf("deserialize faulty verifies: %s", err)
+	}
+	msg.FaultyVerifies = reports
+	if msg.ProposerSig, eof = source.NextVarBytes(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	sigs, err := deserializeSigs(source)
+	if err != nil {
+		return fmt.Errorf("deserialize endorsers sig: %s", err)
+	}
+	msg.EndorsersSig = sigs
+	if msg.CommitterSig, eof = source.NextVarBytes(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
 type peerHandshakeMsg struct {
 	CommittedBlockNumber uint32               `json:"committed_block_number"`
 	CommittedBlockHash   common.Uint256       `json:"committed_block_hash"`
 	CommittedBlockLeader uint32               `json:"committed_block_leader"`
 	ChainConfig          *vconfig.ChainConfig `json:"chain_config"`
+	MsgVersion           uint32               `json:"msg_version,omitempty"` // highest msg encoding the peer decodes
 }
 
 func (msg *peerHandshakeMsg) Type() MsgType {
@@ -221,6 +419,51 @@ func (msg *peerHandshakeMsg) Serialize() ([]byte, error) {
 	return json.Marshal(msg)
 }
 
+func (msg *peerHandshakeMsg) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteUint32(msg.CommittedBlockNumber)
+	sink.WriteHash(msg.CommittedBlockHash)
+	sink.WriteUint32(msg.CommittedBlockLeader)
+	// chain config is only sent on handshake, reuse its json encoding
+	var cfg []byte
+	if msg.ChainConfig != nil {
+		data, err := json.Marshal(msg.ChainConfig)
+		if err != nil {
+			return err
+		}
+		cfg = data
+	}
+	sink.WriteVarBytes(cfg)
+	sink.WriteUint32(msg.MsgVersion)
+	return nil
+}
+
+func (msg *peerHandshakeMsg) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if msg.CommittedBlockNumber, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.CommittedBlockHash, eof = source.NextHash(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.CommittedBlockLeader, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	cfg, eof := source.NextVarBytes()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if len(cfg) > 0 {
+		msg.ChainConfig = &vconfig.ChainConfig{}
+		if err := json.Unmarshal(cfg, msg.ChainConfig); err != nil {
+			return fmt.Errorf("unmarshal chain config: %s", err)
+		}
+	}
+	if msg.MsgVersion, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
 type peerHeartbeatMsg struct {
 	CommittedBlockNumber uint32         `json:"committed_block_number"`
 	CommittedBlockHash   common.Uint256 `json:"committed_block_hash"`
@@ -246,6 +489,43 @@ func (msg *peerHeartbeatMsg) Serialize() ([]byte, error) {
 	return json.Marshal(msg)
 }
 
+func (msg *peerHeartbeatMsg) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteUint32(msg.CommittedBlockNumber)
+	sink.WriteHash(msg.CommittedBlockHash)
+	sink.WriteUint32(msg.CommittedBlockLeader)
+	serializeBytesList(sink, msg.Endorsers)
+	serializeBytesList(sink, msg.EndorsersSig)
+	sink.WriteUint32(msg.ChainConfigView)
+	return nil
+}
+
+func (msg *peerHeartbeatMsg) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if msg.CommittedBlockNumber, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.CommittedBlockHash, eof = source.NextHash(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.CommittedBlockLeader, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	endorsers, err := deserializeBytesList(source)
+	if err != nil {
+		return fmt.Errorf("deserialize endorsers: %s", err)
+	}
+	msg.Endorsers = endorsers
+	sigs, err := deserializeBytesList(source)
+	if err != nil {
+		return fmt.Errorf("deserialize endorsers sig: %s", err)
+	}
+	msg.EndorsersSig = sigs
+	if msg.ChainConfigView, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
 type BlockInfoFetchMsg struct {
 	StartBlockNum uint32 `json:"start_block_num"`
 }
@@ -266,6 +546,19 @@ func (msg *BlockInfoFetchMsg) Serialize() ([]byte, error) {
 	return json.Marshal(msg)
 }
 
+func (msg *BlockInfoFetchMsg) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteUint32(msg.StartBlockNum)
+	return nil
+}
+
+func (msg *BlockInfoFetchMsg) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if msg.StartBlockNum, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
 type BlockInfo_ struct {
 	BlockNum   uint32            `json:"block_num"`
 	Proposer   uint32            `json:"proposer"`
@@ -293,6 +586,41 @@ func (msg *BlockInfoFetchRespMsg) Serialize() ([]byte, error) {
 	return json.Marshal(msg)
 }
 
+func (msg *BlockInfoFetchRespMsg) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteVarUint(uint64(len(msg.Blocks)))
+	for _, blk := range msg.Blocks {
+		sink.WriteUint32(blk.BlockNum)
+		sink.WriteUint32(blk.Proposer)
+		serializeSigs(sink, blk.Signatures)
+	}
+	return nil
+}
+
+func (msg *BlockInfoFetchRespMsg) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	blocks := make([]*BlockInfo_, 0)
+	for i := uint64(0); i < n; i++ {
+		blk := &BlockInfo_{}
+		if blk.BlockNum, eof = source.NextUint32(); eof {
+			return io.ErrUnexpectedEOF
+		}
+		if blk.Proposer, eof = source.NextUint32(); eof {
+			return io.ErrUnexpectedEOF
+		}
+		sigs, err := deserializeSigs(source)
+		if err != nil {
+			return fmt.Errorf("deserialize block %d signatures: %s", blk.BlockNum, err)
+		}
+		blk.Signatures = sigs
+		blocks = append(blocks, blk)
+	}
+	msg.Blocks = blocks
+	return nil
+}
+
 // block fetch msg is to fetch block which could have not been committed or endorsed
 type blockFetchMsg struct {
 	BlockNum uint32 `json:"block_num"`
@@ -314,6 +642,19 @@ func (msg *blockFetchMsg) Serialize() ([]byte, error) {
 	return json.Marshal(msg)
 }
 
+func (msg *blockFetchMsg) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteUint32(msg.BlockNum)
+	return nil
+}
+
+func (msg *blockFetchMsg) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if msg.BlockNum, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
 type BlockFetchRespMsg struct {
 	BlockNumber uint32         `json:"block_number"`
 	BlockHash   common.Uint256 `json:"block_hash"`
@@ -363,6 +704,37 @@ func (msg *BlockFetchRespMsg) Deserialize(data []byte) error {
 	return nil
 }
 
+func (msg *BlockFetchRespMsg) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteUint32(msg.BlockNumber)
+	sink.WriteHash(msg.BlockHash)
+	data, err := msg.BlockData.Serialize()
+	if err != nil {
+		return err
+	}
+	sink.WriteVarBytes(data)
+	return nil
+}
+
+func (msg *BlockFetchRespMsg) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if msg.BlockNumber, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.BlockHash, eof = source.NextHash(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	data, eof := source.NextVarBytes()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	blk := &Block{}
+	if err := blk.Deserialize(data); err != nil {
+		return fmt.Errorf("unmarshal block type: %s", err)
+	}
+	msg.BlockData = blk
+	return nil
+}
+
 // proposal fetch msg is to fetch proposal when peer failed to get proposal locally
 type proposalFetchMsg struct {
 	ProposerID uint32 `json:"proposer_id"`
@@ -384,3 +756,20 @@ func (msg *proposalFetchMsg) GetBlockNum() uint32 {
 func (msg *proposalFetchMsg) Serialize() ([]byte, error) {
 	return json.Marshal(msg)
 }
+
+func (msg *proposalFetchMsg) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteUint32(msg.ProposerID)
+	sink.WriteUint32(msg.BlockNum)
+	return nil
+}
+
+func (msg *proposalFetchMsg) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if msg.ProposerID, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if msg.BlockNum, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
@@ -19,6 +19,7 @@
 package vbft
 
 import (
+	"bytes"
 	"encoding/json"
 	"testing"
 	"time"
@@ -62,8 +63,7 @@ func constructProposalMsgTest(acc *account.Account) *blockProposalMsg {
 			Header:       blkHeader,
 			Transactions: nil,
 		},
-		Info:                vbftBlkInfo,
-		PrevBlockMerkleRoot: common.Uint256{},
+		Info: vbftBlkInfo,
 	}
 	msg := &blockProposalMsg{
 		Block: blk,
@@ -384,8 +384,7 @@ func constructBlock() (*Block, error) {
 			Header:       blkHeader,
 			Transactions: nil,
 		},
-		Info:                vbftBlkInfo,
-		PrevBlockMerkleRoot: common.Uint256{},
+		Info: vbftBlkInfo,
 	}
 	blk.Block.Hash()
 	blk.Block.Transactions = txs
@@ -434,3 +433,98 @@ func TestBlockFetchRespMsgDeserialize(t *testing.T) {
 	}
 	t.Logf("BlockFetchRespMsg Serialize succ: %v\n", respmsg.BlockNumber)
 }
+
+func constructBinaryTestMsgs(acc *account.Account) []ConsensusMsg {
+	proposal := constructProposalMsgTest(acc)
+	blkHash, _ := HashBlock(proposal.Block)
+	endorse, _ := constructEndorseMsg(acc, proposal, blkHash)
+	endorse.FaultyProposals = []*FaultyReport{{FaultyID: 2, FaultyMsgHash: common.Uint256{2}}}
+	endorse.ProposerSig = []byte{1, 2, 3}
+	commit, _ := constructCommitMsg(acc, proposal, blkHash)
+	commit.FaultyVerifies = []*FaultyReport{{FaultyID: 3, FaultyMsgHash: common.Uint256{3}}}
+	commit.ProposerSig = []byte{1, 2, 3}
+	commit.EndorsersSig = map[uint32][]byte{1: {1}, 3: {3}, 2: {2}}
+	handshake, _ := constructHandshakeMsg(acc)
+	handshake.ChainConfig = &vconfig.ChainConfig{Version: 1, View: 2, N: 7, C: 2, PosTable: []uint32{1, 2, 3}}
+	handshake.MsgVersion = MsgVersion
+	heartbeat, _ := constructHeartbeatMsg(acc)
+	heartbeat.Endorsers = [][]byte{{1}, {2}}
+	heartbeat.EndorsersSig = [][]byte{{3}, {4}}
+	infoFetch, _ := constructBlockInfoFetchMsg(acc)
+	infoFetchResp, _ := constructBlockInfoFetchRespMsg(acc)
+	infoFetchResp.Blocks[0].Signatures = map[uint32][]byte{1: {1}, 2: {2}}
+	blkFetch, _ := constructBlockFetchMsg(acc)
+	blkFetchResp, _ := constructBlockFetchRespMsg(acc, proposal.Block)
+	proposalFetch, _ := constructProposalFetchMsg(acc)
+	proposalFetch.ProposerID = 3
+	return []ConsensusMsg{proposal, endorse, commit, handshake, heartbeat, infoFetch, infoFetchResp,
+		blkFetch, blkFetchResp, proposalFetch}
+}
+
+func TestConsensusMsgBinaryRoundTrip(t *testing.T) {
+	acc := account.NewAccount("SHA256withECDSA")
+	for _, msg := range constructBinaryTestMsgs(acc) {
+		sink := common.NewZeroCopySink(nil)
+		if err := msg.Serialization(sink); err != nil {
+			t.Fatalf("serialize msg (type: %d): %s", msg.Type(), err)
+		}
+		decoded, err := newConsensusMsg(msg.Type())
+		if err != nil {
+			t.Fatalf("new msg (type: %d): %s", msg.Type(), err)
+		}
+		source := common.NewZeroCopySource(sink.Bytes())
+		if err := decoded.Deserialization(source); err != nil {
+			t.Fatalf("deserialize msg (type: %d): %s", msg.Type(), err)
+		}
+		if source.Len() != 0 {
+			t.Fatalf("msg (type: %d) left %d bytes", msg.Type(), source.Len())
+		}
+		// maps are serialized in key order, so equal msgs encode to equal bytes
+		resink := common.NewZeroCopySink(nil)
+		if err := decoded.Serialization(resink); err != nil {
+			t.Fatalf("reserialize msg (type: %d): %s", msg.Type(), err)
+		}
+		if !bytes.Equal(sink.Bytes(), resink.Bytes()) {
+			t.Fatalf("msg (type: %d) changed in round trip", msg.Type())
+		}
+		if decoded.GetBlockNum() != msg.GetBlockNum() {
+			t.Fatalf("msg (type: %d) block num %d, expected %d", msg.Type(), decoded.GetBlockNum(), msg.GetBlockNum())
+		}
+
+		// truncated msgs are refused
+		data := sink.Bytes()
+		truncated, _ := newConsensusMsg(msg.Type())
+		if err := truncated.Deserialization(common.NewZeroCopySource(data[:len(data)-1])); err == nil {
+			t.Fatalf("deserialized truncated msg (type: %d)", msg.Type())
+		}
+	}
+}
+
+func TestBlockCommitMsgBinaryFields(t *testing.T) {
+	acc := account.NewAccount("SHA256withECDSA")
+	proposal := constructProposalMsgTest(acc)
+	blkHash, _ := HashBlock(proposal.Block)
+	msg, _ := constructCommitMsg(acc, proposal, blkHash)
+	msg.EndorsersSig = map[uint32][]byte{1: {1}, 2: {2}}
+	msg.FaultyVerifies = []*FaultyReport{{FaultyID: 3, FaultyMsgHash: common.Uint256{3}}}
+	sink := common.NewZeroCopySink(nil)
+	if err := msg.Serialization(sink); err != nil {
+		t.Fatalf("serialize commit msg: %s", err)
+	}
+	decoded := &blockCommitMsg{}
+	if err := decoded.Deserialization(common.NewZeroCopySource(sink.Bytes())); err != nil {
+		t.Fatalf("deserialize commit msg: %s", err)
+	}
+	if decoded.CommitBlockHash != blkHash || !decoded.CommitForEmpty || decoded.Committer != msg.Committer {
+		t.Fatalf("unexpected commit msg: %+v", decoded)
+	}
+	if len(decoded.EndorsersSig) != 2 || !bytes.Equal(decoded.EndorsersSig[2], []byte{2}) {
+		t.Fatalf("unexpected endorsers sig: %v", decoded.EndorsersSig)
+	}
+	if len(decoded.FaultyVerifies) != 1 || *decoded.FaultyVerifies[0] != *msg.FaultyVerifies[0] {
+		t.Fatalf("unexpected faulty verifies: %v", decoded.FaultyVerifies)
+	}
+	if err := decoded.Verify(acc.PublicKey); err != nil {
+		t.Fatalf("verify decoded commit msg: %s", err)
+	}
+}
@@ -89,6 +89,22 @@ func (self *Server) isProposer(blockNum uint32, peerIdx uint32) bool {
 	return false
 }
 
+// getLeaderProposer returns the first active proposer of the current block
+func (self *Server) getLeaderProposer(blockNum uint32) (uint32, bool) {
+	self.metaLock.RLock()
+	defer self.metaLock.RUnlock()
+
+	if blockNum != self.currentParticipantConfig.BlockNum {
+		return 0, false
+	}
+	for _, id := range self.currentParticipantConfig.Proposers {
+		if self.isPeerAlive(id, blockNum) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
 func (self *Server) is2ndProposer(blockNum uint32, peerIdx uint32) bool {
 	rank := self.getProposerRank(blockNum, peerIdx)
 	return rank > 0 && rank <= int(self.config.C)
@@ -222,6 +222,41 @@ func (pool *PeerPool) getNeighbours() []*Peer {
 	return peers
 }
 
+// getPeerMsgVersion returns the msg encoding announced by the peer in its handshake
+func (pool *PeerPool) getPeerMsgVersion(peerIdx uint32) uint32 {
+	pool.lock.RLock()
+	defer pool.lock.RUnlock()
+
+	p := pool.peers[peerIdx]
+	if p == nil || p.handShake == nil {
+		return MsgVersionJSON
+	}
+	if p.handShake.MsgVersion > MsgVersion {
+		return MsgVersion
+	}
+	return p.handShake.MsgVersion
+}
+
+// getBroadcastMsgVersion returns the msg encoding all connected peers decode
+func (pool *PeerPool) getBroadcastMsgVersion() uint32 {
+	pool.lock.RLock()
+	defer pool.lock.RUnlock()
+
+	version := MsgVersion
+	for _, p := range pool.peers {
+		if !p.connected {
+			continue
+		}
+		if p.handShake == nil {
+			return MsgVersionJSON
+		}
+		if p.handShake.MsgVersion < version {
+			version = p.handShake.MsgVersion
+		}
+	}
+	return version
+}
+
 func (pool *PeerPool) GetPeerIndex(nodeId string) (uint32, bool) {
 	pool.lock.RLock()
 	defer pool.lock.RUnlock()
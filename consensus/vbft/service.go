@@ -22,6 +22,7 @@ import (
 	"bytes"
 	"fmt"
 	"math"
+	"path/filepath"
 	"reflect"
 	"sync"
 	"time"
@@ -31,6 +32,7 @@ import (
 	"github.com/ontio/ontology-eventbus/actor"
 	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	actorTypes "github.com/polynetwork/poly/consensus/actor"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
@@ -92,6 +94,7 @@ type Server struct {
 	poolActor     *actorTypes.TxPoolActor
 	p2p           *actorTypes.P2PActor
 	ledger        *ledger.Ledger
+	dataDir       string
 	incrValidator *increment.IncrementValidator
 	pid           *actor.PID
 
@@ -119,6 +122,10 @@ type Server struct {
 	stateMgr   *StateMgr
 	timer      *EventTimer
 
+	equivPool  *EquivocationPool // signed headers of recent rounds, for equivocation detecting
+	signRecord *signRecordStore  // blocks signed by self at last signed height, kept across restarts
+	metrics    *consensusMetrics // round timings, peer liveness and timer expiries for monitoring
+
 	msgRecvC   map[uint32]chan *p2pMsgPayload
 	msgC       chan ConsensusMsg
 	bftActionC chan *BftAction
@@ -130,13 +137,27 @@ type Server struct {
 }
 
 func NewVbftServer(account *account.Account, txpool, p2p *actor.PID) (*Server, error) {
+	dataDir := filepath.Join(config.DefConfig.Common.DataDir, config.DefConfig.P2PNode.NetworkName)
+	return newVbftServer(account, txpool, p2p, ledger.DefLedger, dataDir, "consensus_vbft")
+}
+
+// NewLocalVbftServer creates the server of a validator sharing the process with other validators,
+// the validator keeps its blocks in db and its sign record in dataDir
+func NewLocalVbftServer(account *account.Account, txpool, p2p *actor.PID, db *ledger.Ledger, dataDir string) (*Server, error) {
+	return newVbftServer(account, txpool, p2p, db, dataDir, "")
+}
+
+func newVbftServer(account *account.Account, txpool, p2p *actor.PID, db *ledger.Ledger, dataDir string,
+	name string) (*Server, error) {
 	server := &Server{
 		msgHistoryDuration: 64,
 		account:            account,
 		poolActor:          &actorTypes.TxPoolActor{Pool: txpool},
 		p2p:                &actorTypes.P2PActor{P2P: p2p},
-		ledger:             ledger.DefLedger,
+		ledger:             db,
+		dataDir:            dataDir,
 		incrValidator:      increment.NewIncrementValidator(20),
+		metrics:            newConsensusMetrics(),
 	}
 	server.stateMgr = newStateMgr(server)
 
@@ -144,7 +165,13 @@ func NewVbftServer(account *account.Account, txpool, p2p *actor.PID) (*Server, e
 		return server
 	})
 
-	pid, err := actor.SpawnNamed(props, "consensus_vbft")
+	var pid *actor.PID
+	var err error
+	if name == "" {
+		pid = actor.Spawn(props)
+	} else {
+		pid, err = actor.SpawnNamed(props, name)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -173,7 +200,14 @@ func (self *Server) Receive(context actor.Context) {
 		log.Info("vbft actor start consensus")
 	case *actorTypes.StopConsensus:
 		self.stop()
+		if context.Sender() != nil {
+			context.Respond(msg)
+		}
 	case *message.SaveBlockCompleteMsg:
+		// the event hub is shared by the ledgers in the process, skip blocks saved by other ledgers
+		if self.ledger.GetBlockHash(msg.Block.Header.Height) != msg.Block.Hash() {
+			return
+		}
 		log.Infof("vbft actor SaveBlockCompleteMsg receives block complete event. block height=%d, numtx=%d",
 			msg.Block.Header.Height, len(msg.Block.Transactions))
 		self.handleBlockPersistCompleted(msg.Block)
@@ -283,6 +317,7 @@ func (self *Server) LoadChainConfig(blkNum uint32) error {
 	self.metaLock.Lock()
 	self.config = &cfg
 	self.metaLock.Unlock()
+	self.metrics.setChainConfigView(cfg.View)
 
 	self.metaLock.RLock()
 	defer self.metaLock.RUnlock()
@@ -336,6 +371,7 @@ func (self *Server) updateChainConfig() error {
 	self.config = block.Info.NewChainConfig
 	self.LastConfigBlockNum = block.getLastConfigBlockNum()
 	self.metaLock.Unlock()
+	self.metrics.setChainConfigView(block.Info.NewChainConfig.View)
 
 	self.metaLock.RLock()
 	defer self.metaLock.RUnlock()
@@ -423,6 +459,12 @@ func (self *Server) initialize() error {
 		return fmt.Errorf("init blockpool: %s", err)
 	}
 	self.msgPool = newMsgPool(self, self.msgHistoryDuration)
+	self.equivPool = newEquivocationPool(self, self.msgHistoryDuration)
+	self.signRecord, err = openSignRecordStore(filepath.Join(self.dataDir, SIGN_RECORD_FILE))
+	if err != nil {
+		log.Errorf("open sign record: %s", err)
+		return fmt.Errorf("open sign record: %s", err)
+	}
 	self.peerPool = NewPeerPool(0, self) // FIXME: maxSize
 	self.timer = NewEventTimer(self)
 	self.syncer = newSyncer(self)
@@ -564,6 +606,7 @@ func (self *Server) run(peerPubKey keypair.PublicKey) error {
 		delete(self.msgRecvC, peerIdx)
 
 		self.peerPool.peerDisconnected(peerIdx)
+		self.metrics.onPeerDisconnected(peerIdx)
 		self.stateMgr.StateEventC <- &StateEvent{
 			Type: UpdatePeerState,
 			peerState: &PeerState{
@@ -649,6 +692,7 @@ func (self *Server) updateParticipantConfig() error {
 
 func (self *Server) startNewRound() error {
 	blkNum := self.GetCurrentBlockNo()
+	self.metrics.newRound(blkNum)
 
 	if err := self.updateParticipantConfig(); err != nil {
 		log.Errorf("startNewRound error:%s", err)
@@ -750,6 +794,9 @@ func (self *Server) onConsensusMsg(peerIdx uint32, msg ConsensusMsg, msgHash com
 		log.Debugf("dup msg with msg type %d from %d", msg.Type(), peerIdx)
 		return
 	}
+	if msg.Type() <= BlockCommitMessage {
+		self.equivPool.AddMsg(msg)
+	}
 
 	switch msg.Type() {
 	case BlockProposalMessage:
@@ -815,6 +862,10 @@ func (self *Server) onConsensusMsg(peerIdx uint32, msg ConsensusMsg, msgHash com
 		}
 
 		// TODO: verify msg
+		for _, report := range pMsg.FaultyProposals {
+			log.Warnf("server %d, endorser %d reported faulty proposal from %d, blk %d, hash %s",
+				self.Index, pMsg.Endorser, report.FaultyID, pMsg.BlockNum, report.FaultyMsgHash.ToHexString())
+		}
 
 		msgBlkNum := pMsg.GetBlockNum()
 		if msgBlkNum > self.GetCurrentBlockNo() {
@@ -872,6 +923,10 @@ func (self *Server) onConsensusMsg(peerIdx uint32, msg ConsensusMsg, msgHash com
 		}
 
 		// TODO: verify msg
+		for _, report := range pMsg.FaultyVerifies {
+			log.Warnf("server %d, committer %d reported faulty verify from %d, blk %d, hash %s",
+				self.Index, pMsg.Committer, report.FaultyID, pMsg.BlockNum, report.FaultyMsgHash.ToHexString())
+		}
 
 		msgBlkNum := pMsg.GetBlockNum()
 		if msgBlkNum > self.GetCurrentBlockNo() {
@@ -1109,6 +1164,14 @@ func (self *Server) processProposalMsg(msg *blockProposalMsg) {
 	}
 
 	txs := msg.Block.Block.Transactions
+	for _, tx := range txs {
+		if err := tx.VerifyValidity(msgBlkNum); err != nil {
+			log.Errorf("server %d failed to verify tx %x of block %d proposal from %d: %s",
+				self.Index, tx.Hash(), msgBlkNum, msg.Block.getProposer(), err)
+			self.msgPool.DropMsg(msg)
+			return
+		}
+	}
 	if len(txs) > 0 && self.nonSystxs(txs, msgBlkNum) {
 		height := uint32(msgBlkNum) - 1
 		start, end := self.incrValidator.BlockRange()
@@ -1242,6 +1305,7 @@ func (self *Server) processMsgEvent() error {
 			msgBlkNum := pMsg.GetBlockNum()
 			if msgBlkNum == self.GetCurrentBlockNo() {
 				lbm.addMsg(msgBlkNum, msg)
+				self.metrics.onRoundMsg(msgBlkNum, msg.Type())
 				// add proposal to block-pool
 				if err := self.blockPool.newBlockProposal(pMsg); err != nil {
 					if err == errDupProposal {
@@ -1298,6 +1362,7 @@ func (self *Server) processMsgEvent() error {
 
 			if msgBlkNum == self.GetCurrentBlockNo() {
 				lbm.addMsg(msgBlkNum, msg)
+				self.metrics.onRoundMsg(msgBlkNum, msg.Type())
 
 				if pMsg.EndorsedProposer != self.Index && len(self.msgPool.GetProposalMsgs(msgBlkNum)) == 0 {
 					self.fetchProposal(msgBlkNum, pMsg.EndorsedProposer)
@@ -1374,6 +1439,7 @@ func (self *Server) processMsgEvent() error {
 
 			if msgBlkNum == self.GetCurrentBlockNo() {
 				lbm.addMsg(msgBlkNum, msg)
+				self.metrics.onRoundMsg(msgBlkNum, msg.Type())
 				//              if countOfCommitment(msg.proposal) >= 2C + 1:
 				//                      stop WaitCommitsTimer
 				//                      sealProposal(msg.BlockHash)
@@ -1672,6 +1738,7 @@ func (self *Server) actionLoop() {
 }
 
 func (self *Server) processTimerEvent(evt *TimerEvent) error {
+	self.metrics.onTimerEvent(evt.evtType)
 	switch evt.evtType {
 	case EventProposalBackoff:
 		// 1. if endorsed, return
@@ -1707,6 +1774,7 @@ func (self *Server) processTimerEvent(evt *TimerEvent) error {
 		//
 		// then propose for empty block, start 2ndProposal timeout, return
 		//
+		self.checkLeaderProposal(evt.blockNum)
 		return self.handleProposalTimeout(evt)
 
 	case EventRandomBackoff:
@@ -1879,6 +1947,7 @@ func (self *Server) processHandshakeMsg(peerIdx uint32, msg *peerHandshakeMsg) e
 	if err := self.peerPool.peerHandshake(peerIdx, msg); err != nil {
 		return fmt.Errorf("failed to update peer %d: %s", peerIdx, err)
 	}
+	self.metrics.onPeerSeen(peerIdx, msg.CommittedBlockNumber)
 	self.stateMgr.StateEventC <- &StateEvent{
 		Type: UpdatePeerConfig,
 		peerState: &PeerState{
@@ -1897,6 +1966,7 @@ func (self *Server) processHeartbeatMsg(peerIdx uint32, msg *peerHeartbeatMsg) e
 	if err := self.peerPool.peerHeartbeat(peerIdx, msg); err != nil {
 		return fmt.Errorf("failed to update peer %d: %s", peerIdx, err)
 	}
+	self.metrics.onPeerSeen(peerIdx, msg.CommittedBlockNumber)
 	log.Debugf("server %d received heartbeat from peer %d, chainview %d, blkNum %d",
 		self.Index, peerIdx, msg.ChainConfigView, msg.CommittedBlockNumber)
 	self.stateMgr.StateEventC <- &StateEvent{
@@ -2098,7 +2168,11 @@ func (self *Server) sealBlock(block *Block, empty bool, sigdata bool) error {
 	self.msgPool.onBlockSealed(sealedBlkNum)
 	self.blockPool.onBlockSealed(sealedBlkNum)
 
-	_, h := self.blockPool.getSealedBlock(sealedBlkNum)
+	sealed, h := self.blockPool.getSealedBlock(sealedBlkNum)
+	if sealed != nil {
+		self.equivPool.onBlockSealed(sealed.Block)
+	}
+	self.metrics.onBlockSealed(sealedBlkNum, empty)
 	prevBlkHash := block.getPrevBlockHash()
 	log.Infof("server %d, sealed block %d, proposer %d, prevhash: %s, hash: %s", self.Index,
 		sealedBlkNum, block.getProposer(), prevBlkHash.ToHexString(), h.ToHexString())
@@ -2126,7 +2200,13 @@ func (self *Server) msgSendLoop() {
 			if self.nonConsensusNode() {
 				continue
 			}
-			payload, err := SerializeVbftMsg(evt.Msg)
+			var version uint32
+			if evt.ToPeer == math.MaxUint32 {
+				version = self.peerPool.getBroadcastMsgVersion()
+			} else {
+				version = self.peerPool.getPeerMsgVersion(evt.ToPeer)
+			}
+			payload, err := SerializeVbftMsgWithVersion(evt.Msg, version)
 			if err != nil {
 				log.Errorf("server %d failed to serialized msg (type: %d): %s", self.Index, evt.Msg.Type(), err)
 				continue
@@ -2160,6 +2240,17 @@ func (self *Server) createGovernaceTransaction(blkNum uint32) *types.Transaction
 	return tx
 }
 
+func (self *Server) createEquivocationTransaction(evidence *node_manager.EquivocationEvidence, blkNum uint32) *types.Transaction {
+	args := common.NewZeroCopySink(nil)
+	evidence.Serialization(args)
+	contractInvokeParam := &states.ContractInvokeParam{Address: utils.NodeManagerContractAddress,
+		Method: node_manager.REPORT_EQUIVOCATION, Args: args.Bytes()}
+	invokeCode := new(common.ZeroCopySink)
+	contractInvokeParam.Serialization(invokeCode)
+	tx := genesis.NewInvokeTransaction(invokeCode.Bytes(), blkNum)
+	return tx
+}
+
 //checkNeedUpdateChainConfig use blockcount
 func (self *Server) checkNeedUpdateChainConfig(blockNum uint32) bool {
 	prevBlk, _ := self.blockPool.getSealedBlock(blockNum - 1)
@@ -2176,7 +2267,7 @@ func (self *Server) checkNeedUpdateChainConfig(blockNum uint32) bool {
 
 //checkUpdateChainConfig query leveldb check is force update
 func (self *Server) checkUpdateChainConfig(blkNum uint32) bool {
-	force, err := isUpdate(self.blockPool.getExecWriteSet(blkNum-1), self.config.View)
+	force, err := isUpdate(self.blockPool.getExecWriteSet(blkNum-1), self.ledger, self.config.View)
 	if err != nil {
 		log.Errorf("checkUpdateChainConfig err:%s", err)
 		return false
@@ -2226,7 +2317,7 @@ func (self *Server) makeProposal(blkNum uint32, forEmpty bool) error {
 	cfg := &vconfig.ChainConfig{}
 	cfg = nil
 	if self.checkNeedUpdateChainConfig(blkNum) || self.checkUpdateChainConfig(blkNum) {
-		chainconfig, err := getChainConfig(self.blockPool.getExecWriteSet(blkNum-1), blkNum)
+		chainconfig, err := getChainConfig(self.blockPool.getExecWriteSet(blkNum-1), self.ledger, blkNum)
 		if err != nil {
 			return fmt.Errorf("getChainConfig failed:%s", err)
 		}
@@ -2242,9 +2333,18 @@ func (self *Server) makeProposal(blkNum uint32, forEmpty bool) error {
 	if self.nonConsensusNode() {
 		return fmt.Errorf("%d quit consensus node", self.Index)
 	}
+	//add transactions reporting equivocation evidences, not mixed with chainconfig updating
+	if cfg == nil && blkNum >= config.GetActivationHeight(config.FEATURE_EQUIVOCATION_SLASH, config.DefConfig.P2PNode.NetworkId) {
+		for _, evidence := range self.equivPool.GetEvidences(MAX_EVIDENCE_PER_BLOCK) {
+			sysTxs = append(sysTxs, self.createEquivocationTransaction(evidence, blkNum))
+		}
+	}
 
 	if !forEmpty {
 		for _, e := range self.poolActor.GetTxnPool(true, validHeight) {
+			if err := e.Tx.VerifyValidity(blkNum); err != nil {
+				continue
+			}
 			if err := self.incrValidator.Verify(e.Tx, validHeight); err == nil {
 				userTxs = append(userTxs, e.Tx)
 			}
@@ -2340,6 +2440,23 @@ func (self *Server) fetchProposal(blkNum uint32, proposer uint32) error {
 	return nil
 }
 
+// checkLeaderProposal records a missed proposal if the leader proposal has not arrived on proposal timeout
+func (self *Server) checkLeaderProposal(blkNum uint32) {
+	if !isReady(self.getState()) {
+		return
+	}
+	leader, present := self.getLeaderProposer(blkNum)
+	if !present {
+		return
+	}
+	for _, p := range self.blockPool.getBlockProposals(blkNum) {
+		if p.Block.getProposer() == leader {
+			return
+		}
+	}
+	self.metrics.onMissedProposal(leader)
+}
+
 func (self *Server) handleProposalTimeout(evt *TimerEvent) error {
 	if self.blockPool.endorsedForBlock(evt.blockNum) {
 		return nil
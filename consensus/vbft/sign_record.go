@@ -0,0 +1,189 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/polynetwork/poly/common"
+)
+
+const SIGN_RECORD_FILE = "vbft_sign_record"
+
+// SignRecord keeps the blocks signed by the server at its last signed height,
+// an honest server signs at most one proposal (block and empty block), one endorsement,
+// one empty endorsement and one commitment of a height
+type SignRecord struct {
+	BlockNum      uint32
+	Proposal      common.Uint256
+	EmptyProposal common.Uint256
+	Endorsed      common.Uint256
+	EmptyEndorsed common.Uint256
+	Committed     common.Uint256
+}
+
+func (self *SignRecord) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(self.BlockNum)
+	sink.WriteHash(self.Proposal)
+	sink.WriteHash(self.EmptyProposal)
+	sink.WriteHash(self.Endorsed)
+	sink.WriteHash(self.EmptyEndorsed)
+	sink.WriteHash(self.Committed)
+}
+
+func (self *SignRecord) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	self.BlockNum, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	for _, hash := range []*common.Uint256{&self.Proposal, &self.EmptyProposal, &self.Endorsed,
+		&self.EmptyEndorsed, &self.Committed} {
+		*hash, eof = source.NextHash()
+		if eof {
+			return io.ErrUnexpectedEOF
+		}
+	}
+	return nil
+}
+
+// signRecordStore persists the sign record before any signature leaves the server,
+// so that a restarted server never signs conflicting blocks of the height it signed before
+type signRecordStore struct {
+	lock   sync.Mutex
+	path   string
+	record *SignRecord
+}
+
+func openSignRecordStore(path string) (*signRecordStore, error) {
+	store := &signRecordStore{
+		path:   path,
+		record: &SignRecord{},
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read sign record %s: %s", path, err)
+	}
+	if err := store.record.Deserialization(common.NewZeroCopySource(data)); err != nil {
+		return nil, fmt.Errorf("load sign record %s: %s", path, err)
+	}
+	return store, nil
+}
+
+// signProposal records the block and empty block proposed by the server
+func (self *signRecordStore) signProposal(blkNum uint32, blkHash, emptyBlkHash common.Uint256) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	record, err := self.recordOf(blkNum)
+	if err != nil {
+		return err
+	}
+	if record.Proposal != common.UINT256_EMPTY &&
+		(record.Proposal != blkHash || record.EmptyProposal != emptyBlkHash) {
+		return fmt.Errorf("block %d has been proposed: %s", blkNum, record.Proposal.ToHexString())
+	}
+	record.Proposal, record.EmptyProposal = blkHash, emptyBlkHash
+	return self.save(record)
+}
+
+// signEndorsement records the block endorsed by the server
+func (self *signRecordStore) signEndorsement(blkNum uint32, blkHash common.Uint256, forEmpty bool) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	record, err := self.recordOf(blkNum)
+	if err != nil {
+		return err
+	}
+	endorsed := &record.Endorsed
+	if forEmpty {
+		endorsed = &record.EmptyEndorsed
+	}
+	if *endorsed != common.UINT256_EMPTY && *endorsed != blkHash {
+		return fmt.Errorf("block %d has been endorsed: %s, empty: %t", blkNum, endorsed.ToHexString(), forEmpty)
+	}
+	*endorsed = blkHash
+	return self.save(record)
+}
+
+// signCommitment records the block committed by the server
+func (self *signRecordStore) signCommitment(blkNum uint32, blkHash common.Uint256) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	record, err := self.recordOf(blkNum)
+	if err != nil {
+		return err
+	}
+	if record.Committed != common.UINT256_EMPTY && record.Committed != blkHash {
+		return fmt.Errorf("block %d has been committed: %s", blkNum, record.Committed.ToHexString())
+	}
+	record.Committed = blkHash
+	return self.save(record)
+}
+
+// recordOf returns a copy of the record to update for blkNum, blocks below the last signed height are refused
+func (self *signRecordStore) recordOf(blkNum uint32) (*SignRecord, error) {
+	if blkNum < self.record.BlockNum {
+		return nil, fmt.Errorf("block %d is below last signed block %d", blkNum, self.record.BlockNum)
+	}
+	if blkNum > self.record.BlockNum {
+		return &SignRecord{BlockNum: blkNum}, nil
+	}
+	record := *self.record
+	return &record, nil
+}
+
+func (self *signRecordStore) save(record *SignRecord) error {
+	if *record == *self.record {
+		return nil
+	}
+	sink := common.NewZeroCopySink(nil)
+	record.Serialization(sink)
+
+	tmp := self.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("save sign record: %s", err)
+	}
+	if _, err := f.Write(sink.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("save sign record: %s", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("save sign record: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("save sign record: %s", err)
+	}
+	if err := os.Rename(tmp, self.path); err != nil {
+		return fmt.Errorf("save sign record: %s", err)
+	}
+	self.record = record
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+)
+
+func TestSignRecordStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vbft_sign_record")
+	if err != nil {
+		t.Fatalf("create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, SIGN_RECORD_FILE)
+
+	store, err := openSignRecordStore(path)
+	if err != nil {
+		t.Fatalf("open sign record: %s", err)
+	}
+	if err := store.signProposal(10, common.Uint256{1}, common.Uint256{2}); err != nil {
+		t.Fatalf("sign proposal: %s", err)
+	}
+	if err := store.signEndorsement(10, common.Uint256{1}, false); err != nil {
+		t.Fatalf("sign endorsement: %s", err)
+	}
+	if err := store.signEndorsement(10, common.Uint256{3}, true); err != nil {
+		t.Fatalf("sign empty endorsement: %s", err)
+	}
+	if err := store.signCommitment(10, common.Uint256{1}); err != nil {
+		t.Fatalf("sign commitment: %s", err)
+	}
+	// signing the same blocks again is allowed
+	if err := store.signEndorsement(10, common.Uint256{1}, false); err != nil {
+		t.Fatalf("sign endorsement again: %s", err)
+	}
+
+	// the record survives restarting
+	store, err = openSignRecordStore(path)
+	if err != nil {
+		t.Fatalf("reopen sign record: %s", err)
+	}
+	if err := store.signProposal(10, common.Uint256{4}, common.Uint256{2}); err == nil {
+		t.Fatalf("signed conflicting proposal")
+	}
+	if err := store.signEndorsement(10, common.Uint256{4}, false); err == nil {
+		t.Fatalf("signed conflicting endorsement")
+	}
+	if err := store.signEndorsement(10, common.Uint256{4}, true); err == nil {
+		t.Fatalf("signed conflicting empty endorsement")
+	}
+	if err := store.signCommitment(10, common.Uint256{4}); err == nil {
+		t.Fatalf("signed conflicting commitment")
+	}
+	if err := store.signCommitment(9, common.Uint256{4}); err == nil {
+		t.Fatalf("signed block below last signed block")
+	}
+
+	// a new height starts a new record
+	if err := store.signProposal(11, common.Uint256{4}, common.Uint256{5}); err != nil {
+		t.Fatalf("sign proposal of next block: %s", err)
+	}
+	store, err = openSignRecordStore(path)
+	if err != nil {
+		t.Fatalf("reopen sign record: %s", err)
+	}
+	if store.record.BlockNum != 11 || store.record.Proposal != (common.Uint256{4}) || store.record.Committed != common.UINT256_EMPTY {
+		t.Fatalf("unexpected record: %+v", store.record)
+	}
+}
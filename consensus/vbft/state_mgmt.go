@@ -168,6 +168,7 @@ func (self *StateMgr) run() {
 					log.Errorf("server %d, live ticker: %s", self.server.Index, err)
 				}
 			}
+			self.server.metrics.setState(self.currentState)
 
 		case <-self.server.quitC:
 			log.Infof("server %d, state mgr quit", self.server.Index)
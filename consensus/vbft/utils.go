@@ -125,8 +125,8 @@ func verifyVrf(pk keypair.PublicKey, blkNum uint32, prevVrf, newVrf, proof []byt
 	}
 	return nil
 }
-func GetVbftConfigInfo(memdb *overlaydb.MemDB) (*config.VBFTConfig, error) {
-	data, err := GetStorageValue(memdb, ledger.DefLedger, nutils.NodeManagerContractAddress, []byte(node_manager.VBFT_CONFIG))
+func GetVbftConfigInfo(memdb *overlaydb.MemDB, backend *ledger.Ledger) (*config.VBFTConfig, error) {
+	data, err := GetStorageValue(memdb, backend, nutils.NodeManagerContractAddress, []byte(node_manager.VBFT_CONFIG))
 	if err != nil {
 		return nil, err
 	}
@@ -145,14 +145,14 @@ func GetVbftConfigInfo(memdb *overlaydb.MemDB) (*config.VBFTConfig, error) {
 	return chainconfig, nil
 }
 
-func GetPeersConfig(memdb *overlaydb.MemDB) ([]*config.VBFTPeerInfo, error) {
-	goveranceview, err := GetGovernanceView(memdb)
+func GetPeersConfig(memdb *overlaydb.MemDB, backend *ledger.Ledger) ([]*config.VBFTPeerInfo, error) {
+	goveranceview, err := GetGovernanceView(memdb, backend)
 	if err != nil {
 		return nil, err
 	}
 	viewBytes := nutils.GetUint32Bytes(goveranceview.View)
 	key := append([]byte(node_manager.PEER_POOL), viewBytes...)
-	data, err := GetStorageValue(memdb, ledger.DefLedger, nutils.NodeManagerContractAddress, key)
+	data, err := GetStorageValue(memdb, backend, nutils.NodeManagerContractAddress, key)
 	if err != nil {
 		return nil, err
 	}
@@ -176,8 +176,8 @@ func GetPeersConfig(memdb *overlaydb.MemDB) ([]*config.VBFTPeerInfo, error) {
 	return peerstakes, nil
 }
 
-func isUpdate(memdb *overlaydb.MemDB, view uint32) (bool, error) {
-	goveranceview, err := GetGovernanceView(memdb)
+func isUpdate(memdb *overlaydb.MemDB, backend *ledger.Ledger, view uint32) (bool, error) {
+	goveranceview, err := GetGovernanceView(memdb, backend)
 	if err != nil {
 		return false, err
 	}
@@ -211,8 +211,8 @@ func GetStorageValue(memdb *overlaydb.MemDB, backend *ledger.Ledger, addr common
 	return
 }
 
-func GetGovernanceView(memdb *overlaydb.MemDB) (*node_manager.GovernanceView, error) {
-	value, err := GetStorageValue(memdb, ledger.DefLedger, nutils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW))
+func GetGovernanceView(memdb *overlaydb.MemDB, backend *ledger.Ledger) (*node_manager.GovernanceView, error) {
+	value, err := GetStorageValue(memdb, backend, nutils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW))
 	if err != nil {
 		return nil, err
 	}
@@ -224,17 +224,17 @@ func GetGovernanceView(memdb *overlaydb.MemDB) (*node_manager.GovernanceView, er
 	return governanceView, nil
 }
 
-func getChainConfig(memdb *overlaydb.MemDB, blkNum uint32) (*vconfig.ChainConfig, error) {
-	config, err := GetVbftConfigInfo(memdb)
+func getChainConfig(memdb *overlaydb.MemDB, backend *ledger.Ledger, blkNum uint32) (*vconfig.ChainConfig, error) {
+	config, err := GetVbftConfigInfo(memdb, backend)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chainconfig from leveldb: %s", err)
 	}
 
-	peersinfo, err := GetPeersConfig(memdb)
+	peersinfo, err := GetPeersConfig(memdb, backend)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get peersinfo from leveldb: %s", err)
 	}
-	goverview, err := GetGovernanceView(memdb)
+	goverview, err := GetGovernanceView(memdb, backend)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get governanceview failed:%s", err)
 	}
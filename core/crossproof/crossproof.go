@@ -0,0 +1,143 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package crossproof verifies the proof of a cross chain tx made in poly the way target chains do,
+// so that relayers and target chain developers share one reference implementation
+package crossproof
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/merkle"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+)
+
+// Keepers returns the consensus peers set by the config block cfgHeader,
+// they sign the blocks until the next config block
+func Keepers(cfgHeader *types.Header) ([]keypair.PublicKey, error) {
+	blkInfo, err := vconfig.VbftBlock(cfgHeader)
+	if err != nil {
+		return nil, fmt.Errorf("Keepers, %v", err)
+	}
+	if blkInfo.NewChainConfig == nil {
+		return nil, fmt.Errorf("Keepers, block %d is not a config block", cfgHeader.Height)
+	}
+	keepers := make([]keypair.PublicKey, 0, len(blkInfo.NewChainConfig.Peers))
+	for _, peer := range blkInfo.NewChainConfig.Peers {
+		pk, err := vconfig.Pubkey(peer.ID)
+		if err != nil {
+			return nil, fmt.Errorf("Keepers, peer %s: %v", peer.ID, err)
+		}
+		keepers = append(keepers, pk)
+	}
+	return keepers, nil
+}
+
+// VerifyHeader checks that sigs of header are made by at least n - (n - 1) / 3 of the n keepers
+func VerifyHeader(header *types.Header, sigs [][]byte, keepers []keypair.PublicKey) error {
+	n := len(keepers)
+	if n == 0 {
+		return fmt.Errorf("VerifyHeader, no keepers")
+	}
+	hash := header.Hash()
+	if err := signature.VerifyMultiSignature(hash[:], keepers, n-(n-1)/3, sigs); err != nil {
+		return fmt.Errorf("VerifyHeader, header %d: %v", header.Height, err)
+	}
+	return nil
+}
+
+// VerifyHeaderProof checks that header is a block before signedHeader, by the merkle path headerProof
+// of its hash to the block root of signedHeader
+func VerifyHeaderProof(header *types.Header, headerProof []byte, signedHeader *types.Header) error {
+	if header.Height >= signedHeader.Height {
+		return fmt.Errorf("VerifyHeaderProof, header %d is not before signed header %d", header.Height, signedHeader.Height)
+	}
+	value, err := merkle.MerkleProve(headerProof, signedHeader.BlockRoot[:])
+	if err != nil {
+		return fmt.Errorf("VerifyHeaderProof, merkle.MerkleProve error: %v", err)
+	}
+	hash := header.Hash()
+	if !bytes.Equal(value, hash[:]) {
+		return fmt.Errorf("VerifyHeaderProof, proven hash %x is not hash %s of header %d", value, hash.ToHexString(), header.Height)
+	}
+	return nil
+}
+
+// VerifyCrossStatesProof proves a cross chain tx against the cross state root of header. The root in the
+// header of a block commits the cross states of its previous block, where the tx is made
+func VerifyCrossStatesProof(header *types.Header, proof []byte) (*scom.ToMerkleValue, error) {
+	raw, err := merkle.MerkleProve(proof, header.CrossStateRoot[:])
+	if err != nil {
+		return nil, fmt.Errorf("VerifyCrossStatesProof, merkle.MerkleProve error: %v", err)
+	}
+	source := common.NewZeroCopySource(raw)
+	value := new(scom.ToMerkleValue)
+	if err := value.Deserialization(source); err != nil {
+		return nil, fmt.Errorf("VerifyCrossStatesProof, deserialize merkle value error: %v", err)
+	}
+	if source.Len() != 0 {
+		return nil, fmt.Errorf("VerifyCrossStatesProof, %d trailing bytes after merkle value", source.Len())
+	}
+	return value, nil
+}
+
+// Verify fully validates the cross chain tx proven by proof at header, which is signed by keepers with sigs.
+// The proven value is returned, and if expected is not nil it must be equal to it
+func Verify(keepers []keypair.PublicKey, header *types.Header, sigs [][]byte, proof []byte,
+	expected *scom.ToMerkleValue) (*scom.ToMerkleValue, error) {
+	if err := VerifyHeader(header, sigs, keepers); err != nil {
+		return nil, err
+	}
+	return verifyValue(header, proof, expected)
+}
+
+// VerifyWithHeaderProof is Verify for a header before the epoch of keepers, which is proven by headerProof
+// to the block root of signedHeader, signedHeader is signed by keepers with sigs
+func VerifyWithHeaderProof(keepers []keypair.PublicKey, header *types.Header, headerProof []byte,
+	signedHeader *types.Header, sigs [][]byte, proof []byte, expected *scom.ToMerkleValue) (*scom.ToMerkleValue, error) {
+	if err := VerifyHeader(signedHeader, sigs, keepers); err != nil {
+		return nil, err
+	}
+	if err := VerifyHeaderProof(header, headerProof, signedHeader); err != nil {
+		return nil, err
+	}
+	return verifyValue(header, proof, expected)
+}
+
+func verifyValue(header *types.Header, proof []byte, expected *scom.ToMerkleValue) (*scom.ToMerkleValue, error) {
+	value, err := VerifyCrossStatesProof(header, proof)
+	if err != nil {
+		return nil, err
+	}
+	if expected != nil {
+		sink, expectedSink := common.NewZeroCopySink(nil), common.NewZeroCopySink(nil)
+		value.Serialization(sink)
+		expected.Serialization(expectedSink)
+		if !bytes.Equal(sink.Bytes(), expectedSink.Bytes()) {
+			return nil, fmt.Errorf("verifyValue, proven merkle value of tx %x is not the expected one of tx %x",
+				value.TxHash, expected.TxHash)
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package crossproof
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/merkle"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccounts(n int) ([]*account.Account, []keypair.PublicKey) {
+	accts := make([]*account.Account, 0, n)
+	keepers := make([]keypair.PublicKey, 0, n)
+	for i := 0; i < n; i++ {
+		acct := account.NewAccount("")
+		accts = append(accts, acct)
+		keepers = append(keepers, acct.PublicKey)
+	}
+	return accts, keepers
+}
+
+func signHeader(t *testing.T, header *types.Header, accts []*account.Account) {
+	hash := header.Hash()
+	header.SigData = nil
+	for _, acct := range accts {
+		sig, err := signature.Sign(acct, hash[:])
+		require.Nil(t, err)
+		header.SigData = append(header.SigData, sig)
+	}
+}
+
+func merkleValue(i int) *scom.ToMerkleValue {
+	return &scom.ToMerkleValue{
+		TxHash:      []byte{byte(i), 1},
+		FromChainID: 2,
+		MakeTxParam: &scom.MakeTxParam{
+			TxHash:              []byte{byte(i), 2},
+			CrossChainID:        []byte{byte(i), 3},
+			FromContractAddress: []byte{4},
+			ToChainID:           6,
+			ToContractAddress:   []byte{5},
+			Method:              "unlock",
+			Args:                []byte{byte(i)},
+		},
+	}
+}
+
+// crossStates returns the values made by a block, their cross state root and the proof of each value
+func crossStates(t *testing.T, n int) ([]*scom.ToMerkleValue, common.Uint256, [][]byte) {
+	values := make([]*scom.ToMerkleValue, 0, n)
+	raws := make([][]byte, 0, n)
+	hashes := make([]common.Uint256, 0, n)
+	for i := 0; i < n; i++ {
+		sink := common.NewZeroCopySink(nil)
+		values = append(values, merkleValue(i))
+		values[i].Serialization(sink)
+		raws = append(raws, sink.Bytes())
+		hashes = append(hashes, merkle.HashLeaf(sink.Bytes()))
+	}
+	proofs := make([][]byte, 0, n)
+	for _, raw := range raws {
+		proof, err := merkle.MerkleLeafPath(raw, hashes)
+		require.Nil(t, err)
+		proofs = append(proofs, proof)
+	}
+	return values, merkle.TreeHasher{}.HashFullTreeWithLeafHash(hashes), proofs
+}
+
+func TestKeepers(t *testing.T) {
+	_, keepers := newAccounts(4)
+	cfg := &vconfig.ChainConfig{}
+	for i, keeper := range keepers {
+		cfg.Peers = append(cfg.Peers, &vconfig.PeerConfig{Index: uint32(i), ID: vconfig.PubkeyID(keeper)})
+	}
+	payload, err := json.Marshal(&vconfig.VbftBlockInfo{NewChainConfig: cfg})
+	require.Nil(t, err)
+	got, err := Keepers(&types.Header{Height: 10, ConsensusPayload: payload})
+	assert.Nil(t, err)
+	assert.Equal(t, keepers, got)
+
+	payload, err = json.Marshal(&vconfig.VbftBlockInfo{LastConfigBlockNum: 10})
+	require.Nil(t, err)
+	_, err = Keepers(&types.Header{Height: 11, ConsensusPayload: payload})
+	assert.NotNil(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	accts, keepers := newAccounts(4)
+	values, root, proofs := crossStates(t, 3)
+	header := &types.Header{Height: 100, CrossStateRoot: root}
+
+	// 3 of 4 keepers are required
+	signHeader(t, header, accts[:2])
+	_, err := Verify(keepers, header, header.SigData, proofs[0], nil)
+	assert.NotNil(t, err)
+	signHeader(t, header, []*account.Account{accts[0], accts[0], accts[1]})
+	_, err = Verify(keepers, header, header.SigData, proofs[0], nil)
+	assert.NotNil(t, err)
+
+	signHeader(t, header, accts[1:])
+	for i, proof := range proofs {
+		value, err := Verify(keepers, header, header.SigData, proof, values[i])
+		assert.Nil(t, err)
+		assert.Equal(t, values[i], value)
+	}
+	_, err = Verify(keepers, header, header.SigData, proofs[0], values[1])
+	assert.NotNil(t, err)
+
+	// not signed by keepers
+	others, otherKeepers := newAccounts(4)
+	_, err = Verify(otherKeepers, header, header.SigData, proofs[0], nil)
+	assert.NotNil(t, err)
+	signHeader(t, header, others)
+	_, err = Verify(keepers, header, header.SigData, proofs[0], nil)
+	assert.NotNil(t, err)
+
+	// proof of another cross state root
+	_, _, otherProofs := crossStates(t, 5)
+	signHeader(t, header, accts)
+	_, err = Verify(keepers, header, header.SigData, otherProofs[4], nil)
+	assert.NotNil(t, err)
+}
+
+func TestVerifyWithHeaderProof(t *testing.T) {
+	accts, keepers := newAccounts(7)
+	values, root, proofs := crossStates(t, 2)
+
+	headers := make([]*types.Header, 0)
+	hashes := make([]common.Uint256, 0)
+	for i := uint32(0); i < 8; i++ {
+		header := &types.Header{Height: i, Timestamp: i}
+		if i == 5 {
+			header.CrossStateRoot = root
+		}
+		hash := header.Hash()
+		headers = append(headers, header)
+		hashes = append(hashes, merkle.HashLeaf(hash[:]))
+	}
+	signedHeader := &types.Header{Height: 8, BlockRoot: merkle.TreeHasher{}.HashFullTreeWithLeafHash(hashes)}
+	signHeader(t, signedHeader, accts[:5])
+	hash := headers[5].Hash()
+	headerProof, err := merkle.MerkleLeafPath(hash[:], hashes)
+	require.Nil(t, err)
+
+	value, err := VerifyWithHeaderProof(keepers, headers[5], headerProof, signedHeader, signedHeader.SigData, proofs[1], nil)
+	assert.Nil(t, err)
+	assert.Equal(t, values[1], value)
+
+	// header proof of another block
+	hash = headers[4].Hash()
+	otherProof, err := merkle.MerkleLeafPath(hash[:], hashes)
+	require.Nil(t, err)
+	_, err = VerifyWithHeaderProof(keepers, headers[5], otherProof, signedHeader, signedHeader.SigData, proofs[1], nil)
+	assert.NotNil(t, err)
+
+	// header must be before the signed header
+	_, err = VerifyWithHeaderProof(keepers, signedHeader, headerProof, headers[5], signedHeader.SigData, proofs[1], nil)
+	assert.NotNil(t, err)
+
+	// 4 of 7 keepers are not enough
+	signHeader(t, signedHeader, accts[:4])
+	_, err = VerifyWithHeaderProof(keepers, headers[5], headerProof, signedHeader, signedHeader.SigData, proofs[1], nil)
+	assert.NotNil(t, err)
+}
@@ -102,7 +102,7 @@ func NewInvokeTransaction(invokeCode []byte, nonce uint32) *types.Transaction {
 		Code: invokeCode,
 	}
 	tx := &types.Transaction{
-		Version: types.CURR_TX_VERSION,
+		Version: types.TX_VERSION_LEGACY,
 		TxType:  types.Invoke,
 		Payload: invokePayload,
 		Nonce:   nonce,
@@ -26,6 +26,7 @@ import (
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/core/store"
+	scom "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/store/ledgerstore"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native/event"
@@ -189,6 +190,22 @@ func (self *Ledger) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify
 	return self.ldgStore.GetEventNotifyByBlock(height)
 }
 
+func (self *Ledger) GetCrossChainTx(fromChainID uint64, crossChainID []byte) (*scom.CrossChainTx, error) {
+	return self.ldgStore.GetCrossChainTx(fromChainID, crossChainID)
+}
+
+func (self *Ledger) GetCrossChainTxsByHeight(height uint32) ([]*scom.CrossChainTx, error) {
+	return self.ldgStore.GetCrossChainTxsByHeight(height)
+}
+
+func (self *Ledger) ExportSnapshot(dir string) (*scom.SnapshotManifest, error) {
+	return self.ldgStore.ExportSnapshot(dir)
+}
+
+func (self *Ledger) ImportSnapshot(dir string, trustedStateHash common.Uint256) (*scom.SnapshotManifest, error) {
+	return self.ldgStore.ImportSnapshot(dir, trustedStateHash)
+}
+
 func (self *Ledger) Close() error {
 	return self.ldgStore.Close()
 }
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+)
+
+// CrossChainTx record of a cross chain tx which made proof in poly
+type CrossChainTx struct {
+	FromChainID  uint64
+	CrossChainID []byte //Cross chain id from source chain
+	TxHash       []byte //Tx hash of MakeTxParam from source chain
+	ToChainID    uint64
+	PolyTxHash   common.Uint256 //Hash of poly transaction making the proof
+	PolyHeight   uint32
+	ProofKey     []byte //Storage key of the request, used to get the cross states proof
+}
+
+func (this *CrossChainTx) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.FromChainID)
+	sink.WriteVarBytes(this.CrossChainID)
+	sink.WriteVarBytes(this.TxHash)
+	sink.WriteUint64(this.ToChainID)
+	sink.WriteHash(this.PolyTxHash)
+	sink.WriteUint32(this.PolyHeight)
+	sink.WriteVarBytes(this.ProofKey)
+}
+
+func (this *CrossChainTx) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.FromChainID, eof = source.NextUint64()
+	if eof {
+		return fmt.Errorf("CrossChainTx deserialize FromChainID error")
+	}
+	this.CrossChainID, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("CrossChainTx deserialize CrossChainID error")
+	}
+	this.TxHash, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("CrossChainTx deserialize TxHash error")
+	}
+	this.ToChainID, eof = source.NextUint64()
+	if eof {
+		return fmt.Errorf("CrossChainTx deserialize ToChainID error")
+	}
+	this.PolyTxHash, eof = source.NextHash()
+	if eof {
+		return fmt.Errorf("CrossChainTx deserialize PolyTxHash error")
+	}
+	this.PolyHeight, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("CrossChainTx deserialize PolyHeight error")
+	}
+	this.ProofKey, eof = source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("CrossChainTx deserialize ProofKey error")
+	}
+	return nil
+}
@@ -45,6 +45,10 @@ const (
 	SYS_STATE_MERKLE_TREE  DataEntryPrefix = 0x20 // state merkle tree root key prefix
 	SYS_CROSS_STATES       DataEntryPrefix = 0x22
 	SYS_CROSS_STATES_HASH  DataEntryPrefix = 0x23
+	SYS_PRUNED_BLOCK       DataEntryPrefix = 0x17 //Highest pruned block height key prefix
 
 	EVENT_NOTIFY DataEntryPrefix = 0x14 //Event notify key prefix
+
+	EVENT_CROSS_CHAIN_TX     DataEntryPrefix = 0x15 //From chain id + cross chain id => cross chain tx key prefix
+	IX_CROSS_CHAIN_TX_HEIGHT DataEntryPrefix = 0x16 //Block height + from chain id + cross chain id key prefix
 )
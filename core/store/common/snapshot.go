@@ -0,0 +1,291 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/types"
+)
+
+// Kind of snapshot chunk
+const (
+	SNAPSHOT_CHUNK_HASHES byte = 0x01 //Consecutive block hashes from Start
+	SNAPSHOT_CHUNK_BLOCK  byte = 0x02 //A full block kept by the snapshot
+	SNAPSHOT_CHUNK_STATES byte = 0x03 //Raw key-value pairs of state store
+)
+
+const (
+	SNAPSHOT_VERSION       = byte(1)
+	SNAPSHOT_BASE_DIR      = "snapshot"          //Snapshots are saved in SNAPSHOT_BASE_DIR/<height> of ledger data dir
+	SNAPSHOT_DOWNLOAD_DIR  = "snapshot-download" //Snapshots fetched from peers are saved in SNAPSHOT_DOWNLOAD_DIR/<height>
+	SNAPSHOT_MANIFEST_FILE = "manifest"
+	SNAPSHOT_CHUNK_FILE    = "chunk-%06d"
+)
+
+// SnapshotManifest describes a state snapshot taken at Height, the chunks are
+// verified by their hashes and the snapshot by the signed header of BlockHash.
+// StateHash commits to BlockHash and the full key space of state store, it is
+// what an operator gets from a trusted node to import the snapshot
+type SnapshotManifest struct {
+	Version     byte
+	Height      uint32
+	BlockHash   common.Uint256
+	StateHash   common.Uint256
+	ChunkHashes []common.Uint256
+}
+
+func (this *SnapshotManifest) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteByte(this.Version)
+	sink.WriteUint32(this.Height)
+	sink.WriteHash(this.BlockHash)
+	sink.WriteHash(this.StateHash)
+	sink.WriteVarUint(uint64(len(this.ChunkHashes)))
+	for _, hash := range this.ChunkHashes {
+		sink.WriteHash(hash)
+	}
+}
+
+func (this *SnapshotManifest) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Version, eof = source.NextByte()
+	if eof {
+		return fmt.Errorf("SnapshotManifest deserialize Version error")
+	}
+	this.Height, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("SnapshotManifest deserialize Height error")
+	}
+	this.BlockHash, eof = source.NextHash()
+	if eof {
+		return fmt.Errorf("SnapshotManifest deserialize BlockHash error")
+	}
+	this.StateHash, eof = source.NextHash()
+	if eof {
+		return fmt.Errorf("SnapshotManifest deserialize StateHash error")
+	}
+	n, eof := source.NextVarUint()
+	if eof || n > source.Len()/common.UINT256_SIZE {
+		return fmt.Errorf("SnapshotManifest deserialize ChunkHashes length error")
+	}
+	this.ChunkHashes = make([]common.Uint256, 0, n)
+	for i := uint64(0); i < n; i++ {
+		hash, eof := source.NextHash()
+		if eof {
+			return fmt.Errorf("SnapshotManifest deserialize ChunkHashes error")
+		}
+		this.ChunkHashes = append(this.ChunkHashes, hash)
+	}
+	return nil
+}
+
+// Hash return the identity of snapshot
+func (this *SnapshotManifest) Hash() common.Uint256 {
+	sink := common.NewZeroCopySink(nil)
+	this.Serialization(sink)
+	return sha256.Sum256(sink.Bytes())
+}
+
+// SnapshotStateHasher computes the StateHash of snapshot from the block hash and the
+// state key-value pairs written in the order of state store
+type SnapshotStateHasher struct {
+	hasher hash.Hash
+}
+
+func NewSnapshotStateHasher(blockHash common.Uint256) *SnapshotStateHasher {
+	hasher := sha256.New()
+	hasher.Write(blockHash[:])
+	return &SnapshotStateHasher{hasher: hasher}
+}
+
+func (this *SnapshotStateHasher) Write(key, value []byte) {
+	sink := common.NewZeroCopySink(make([]byte, 0, len(key)+len(value)+18))
+	sink.WriteVarBytes(key)
+	sink.WriteVarBytes(value)
+	this.hasher.Write(sink.Bytes())
+}
+
+func (this *SnapshotStateHasher) Sum() common.Uint256 {
+	var hash common.Uint256
+	copy(hash[:], this.hasher.Sum(nil))
+	return hash
+}
+
+// SnapshotChunk is a piece of snapshot, only the fields of its Kind are used
+type SnapshotChunk struct {
+	Kind   byte
+	Start  uint32
+	Hashes []common.Uint256
+	Block  *types.Block
+	Keys   [][]byte
+	Values [][]byte
+}
+
+func (this *SnapshotChunk) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteByte(this.Kind)
+	switch this.Kind {
+	case SNAPSHOT_CHUNK_HASHES:
+		sink.WriteUint32(this.Start)
+		sink.WriteVarUint(uint64(len(this.Hashes)))
+		for _, hash := range this.Hashes {
+			sink.WriteHash(hash)
+		}
+	case SNAPSHOT_CHUNK_BLOCK:
+		if err := this.Block.Serialization(sink); err != nil {
+			return err
+		}
+	case SNAPSHOT_CHUNK_STATES:
+		if len(this.Keys) != len(this.Values) {
+			return fmt.Errorf("SnapshotChunk keys count %d not equal values count %d", len(this.Keys), len(this.Values))
+		}
+		sink.WriteVarUint(uint64(len(this.Keys)))
+		for i, key := range this.Keys {
+			sink.WriteVarBytes(key)
+			sink.WriteVarBytes(this.Values[i])
+		}
+	default:
+		return fmt.Errorf("unknown snapshot chunk kind %d", this.Kind)
+	}
+	return nil
+}
+
+func (this *SnapshotChunk) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Kind, eof = source.NextByte()
+	if eof {
+		return fmt.Errorf("SnapshotChunk deserialize Kind error")
+	}
+	switch this.Kind {
+	case SNAPSHOT_CHUNK_HASHES:
+		this.Start, eof = source.NextUint32()
+		if eof {
+			return fmt.Errorf("SnapshotChunk deserialize Start error")
+		}
+		n, eof := source.NextVarUint()
+		if eof || n > source.Len()/common.UINT256_SIZE {
+			return fmt.Errorf("SnapshotChunk deserialize Hashes length error")
+		}
+		this.Hashes = make([]common.Uint256, 0, n)
+		for i := uint64(0); i < n; i++ {
+			hash, eof := source.NextHash()
+			if eof {
+				return fmt.Errorf("SnapshotChunk deserialize Hashes error")
+			}
+			this.Hashes = append(this.Hashes, hash)
+		}
+	case SNAPSHOT_CHUNK_BLOCK:
+		this.Block = new(types.Block)
+		if err := this.Block.Deserialization(source); err != nil {
+			return fmt.Errorf("SnapshotChunk deserialize Block error %s", err)
+		}
+	case SNAPSHOT_CHUNK_STATES:
+		n, eof := source.NextVarUint()
+		if eof || n > source.Len() {
+			return fmt.Errorf("SnapshotChunk deserialize States length error")
+		}
+		this.Keys = make([][]byte, 0, n)
+		this.Values = make([][]byte, 0, n)
+		for i := uint64(0); i < n; i++ {
+			key, eof := source.NextVarBytes()
+			if eof {
+				return fmt.Errorf("SnapshotChunk deserialize Keys error")
+			}
+			value, eof := source.NextVarBytes()
+			if eof {
+				return fmt.Errorf("SnapshotChunk deserialize Values error")
+			}
+			this.Keys = append(this.Keys, key)
+			this.Values = append(this.Values, value)
+		}
+	default:
+		return fmt.Errorf("unknown snapshot chunk kind %d", this.Kind)
+	}
+	return nil
+}
+
+// GetSnapshotDir return the directory of snapshot at height under base directory
+func GetSnapshotDir(base string, height uint32) string {
+	return filepath.Join(base, strconv.FormatUint(uint64(height), 10))
+}
+
+// GetLatestSnapshotDir return the directory of the highest snapshot under base directory
+func GetLatestSnapshotDir(base string) (string, error) {
+	infos, err := ioutil.ReadDir(base)
+	if err != nil {
+		return "", err
+	}
+	heights := make([]uint64, 0, len(infos))
+	for _, info := range infos {
+		height, err := strconv.ParseUint(info.Name(), 10, 32)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		heights = append(heights, height)
+	}
+	if len(heights) == 0 {
+		return "", ErrNotFound
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+	return GetSnapshotDir(base, uint32(heights[0])), nil
+}
+
+// SaveSnapshotManifest persist manifest to snapshot directory
+func SaveSnapshotManifest(dir string, manifest *SnapshotManifest) error {
+	sink := common.NewZeroCopySink(nil)
+	manifest.Serialization(sink)
+	return ioutil.WriteFile(filepath.Join(dir, SNAPSHOT_MANIFEST_FILE), sink.Bytes(), 0644)
+}
+
+// LoadSnapshotManifest return the manifest in snapshot directory
+func LoadSnapshotManifest(dir string) (*SnapshotManifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, SNAPSHOT_MANIFEST_FILE))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	manifest := new(SnapshotManifest)
+	if err = manifest.Deserialization(common.NewZeroCopySource(data)); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// SaveSnapshotChunk persist the serialized chunk of index to snapshot directory
+func SaveSnapshotChunk(dir string, index int, data []byte) error {
+	return ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf(SNAPSHOT_CHUNK_FILE, index)), data, 0644)
+}
+
+// LoadSnapshotChunk return the serialized chunk of index in snapshot directory
+func LoadSnapshotChunk(dir string, index int) ([]byte, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, fmt.Sprintf(SNAPSHOT_CHUNK_FILE, index)))
+	if err != nil && os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
@@ -90,6 +90,8 @@ type EventStore interface {
 	SaveEventNotifyByBlock(height uint32, txHashs []common.Uint256) error
 	//GetEventNotifyByTx return event notify by transaction hash
 	GetEventNotifyByTx(txHash common.Uint256) (*event.ExecuteNotify, error)
+	//SaveCrossChainTx save cross chain tx made in poly
+	SaveCrossChainTx(tx *CrossChainTx) error
 	//Commit event notify to store
 	CommitTo() error
 }
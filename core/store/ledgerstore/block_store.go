@@ -356,6 +356,10 @@ func (this *BlockStore) loadTransaction(txHash common.Uint256) (*types.Transacti
 	if eof {
 		return nil, 0, io.ErrUnexpectedEOF
 	}
+	if source.Len() == 0 {
+		//transaction body has been pruned, only the height marker is left
+		return nil, height, scom.ErrNotFound
+	}
 	tx = new(types.Transaction)
 	err = tx.Deserialization(source)
 	if err != nil {
@@ -383,6 +387,51 @@ func (this *BlockStore) ContainTransaction(txHash common.Uint256) (bool, error)
 	return true, nil
 }
 
+//PruneBlock drop the transactions of block, but keep the header and the height of every transaction,
+//so that duplicated transactions can still be detected by ContainTransaction
+func (this *BlockStore) PruneBlock(blockHash common.Uint256) error {
+	_, txHashes, err := this.loadHeaderWithTx(blockHash)
+	if err != nil {
+		return err
+	}
+	for _, txHash := range txHashes {
+		key := this.getTransactionKey(txHash)
+		value, err := this.store.Get(key)
+		if err != nil {
+			if err == scom.ErrNotFound {
+				continue
+			}
+			return err
+		}
+		if len(value) > 4 {
+			this.store.BatchPut(key, value[:4])
+		}
+	}
+	return nil
+}
+
+//GetPrunedHeight return the highest block height which has been pruned
+func (this *BlockStore) GetPrunedHeight() (uint32, error) {
+	value, err := this.store.Get(this.getPrunedBlockKey())
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if len(value) != 4 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return binary.LittleEndian.Uint32(value), nil
+}
+
+//SavePrunedHeight persist the highest block height which has been pruned
+func (this *BlockStore) SavePrunedHeight(height uint32) {
+	value := make([]byte, 4)
+	binary.LittleEndian.PutUint32(value, height)
+	this.store.BatchPut(this.getPrunedBlockKey(), value)
+}
+
 //GetVersion return the version of store
 func (this *BlockStore) GetVersion() (byte, error) {
 	key := this.getVersionKey()
@@ -454,6 +503,10 @@ func (this *BlockStore) getBlockMerkleTreeKey() []byte {
 	return []byte{byte(scom.SYS_BLOCK_MERKLE_TREE)}
 }
 
+func (this *BlockStore) getPrunedBlockKey() []byte {
+	return []byte{byte(scom.SYS_PRUNED_BLOCK)}
+}
+
 func (this *BlockStore) getVersionKey() []byte {
 	return []byte{byte(scom.SYS_VERSION)}
 }
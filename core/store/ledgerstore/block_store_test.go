@@ -25,6 +25,7 @@ import (
 	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/core/payload"
+	scom "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/types"
 	"testing"
 	"time"
@@ -348,3 +349,73 @@ func TestBlock(t *testing.T) {
 		return
 	}
 }
+
+func TestPruneBlock(t *testing.T) {
+	tx := &types.Transaction{
+		TxType:     types.Invoke,
+		Nonce:      2,
+		Payload:    &payload.InvokeCode{Code: []byte("prune")},
+		Attributes: []byte{},
+	}
+	sink := common.NewZeroCopySink(nil)
+	if err := tx.Serialization(sink); err != nil {
+		t.Errorf("Serialization error %s", err)
+		return
+	}
+	tx, err := types.TransactionFromRawBytes(sink.Bytes())
+	if err != nil {
+		t.Errorf("TransactionFromRawBytes error %s", err)
+		return
+	}
+	block := &types.Block{
+		Header:       &types.Header{Height: 2},
+		Transactions: []*types.Transaction{tx},
+	}
+	blockHash := block.Hash()
+	txHash := tx.Hash()
+
+	testBlockStore.NewBatch()
+	if err = testBlockStore.SaveBlock(block); err != nil {
+		t.Errorf("SaveBlock error %s", err)
+		return
+	}
+	if err = testBlockStore.CommitTo(); err != nil {
+		t.Errorf("CommitTo error %s", err)
+		return
+	}
+
+	testBlockStore.NewBatch()
+	if err = testBlockStore.PruneBlock(blockHash); err != nil {
+		t.Errorf("PruneBlock error %s", err)
+		return
+	}
+	testBlockStore.SavePrunedHeight(2)
+	if err = testBlockStore.CommitTo(); err != nil {
+		t.Errorf("CommitTo error %s", err)
+		return
+	}
+
+	if _, err = testBlockStore.GetHeader(blockHash); err != nil {
+		t.Errorf("GetHeader of pruned block error %s", err)
+		return
+	}
+	if _, err = testBlockStore.GetBlock(blockHash); err == nil {
+		t.Errorf("TestPruneBlock GetBlock should fail after pruning")
+		return
+	}
+	_, height, err := testBlockStore.GetTransaction(txHash)
+	if err != scom.ErrNotFound || height != 2 {
+		t.Errorf("TestPruneBlock GetTransaction height:%d error:%v", height, err)
+		return
+	}
+	exist, err := testBlockStore.ContainTransaction(txHash)
+	if err != nil || !exist {
+		t.Errorf("TestPruneBlock ContainTransaction should be true after pruning")
+		return
+	}
+	prunedHeight, err := testBlockStore.GetPrunedHeight()
+	if err != nil || prunedHeight != 2 {
+		t.Errorf("TestPruneBlock GetPrunedHeight %d error:%v", prunedHeight, err)
+		return
+	}
+}
@@ -134,6 +134,91 @@ func (this *EventStore) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNo
 	return evtNotifies, nil
 }
 
+//SaveCrossChainTx persist cross chain tx and index it by block height
+func (this *EventStore) SaveCrossChainTx(tx *scom.CrossChainTx) error {
+	sink := common.NewZeroCopySink(nil)
+	tx.Serialization(sink)
+	this.store.BatchPut(this.getCrossChainTxKey(tx.FromChainID, tx.CrossChainID), sink.Bytes())
+	this.store.BatchPut(this.getCrossChainTxHeightKey(tx.PolyHeight, tx.FromChainID, tx.CrossChainID), []byte{})
+	return nil
+}
+
+//GetCrossChainTx return cross chain tx by from chain id and cross chain id
+func (this *EventStore) GetCrossChainTx(fromChainID uint64, crossChainID []byte) (*scom.CrossChainTx, error) {
+	data, err := this.store.Get(this.getCrossChainTxKey(fromChainID, crossChainID))
+	if err != nil {
+		return nil, err
+	}
+	tx := new(scom.CrossChainTx)
+	if err = tx.Deserialization(common.NewZeroCopySource(data)); err != nil {
+		return nil, fmt.Errorf("CrossChainTx.Deserialization error %s", err)
+	}
+	return tx, nil
+}
+
+//GetCrossChainTxsByHeight return all cross chain tx made in block
+func (this *EventStore) GetCrossChainTxsByHeight(height uint32) ([]*scom.CrossChainTx, error) {
+	prefix := this.getCrossChainTxHeightKey(height, 0, nil)[:5]
+	iter := this.store.NewIterator(prefix)
+	defer iter.Release()
+	txs := make([]*scom.CrossChainTx, 0)
+	for iter.Next() {
+		key := iter.Key()
+		if len(key) < 13 {
+			continue
+		}
+		tx, err := this.GetCrossChainTx(binary.LittleEndian.Uint64(key[5:13]), key[13:])
+		if err != nil {
+			return nil, fmt.Errorf("getCrossChainTx Height:%d error:%s", height, err)
+		}
+		txs = append(txs, tx)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+//PruneEvents delete the event notifies and cross chain txs made in block
+func (this *EventStore) PruneEvents(height uint32) error {
+	key, err := this.getEventNotifyByBlockKey(height)
+	if err != nil {
+		return err
+	}
+	data, err := this.store.Get(key)
+	if err != nil && err != scom.ErrNotFound {
+		return err
+	}
+	if err == nil {
+		reader := bytes.NewBuffer(data)
+		size, err := serialization.ReadUint32(reader)
+		if err != nil {
+			return fmt.Errorf("ReadUint32 error %s", err)
+		}
+		for i := uint32(0); i < size; i++ {
+			var txHash common.Uint256
+			if err = txHash.Deserialize(reader); err != nil {
+				return fmt.Errorf("txHash.Deserialize error %s", err)
+			}
+			this.store.BatchDelete(this.getEventNotifyByTxKey(txHash))
+		}
+		this.store.BatchDelete(key)
+	}
+
+	prefix := this.getCrossChainTxHeightKey(height, 0, nil)[:5]
+	iter := this.store.NewIterator(prefix)
+	defer iter.Release()
+	for iter.Next() {
+		key := iter.Key()
+		if len(key) < 13 {
+			continue
+		}
+		this.store.BatchDelete(this.getCrossChainTxKey(binary.LittleEndian.Uint64(key[5:13]), key[13:]))
+		this.store.BatchDelete(append([]byte{}, key...))
+	}
+	return iter.Error()
+}
+
 //CommitTo event store batch to store
 func (this *EventStore) CommitTo() error {
 	return this.store.BatchCommit()
@@ -207,3 +292,20 @@ func (this *EventStore) getEventNotifyByTxKey(txHash common.Uint256) []byte {
 	copy(key[1:], data)
 	return key
 }
+
+func (this *EventStore) getCrossChainTxKey(fromChainID uint64, crossChainID []byte) []byte {
+	key := make([]byte, 9+len(crossChainID))
+	key[0] = byte(scom.EVENT_CROSS_CHAIN_TX)
+	binary.LittleEndian.PutUint64(key[1:], fromChainID)
+	copy(key[9:], crossChainID)
+	return key
+}
+
+func (this *EventStore) getCrossChainTxHeightKey(height uint32, fromChainID uint64, crossChainID []byte) []byte {
+	key := make([]byte, 13+len(crossChainID))
+	key[0] = byte(scom.IX_CROSS_CHAIN_TX_HEIGHT)
+	binary.BigEndian.PutUint32(key[1:], height)
+	binary.LittleEndian.PutUint64(key[5:], fromChainID)
+	copy(key[13:], crossChainID)
+	return key
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/native/event"
+	ccom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveCrossChainTxs(t *testing.T) {
+	enable := config.DefConfig.Common.EnableEventLog
+	config.DefConfig.Common.EnableEventLog = true
+	defer func() { config.DefConfig.Common.EnableEventLog = enable }()
+
+	polyTxHash := common.Uint256{1, 2, 3}
+	notify := &event.ExecuteNotify{
+		TxHash: polyTxHash,
+		State:  event.CONTRACT_STATE_SUCCESS,
+		Notify: []*event.NotifyEventInfo{
+			{
+				ContractAddress: utils.HeaderSyncContractAddress,
+				States:          []interface{}{"other"},
+			},
+			{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States: []interface{}{ccom.NOTIFY_MAKE_PROOF, uint64(2), uint64(3), hex.EncodeToString([]byte{4}),
+					uint32(100), hex.EncodeToString([]byte{5}), hex.EncodeToString([]byte{6})},
+			},
+		},
+	}
+	store := testLedgerStore.eventStore
+	store.NewBatch()
+	assert.NoError(t, SaveCrossChainTxs(store, 100, notify))
+	assert.NoError(t, store.CommitTo())
+
+	tx, err := store.GetCrossChainTx(2, []byte{6})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), tx.ToChainID)
+	assert.Equal(t, []byte{4}, tx.TxHash)
+	assert.Equal(t, []byte{5}, tx.ProofKey)
+	assert.Equal(t, polyTxHash, tx.PolyTxHash)
+	assert.Equal(t, uint32(100), tx.PolyHeight)
+
+	txs, err := store.GetCrossChainTxsByHeight(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []*scom.CrossChainTx{tx}, txs)
+
+	txs, err = store.GetCrossChainTxsByHeight(101)
+	assert.NoError(t, err)
+	assert.Empty(t, txs)
+}
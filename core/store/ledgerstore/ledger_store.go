@@ -51,6 +51,7 @@ import (
 const (
 	SYSTEM_VERSION          = byte(1)      //Version of ledger store
 	HEADER_INDEX_BATCH_SIZE = uint32(2000) //Bath size of saving header index
+	PRUNE_BATCH_SIZE        = uint32(100)  //Max count of blocks pruned when saving a block
 )
 
 var (
@@ -656,6 +657,10 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block, result sto
 		if err != nil {
 			return fmt.Errorf("SaveNotify error %s", err)
 		}
+		err = SaveCrossChainTxs(this.eventStore, blockHeight, notify)
+		if err != nil {
+			return fmt.Errorf("SaveCrossChainTxs error %s", err)
+		}
 	}
 
 	err := this.stateStore.AddStateMerkleTreeRoot(blockHeight, result.Hash)
@@ -712,6 +717,45 @@ func (this *LedgerStoreImp) saveBlockToEventStore(block *types.Block) error {
 	return nil
 }
 
+//pruneBlocks drop the block bodies, events and cross states which fall out of the retained window.
+//Headers, the block merkle tree and cross states roots are always kept, so GetMerkleProof keeps working
+//for all heights and GetCrossStatesProof for the retained window. Bodies of vbft config blocks are kept for consensus.
+func (this *LedgerStoreImp) pruneBlocks(blockHeight uint32) error {
+	keep := uint32(config.DefConfig.Common.PruneKeepBlocks)
+	if keep == 0 || blockHeight <= keep {
+		return nil
+	}
+	prunedHeight, err := this.blockStore.GetPrunedHeight()
+	if err != nil {
+		return fmt.Errorf("GetPrunedHeight error %s", err)
+	}
+	end := blockHeight - keep
+	if end > prunedHeight+PRUNE_BATCH_SIZE {
+		end = prunedHeight + PRUNE_BATCH_SIZE
+	}
+	if end <= prunedHeight {
+		return nil
+	}
+	for height := prunedHeight + 1; height <= end; height++ {
+		blockHash := this.getHeaderIndex(height)
+		header, err := this.blockStore.GetHeader(blockHash)
+		if err != nil {
+			return fmt.Errorf("GetHeader height:%d error %s", height, err)
+		}
+		if blkInfo, err := vconfig.VbftBlock(header); err != nil || blkInfo.NewChainConfig == nil {
+			if err = this.blockStore.PruneBlock(blockHash); err != nil {
+				return fmt.Errorf("PruneBlock height:%d error %s", height, err)
+			}
+		}
+		if err = this.eventStore.PruneEvents(height); err != nil {
+			return fmt.Errorf("PruneEvents height:%d error %s", height, err)
+		}
+		this.stateStore.PruneCrossStates(height)
+	}
+	this.blockStore.SavePrunedHeight(end)
+	return nil
+}
+
 func (this *LedgerStoreImp) tryGetSavingBlockLock() (hasLocked bool) {
 	select {
 	case this.savingBlockSemaphore <- true:
@@ -759,6 +803,10 @@ func (this *LedgerStoreImp) submitBlock(block *types.Block, result store.Execute
 	if err != nil {
 		return fmt.Errorf("save to event store height:%d error:%s", blockHeight, err)
 	}
+	err = this.pruneBlocks(blockHeight)
+	if err != nil {
+		return fmt.Errorf("prune blocks height:%d error:%s", blockHeight, err)
+	}
 	err = this.blockStore.CommitTo()
 	if err != nil {
 		return fmt.Errorf("blockStore.CommitTo height:%d error %s", blockHeight, err)
@@ -811,6 +859,10 @@ func (this *LedgerStoreImp) saveBlock(block *types.Block, stateMerkleRoot common
 func (this *LedgerStoreImp) handleTransaction(overlay *overlaydb.OverlayDB, cache *storage.CacheDB, block *types.Block, tx *types.Transaction) (*event.ExecuteNotify, []common.Uint256, error) {
 	txHash := tx.Hash()
 	notify := &event.ExecuteNotify{TxHash: txHash, State: event.CONTRACT_STATE_FAIL}
+	if err := tx.VerifyValidity(block.Header.Height); err != nil {
+		log.Debugf("handleTransaction tx %s error %s", txHash.ToHexString(), err)
+		return notify, nil, nil
+	}
 	if tx.TxType == types.Invoke {
 		crossHashes, err := this.stateStore.HandleInvokeTransaction(this, overlay, cache, tx, block, notify)
 		if overlay.Error() != nil {
@@ -870,11 +922,14 @@ func (this *LedgerStoreImp) PreExecuteContract(tx *types.Transaction) (*cstates.
 	if err != nil {
 		return result, fmt.Errorf("PreExecuteContract Error: %+v\n", err)
 	}
+	service.SetBlockHashes(this.GetBlockHash)
 	res, err := service.Invoke()
+	result.Gas = service.GasConsumed()
 	if err != nil {
 		return result, err
 	}
-	return &sstate.PreExecResult{State: event.CONTRACT_STATE_SUCCESS, Result: common.ToHexString(res.([]byte)), Notify: service.GetNotify()}, nil
+	return &sstate.PreExecResult{State: event.CONTRACT_STATE_SUCCESS, Gas: service.GasConsumed(),
+		Result: common.ToHexString(res.([]byte)), Notify: service.GetNotify()}, nil
 }
 
 //IsContainBlock return whether the block is in store
@@ -971,6 +1026,16 @@ func (this *LedgerStoreImp) GetEventNotifyByBlock(height uint32) ([]*event.Execu
 	return this.eventStore.GetEventNotifyByBlock(height)
 }
 
+//GetCrossChainTx return the cross chain tx made in poly. Wrap function of EventStore.GetCrossChainTx
+func (this *LedgerStoreImp) GetCrossChainTx(fromChainID uint64, crossChainID []byte) (*scom.CrossChainTx, error) {
+	return this.eventStore.GetCrossChainTx(fromChainID, crossChainID)
+}
+
+//GetCrossChainTxsByHeight return the cross chain txs made in block. Wrap function of EventStore.GetCrossChainTxsByHeight
+func (this *LedgerStoreImp) GetCrossChainTxsByHeight(height uint32) ([]*scom.CrossChainTx, error) {
+	return this.eventStore.GetCrossChainTxsByHeight(height)
+}
+
 //Close ledger store.
 func (this *LedgerStoreImp) Close() error {
 	err := this.blockStore.Close()
@@ -0,0 +1,364 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/consensus/vbft/config"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/merkle"
+)
+
+const (
+	SNAPSHOT_HISTORY_BLOCKS    = uint32(128)                  //Count of latest full blocks kept by snapshot
+	SNAPSHOT_HASHES_PER_CHUNK  = 50 * HEADER_INDEX_BATCH_SIZE //Count of block hashes per snapshot chunk
+	SNAPSHOT_STATES_CHUNK_SIZE = 4 * 1024 * 1024              //Max size of state key-value pairs per snapshot chunk
+)
+
+// ExportSnapshot write the snapshot of current block to dir. The snapshot holds all block hashes,
+// the genesis block, the last config block, the latest SNAPSHOT_HISTORY_BLOCKS blocks and the full key space
+// of state store, including the block merkle tree, the state merkle tree and the cross states.
+func (this *LedgerStoreImp) ExportSnapshot(dir string) (*scom.SnapshotManifest, error) {
+	this.getSavingBlockLock()
+	defer this.releaseSavingBlockLock()
+
+	height, blockHash := this.GetCurrentBlock()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("MkdirAll error %s", err)
+	}
+	manifest := &scom.SnapshotManifest{
+		Version:   scom.SNAPSHOT_VERSION,
+		Height:    height,
+		BlockHash: blockHash,
+	}
+	saveChunk := func(chunk *scom.SnapshotChunk) error {
+		sink := common.NewZeroCopySink(nil)
+		if err := chunk.Serialization(sink); err != nil {
+			return err
+		}
+		if err := scom.SaveSnapshotChunk(dir, len(manifest.ChunkHashes), sink.Bytes()); err != nil {
+			return err
+		}
+		manifest.ChunkHashes = append(manifest.ChunkHashes, sha256.Sum256(sink.Bytes()))
+		return nil
+	}
+
+	for start := uint32(0); start <= height; start += SNAPSHOT_HASHES_PER_CHUNK {
+		chunk := &scom.SnapshotChunk{Kind: scom.SNAPSHOT_CHUNK_HASHES, Start: start}
+		for h := start; h <= height && h-start < SNAPSHOT_HASHES_PER_CHUNK; h++ {
+			chunk.Hashes = append(chunk.Hashes, this.getHeaderIndex(h))
+		}
+		if err := saveChunk(chunk); err != nil {
+			return nil, fmt.Errorf("save block hashes from %d error %s", start, err)
+		}
+	}
+
+	header, err := this.GetHeaderByHash(blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderByHash error %s", err)
+	}
+	for _, h := range snapshotBlockHeights(header) {
+		block, err := this.GetBlockByHeight(h)
+		if err != nil {
+			return nil, fmt.Errorf("GetBlockByHeight %d error %s", h, err)
+		}
+		if err = saveChunk(&scom.SnapshotChunk{Kind: scom.SNAPSHOT_CHUNK_BLOCK, Block: block}); err != nil {
+			return nil, fmt.Errorf("save block %d error %s", h, err)
+		}
+	}
+
+	iter := this.stateStore.store.NewIterator(nil)
+	defer iter.Release()
+	hasher := scom.NewSnapshotStateHasher(blockHash)
+	chunk := &scom.SnapshotChunk{Kind: scom.SNAPSHOT_CHUNK_STATES}
+	size := 0
+	for iter.Next() {
+		key := append([]byte{}, iter.Key()...)
+		value := append([]byte{}, iter.Value()...)
+		hasher.Write(key, value)
+		chunk.Keys = append(chunk.Keys, key)
+		chunk.Values = append(chunk.Values, value)
+		size += len(key) + len(value)
+		if size >= SNAPSHOT_STATES_CHUNK_SIZE {
+			if err = saveChunk(chunk); err != nil {
+				return nil, fmt.Errorf("save states error %s", err)
+			}
+			chunk = &scom.SnapshotChunk{Kind: scom.SNAPSHOT_CHUNK_STATES}
+			size = 0
+		}
+	}
+	if err = iter.Error(); err != nil {
+		return nil, fmt.Errorf("iterate states error %s", err)
+	}
+	if len(chunk.Keys) > 0 {
+		if err = saveChunk(chunk); err != nil {
+			return nil, fmt.Errorf("save states error %s", err)
+		}
+	}
+	manifest.StateHash = hasher.Sum()
+
+	if err = scom.SaveSnapshotManifest(dir, manifest); err != nil {
+		return nil, fmt.Errorf("SaveSnapshotManifest error %s", err)
+	}
+	return manifest, nil
+}
+
+// ImportSnapshot fill the empty ledger store with the snapshot in dir. Chunks are checked against the manifest,
+// the block hashes against the BlockRoot of the signed header at snapshot height, the cross states root against
+// its CrossStateRoot, and the header is verified by the bookkeepers of the last config block.
+// The states are only self-consistent with the snapshot, so trustedStateHash, the StateHash printed by
+// the export on a trusted node, is required and checked against the states actually imported.
+func (this *LedgerStoreImp) ImportSnapshot(dir string, trustedStateHash common.Uint256) (*scom.SnapshotManifest, error) {
+	if trustedStateHash == common.UINT256_EMPTY {
+		return nil, fmt.Errorf("trusted state hash is required")
+	}
+	hasInit, err := this.hasAlreadyInitGenesisBlock()
+	if err != nil {
+		return nil, fmt.Errorf("hasAlreadyInit error %s", err)
+	}
+	if hasInit {
+		return nil, fmt.Errorf("ledger store is not empty")
+	}
+	manifest, err := scom.LoadSnapshotManifest(dir)
+	if err != nil {
+		return nil, fmt.Errorf("LoadSnapshotManifest error %s", err)
+	}
+	if manifest.Version != scom.SNAPSHOT_VERSION {
+		return nil, fmt.Errorf("unsupported snapshot version %d", manifest.Version)
+	}
+	if trustedStateHash != manifest.StateHash {
+		return nil, fmt.Errorf("snapshot state hash %s is not the trusted state hash %s",
+			manifest.StateHash.ToHexString(), trustedStateHash.ToHexString())
+	}
+	if err = this.blockStore.ClearAll(); err != nil {
+		return nil, fmt.Errorf("blockStore.ClearAll error %s", err)
+	}
+	if err = this.stateStore.ClearAll(); err != nil {
+		return nil, fmt.Errorf("stateStore.ClearAll error %s", err)
+	}
+	if err = this.eventStore.ClearAll(); err != nil {
+		return nil, fmt.Errorf("eventStore.ClearAll error %s", err)
+	}
+
+	height := manifest.Height
+	nextHeight := uint32(0)
+	blockTree := merkle.NewTree(0, nil, this.stateStore.merkleHashStore)
+	blockTree.Append(common.UINT256_EMPTY.ToArray()) //prev block hash of genesis block
+	hasher := scom.NewSnapshotStateHasher(manifest.BlockHash)
+	for i, chunkHash := range manifest.ChunkHashes {
+		data, err := scom.LoadSnapshotChunk(dir, i)
+		if err != nil {
+			return nil, fmt.Errorf("LoadSnapshotChunk %d error %s", i, err)
+		}
+		if common.Uint256(sha256.Sum256(data)) != chunkHash {
+			return nil, fmt.Errorf("snapshot chunk %d hash mismatch", i)
+		}
+		chunk := new(scom.SnapshotChunk)
+		if err = chunk.Deserialization(common.NewZeroCopySource(data)); err != nil {
+			return nil, fmt.Errorf("snapshot chunk %d deserialize error %s", i, err)
+		}
+		this.blockStore.NewBatch()
+		this.stateStore.NewBatch()
+		switch chunk.Kind {
+		case scom.SNAPSHOT_CHUNK_HASHES:
+			if chunk.Start != nextHeight || uint64(nextHeight)+uint64(len(chunk.Hashes)) > uint64(height)+1 {
+				return nil, fmt.Errorf("snapshot chunk %d unexpected block hashes from %d", i, chunk.Start)
+			}
+			for _, hash := range chunk.Hashes {
+				this.setHeaderIndex(nextHeight, hash)
+				this.blockStore.SaveBlockHash(nextHeight, hash)
+				if nextHeight < height {
+					blockTree.Append(hash.ToArray())
+				}
+				nextHeight++
+			}
+		case scom.SNAPSHOT_CHUNK_BLOCK:
+			block := chunk.Block
+			if nextHeight != height+1 || block.Header.Height > height || this.getHeaderIndex(block.Header.Height) != block.Hash() {
+				return nil, fmt.Errorf("snapshot chunk %d unexpected block %d", i, block.Header.Height)
+			}
+			txHashes := make([]common.Uint256, 0, len(block.Transactions))
+			for _, tx := range block.Transactions {
+				txHashes = append(txHashes, tx.Hash())
+			}
+			if common.ComputeMerkleRoot(txHashes) != block.Header.TransactionsRoot {
+				return nil, fmt.Errorf("snapshot chunk %d mismatched transaction root of block %d", i, block.Header.Height)
+			}
+			if err = this.blockStore.SaveBlock(block); err != nil {
+				return nil, fmt.Errorf("SaveBlock %d error %s", block.Header.Height, err)
+			}
+		case scom.SNAPSHOT_CHUNK_STATES:
+			for j, key := range chunk.Keys {
+				hasher.Write(key, chunk.Values[j])
+				this.stateStore.BatchPutRawKeyVal(key, chunk.Values[j])
+			}
+		default:
+			return nil, fmt.Errorf("snapshot chunk %d unknown kind %d", i, chunk.Kind)
+		}
+		if err = this.blockStore.CommitTo(); err != nil {
+			return nil, fmt.Errorf("blockStore.CommitTo error %s", err)
+		}
+		if err = this.stateStore.CommitTo(); err != nil {
+			return nil, fmt.Errorf("stateStore.CommitTo error %s", err)
+		}
+	}
+
+	if stateHash := hasher.Sum(); stateHash != trustedStateHash {
+		return nil, fmt.Errorf("imported state hash %s is not the trusted state hash %s",
+			stateHash.ToHexString(), trustedStateHash.ToHexString())
+	}
+	if err = this.verifySnapshot(manifest, blockTree); err != nil {
+		return nil, fmt.Errorf("verify snapshot error %s", err)
+	}
+	if err = this.stateStore.merkleHashStore.Flush(); err != nil {
+		return nil, fmt.Errorf("merkle hash store flush error %s", err)
+	}
+
+	this.blockStore.NewBatch()
+	for start := uint32(0); start+HEADER_INDEX_BATCH_SIZE <= height; start += HEADER_INDEX_BATCH_SIZE {
+		list := make([]common.Uint256, 0, HEADER_INDEX_BATCH_SIZE)
+		for h := start; h < start+HEADER_INDEX_BATCH_SIZE; h++ {
+			list = append(list, this.getHeaderIndex(h))
+		}
+		if err = this.blockStore.SaveHeaderIndexList(start, list); err != nil {
+			return nil, fmt.Errorf("SaveHeaderIndexList error %s", err)
+		}
+	}
+	if err = this.blockStore.SaveCurrentBlock(height, manifest.BlockHash); err != nil {
+		return nil, fmt.Errorf("blockStore.SaveCurrentBlock error %s", err)
+	}
+	if height >= SNAPSHOT_HISTORY_BLOCKS {
+		this.blockStore.SavePrunedHeight(height - SNAPSHOT_HISTORY_BLOCKS)
+	}
+	if err = this.blockStore.CommitTo(); err != nil {
+		return nil, fmt.Errorf("blockStore.CommitTo error %s", err)
+	}
+	this.eventStore.NewBatch()
+	if err = this.eventStore.SaveCurrentBlock(height, manifest.BlockHash); err != nil {
+		return nil, fmt.Errorf("eventStore.SaveCurrentBlock error %s", err)
+	}
+	if err = this.eventStore.CommitTo(); err != nil {
+		return nil, fmt.Errorf("eventStore.CommitTo error %s", err)
+	}
+	if err = this.initGenesisBlock(); err != nil {
+		return nil, fmt.Errorf("init error %s", err)
+	}
+	log.Infof("import snapshot at height %d hash %s success", height, manifest.BlockHash.ToHexString())
+	return manifest, nil
+}
+
+func (this *LedgerStoreImp) verifySnapshot(manifest *scom.SnapshotManifest, blockTree *merkle.CompactMerkleTree) error {
+	height := manifest.Height
+	if uint32(len(this.headerIndex)) != height+1 || this.getHeaderIndex(height) != manifest.BlockHash {
+		return fmt.Errorf("block hashes are not complete")
+	}
+	header, err := this.blockStore.GetHeader(manifest.BlockHash)
+	if err != nil {
+		return fmt.Errorf("get snapshot header error %s", err)
+	}
+	if height > 0 {
+		if blockRoot := blockTree.Root(); blockRoot != header.BlockRoot {
+			return fmt.Errorf("block root %s is not %s of header", blockRoot.ToHexString(), header.BlockRoot.ToHexString())
+		}
+		crossStateRoot, err := this.stateStore.GetCrossStateRoot(height - 1)
+		if err != nil {
+			return fmt.Errorf("GetCrossStateRoot error %s", err)
+		}
+		if crossStateRoot != header.CrossStateRoot {
+			return fmt.Errorf("cross state root %s is not %s of header", crossStateRoot.ToHexString(), header.CrossStateRoot.ToHexString())
+		}
+	}
+
+	treeSize, hashes, err := this.stateStore.GetBlockMerkleTree()
+	if err != nil {
+		return fmt.Errorf("GetBlockMerkleTree error %s", err)
+	}
+	if treeSize != blockTree.TreeSize() || merkle.NewTree(treeSize, hashes, nil).Root() != blockTree.Root() {
+		return fmt.Errorf("block merkle tree is inconsistent with block hashes")
+	}
+	treeSize, hashes, err = this.stateStore.GetStateMerkleTree()
+	if err != nil && err != scom.ErrNotFound {
+		return fmt.Errorf("GetStateMerkleTree error %s", err)
+	}
+	stateRoot, err := this.stateStore.GetStateMerkleRoot(height)
+	if err != nil {
+		return fmt.Errorf("GetStateMerkleRoot error %s", err)
+	}
+	if merkle.NewTree(treeSize, hashes, nil).Root() != stateRoot {
+		return fmt.Errorf("state merkle tree is inconsistent with state merkle root")
+	}
+	stateHash, stateHeight, err := this.stateStore.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("stateStore.GetCurrentBlock error %s", err)
+	}
+	if stateHash != manifest.BlockHash || stateHeight != height {
+		return fmt.Errorf("states are saved at height %d, not snapshot height", stateHeight)
+	}
+
+	peerInfo := make(map[string]uint32)
+	if blkInfo, err := vconfig.VbftBlock(header); err == nil && blkInfo.LastConfigBlockNum != math.MaxUint32 {
+		cfgHeader, err := this.blockStore.GetHeader(this.getHeaderIndex(blkInfo.LastConfigBlockNum))
+		if err != nil {
+			return fmt.Errorf("get config header %d error %s", blkInfo.LastConfigBlockNum, err)
+		}
+		cfgInfo, err := vconfig.VbftBlock(cfgHeader)
+		if err != nil {
+			return err
+		}
+		if cfgInfo.NewChainConfig == nil {
+			return fmt.Errorf("block %d is not a config block", blkInfo.LastConfigBlockNum)
+		}
+		for _, p := range cfgInfo.NewChainConfig.Peers {
+			peerInfo[p.ID] = p.Index
+		}
+	}
+	if _, err = this.verifyHeader(header, peerInfo); err != nil {
+		return fmt.Errorf("verifyHeader error %s", err)
+	}
+	return nil
+}
+
+// snapshotBlockHeights return the heights of full blocks kept by snapshot of header
+func snapshotBlockHeights(header *types.Header) []uint32 {
+	heights := map[uint32]bool{0: true}
+	if blkInfo, err := vconfig.VbftBlock(header); err == nil && blkInfo.LastConfigBlockNum != math.MaxUint32 {
+		heights[blkInfo.LastConfigBlockNum] = true
+	}
+	start := uint32(0)
+	if header.Height >= SNAPSHOT_HISTORY_BLOCKS {
+		start = header.Height - SNAPSHOT_HISTORY_BLOCKS + 1
+	}
+	for h := start; h <= header.Height; h++ {
+		heights[h] = true
+	}
+	result := make([]uint32, 0, len(heights))
+	for h := range heights {
+		result = append(result, h)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir := "test/snapshot"
+	manifest, err := testLedgerStore.ExportSnapshot(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, testLedgerStore.GetCurrentBlockHeight(), manifest.Height)
+	assert.Equal(t, testLedgerStore.GetCurrentBlockHash(), manifest.BlockHash)
+
+	loaded, err := scom.LoadSnapshotManifest(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, manifest.Hash(), loaded.Hash())
+
+	store, err := NewLedgerStore("test/snapshot-ledger")
+	assert.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.ImportSnapshot(dir, common.Uint256{})
+	assert.Error(t, err, "trusted state hash should be required")
+	_, err = store.ImportSnapshot(dir, common.Uint256{1})
+	assert.Error(t, err, "snapshot of untrusted state hash should be refused")
+
+	imported, err := store.ImportSnapshot(dir, manifest.StateHash)
+	assert.NoError(t, err)
+	assert.Equal(t, manifest.Hash(), imported.Hash())
+
+	err = store.init()
+	assert.NoError(t, err)
+	assert.Equal(t, manifest.Height, store.GetCurrentBlockHeight())
+	assert.Equal(t, manifest.BlockHash, store.GetCurrentBlockHash())
+	root, err := store.GetStateMerkleRoot(manifest.Height)
+	assert.NoError(t, err)
+	expect, err := testLedgerStore.GetStateMerkleRoot(manifest.Height)
+	assert.NoError(t, err)
+	assert.Equal(t, expect, root)
+
+	_, err = store.ImportSnapshot(dir, manifest.StateHash)
+	assert.Error(t, err, "snapshot should only be imported to empty ledger")
+
+	// a consistent snapshot with forged states still claims the trusted state hash
+	last := len(manifest.ChunkHashes) - 1
+	data, err := scom.LoadSnapshotChunk(dir, last)
+	assert.NoError(t, err)
+	chunk := new(scom.SnapshotChunk)
+	assert.NoError(t, chunk.Deserialization(common.NewZeroCopySource(data)))
+	assert.Equal(t, scom.SNAPSHOT_CHUNK_STATES, chunk.Kind)
+	chunk.Values[0] = append(chunk.Values[0], 1)
+	sink := common.NewZeroCopySink(nil)
+	assert.NoError(t, chunk.Serialization(sink))
+	assert.NoError(t, scom.SaveSnapshotChunk(dir, last, sink.Bytes()))
+	manifest.ChunkHashes[last] = sha256.Sum256(sink.Bytes())
+	assert.NoError(t, scom.SaveSnapshotManifest(dir, manifest))
+
+	other, err := NewLedgerStore("test/snapshot-ledger2")
+	assert.NoError(t, err)
+	defer other.Close()
+	_, err = other.ImportSnapshot(dir, manifest.StateHash)
+	assert.Error(t, err, "forged states should be refused")
+	assert.Contains(t, err.Error(), "imported state hash")
+
+	assert.NoError(t, scom.SaveSnapshotChunk(dir, last, []byte{scom.SNAPSHOT_CHUNK_STATES, 0}))
+	_, err = other.ImportSnapshot(dir, manifest.StateHash)
+	assert.Error(t, err, "tampered chunk should be refused")
+}
@@ -243,6 +243,11 @@ func (self *StateStore) GetCrossStateRoot(height uint32) (common.Uint256, error)
 	return hash, nil
 }
 
+//PruneCrossStates delete the cross states of block, the cross states root is kept for consensus
+func (self *StateStore) PruneCrossStates(height uint32) {
+	self.store.BatchDelete(genCrossStatesKey(height))
+}
+
 func (self *StateStore) GetCrossStates(height uint32) (hashes []common.Uint256, err error) {
 	key := genCrossStatesKey(height)
 
@@ -19,6 +19,7 @@
 package ledgerstore
 
 import (
+	"encoding/hex"
 	"fmt"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
@@ -29,6 +30,8 @@ import (
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/event"
+	ccom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/polynetwork/poly/native/storage"
 )
 
@@ -41,7 +44,10 @@ func (self *StateStore) HandleInvokeTransaction(store store.LedgerStore, overlay
 	if err != nil {
 		return nil, fmt.Errorf("HandleInvokeTransaction Error: %+v\n", err)
 	}
-	if _, err := service.Invoke(); err != nil {
+	service.SetBlockHashes(store.GetBlockHash)
+	_, err = service.Invoke()
+	notify.GasConsumed = service.GasConsumed()
+	if err != nil {
 		return nil, err
 	}
 	notify.Notify = append(notify.Notify, service.GetNotify()...)
@@ -60,3 +66,55 @@ func SaveNotify(eventStore scommon.EventStore, txHash common.Uint256, notify *ev
 	event.PushSmartCodeEvent(txHash, 0, event.EVENT_NOTIFY, notify)
 	return nil
 }
+
+//SaveCrossChainTxs persist cross chain txs of which proofs are made by the transaction
+func SaveCrossChainTxs(eventStore scommon.EventStore, height uint32, notify *event.ExecuteNotify) error {
+	if !config.DefConfig.Common.EnableEventLog || notify.State != event.CONTRACT_STATE_SUCCESS {
+		return nil
+	}
+	for _, n := range notify.Notify {
+		tx, ok := crossChainTxFromNotify(n)
+		if !ok {
+			continue
+		}
+		tx.PolyTxHash, tx.PolyHeight = notify.TxHash, height
+		if err := eventStore.SaveCrossChainTx(tx); err != nil {
+			return fmt.Errorf("SaveCrossChainTx error %s", err)
+		}
+	}
+	return nil
+}
+
+//crossChainTxFromNotify parse the makeProof notify of cross chain manager
+func crossChainTxFromNotify(n *event.NotifyEventInfo) (*scommon.CrossChainTx, bool) {
+	if n.ContractAddress != utils.CrossChainManagerContractAddress {
+		return nil, false
+	}
+	states, ok := n.States.([]interface{})
+	if !ok || len(states) < 7 {
+		return nil, false
+	}
+	if name, _ := states[0].(string); name != ccom.NOTIFY_MAKE_PROOF {
+		return nil, false
+	}
+	fromChainID, ok1 := states[1].(uint64)
+	toChainID, ok2 := states[2].(uint64)
+	txHash, ok3 := states[3].(string)
+	key, ok4 := states[5].(string)
+	crossChainID, ok5 := states[6].(string)
+	if !(ok1 && ok2 && ok3 && ok4 && ok5) {
+		return nil, false
+	}
+	tx := &scommon.CrossChainTx{FromChainID: fromChainID, ToChainID: toChainID}
+	var err error
+	if tx.TxHash, err = hex.DecodeString(txHash); err != nil {
+		return nil, false
+	}
+	if tx.ProofKey, err = hex.DecodeString(key); err != nil {
+		return nil, false
+	}
+	if tx.CrossChainID, err = hex.DecodeString(crossChainID); err != nil {
+		return nil, false
+	}
+	return tx, true
+}
@@ -322,6 +322,22 @@ func (p *MemDB) Delete(key []byte) {
 	p.Put(key, nil)
 }
 
+// Erase removes the key from the MemDB, as if it had never been put or deleted.
+// It is a no-op if the MemDB does not contain the key
+func (p *MemDB) Erase(key []byte) {
+	node, exact := p.findGE(key, true)
+	if !exact {
+		return
+	}
+	h := p.nodeData[node+nHeight]
+	for i, n := range p.prevNode[:h] {
+		m := n + nNext + i
+		p.nodeData[m] = p.nodeData[p.nodeData[m]+nNext+i]
+	}
+	p.kvSize -= p.nodeData[node+nKey] + p.nodeData[node+nVal]
+	p.n--
+}
+
 // Get gets the value for the given key. It returns unkown == true if the
 // MemDB does not contain the key. It returns nil, false if MemDB has deleted the key
 //
@@ -35,3 +35,34 @@ func TestIter(t *testing.T) {
 	assert.Equal(t, iter.Last(), true)
 	assert.Equal(t, len(iter.Value()), 0)
 }
+
+func TestErase(t *testing.T) {
+	db := NewMemDB(0, 0)
+	for i := byte(0); i < 100; i++ {
+		db.Put([]byte{i}, []byte{i})
+	}
+	db.Delete([]byte{100})
+	for i := byte(0); i <= 100; i += 2 {
+		db.Erase([]byte{i})
+	}
+	db.Erase([]byte{200})
+	assert.Equal(t, 50, db.Len())
+	for i := byte(0); i <= 100; i++ {
+		value, unknown := db.Get([]byte{i})
+		assert.Equal(t, i%2 == 0, unknown)
+		if !unknown {
+			assert.Equal(t, []byte{i}, value)
+		}
+	}
+	count := 0
+	db.ForEach(func(key, val []byte) {
+		assert.Equal(t, byte(1), key[0]%2)
+		count++
+	})
+	assert.Equal(t, 50, count)
+
+	db.Put([]byte{0}, []byte{0})
+	value, unknown := db.Get([]byte{0})
+	assert.False(t, unknown)
+	assert.Equal(t, []byte{0}, value)
+}
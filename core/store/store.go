@@ -22,6 +22,7 @@ import (
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/core/states"
+	scom "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/store/overlaydb"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native/event"
@@ -67,4 +68,8 @@ type LedgerStore interface {
 	PreExecuteContract(tx *types.Transaction) (*cstates.PreExecResult, error)
 	GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error)
 	GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify, error)
+	GetCrossChainTx(fromChainID uint64, crossChainID []byte) (*scom.CrossChainTx, error)
+	GetCrossChainTxsByHeight(height uint32) ([]*scom.CrossChainTx, error)
+	ExportSnapshot(dir string) (*scom.SnapshotManifest, error)
+	ImportSnapshot(dir string, trustedStateHash common.Uint256) (*scom.SnapshotManifest, error)
 }
@@ -47,7 +47,7 @@ type Transaction struct {
 	GasLimit   uint64
 	GasPrice   uint64
 	Payload    Payload
-	Attributes []byte //this must be 0 now, Attribute Array length use VarUint encoding, so byte is enough for extension
+	Attributes []byte //this must be empty for version 0, Attribute Array length use VarUint encoding, so byte is enough for extension
 	Payer      common.Address
 	CoinType   CoinType
 	Sigs       []Sig
@@ -78,8 +78,8 @@ func (tx *Transaction) SerializeUnsigned(sink *common.ZeroCopySink) error {
 	default:
 		return errors.New("wrong transaction payload type")
 	}
-	if len(tx.Attributes) > MAX_ATTRIBUTES_LEN {
-		return fmt.Errorf("attributes length %d over max length %d", tx.Attributes, MAX_ATTRIBUTES_LEN)
+	if err := tx.checkAttributes(); err != nil {
+		return err
 	}
 	sink.WriteVarBytes(tx.Attributes)
 	sink.WriteAddress(tx.Payer)
@@ -202,8 +202,8 @@ func (tx *Transaction) DeserializationUnsigned(source *common.ZeroCopySource) er
 	if eof {
 		return errors.New("[deserializationUnsigned] read attributes error")
 	}
-	if len(tx.Attributes) > MAX_ATTRIBUTES_LEN {
-		return fmt.Errorf("[deserializationUnsigned] attributes length %d over max limit %d", tx.Attributes, MAX_ATTRIBUTES_LEN)
+	if err := tx.checkAttributes(); err != nil {
+		return fmt.Errorf("[deserializationUnsigned] %s", err)
 	}
 	tx.Payer, eof = source.NextAddress()
 	if eof {
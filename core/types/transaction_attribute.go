@@ -34,11 +34,14 @@ const (
 	Script         TransactionAttributeUsage = 0x20
 	DescriptionUrl TransactionAttributeUsage = 0x81
 	Description    TransactionAttributeUsage = 0x90
+	ValidFrom      TransactionAttributeUsage = 0xa0
+	ValidUntil     TransactionAttributeUsage = 0xa1
 )
 
 func IsValidAttributeType(usage TransactionAttributeUsage) bool {
 	return usage == Nonce || usage == Script ||
-		usage == DescriptionUrl || usage == Description
+		usage == DescriptionUrl || usage == Description ||
+		usage == ValidFrom || usage == ValidUntil
 }
 
 type TxAttribute struct {
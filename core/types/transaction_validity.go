@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+)
+
+// TxValidity is the window of block heights in which a transaction can be
+// packed, it is carried in the attributes of the transactions of version 1.
+type TxValidity struct {
+	ValidFrom  uint32 // The first height at which the tx is valid, 0 for no lower bound
+	ValidUntil uint32 // The last height at which the tx is valid
+}
+
+// Attributes returns the transaction attributes carrying the validity window.
+func (v *TxValidity) Attributes() []byte {
+	sink := common.NewZeroCopySink(nil)
+	if v.ValidFrom != 0 {
+		sink.WriteByte(byte(ValidFrom))
+		sink.WriteVarBytes(uint32Bytes(v.ValidFrom))
+	}
+	sink.WriteByte(byte(ValidUntil))
+	sink.WriteVarBytes(uint32Bytes(v.ValidUntil))
+	return sink.Bytes()
+}
+
+// Contains returns whether the height is in the validity window.
+func (v *TxValidity) Contains(height uint32) bool {
+	return height >= v.ValidFrom && height <= v.ValidUntil
+}
+
+// ParseTxValidity parses the validity window out of the transaction attributes,
+// each attribute is a usage byte followed by var bytes of data.
+func ParseTxValidity(attrs []byte) (*TxValidity, error) {
+	v := new(TxValidity)
+	found := make(map[TransactionAttributeUsage]bool)
+	source := common.NewZeroCopySource(attrs)
+	for source.Len() > 0 {
+		usage, _ := source.NextByte()
+		data, eof := source.NextVarBytes()
+		if eof {
+			return nil, fmt.Errorf("read data of attribute %x error", usage)
+		}
+		u := TransactionAttributeUsage(usage)
+		if found[u] {
+			return nil, fmt.Errorf("duplicated attribute %x", usage)
+		}
+		found[u] = true
+		if u != ValidFrom && u != ValidUntil {
+			return nil, fmt.Errorf("unsupported attribute %x", usage)
+		}
+		if len(data) != 4 {
+			return nil, fmt.Errorf("invalid data length %d of attribute %x", len(data), usage)
+		}
+		height, _ := common.NewZeroCopySource(data).NextUint32()
+		if u == ValidFrom {
+			v.ValidFrom = height
+		} else {
+			v.ValidUntil = height
+		}
+	}
+	if !found[ValidUntil] {
+		return nil, errors.New("missing valid until attribute")
+	}
+	if v.ValidFrom > v.ValidUntil {
+		return nil, fmt.Errorf("valid from %d is over valid until %d", v.ValidFrom, v.ValidUntil)
+	}
+	return v, nil
+}
+
+// Validity returns the validity window of the transaction, nil for the
+// legacy transactions which are valid at any height.
+func (tx *Transaction) Validity() (*TxValidity, error) {
+	if tx.Version == TX_VERSION_LEGACY {
+		return nil, nil
+	}
+	return ParseTxValidity(tx.Attributes)
+}
+
+// VerifyValidity checks whether the transaction can be packed in the block
+// of the height.
+func (tx *Transaction) VerifyValidity(height uint32) error {
+	if tx.Version == TX_VERSION_LEGACY {
+		return nil
+	}
+	if activated := config.GetActivationHeight(config.FEATURE_TX_EXPIRY, config.DefConfig.P2PNode.NetworkId); height < activated {
+		return fmt.Errorf("tx version %d is not accepted before height %d", tx.Version, activated)
+	}
+	v, err := tx.Validity()
+	if err != nil {
+		return err
+	}
+	if !v.Contains(height) {
+		return fmt.Errorf("tx is valid from height %d until %d, not at %d", v.ValidFrom, v.ValidUntil, height)
+	}
+	return nil
+}
+
+// IsExpired returns whether the transaction can not be packed in the block
+// of the height or any later block.
+func (tx *Transaction) IsExpired(height uint32) bool {
+	v, err := tx.Validity()
+	if err != nil {
+		return true
+	}
+	return v != nil && v.ValidUntil < height
+}
+
+// checkAttributes checks the attributes are well formed for the tx version.
+func (tx *Transaction) checkAttributes() error {
+	if len(tx.Attributes) > MAX_ATTRIBUTES_LEN {
+		return fmt.Errorf("attributes length %d over max length %d", len(tx.Attributes), MAX_ATTRIBUTES_LEN)
+	}
+	if tx.Version == TX_VERSION_LEGACY {
+		if len(tx.Attributes) != 0 {
+			return fmt.Errorf("attributes of tx version %d must be empty", tx.Version)
+		}
+		return nil
+	}
+	_, err := ParseTxValidity(tx.Attributes)
+	return err
+}
+
+func uint32Bytes(v uint32) []byte {
+	sink := common.NewZeroCopySink(make([]byte, 0, 4))
+	sink.WriteUint32(v)
+	return sink.Bytes()
+}
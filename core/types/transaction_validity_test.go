@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+func newValidityTx(version byte, attrs []byte) *Transaction {
+	return &Transaction{
+		Version:    version,
+		TxType:     Invoke,
+		Payload:    &payload.InvokeCode{Code: []byte{1}},
+		Attributes: attrs,
+	}
+}
+
+func TestTxValidity_Attributes(t *testing.T) {
+	v := &TxValidity{ValidFrom: 10, ValidUntil: 20}
+	v2, err := ParseTxValidity(v.Attributes())
+	assert.Nil(t, err)
+	assert.Equal(t, v, v2)
+
+	v = &TxValidity{ValidUntil: 20}
+	v2, err = ParseTxValidity(v.Attributes())
+	assert.Nil(t, err)
+	assert.Equal(t, v, v2)
+	assert.True(t, v2.Contains(0))
+	assert.True(t, v2.Contains(20))
+	assert.False(t, v2.Contains(21))
+
+	_, err = ParseTxValidity(nil)
+	assert.NotNil(t, err)
+	_, err = ParseTxValidity((&TxValidity{ValidFrom: 21, ValidUntil: 20}).Attributes())
+	assert.NotNil(t, err)
+	attrs := v.Attributes()
+	_, err = ParseTxValidity(append(attrs, attrs...))
+	assert.NotNil(t, err)
+	_, err = ParseTxValidity(append(attrs, byte(Description), 0))
+	assert.NotNil(t, err)
+	_, err = ParseTxValidity(attrs[:len(attrs)-1])
+	assert.NotNil(t, err)
+}
+
+func TestTransaction_Validity(t *testing.T) {
+	attrs := (&TxValidity{ValidFrom: 10, ValidUntil: 20}).Attributes()
+
+	// Legacy transactions carry no attributes
+	sink := common.NewZeroCopySink(nil)
+	assert.NotNil(t, newValidityTx(TX_VERSION_LEGACY, attrs).Serialization(sink))
+	sink = common.NewZeroCopySink(nil)
+	assert.NotNil(t, newValidityTx(TX_VERSION_VALIDITY, nil).Serialization(sink))
+
+	sink = common.NewZeroCopySink(nil)
+	assert.Nil(t, newValidityTx(TX_VERSION_VALIDITY, attrs).Serialization(sink))
+	tx, err := TransactionFromRawBytes(sink.Bytes())
+	assert.Nil(t, err)
+	validity, err := tx.Validity()
+	assert.Nil(t, err)
+	assert.Equal(t, &TxValidity{ValidFrom: 10, ValidUntil: 20}, validity)
+
+	legacy := newValidityTx(TX_VERSION_LEGACY, nil)
+	validity, err = legacy.Validity()
+	assert.Nil(t, err)
+	assert.Nil(t, validity)
+
+	networkId, genesisConfig := config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis
+	defer func() { config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis = networkId, genesisConfig }()
+
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	config.DefConfig.Genesis = &config.GenesisConfig{
+		ActivationHeights: map[string]uint32{string(config.FEATURE_TX_EXPIRY): 0},
+	}
+	assert.NotNil(t, tx.VerifyValidity(9))
+	assert.Nil(t, tx.VerifyValidity(10))
+	assert.Nil(t, tx.VerifyValidity(20))
+	assert.NotNil(t, tx.VerifyValidity(21))
+	assert.False(t, tx.IsExpired(9))
+	assert.False(t, tx.IsExpired(20))
+	assert.True(t, tx.IsExpired(21))
+	assert.Nil(t, legacy.VerifyValidity(21))
+	assert.False(t, legacy.IsExpired(21))
+
+	// The new version is not accepted before the height scheduled
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	assert.NotNil(t, tx.VerifyValidity(10))
+	assert.Nil(t, legacy.VerifyValidity(10))
+}
@@ -18,6 +18,11 @@
 
 package types
 
-const CURR_TX_VERSION = 0
+const (
+	TX_VERSION_LEGACY   = 0 // transactions without attributes
+	TX_VERSION_VALIDITY = 1 // transactions with a validity window in the attributes
+)
+
+const CURR_TX_VERSION = TX_VERSION_VALIDITY
 const CURR_HEADER_VERSION = 0
-const MAX_ATTRIBUTES_LEN = 0
+const MAX_ATTRIBUTES_LEN = 64
@@ -44,6 +44,11 @@ func VerifyTransaction(tx *types.Transaction) ontErrors.ErrCode {
 		return ontErrors.ErrTransactionPayload
 	}
 
+	if _, err := tx.Validity(); err != nil {
+		log.Warn("[VerifyTransaction],", err)
+		return ontErrors.ErrTxValidity
+	}
+
 	return ontErrors.ErrNoError
 }
 
@@ -52,6 +57,11 @@ func VerifyTransactionWithLedger(tx *types.Transaction, ledger *ledger.Ledger) o
 	return ontErrors.ErrNoError
 }
 
+// VerifyTransactionSignatures verifys the signatures of a transaction packed in a block
+func VerifyTransactionSignatures(tx *types.Transaction) error {
+	return checkTransactionSignatures(tx)
+}
+
 func checkTransactionSignatures(tx *types.Transaction) error {
 	hash := tx.Hash()
 
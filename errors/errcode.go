@@ -53,6 +53,9 @@ const (
 	ErrGasPrice             ErrCode = 45020
 	ErrVerifySignature      ErrCode = 45021
 	ErrInValidShard         ErrCode = 45022
+	ErrGasLimit             ErrCode = 45023
+	ErrTxValidity           ErrCode = 45024
+	ErrRelayerQuota         ErrCode = 45025
 )
 
 func (err ErrCode) Error() string {
@@ -103,6 +106,12 @@ func (err ErrCode) Error() string {
 		return "transaction verify signature fail"
 	case ErrInValidShard:
 		return "transaction shardId unmatch"
+	case ErrGasLimit:
+		return "insufficient gas limit"
+	case ErrTxValidity:
+		return "transaction out of validity window"
+	case ErrRelayerQuota:
+		return "relayer quota exceeded"
 
 	}
 
@@ -19,16 +19,49 @@
 package actor
 
 import (
+	"errors"
+	"io"
+
 	"github.com/ontio/ontology-eventbus/actor"
 	cactor "github.com/polynetwork/poly/consensus/actor"
+	"github.com/polynetwork/poly/consensus/vbft"
 )
 
+//ConsensusMonitor exposes the consensus status of this node
+type ConsensusMonitor interface {
+	GetConsensusStatus() vbft.ConsensusStatus
+	WriteMetrics(w io.Writer) error
+}
+
 var consensusSrvPid *actor.PID
+var consensusMonitor ConsensusMonitor
+
+var ErrNoConsensusMonitor = errors.New("consensus status is not available")
 
 func SetConsensusPid(actr *actor.PID) {
 	consensusSrvPid = actr
 }
 
+func SetConsensusMonitor(monitor ConsensusMonitor) {
+	consensusMonitor = monitor
+}
+
+//get the consensus status of this node
+func GetConsensusStatus() (vbft.ConsensusStatus, error) {
+	if consensusMonitor == nil {
+		return vbft.ConsensusStatus{}, ErrNoConsensusMonitor
+	}
+	return consensusMonitor.GetConsensusStatus(), nil
+}
+
+//write the consensus metrics in prometheus text format
+func WriteConsensusMetrics(w io.Writer) error {
+	if consensusMonitor == nil {
+		return ErrNoConsensusMonitor
+	}
+	return consensusMonitor.WriteMetrics(w)
+}
+
 //start consensus to consensus actor
 func ConsensusSrvStart() error {
 	if consensusSrvPid != nil {
@@ -21,6 +21,7 @@ package actor
 import (
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/core/ledger"
+	scom "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native/event"
 	cstate "github.com/polynetwork/poly/native/states"
@@ -97,6 +98,16 @@ func GetEventNotifyByHeight(height uint32) ([]*event.ExecuteNotify, error) {
 	return ledger.DefLedger.GetEventNotifyByBlock(height)
 }
 
+//GetCrossChainTx from ledger
+func GetCrossChainTx(fromChainID uint64, crossChainID []byte) (*scom.CrossChainTx, error) {
+	return ledger.DefLedger.GetCrossChainTx(fromChainID, crossChainID)
+}
+
+//GetCrossChainTxsByHeight from ledger
+func GetCrossChainTxsByHeight(height uint32) ([]*scom.CrossChainTx, error) {
+	return ledger.DefLedger.GetCrossChainTxsByHeight(height)
+}
+
 //GetMerkleProof from ledger
 func GetMerkleProof(proofHeight uint32, rootHeight uint32) ([]byte, error) {
 	return ledger.DefLedger.GetMerkleProof(proofHeight, rootHeight)
@@ -26,6 +26,7 @@ import (
 	"github.com/polynetwork/poly/common/log"
 	ac "github.com/polynetwork/poly/p2pserver/actor/server"
 	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/peer"
 )
 
 var netServerPid *actor.PID
@@ -204,3 +205,63 @@ func GetNodeType() (uint64, error) {
 	}
 	return r.NodeType, nil
 }
+
+//GetPeers from netSever actor
+func GetPeers() ([]ac.PeerInfo, []peer.BanEntry, error) {
+	if netServerPid == nil {
+		return nil, nil, nil
+	}
+	future := netServerPid.RequestFuture(&ac.GetPeersReq{}, REQ_TIMEOUT*time.Second)
+	result, err := future.Result()
+	if err != nil {
+		log.Errorf(ERR_ACTOR_COMM, err)
+		return nil, nil, err
+	}
+	r, ok := result.(*ac.GetPeersRsp)
+	if !ok {
+		return nil, nil, errors.New("fail")
+	}
+	return r.Peers, r.Bans, nil
+}
+
+//BanPeer from netSever actor
+func BanPeer(id uint64, ip string, duration time.Duration, reason string) error {
+	if netServerPid == nil {
+		return errors.New("net server not started")
+	}
+	req := &ac.BanPeerReq{
+		Id:       id,
+		IP:       ip,
+		Duration: duration,
+		Reason:   reason,
+	}
+	future := netServerPid.RequestFuture(req, REQ_TIMEOUT*time.Second)
+	result, err := future.Result()
+	if err != nil {
+		log.Errorf(ERR_ACTOR_COMM, err)
+		return err
+	}
+	r, ok := result.(*ac.BanPeerRsp)
+	if !ok {
+		return errors.New("fail")
+	}
+	return r.Error
+}
+
+//UnbanPeer from netSever actor
+func UnbanPeer(id uint64, ip string) (bool, error) {
+	if netServerPid == nil {
+		return false, nil
+	}
+	future := netServerPid.RequestFuture(&ac.UnbanPeerReq{Id: id, IP: ip}, REQ_TIMEOUT*time.Second)
+	result, err := future.Result()
+	if err != nil {
+		log.Errorf(ERR_ACTOR_COMM, err)
+		return false, err
+	}
+	r, ok := result.(*ac.UnbanPeerRsp)
+	if !ok {
+		return false, errors.New("fail")
+	}
+	return r.Found, nil
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology-eventbus/actor"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	scommon "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/types"
@@ -98,10 +99,14 @@ func AppendTxToPool(txn *types.Transaction) (polyErrors.ErrCode, string) {
 		return polyErrors.ErrNoError, ""
 	}
 	//add Pre Execute Contract
-	_, err = PreExecuteContract(txn)
+	result, err := PreExecuteContract(txn)
 	if err != nil {
 		return polyErrors.ErrUnknown, err.Error()
 	}
+	height := GetCurrentBlockHeight() + 1
+	if height >= config.GetActivationHeight(config.FEATURE_GAS_METERING, config.DefConfig.P2PNode.NetworkId) && result.Gas > txn.GasLimit {
+		return polyErrors.ErrGasLimit, fmt.Sprintf("gas limit %d is lower than gas consumed %d", txn.GasLimit, result.Gas)
+	}
 	ch := make(chan *tcomn.TxResult, 1)
 	txReq := &tcomn.TxReq{txn, tcomn.HttpSender, ch}
 	txnPid.Tell(txReq)
@@ -158,7 +163,7 @@ func GetTxFromPool(hash common.Uint256) (tcomn.TXEntry, error) {
 	if !ok {
 		return tcomn.TXEntry{}, errors.New("fail")
 	}
-	txnEntry := tcomn.TXEntry{rsp.Txn, txStatus.TxStatus}
+	txnEntry := tcomn.TXEntry{Tx: rsp.Txn, Attrs: txStatus.TxStatus}
 	return txnEntry, nil
 }
 
@@ -20,13 +20,22 @@
 package common
 
 import (
+	"encoding/hex"
+	"fmt"
+	"math"
+
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/crossproof"
+	"github.com/polynetwork/poly/core/genesis"
+	scom "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/types"
 	ontErrors "github.com/polynetwork/poly/errors"
 	bactor "github.com/polynetwork/poly/http/base/actor"
 	"github.com/polynetwork/poly/native/event"
+	ccmcom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	cstate "github.com/polynetwork/poly/native/states"
 )
 
@@ -57,6 +66,7 @@ type ExecuteNotify struct {
 
 type PreExecuteResult struct {
 	State  byte
+	Gas    uint64
 	Result interface{}
 	Notify []NotifyEventInfo
 }
@@ -137,6 +147,27 @@ type NodeInfo struct {
 	//RxTxnCnt uint64 // The transaction received by this node
 }
 
+type PeerInfo struct {
+	ID            uint64 // The peer's id
+	Addr          string // The peer's sync address
+	SoftVersion   string
+	Height        uint64 // The peer latest block height
+	Authenticated bool   // Whether the peer id is bound to a node key
+	Score         int    // The misbehavior score, the peer is banned once it reaches the ban score
+}
+
+type BanInfo struct {
+	ID     uint64 // The banned peer id, 0 if banned by ip
+	IP     string
+	Reason string
+	Until  int64 // The unix time the ban expires at
+}
+
+type PeersInfo struct {
+	Peers  []PeerInfo
+	Banned []BanInfo
+}
+
 type ConsensusInfo struct {
 	// TODO
 }
@@ -151,6 +182,115 @@ type TXNEntryInfo struct {
 	State []TXNAttrInfo // the result from each validator
 }
 
+type CrossChainTxInfo struct {
+	FromChainID  uint64
+	CrossChainID string
+	TxHash       string
+	ToChainID    uint64
+	PolyTxHash   string
+	PolyHeight   uint32
+	ProofKey     string
+}
+
+type CrossStatesProofInfo struct {
+	Height            uint32
+	SignedHeight      uint32
+	PolyTxHash        string
+	FromChainID       uint64
+	TxHash            string
+	CrossChainID      string
+	ToChainID         uint64
+	ToContractAddress string
+	Method            string
+}
+
+type CrossChainPauseInfo struct {
+	FromChainID       uint64
+	ToChainID         uint64
+	ToContractAddress string
+	Method            string
+	ExpireHeight      uint32
+}
+
+// status of side chains
+const (
+	SIDE_CHAIN_ACTIVE           = "active"          //registered and not blacked
+	SIDE_CHAIN_BLACKED          = "blacked"         //cross chain txs from or to it are refused
+	SIDE_CHAIN_QUITTING         = "quitting"        //quit request waiting for approval
+	SIDE_CHAIN_UPDATING         = "updating"        //update request waiting for approval
+	SIDE_CHAIN_PENDING_REGISTER = "pendingRegister" //register request waiting for approval
+	SIDE_CHAIN_PENDING_UPDATE   = "pendingUpdate"   //requested update of a registered chain
+)
+
+type SideChainInfo struct {
+	ChainID      uint64
+	Name         string
+	Router       uint64
+	BlocksToWait uint64
+	CCMCAddress  string
+	Address      string
+	Status       string
+}
+
+type RelayerRequestInfo struct {
+	ID          uint64
+	AddressList []string
+	Address     string
+}
+
+type RelayerQuotaInfo struct {
+	Relayer         string
+	Limited         bool
+	HeadersPerBlock uint64
+	ImportsPerBlock uint64
+	MaxBatchSize    uint64
+	UsageHeight     uint32
+	UsedHeaders     uint64
+	UsedImports     uint64
+}
+
+type RelayerRewardInfo struct {
+	FromChainID uint64
+	ToChainID   uint64
+	Deliveries  uint64
+	Headers     uint64
+	Earned      uint64
+	Claimed     uint64
+	Unclaimed   uint64
+}
+
+type RelayerRewardsInfo struct {
+	Relayer string
+	Rewards []RelayerRewardInfo
+}
+
+type PendingApplications struct {
+	SideChainRegisters []SideChainInfo
+	SideChainUpdates   []SideChainInfo
+	SideChainQuits     []uint64
+	RelayerRegisters   []RelayerRequestInfo
+	RelayerRemoves     []RelayerRequestInfo
+}
+
+type PeerPoolItemInfo struct {
+	Index      uint32
+	PeerPubkey string
+	Address    string
+	Status     string
+}
+
+type ProposalInfo struct {
+	ID           uint64
+	Proposer     string
+	Method       string
+	Input        string
+	CreateHeight uint32
+	ExpireHeight uint32
+	Signers      []string
+	Status       string
+	EndHeight    uint32
+}
+
 func GetExecuteNotify(obj *event.ExecuteNotify) (map[string]bool, ExecuteNotify) {
 	evts := []NotifyEventInfo{}
 	var contractAddrs = make(map[string]bool)
@@ -167,7 +307,54 @@ func ConvertPreExecuteResult(obj *cstate.PreExecResult) PreExecuteResult {
 	for _, v := range obj.Notify {
 		evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States})
 	}
-	return PreExecuteResult{obj.State, obj.Result, evts}
+	return PreExecuteResult{obj.State, obj.Gas, obj.Result, evts}
+}
+
+// PreExecNativeMethod pre-executes method of a native contract against the current state
+func PreExecNativeMethod(contract common.Address, method string, args []byte) (*cstate.PreExecResult, error) {
+	sink := common.NewZeroCopySink(nil)
+	(&cstate.ContractInvokeParam{Address: contract, Method: method, Args: args}).Serialization(sink)
+	return bactor.PreExecuteContract(genesis.NewInvokeTransaction(sink.Bytes(), 0))
+}
+
+func GetCrossChainTxInfo(tx *scom.CrossChainTx) CrossChainTxInfo {
+	return CrossChainTxInfo{
+		FromChainID:  tx.FromChainID,
+		CrossChainID: hex.EncodeToString(tx.CrossChainID),
+		TxHash:       hex.EncodeToString(tx.TxHash),
+		ToChainID:    tx.ToChainID,
+		PolyTxHash:   tx.PolyTxHash.ToHexString(),
+		PolyHeight:   tx.PolyHeight,
+		ProofKey:     hex.EncodeToString(tx.ProofKey),
+	}
+}
+
+// ListCrossChainTxs returns the cross chain txs made from startHeight to endHeight, endHeight
+// is clamped to the current block height. A zero chain id matches any chain
+func ListCrossChainTxs(startHeight, endHeight uint32, fromChainID, toChainID uint64) ([]CrossChainTxInfo, error) {
+	if curHeight := bactor.GetCurrentBlockHeight(); endHeight > curHeight {
+		endHeight = curHeight
+	}
+	infos := make([]CrossChainTxInfo, 0)
+	if startHeight > endHeight {
+		return infos, nil
+	}
+	if endHeight-startHeight >= MAX_SEARCH_HEIGHT {
+		return nil, fmt.Errorf("height range %d-%d exceeds %d blocks", startHeight, endHeight, MAX_SEARCH_HEIGHT)
+	}
+	for height := startHeight; height <= endHeight; height++ {
+		txs, err := bactor.GetCrossChainTxsByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range txs {
+			if (fromChainID != 0 && tx.FromChainID != fromChainID) || (toChainID != 0 && tx.ToChainID != toChainID) {
+				continue
+			}
+			infos = append(infos, GetCrossChainTxInfo(tx))
+		}
+	}
+	return infos, nil
 }
 
 func SendTxToPool(txn *types.Transaction) (ontErrors.ErrCode, string) {
@@ -223,11 +410,18 @@ func GetBlockInfo(block *types.Block) BlockInfo {
 
 func TransArryByteToHexString(ptx *types.Transaction) *Transactions {
 	trans := new(Transactions)
+	trans.Version = ptx.Version
 	trans.TxType = ptx.TxType
 	trans.Nonce = ptx.Nonce
 	trans.Payload = TransPayloadToHex(ptx.Payload)
 
 	trans.Attributes = make([]TxAttributeInfo, 0)
+	if validity, _ := ptx.Validity(); validity != nil {
+		if validity.ValidFrom != 0 {
+			trans.Attributes = append(trans.Attributes, TxAttributeInfo{Usage: types.ValidFrom, Data: fmt.Sprint(validity.ValidFrom)})
+		}
+		trans.Attributes = append(trans.Attributes, TxAttributeInfo{Usage: types.ValidUntil, Data: fmt.Sprint(validity.ValidUntil)})
+	}
 	trans.Sigs = []Sig{}
 	for _, sig := range ptx.Sigs {
 		e := Sig{M: sig.M}
@@ -276,3 +470,78 @@ func GetAddress(str string) (common.Address, error) {
 	}
 	return address, err
 }
+
+// VerifyCrossStatesProof verifies the cross states proof made at height as target chains do. The proof is
+// checked against the header at height + 1, which is signed by the keepers of its epoch, or if rootHeight
+// is not zero, proven by the block root of the header at rootHeight signed by the keepers of that epoch
+func VerifyCrossStatesProof(height uint32, proof []byte, rootHeight uint32) (*CrossStatesProofInfo, error) {
+	header, err := getHeader(height + 1)
+	if err != nil {
+		return nil, err
+	}
+	signedHeader := header
+	var headerProof []byte
+	if rootHeight != 0 {
+		if rootHeight <= height+1 {
+			return nil, fmt.Errorf("root height %d should be above %d", rootHeight, height+1)
+		}
+		if signedHeader, err = getHeader(rootHeight); err != nil {
+			return nil, err
+		}
+		if headerProof, err = bactor.GetMerkleProof(height+1, rootHeight); err != nil {
+			return nil, fmt.Errorf("get merkle proof of header %d error: %s", height+1, err)
+		}
+	}
+	keepers, err := getKeepers(signedHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	var value *ccmcom.ToMerkleValue
+	if headerProof == nil {
+		value, err = crossproof.Verify(keepers, header, header.SigData, proof, nil)
+	} else {
+		value, err = crossproof.VerifyWithHeaderProof(keepers, header, headerProof, signedHeader, signedHeader.SigData, proof, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &CrossStatesProofInfo{
+		Height:            header.Height,
+		SignedHeight:      signedHeader.Height,
+		PolyTxHash:        hex.EncodeToString(value.TxHash),
+		FromChainID:       value.FromChainID,
+		TxHash:            hex.EncodeToString(value.MakeTxParam.TxHash),
+		CrossChainID:      hex.EncodeToString(value.MakeTxParam.CrossChainID),
+		ToChainID:         value.MakeTxParam.ToChainID,
+		ToContractAddress: hex.EncodeToString(value.MakeTxParam.ToContractAddress),
+		Method:            value.MakeTxParam.Method,
+	}, nil
+}
+
+// getKeepers returns the keepers signing header, set by the last config block before it
+func getKeepers(header *types.Header) ([]keypair.PublicKey, error) {
+	blkInfo, err := vconfig.VbftBlock(header)
+	if err != nil {
+		return nil, fmt.Errorf("header %d: %s", header.Height, err)
+	}
+	if blkInfo.LastConfigBlockNum == math.MaxUint32 {
+		return nil, fmt.Errorf("header %d has no config block", header.Height)
+	}
+	cfgHeader, err := getHeader(blkInfo.LastConfigBlockNum)
+	if err != nil {
+		return nil, err
+	}
+	return crossproof.Keepers(cfgHeader)
+}
+
+func getHeader(height uint32) (*types.Header, error) {
+	header, err := bactor.GetHeaderByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("get header %d error: %s", height, err)
+	}
+	if header == nil {
+		return nil, fmt.Errorf("header %d not found", height)
+	}
+	return header, nil
+}
@@ -83,4 +83,6 @@ var ErrMap = map[int64]string{
 	int64(ontErrors.ErrXmitFail):             "INTERNAL ERROR, ErrXmitFail",
 	int64(ontErrors.ErrNoAccount):            "INTERNAL ERROR, ErrNoAccount",
 	int64(ontErrors.ErrInValidShard):         "UNMATCH SHARD ID",
+	int64(ontErrors.ErrTxValidity):           "TRANSACTION OUT OF VALIDITY WINDOW",
+	int64(ontErrors.ErrRelayerQuota):         "RELAYER QUOTA EXCEEDED",
 }
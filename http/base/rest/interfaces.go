@@ -29,6 +29,7 @@ import (
 	bactor "github.com/polynetwork/poly/http/base/actor"
 	bcomn "github.com/polynetwork/poly/http/base/common"
 	berr "github.com/polynetwork/poly/http/base/error"
+	"math"
 	"strconv"
 )
 
@@ -437,3 +438,69 @@ func GetMemPoolTxState(cmd map[string]interface{}) map[string]interface{} {
 	resp["Result"] = bcomn.TXNEntryInfo{attrs}
 	return resp
 }
+
+//get cross chain tx by from chain id and cross chain id
+func GetCrossChainTx(cmd map[string]interface{}) map[string]interface{} {
+	if !config.DefConfig.Common.EnableEventLog {
+		return ResponsePack(berr.INVALID_METHOD)
+	}
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["ChainID"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	fromChainID, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	str, ok = cmd["CrossChainID"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	crossChainID, err := hex.DecodeString(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	tx, err := bactor.GetCrossChainTx(fromChainID, crossChainID)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return ResponsePack(berr.SUCCESS)
+		}
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = bcomn.GetCrossChainTxInfo(tx)
+	return resp
+}
+
+//list cross chain txs between heights, optionally filtered by from and to chain id
+func ListCrossChainTxs(cmd map[string]interface{}) map[string]interface{} {
+	if !config.DefConfig.Common.EnableEventLog {
+		return ResponsePack(berr.INVALID_METHOD)
+	}
+	resp := ResponsePack(berr.SUCCESS)
+	var args [4]uint64
+	for i, name := range []string{"StartHeight", "EndHeight", "FromChainID", "ToChainID"} {
+		str, ok := cmd[name].(string)
+		if !ok {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+		if len(str) == 0 && i >= 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			return ResponsePack(berr.INVALID_PARAMS)
+		}
+		args[i] = v
+	}
+	startHeight, endHeight := args[0], args[1]
+	if startHeight > endHeight || endHeight > math.MaxUint32 || endHeight-startHeight >= uint64(bcomn.MAX_SEARCH_HEIGHT) {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	txs, err := bcomn.ListCrossChainTxs(uint32(startHeight), uint32(endHeight), args[2], args[3])
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = txs
+	return resp
+}
@@ -21,6 +21,8 @@ package rpc
 import (
 	"encoding/hex"
 	"fmt"
+	"math"
+	"sort"
 
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
@@ -32,6 +34,14 @@ import (
 	bactor "github.com/polynetwork/poly/http/base/actor"
 	bcomn "github.com/polynetwork/poly/http/base/common"
 	berr "github.com/polynetwork/poly/http/base/error"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/header_sync"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 //get best block hash
@@ -98,6 +108,15 @@ func GetLatestBlockMsgsSnap(params []interface{}) map[string]interface{} {
 	return responseSuccess(result)
 }
 
+// get the consensus status of this node
+func GetConsensusStatus(params []interface{}) map[string]interface{} {
+	status, err := bactor.GetConsensusStatus()
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(status)
+}
+
 // get cross state root
 func GetCrossStateRoot(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
@@ -457,6 +476,37 @@ func GetCrossStatesProof(params []interface{}) map[string]interface{} {
 	return responseSuccess(bcomn.MerkleProof{"CrossStatesProof", hex.EncodeToString(proof)})
 }
 
+//verify a cross chain state proof made at height the way target chains do, against the header at height + 1
+//or, if root height is given, against the header at root height by the block root merkle path
+func VerifyCrossStatesProof(params []interface{}) map[string]interface{} {
+	if len(params) < 2 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	height, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[1].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	proof, err := hex.DecodeString(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var rootHeight float64
+	if len(params) > 2 {
+		if rootHeight, ok = params[2].(float64); !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	info, err := bcomn.VerifyCrossStatesProof(uint32(height), proof, uint32(rootHeight))
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	return responseSuccess(info)
+}
+
 func GetHeaderByHeight(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
 		return responsePack(berr.INVALID_PARAMS, nil)
@@ -516,3 +566,491 @@ func GetStateMerkleRoot(params []interface{}) map[string]interface{} {
 	}
 
 }
+
+//get the synced height of a side chain
+func GetSideChainHeight(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	chainID, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	value, err := preExecHeaderQuery(header_sync.GET_CURRENT_HEIGHT, uint64(chainID), 0)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	if len(value) != 8 {
+		return responsePack(berr.INTERNAL_ERROR, fmt.Sprintf("%s, unexpected height length %d", header_sync.GET_CURRENT_HEIGHT, len(value)))
+	}
+	return responseSuccess(utils.GetBytesUint64(value))
+}
+
+//get the synced canonical header of a side chain by height
+func GetSideChainHeader(params []interface{}) map[string]interface{} {
+	return sideChainHeaderQuery(header_sync.GET_HEADER_BY_HEIGHT, params, true)
+}
+
+//get the validator set of a side chain in force at height
+func GetSideChainEpoch(params []interface{}) map[string]interface{} {
+	return sideChainHeaderQuery(header_sync.GET_EPOCH, params, true)
+}
+
+//get the synced genesis header of a side chain
+func GetSideChainGenesisHeader(params []interface{}) map[string]interface{} {
+	return sideChainHeaderQuery(header_sync.GET_GENESIS_HEADER, params, false)
+}
+
+func sideChainHeaderQuery(method string, params []interface{}, withHeight bool) map[string]interface{} {
+	if len(params) < 1 || (withHeight && len(params) < 2) {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	chainID, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var height float64
+	if withHeight {
+		height, ok = params[1].(float64)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	value, err := preExecHeaderQuery(method, uint64(chainID), uint64(height))
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(hex.EncodeToString(value))
+}
+
+func preExecHeaderQuery(method string, chainID, height uint64) ([]byte, error) {
+	sink := common.NewZeroCopySink(nil)
+	(&hscommon.HeaderQueryParam{ChainID: chainID, Height: height}).Serialization(sink)
+	return preExecNativeQuery(utils.HeaderSyncContractAddress, method, sink.Bytes())
+}
+
+func preExecNativeQuery(contract common.Address, method string, args []byte) ([]byte, error) {
+	result, err := bcomn.PreExecNativeMethod(contract, method, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s, pre-execute error: %v", method, err)
+	}
+	if result.State != event.CONTRACT_STATE_SUCCESS {
+		return nil, fmt.Errorf("%s, pre-execute failed", method)
+	}
+	str, ok := result.Result.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s, unexpected pre-execute result", method)
+	}
+	return common.HexToBytes(str)
+}
+
+//get the cross chain tx made in poly by from chain id and cross chain id
+func GetCrossChainTx(params []interface{}) map[string]interface{} {
+	if !config.DefConfig.Common.EnableEventLog {
+		return responsePack(berr.INVALID_METHOD, "")
+	}
+	if len(params) < 2 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	fromChainID, ok := params[0].(float64)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	str, ok := params[1].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	crossChainID, err := hex.DecodeString(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	tx, err := bactor.GetCrossChainTx(uint64(fromChainID), crossChainID)
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return responseSuccess(nil)
+		}
+		return responsePack(berr.INTERNAL_ERROR, "")
+	}
+	return responseSuccess(bcomn.GetCrossChainTxInfo(tx))
+}
+
+//list the cross chain txs made in poly between heights, optionally filtered by from and to chain id
+func ListCrossChainTxs(params []interface{}) map[string]interface{} {
+	if !config.DefConfig.Common.EnableEventLog {
+		return responsePack(berr.INVALID_METHOD, "")
+	}
+	if len(params) < 2 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	var args [4]uint64
+	for i := 0; i < len(params) && i < len(args); i++ {
+		v, ok := params[i].(float64)
+		if !ok || v < 0 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		args[i] = uint64(v)
+	}
+	startHeight, endHeight := args[0], args[1]
+	if startHeight > endHeight || endHeight > math.MaxUint32 || endHeight-startHeight >= uint64(bcomn.MAX_SEARCH_HEIGHT) {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	txs, err := bcomn.ListCrossChainTxs(uint32(startHeight), uint32(endHeight), args[2], args[3])
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, "")
+	}
+	return responseSuccess(txs)
+}
+
+//get the active pauses of cross chain routes
+func GetCrossChainPauses(params []interface{}) map[string]interface{} {
+	result, err := bcomn.PreExecNativeMethod(utils.CrossChainManagerContractAddress, cross_chain_manager.GET_PAUSES, nil)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	str, ok := result.Result.(string)
+	if !ok {
+		return responsePack(berr.INTERNAL_ERROR, "")
+	}
+	value, err := common.HexToBytes(str)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	list := new(cross_chain_manager.PauseList)
+	if err := list.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	pauses := make([]bcomn.CrossChainPauseInfo, 0, len(list.Pauses))
+	for _, pause := range list.Pauses {
+		pauses = append(pauses, bcomn.CrossChainPauseInfo{
+			FromChainID:       pause.FromChainID,
+			ToChainID:         pause.ToChainID,
+			ToContractAddress: hex.EncodeToString(pause.ToContractAddress),
+			Method:            pause.Method,
+			ExpireHeight:      pause.ExpireHeight,
+		})
+	}
+	return responseSuccess(pauses)
+}
+
+func getSideChainInfos(sideChains []*side_chain_manager.SideChain, statusOf func(chainID uint64) string) []bcomn.SideChainInfo {
+	infos := make([]bcomn.SideChainInfo, 0, len(sideChains))
+	for _, sideChain := range sideChains {
+		infos = append(infos, bcomn.SideChainInfo{
+			ChainID:      sideChain.ChainId,
+			Name:         sideChain.Name,
+			Router:       sideChain.Router,
+			BlocksToWait: sideChain.BlocksToWait,
+			CCMCAddress:  hex.EncodeToString(sideChain.CCMCAddress),
+			Address:      sideChain.Address.ToBase58(),
+			Status:       statusOf(sideChain.ChainId),
+		})
+	}
+	return infos
+}
+
+func getSideChainRequests() (*side_chain_manager.SideChainRequests, error) {
+	value, err := preExecNativeQuery(utils.SideChainManagerContractAddress, side_chain_manager.GET_SIDE_CHAIN_REQUESTS, nil)
+	if err != nil {
+		return nil, err
+	}
+	requests := new(side_chain_manager.SideChainRequests)
+	if err := requests.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+//getSideChainStatus returns the status of registered side chains, which are blacked, quitting, updating or active
+func getSideChainStatus(sideChains []*side_chain_manager.SideChain, requests *side_chain_manager.SideChainRequests) (map[uint64]string, error) {
+	status := make(map[uint64]string, len(sideChains))
+	for _, sideChain := range sideChains {
+		status[sideChain.ChainId] = bcomn.SIDE_CHAIN_ACTIVE
+	}
+	for _, sideChain := range requests.Updates.SideChains {
+		if _, ok := status[sideChain.ChainId]; ok {
+			status[sideChain.ChainId] = bcomn.SIDE_CHAIN_UPDATING
+		}
+	}
+	for _, chainID := range requests.Quits {
+		if _, ok := status[chainID]; ok {
+			status[chainID] = bcomn.SIDE_CHAIN_QUITTING
+		}
+	}
+	for chainID := range status {
+		_, err := bactor.GetStorageItem(utils.CrossChainManagerContractAddress,
+			append([]byte(cross_chain_manager.BLACKED_CHAIN), utils.GetUint64Bytes(chainID)...))
+		if err == nil {
+			status[chainID] = bcomn.SIDE_CHAIN_BLACKED
+		} else if err != scom.ErrNotFound {
+			return nil, fmt.Errorf("get blacked chain %d error: %v", chainID, err)
+		}
+	}
+	return status, nil
+}
+
+func getRelayerRequestInfos(requests []*relayer_manager.RelayerRequest) []bcomn.RelayerRequestInfo {
+	infos := make([]bcomn.RelayerRequestInfo, 0, len(requests))
+	for _, request := range requests {
+		addressList := make([]string, 0, len(request.Request.AddressList))
+		for _, address := range request.Request.AddressList {
+			addressList = append(addressList, address.ToBase58())
+		}
+		infos = append(infos, bcomn.RelayerRequestInfo{
+			ID:          request.ID,
+			AddressList: addressList,
+			Address:     request.Request.Address.ToBase58(),
+		})
+	}
+	return infos
+}
+
+//get the side chains registered in poly
+func GetSideChains(params []interface{}) map[string]interface{} {
+	value, err := preExecNativeQuery(utils.SideChainManagerContractAddress, side_chain_manager.GET_SIDE_CHAINS, nil)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	list := new(side_chain_manager.SideChainList)
+	if err := list.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	requests, err := getSideChainRequests()
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	status, err := getSideChainStatus(list.SideChains, requests)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(getSideChainInfos(list.SideChains, func(chainID uint64) string {
+		return status[chainID]
+	}))
+}
+
+//get the side chain and relayer applications waiting for approval
+func GetPendingApplications(params []interface{}) map[string]interface{} {
+	sideChainRequests, err := getSideChainRequests()
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	value, err := preExecNativeQuery(utils.RelayerManagerContractAddress, relayer_manager.GET_RELAYER_REQUESTS, nil)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	relayerRequests := new(relayer_manager.RelayerRequests)
+	if err := relayerRequests.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(bcomn.PendingApplications{
+		SideChainRegisters: getSideChainInfos(sideChainRequests.Registers.SideChains, func(uint64) string {
+			return bcomn.SIDE_CHAIN_PENDING_REGISTER
+		}),
+		SideChainUpdates: getSideChainInfos(sideChainRequests.Updates.SideChains, func(uint64) string {
+			return bcomn.SIDE_CHAIN_PENDING_UPDATE
+		}),
+		SideChainQuits:   sideChainRequests.Quits,
+		RelayerRegisters: getRelayerRequestInfos(relayerRequests.Registers),
+		RelayerRemoves:   getRelayerRequestInfos(relayerRequests.Removes),
+	})
+}
+
+//get the relayers approved in poly
+func GetRelayers(params []interface{}) map[string]interface{} {
+	value, err := preExecNativeQuery(utils.RelayerManagerContractAddress, relayer_manager.GET_RELAYERS, nil)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	list := new(relayer_manager.RelayerList)
+	if err := list.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	relayers := make([]string, 0, len(list.Relayers))
+	for _, relayer := range list.Relayers {
+		relayers = append(relayers, relayer.ToBase58())
+	}
+	return responseSuccess(relayers)
+}
+
+//get the quota in effect for a relayer and its usage in the latest block it submitted to
+func GetRelayerQuota(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	relayer, err := common.AddressFromBase58(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&relayer_manager.GetRelayerQuotaParam{Relayer: relayer}).Serialization(sink)
+	value, err := preExecNativeQuery(utils.RelayerManagerContractAddress, relayer_manager.GET_RELAYER_QUOTA, sink.Bytes())
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	info := new(relayer_manager.RelayerQuotaInfo)
+	if err := info.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	result := bcomn.RelayerQuotaInfo{
+		Relayer:     info.Relayer.ToBase58(),
+		Limited:     info.Quota != nil,
+		UsageHeight: info.Usage.Height,
+		UsedHeaders: info.Usage.Headers,
+		UsedImports: info.Usage.Imports,
+	}
+	if info.Quota != nil {
+		result.HeadersPerBlock = info.Quota.HeadersPerBlock
+		result.ImportsPerBlock = info.Quota.ImportsPerBlock
+		result.MaxBatchSize = info.Quota.MaxBatchSize
+	}
+	return responseSuccess(result)
+}
+
+//get the work and rewards of a relayer for every chain pair it relayed for
+func GetRelayerRewards(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	relayer, err := common.AddressFromBase58(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&relayer_manager.GetRelayerRewardsParam{Relayer: relayer}).Serialization(sink)
+	value, err := preExecNativeQuery(utils.RelayerManagerContractAddress, relayer_manager.GET_RELAYER_REWARDS, sink.Bytes())
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	rewards := new(relayer_manager.RelayerRewards)
+	if err := rewards.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	result := bcomn.RelayerRewardsInfo{
+		Relayer: rewards.Relayer.ToBase58(),
+		Rewards: make([]bcomn.RelayerRewardInfo, 0, len(rewards.Rewards)),
+	}
+	for _, reward := range rewards.Rewards {
+		result.Rewards = append(result.Rewards, bcomn.RelayerRewardInfo{
+			FromChainID: reward.FromChainID,
+			ToChainID:   reward.ToChainID,
+			Deliveries:  reward.Deliveries,
+			Headers:     reward.Headers,
+			Earned:      reward.Earned,
+			Claimed:     reward.Claimed,
+			Unclaimed:   reward.Unclaimed(),
+		})
+	}
+	return responseSuccess(result)
+}
+
+var peerStatusNames = map[node_manager.Status]string{
+	node_manager.CandidateStatus: "candidate",
+	node_manager.ConsensusStatus: "consensus",
+	node_manager.QuitingStatus:   "quiting",
+	node_manager.BlackStatus:     "black",
+}
+
+//get the consensus peer pool of a governance view, the current view if not given
+func GetPeerPool(params []interface{}) map[string]interface{} {
+	var view uint32
+	if len(params) >= 1 {
+		v, ok := params[0].(float64)
+		if !ok || v < 0 || v > math.MaxUint32 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		view = uint32(v)
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&node_manager.ViewParam{View: view}).Serialization(sink)
+	value, err := preExecNativeQuery(utils.NodeManagerContractAddress, node_manager.GET_PEER_POOL, sink.Bytes())
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	peerPoolMap := new(node_manager.PeerPoolMap)
+	if err := peerPoolMap.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	peers := make([]bcomn.PeerPoolItemInfo, 0, len(peerPoolMap.PeerPoolMap))
+	for _, item := range peerPoolMap.PeerPoolMap {
+		peers = append(peers, bcomn.PeerPoolItemInfo{
+			Index:      item.Index,
+			PeerPubkey: item.PeerPubkey,
+			Address:    item.Address.ToBase58(),
+			Status:     peerStatusNames[item.Status],
+		})
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Index < peers[j].Index
+	})
+	return responseSuccess(peers)
+}
+
+var proposalStatusNames = map[node_manager.ProposalStatus]string{
+	node_manager.ProposalOpen:     "open",
+	node_manager.ProposalExecuted: "executed",
+	node_manager.ProposalExpired:  "expired",
+}
+
+func getProposalInfo(proposal *node_manager.Proposal) bcomn.ProposalInfo {
+	signers := make([]string, 0, len(proposal.Signers))
+	for _, signer := range proposal.Signers {
+		signers = append(signers, signer.ToBase58())
+	}
+	return bcomn.ProposalInfo{
+		ID:           proposal.ID,
+		Proposer:     proposal.Proposer.ToBase58(),
+		Method:       proposal.Method,
+		Input:        hex.EncodeToString(proposal.Input),
+		CreateHeight: proposal.CreateHeight,
+		ExpireHeight: proposal.ExpireHeight,
+		Signers:      signers,
+		Status:       proposalStatusNames[proposal.Status],
+		EndHeight:    proposal.EndHeight,
+	}
+}
+
+//get a governance proposal by id
+func GetProposal(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	id, ok := params[0].(float64)
+	if !ok || id < 0 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&node_manager.ProposalIDParam{ID: uint64(id)}).Serialization(sink)
+	value, err := preExecNativeQuery(utils.NodeManagerContractAddress, node_manager.GET_PROPOSAL, sink.Bytes())
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	proposal := new(node_manager.Proposal)
+	if err := proposal.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(getProposalInfo(proposal))
+}
+
+//get the governance proposals waiting for consensus signs
+func GetOpenProposals(params []interface{}) map[string]interface{} {
+	value, err := preExecNativeQuery(utils.NodeManagerContractAddress, node_manager.GET_OPEN_PROPOSALS, nil)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	list := new(node_manager.ProposalList)
+	if err := list.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	proposals := make([]bcomn.ProposalInfo, 0, len(list.Proposals))
+	for _, proposal := range list.Proposals {
+		proposals = append(proposals, getProposalInfo(proposal))
+	}
+	return responseSuccess(proposals)
+}
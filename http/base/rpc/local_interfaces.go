@@ -19,8 +19,11 @@
 package rpc
 
 import (
+	"net"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/polynetwork/poly/common/log"
 	bactor "github.com/polynetwork/poly/http/base/actor"
@@ -122,3 +125,93 @@ func SetDebugInfo(params []interface{}) map[string]interface{} {
 	}
 	return responsePack(berr.SUCCESS, true)
 }
+
+func ListPeers(params []interface{}) map[string]interface{} {
+	peers, bans, err := bactor.GetPeers()
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, false)
+	}
+	info := common.PeersInfo{
+		Peers:  make([]common.PeerInfo, 0, len(peers)),
+		Banned: make([]common.BanInfo, 0, len(bans)),
+	}
+	for _, p := range peers {
+		info.Peers = append(info.Peers, common.PeerInfo{
+			ID:            p.Id,
+			Addr:          p.Addr,
+			SoftVersion:   p.SoftVersion,
+			Height:        p.Height,
+			Authenticated: p.Authenticated,
+			Score:         p.Score,
+		})
+	}
+	for _, b := range bans {
+		info.Banned = append(info.Banned, common.BanInfo{
+			ID:     b.ID,
+			IP:     b.IP,
+			Reason: b.Reason,
+			Until:  b.Until,
+		})
+	}
+	return responseSuccess(info)
+}
+
+// parsePeer parses the peer param, a decimal peer id string or an ip
+func parsePeer(param interface{}) (uint64, string, bool) {
+	str, ok := param.(string)
+	if !ok {
+		return 0, "", false
+	}
+	if id, err := strconv.ParseUint(str, 10, 64); err == nil && id != 0 {
+		return id, "", true
+	}
+	if net.ParseIP(str) != nil {
+		return 0, str, true
+	}
+	return 0, "", false
+}
+
+// BanPeer bans a peer by [id or ip, duration in sec (optional), reason (optional)]
+func BanPeer(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	id, ip, ok := parsePeer(params[0])
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var duration time.Duration
+	if len(params) > 1 {
+		secs, ok := params[1].(float64)
+		if !ok || secs < 0 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		duration = time.Duration(secs) * time.Second
+	}
+	reason := "banned by admin"
+	if len(params) > 2 {
+		if reason, ok = params[2].(string); !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	if err := bactor.BanPeer(id, ip, duration, reason); err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responsePack(berr.SUCCESS, true)
+}
+
+// UnbanPeer lifts the ban of a peer by [id or ip]
+func UnbanPeer(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	id, ip, ok := parsePeer(params[0])
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	found, err := bactor.UnbanPeer(id, ip)
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, false)
+	}
+	return responseSuccess(found)
+}
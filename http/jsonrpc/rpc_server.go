@@ -39,6 +39,7 @@ func StartRPCServer() error {
 	rpc.HandleFunc("getblockcount", rpc.GetBlockCount)
 	rpc.HandleFunc("getblockhash", rpc.GetBlockHash)
 	rpc.HandleFunc("getlatestblockmsgssnap", rpc.GetLatestBlockMsgsSnap)
+	rpc.HandleFunc("getconsensusstatus", rpc.GetConsensusStatus)
 	rpc.HandleFunc("getcrossstateroot", rpc.GetCrossStateRoot)
 	rpc.HandleFunc("getconnectioncount", rpc.GetConnectionCount)
 	//HandleFunc("getrawmempool", GetRawMemPool)
@@ -56,9 +57,25 @@ func StartRPCServer() error {
 
 	rpc.HandleFunc("getmerkleproof", rpc.GetMerkleProof)
 	rpc.HandleFunc("getcrossstatesproof", rpc.GetCrossStatesProof)
+	rpc.HandleFunc("verifycrossstatesproof", rpc.VerifyCrossStatesProof)
 	rpc.HandleFunc("getheaderbyheight", rpc.GetHeaderByHeight)
 	rpc.HandleFunc("getblocktxsbyheight", rpc.GetBlockTxsByHeight)
 	rpc.HandleFunc("getstatemerkleroot", rpc.GetStateMerkleRoot)
+	rpc.HandleFunc("getsidechainheight", rpc.GetSideChainHeight)
+	rpc.HandleFunc("getsidechainheader", rpc.GetSideChainHeader)
+	rpc.HandleFunc("getsidechainepoch", rpc.GetSideChainEpoch)
+	rpc.HandleFunc("getsidechaingenesisheader", rpc.GetSideChainGenesisHeader)
+	rpc.HandleFunc("getcrosschaintx", rpc.GetCrossChainTx)
+	rpc.HandleFunc("listcrosschaintxs", rpc.ListCrossChainTxs)
+	rpc.HandleFunc("getcrosschainpauses", rpc.GetCrossChainPauses)
+	rpc.HandleFunc("getsidechains", rpc.GetSideChains)
+	rpc.HandleFunc("getpendingapplications", rpc.GetPendingApplications)
+	rpc.HandleFunc("getrelayers", rpc.GetRelayers)
+	rpc.HandleFunc("getrelayerquota", rpc.GetRelayerQuota)
+	rpc.HandleFunc("getrelayerrewards", rpc.GetRelayerRewards)
+	rpc.HandleFunc("getpeerpool", rpc.GetPeerPool)
+	rpc.HandleFunc("getproposal", rpc.GetProposal)
+	rpc.HandleFunc("getopenproposals", rpc.GetOpenProposals)
 
 	err := http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpJsonPort)), nil)
 	if err != nil {
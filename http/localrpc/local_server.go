@@ -43,6 +43,9 @@ func StartLocalServer() error {
 	rpc.HandleFunc("startconsensus", rpc.StartConsensus)
 	rpc.HandleFunc("stopconsensus", rpc.StopConsensus)
 	rpc.HandleFunc("setdebuginfo", rpc.SetDebugInfo)
+	rpc.HandleFunc("listpeers", rpc.ListPeers)
+	rpc.HandleFunc("banpeer", rpc.BanPeer)
+	rpc.HandleFunc("unbanpeer", rpc.UnbanPeer)
 
 	// TODO: only listen to local host
 	err := http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpLocalPort)), nil)
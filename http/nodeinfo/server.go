@@ -28,6 +28,7 @@ import (
 
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/core/ledger"
+	bactor "github.com/polynetwork/poly/http/base/actor"
 	p2p "github.com/polynetwork/poly/p2pserver/net/protocol"
 )
 
@@ -112,9 +113,17 @@ func viewHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := bactor.WriteConsensusMetrics(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func StartServer(n p2p.P2P) {
 	node = n
 	port := int(config.DefConfig.P2PNode.HttpInfoPort)
 	http.HandleFunc("/info", viewHandler)
+	http.HandleFunc("/metrics", metricsHandler)
 	http.ListenAndServe(":"+strconv.Itoa(port), nil)
 }
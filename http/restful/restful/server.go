@@ -74,6 +74,8 @@ const (
 	GET_MEMPOOL_TXSTATE   = "/api/v1/mempool/txstate/:hash"
 	GET_VERSION           = "/api/v1/version"
 	GET_NETWORKID         = "/api/v1/networkid"
+	GET_CROSS_CHAIN_TX    = "/api/v1/crosschain/tx/:chainid/:id"
+	GET_CROSS_CHAIN_TXS   = "/api/v1/crosschain/height/:start/:end"
 
 	POST_RAW_TX = "/api/v1/transaction"
 )
@@ -148,6 +150,8 @@ func (this *restServer) registryMethod() {
 		GET_MEMPOOL_TXSTATE:   {name: "getmempooltxstate", handler: rest.GetMemPoolTxState},
 		GET_VERSION:           {name: "getversion", handler: rest.GetNodeVersion},
 		GET_NETWORKID:         {name: "getnetworkid", handler: rest.GetNetworkId},
+		GET_CROSS_CHAIN_TX:    {name: "getcrosschaintx", handler: rest.GetCrossChainTx},
+		GET_CROSS_CHAIN_TXS:   {name: "listcrosschaintxs", handler: rest.ListCrossChainTxs},
 	}
 
 	postMethodMap := map[string]Action{
@@ -190,6 +194,10 @@ func (this *restServer) getPath(url string) string {
 		return GET_GRANTONG
 	} else if strings.Contains(url, strings.TrimRight(GET_MEMPOOL_TXSTATE, ":hash")) {
 		return GET_MEMPOOL_TXSTATE
+	} else if strings.Contains(url, strings.TrimRight(GET_CROSS_CHAIN_TX, ":chainid/:id")) {
+		return GET_CROSS_CHAIN_TX
+	} else if strings.Contains(url, strings.TrimRight(GET_CROSS_CHAIN_TXS, ":start/:end")) {
+		return GET_CROSS_CHAIN_TXS
 	}
 	return url
 }
@@ -234,6 +242,11 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 		req["Addr"] = getParam(r, "addr")
 	case GET_MEMPOOL_TXSTATE:
 		req["Hash"] = getParam(r, "hash")
+	case GET_CROSS_CHAIN_TX:
+		req["ChainID"], req["CrossChainID"] = getParam(r, "chainid"), getParam(r, "id")
+	case GET_CROSS_CHAIN_TXS:
+		req["StartHeight"], req["EndHeight"] = getParam(r, "start"), getParam(r, "end")
+		req["FromChainID"], req["ToChainID"] = r.FormValue("fromchainid"), r.FormValue("tochainid")
 	default:
 	}
 	return req
@@ -78,22 +78,22 @@ func pushSmartCodeEvent(v interface{}) {
 	go func() {
 		switch object := rs.Result.(type) {
 		case *event.ExecuteNotify:
-			contractAddrs, notify := bcomn.GetExecuteNotify(object)
-			pushEvent(contractAddrs, rs.TxHash.ToHexString(), rs.Error, rs.Action, notify)
+			_, notify := bcomn.GetExecuteNotify(object)
+			pushEvent(websocket.GetNotifyFilterInfos(object), rs.TxHash.ToHexString(), rs.Error, rs.Action, notify)
 		default:
 		}
 	}()
 }
 
-func pushEvent(contractAddrs map[string]bool, txHash string, errcode int64, action string, result interface{}) {
+func pushEvent(infos []*websocket.NotifyFilterInfo, txHash string, errcode int64, action string, result interface{}) {
 	if ws != nil {
 		resp := rest.ResponsePack(Err.SUCCESS)
 		resp["Result"] = result
 		resp["Error"] = errcode
 		resp["Action"] = action
 		resp["Desc"] = Err.ErrMap[resp["Error"].(int64)]
-		ws.PushTxResult(contractAddrs, txHash, resp)
-		ws.BroadcastToSubscribers(contractAddrs, websocket.WSTOPIC_EVENT, resp)
+		ws.PushTxResult(infos, txHash, resp)
+		ws.BroadcastToSubscribers(infos, websocket.WSTOPIC_EVENT, resp)
 	}
 }
 
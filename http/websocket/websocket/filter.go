@@ -0,0 +1,125 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package websocket
+
+import (
+	"github.com/polynetwork/poly/native/event"
+	ccom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// notify names of side chain manager which change the registered side chains
+var sideChainNotifies = map[string]bool{
+	"RegisterSideChain":        true,
+	"ApproveRegisterSideChain": true,
+	"UpdateSideChain":          true,
+	"ApproveUpdateSideChain":   true,
+	"QuitSideChain":            true,
+	"ApproveQuitSideChain":     true,
+}
+
+// NotifyFilterInfo is the info of a notify used to match the subscribe filters
+type NotifyFilterInfo struct {
+	Contract    string
+	Name        string
+	CrossChain  bool //FromChainID and ToChainID are set only for makeProof notify
+	FromChainID uint64
+	ToChainID   uint64
+	SideChain   bool //notify of side chain registration changes
+}
+
+// GetNotifyFilterInfos parse the filter info of each notify in event
+func GetNotifyFilterInfos(obj *event.ExecuteNotify) []*NotifyFilterInfo {
+	infos := make([]*NotifyFilterInfo, 0, len(obj.Notify))
+	for _, v := range obj.Notify {
+		info := &NotifyFilterInfo{Contract: v.ContractAddress.ToHexString()}
+		states, _ := v.States.([]interface{})
+		if len(states) > 0 {
+			info.Name, _ = states[0].(string)
+		}
+		switch {
+		case v.ContractAddress == utils.CrossChainManagerContractAddress && info.Name == ccom.NOTIFY_MAKE_PROOF:
+			if len(states) > 2 {
+				fromChainID, ok1 := states[1].(uint64)
+				toChainID, ok2 := states[2].(uint64)
+				info.CrossChain = ok1 && ok2
+				info.FromChainID, info.ToChainID = fromChainID, toChainID
+			}
+		case v.ContractAddress == utils.NodeManagerContractAddress || v.ContractAddress == utils.SideChainManagerContractAddress:
+			//side chain manager emits the registration changes with node manager address
+			info.SideChain = sideChainNotifies[info.Name]
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsChainID(list []uint64, id uint64) bool {
+	for _, v := range list {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// matchNotify check whether notify passes all the event filters of subscribe
+func (self *subscribe) matchNotify(info *NotifyFilterInfo) bool {
+	if len(self.ContractsFilter) > 0 && !containsString(self.ContractsFilter, info.Contract) {
+		return false
+	}
+	if len(self.NotifyFilter) > 0 && !containsString(self.NotifyFilter, info.Name) {
+		return false
+	}
+	if len(self.FromChainFilter) > 0 && !(info.CrossChain && containsChainID(self.FromChainFilter, info.FromChainID)) {
+		return false
+	}
+	if len(self.ToChainFilter) > 0 && !(info.CrossChain && containsChainID(self.ToChainFilter, info.ToChainID)) {
+		return false
+	}
+	return true
+}
+
+// matchEvent check whether the event should be pushed to subscriber
+func (self *subscribe) matchEvent(infos []*NotifyFilterInfo) bool {
+	for _, info := range infos {
+		if self.SubscribeSideChain && info.SideChain {
+			return true
+		}
+		if self.SubscribeEvent && self.matchNotify(info) {
+			return true
+		}
+	}
+	//keep pushing event without notify to subscriber without filters
+	return len(infos) == 0 && self.SubscribeEvent && !self.hasEventFilter()
+}
+
+func (self *subscribe) hasEventFilter() bool {
+	return len(self.ContractsFilter) > 0 || len(self.NotifyFilter) > 0 ||
+		len(self.FromChainFilter) > 0 || len(self.ToChainFilter) > 0
+}
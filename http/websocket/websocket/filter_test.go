@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package websocket
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/native/event"
+	ccom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func testNotifyInfos() []*NotifyFilterInfo {
+	return GetNotifyFilterInfos(&event.ExecuteNotify{
+		Notify: []*event.NotifyEventInfo{
+			{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States:          []interface{}{ccom.NOTIFY_MAKE_PROOF, uint64(2), uint64(3), "txhash"},
+			},
+			{
+				ContractAddress: utils.NodeManagerContractAddress,
+				States:          []interface{}{"ApproveRegisterSideChain", uint64(5)},
+			},
+		},
+	})
+}
+
+func TestGetNotifyFilterInfos(t *testing.T) {
+	infos := testNotifyInfos()
+	assert.Equal(t, 2, len(infos))
+	assert.Equal(t, &NotifyFilterInfo{
+		Contract:    utils.CrossChainManagerContractAddress.ToHexString(),
+		Name:        ccom.NOTIFY_MAKE_PROOF,
+		CrossChain:  true,
+		FromChainID: 2,
+		ToChainID:   3,
+	}, infos[0])
+	assert.True(t, infos[1].SideChain)
+	assert.False(t, infos[1].CrossChain)
+
+	infos = GetNotifyFilterInfos(&event.ExecuteNotify{
+		Notify: []*event.NotifyEventInfo{{ContractAddress: utils.CrossChainManagerContractAddress, States: "raw"}},
+	})
+	assert.Equal(t, "", infos[0].Name)
+	assert.False(t, infos[0].CrossChain)
+}
+
+func TestMatchNotify(t *testing.T) {
+	info := testNotifyInfos()[0]
+	cases := []struct {
+		sub    subscribe
+		expect bool
+	}{
+		{subscribe{}, true},
+		{subscribe{ContractsFilter: []string{info.Contract}}, true},
+		{subscribe{ContractsFilter: []string{utils.NodeManagerContractAddress.ToHexString()}}, false},
+		{subscribe{NotifyFilter: []string{ccom.NOTIFY_MAKE_PROOF}}, true},
+		{subscribe{NotifyFilter: []string{"other"}}, false},
+		{subscribe{FromChainFilter: []uint64{1, 2}}, true},
+		{subscribe{FromChainFilter: []uint64{3}}, false},
+		{subscribe{ToChainFilter: []uint64{3}}, true},
+		{subscribe{ToChainFilter: []uint64{2}}, false},
+		{subscribe{NotifyFilter: []string{ccom.NOTIFY_MAKE_PROOF}, FromChainFilter: []uint64{2}, ToChainFilter: []uint64{4}}, false},
+	}
+	for i, c := range cases {
+		assert.Equal(t, c.expect, c.sub.matchNotify(info), "case %d", i)
+	}
+
+	//chain filters never match a notify without chain ids
+	sideChain := testNotifyInfos()[1]
+	assert.False(t, (&subscribe{FromChainFilter: []uint64{5}}).matchNotify(sideChain))
+}
+
+func TestMatchEvent(t *testing.T) {
+	infos := testNotifyInfos()
+	cases := []struct {
+		sub    subscribe
+		infos  []*NotifyFilterInfo
+		expect bool
+	}{
+		{subscribe{}, infos, false},
+		{subscribe{SubscribeEvent: true}, infos, true},
+		{subscribe{SubscribeEvent: true, ToChainFilter: []uint64{3}}, infos, true},
+		{subscribe{SubscribeEvent: true, ToChainFilter: []uint64{4}}, infos, false},
+		{subscribe{SubscribeSideChain: true}, infos, true},
+		{subscribe{SubscribeSideChain: true}, infos[:1], false},
+		{subscribe{SubscribeEvent: true}, nil, true},
+		{subscribe{SubscribeEvent: true, NotifyFilter: []string{ccom.NOTIFY_MAKE_PROOF}}, nil, false},
+		{subscribe{SubscribeSideChain: true}, nil, false},
+	}
+	for i, c := range cases {
+		assert.Equal(t, c.expect, c.sub.matchEvent(c.infos), "case %d", i)
+	}
+}
@@ -33,9 +33,13 @@ import (
 	"github.com/polynetwork/poly/common"
 	cfg "github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
+	scom "github.com/polynetwork/poly/core/store/common"
+	bactor "github.com/polynetwork/poly/http/base/actor"
+	bcomn "github.com/polynetwork/poly/http/base/common"
 	Err "github.com/polynetwork/poly/http/base/error"
 	"github.com/polynetwork/poly/http/base/rest"
 	"github.com/polynetwork/poly/http/websocket/session"
+	"github.com/polynetwork/poly/native/event"
 )
 
 const (
@@ -45,6 +49,9 @@ const (
 	WSTOPIC_TXHASHS    = 4
 )
 
+//MAX_REPLAY_BLOCKS is the max number of blocks whose events could be replayed by one subscribe
+const MAX_REPLAY_BLOCKS = 10000
+
 type handler func(map[string]interface{}) map[string]interface{}
 type Handler struct {
 	handler  handler
@@ -54,7 +61,11 @@ type Handler struct {
 //subscribe event for client
 type subscribe struct {
 	ContractsFilter       []string `json:"ContractsFilter"`
+	NotifyFilter          []string `json:"NotifyFilter"`
+	FromChainFilter       []uint64 `json:"FromChainFilter"`
+	ToChainFilter         []uint64 `json:"ToChainFilter"`
 	SubscribeEvent        bool     `json:"SubscribeEvent"`
+	SubscribeSideChain    bool     `json:"SubscribeSideChain"`
 	SubscribeJsonBlock    bool     `json:"SubscribeJsonBlock"`
 	SubscribeRawBlock     bool     `json:"SubscribeRawBlock"`
 	SubscribeBlockTxHashs bool     `json:"SubscribeBlockTxHashs"`
@@ -148,6 +159,9 @@ func (self *WsServer) registryMethod() {
 		if b, ok := cmd["SubscribeEvent"].(bool); ok {
 			sub.SubscribeEvent = b
 		}
+		if b, ok := cmd["SubscribeSideChain"].(bool); ok {
+			sub.SubscribeSideChain = b
+		}
 		if b, ok := cmd["SubscribeJsonBlock"].(bool); ok {
 			sub.SubscribeJsonBlock = b
 		}
@@ -165,6 +179,26 @@ func (self *WsServer) registryMethod() {
 				}
 			}
 		}
+		if nf, ok := cmd["NotifyFilter"].([]interface{}); ok {
+			sub.NotifyFilter = []string{}
+			for _, v := range nf {
+				if name, k := v.(string); k {
+					sub.NotifyFilter = append(sub.NotifyFilter, name)
+				}
+			}
+		}
+		if cf, ok := cmd["FromChainFilter"].([]interface{}); ok {
+			sub.FromChainFilter = parseChainIDs(cf)
+		}
+		if cf, ok := cmd["ToChainFilter"].([]interface{}); ok {
+			sub.ToChainFilter = parseChainIDs(cf)
+		}
+		if start, ok := cmd["StartHeight"].(float64); ok {
+			current := bactor.GetCurrentBlockHeight()
+			if start < 0 || (uint32(start) <= current && current-uint32(start) >= MAX_REPLAY_BLOCKS) {
+				return rest.ResponsePack(Err.INVALID_PARAMS)
+			}
+		}
 		self.SubscribeMap[sessionId] = sub
 
 		resp["Action"] = "subscribe"
@@ -349,8 +383,68 @@ func (self *WsServer) OnDataHandle(curSession *session.Session, bysMsg []byte, r
 	}
 	curSession.Send(marshalResp(resp))
 
+	if actionName == "subscribe" && resp["Error"] == Err.SUCCESS {
+		if start, ok := req["StartHeight"].(float64); ok {
+			go self.replayEvents(curSession, uint32(start))
+		}
+	}
 	return true
 }
+
+//replayEvents push the events matching the session subscribe from startHeight to current block,
+//it runs out of the request goroutine and stops once the session unsubscribes or is closed.
+//Events of new blocks are pushed meanwhile so client should dedupe them by TxHash
+func (self *WsServer) replayEvents(curSession *session.Session, startHeight uint32) {
+	endHeight := bactor.GetCurrentBlockHeight()
+	for height := startHeight; height <= endHeight; height++ {
+		notifies, err := bactor.GetEventNotifyByHeight(height)
+		if err == scom.ErrNotFound {
+			//no event in block
+			continue
+		}
+		if err != nil {
+			resp := rest.ResponsePack(Err.INTERNAL_ERROR)
+			resp["Action"] = "replayevent"
+			resp["Result"] = height
+			curSession.Send(marshalResp(resp))
+			return
+		}
+		for _, notify := range notifies {
+			infos := GetNotifyFilterInfos(notify)
+			self.RLock()
+			sub, ok := self.SubscribeMap[curSession.GetSessionId()]
+			self.RUnlock()
+			if !ok {
+				return
+			}
+			if !sub.matchEvent(infos) {
+				continue
+			}
+			_, result := bcomn.GetExecuteNotify(notify)
+			resp := rest.ResponsePack(Err.SUCCESS)
+			resp["Action"] = event.EVENT_NOTIFY
+			resp["Result"] = result
+			resp["Height"] = height
+			if err := curSession.Send(marshalResp(resp)); err != nil {
+				return
+			}
+		}
+	}
+	resp := rest.ResponsePack(Err.SUCCESS)
+	resp["Action"] = "replaycomplete"
+	resp["Result"] = endHeight
+	curSession.Send(marshalResp(resp))
+}
+
+func parseChainIDs(list []interface{}) []uint64 {
+	ids := []uint64{}
+	for _, v := range list {
+		if id, ok := v.(float64); ok && id >= 0 {
+			ids = append(ids, uint64(id))
+		}
+	}
+	return ids
+}
 func (self *WsServer) InsertTxHashMap(txhash string, sessionid string) {
 	self.Lock()
 	defer self.Unlock()
@@ -382,23 +476,15 @@ func marshalResp(resp map[string]interface{}) []byte {
 	return data
 }
 
-func (self *WsServer) PushTxResult(contractAddrs map[string]bool, txHashStr string, resp map[string]interface{}) {
+func (self *WsServer) PushTxResult(infos []*NotifyFilterInfo, txHashStr string, resp map[string]interface{}) {
 	self.Lock()
 	sessionId := self.TxHashMap[txHashStr]
 	delete(self.TxHashMap, txHashStr)
 	//avoid twice, will send in BroadcastToSubscribers
 	sub := self.SubscribeMap[sessionId]
-	if sub.SubscribeEvent {
-		if len(sub.ContractsFilter) == 0 {
-			self.Unlock()
-			return
-		}
-		for _, addr := range sub.ContractsFilter {
-			if contractAddrs[addr] {
-				self.Unlock()
-				return
-			}
-		}
+	if sub.matchEvent(infos) {
+		self.Unlock()
+		return
 	}
 	self.Unlock()
 
@@ -407,7 +493,7 @@ func (self *WsServer) PushTxResult(contractAddrs map[string]bool, txHashStr stri
 		s.Send(marshalResp(resp))
 	}
 }
-func (self *WsServer) BroadcastToSubscribers(contractAddrs map[string]bool, sub int, resp map[string]interface{}) {
+func (self *WsServer) BroadcastToSubscribers(infos []*NotifyFilterInfo, sub int, resp map[string]interface{}) {
 	// broadcast SubscribeMap
 	self.Lock()
 	defer self.Unlock()
@@ -423,17 +509,8 @@ func (self *WsServer) BroadcastToSubscribers(contractAddrs map[string]bool, sub
 			s.Send(data)
 		} else if sub == WSTOPIC_TXHASHS && v.SubscribeBlockTxHashs {
 			s.Send(data)
-		} else if sub == WSTOPIC_EVENT && v.SubscribeEvent {
-			if len(v.ContractsFilter) == 0 {
-				s.Send(data)
-				continue
-			}
-			for _, addr := range v.ContractsFilter {
-				if contractAddrs[addr] {
-					s.Send(data)
-					break
-				}
-			}
+		} else if sub == WSTOPIC_EVENT && v.matchEvent(infos) {
+			s.Send(data)
 		}
 	}
 }
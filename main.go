@@ -50,7 +50,7 @@ import (
 	"github.com/polynetwork/poly/http/nodeinfo"
 	"github.com/polynetwork/poly/http/restful"
 	"github.com/polynetwork/poly/http/websocket"
-	_ "github.com/polynetwork/poly/native/service"
+	nservice "github.com/polynetwork/poly/native/service"
 	"github.com/polynetwork/poly/p2pserver"
 	netreqactor "github.com/polynetwork/poly/p2pserver/actor/req"
 	p2pactor "github.com/polynetwork/poly/p2pserver/actor/server"
@@ -72,12 +72,14 @@ func setupAPP() *cli.App {
 		cmd.AccountCommand,
 		cmd.InfoCommand,
 		cmd.ImportCommand,
+		cmd.SnapshotCommand,
 		cmd.ExportCommand,
 		cmd.SigTxCommand,
 		cmd.MultiSigAddrCommand,
 		cmd.MultiSigTxCommand,
 		cmd.SendTxCommand,
 		cmd.ShowTxCommand,
+		cmd.DevnetCommand,
 	}
 	app.Flags = []cli.Flag{
 		//common setting
@@ -85,6 +87,7 @@ func setupAPP() *cli.App {
 		utils.LogLevelFlag,
 		utils.DisableEventLogFlag,
 		utils.DataDirFlag,
+		utils.PruneKeepBlocksFlag,
 		//account setting
 		utils.WalletFileFlag,
 		utils.AccountAddressFlag,
@@ -107,6 +110,11 @@ func setupAPP() *cli.App {
 		utils.MaxConnInBoundFlag,
 		utils.MaxConnOutBoundFlag,
 		utils.MaxConnInBoundForSingleIPFlag,
+		utils.SeedAnnounceFlag,
+		utils.SeedPubKeysFlag,
+		utils.RequireSignedVersionFlag,
+		utils.SnapshotSyncHeightFlag,
+		utils.SnapshotSyncStateHashFlag,
 		//test mode setting
 		utils.EnableTestModeFlag,
 		utils.TestModeGenBlockTimeFlag,
@@ -262,6 +270,14 @@ func initLedger(ctx *cli.Context) (*ledger.Ledger, error) {
 		return nil, fmt.Errorf("Init ledger error:%s", err)
 	}
 
+	noCrossChain, noHeaderSync := nservice.UnpairedRouters()
+	for _, router := range noCrossChain {
+		log.Warnf("router %d has a header sync handler but no cross chain handler", router)
+	}
+	for _, router := range noHeaderSync {
+		log.Warnf("router %d has a cross chain handler but no header sync handler", router)
+	}
+
 	log.Infof("Ledger init success")
 	return ledger.DefLedger, nil
 }
@@ -327,6 +343,9 @@ func initConsensus(ctx *cli.Context, p2pPid *actor.PID, txpoolSvr *proc.TXPoolSe
 
 	netreqactor.SetConsensusPid(consensusService.GetPID())
 	hserver.SetConsensusPid(consensusService.GetPID())
+	if monitor, ok := consensusService.(hserver.ConsensusMonitor); ok {
+		hserver.SetConsensusMonitor(monitor)
+	}
 
 	log.Infof("Consensus init success")
 	return consensusService, nil
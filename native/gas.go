@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package native
+
+import (
+	"errors"
+
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/types"
+)
+
+// gas cost of native contract execution, storage access is charged by the CacheDB
+const (
+	TX_BASE_GAS       uint64 = 1000 // charged once for every transaction
+	NATIVE_INVOKE_GAS uint64 = 200  // charged for every native method invoked
+	VERIFY_SIG_GAS    uint64 = 500  // charged for every signature verified
+	VERIFY_HEADER_GAS uint64 = 2000 // charged for every side chain header verified
+	VERIFY_PROOF_GAS  uint64 = 2000 // charged for every cross chain proof verified
+)
+
+var ErrOutOfGas = errors.New("out of gas")
+
+// gasLimited reports whether the execution of tx aborts when its gas limit is exceeded,
+// pre-execution and unsigned transactions built by the node are never limited
+func gasLimited(tx *types.Transaction, height uint32, preExec bool) bool {
+	return !preExec && len(tx.Sigs) > 0 &&
+		height >= config.GetActivationHeight(config.FEATURE_GAS_METERING, config.DefConfig.P2PNode.NetworkId)
+}
+
+// intrinsicGas returns the gas charged for tx before its execution
+func intrinsicGas(tx *types.Transaction) uint64 {
+	gas := TX_BASE_GAS
+	for _, sig := range tx.Sigs {
+		gas += VERIFY_SIG_GAS * uint64(len(sig.SigData))
+	}
+	return gas
+}
+
+// UseGas charges gas to the transaction, ErrOutOfGas is returned once the gas
+// limit of the transaction is exceeded
+func (this *NativeService) UseGas(gas uint64) error {
+	if this.outOfGas {
+		return ErrOutOfGas
+	}
+	this.gasConsumed += gas
+	if this.gasLimited && this.gasConsumed > this.tx.GasLimit {
+		this.outOfGas = true
+		return ErrOutOfGas
+	}
+	return nil
+}
+
+// UseSigVerifyGas charges the verification of count signatures
+func (this *NativeService) UseSigVerifyGas(count int) error {
+	return this.UseGas(VERIFY_SIG_GAS * uint64(count))
+}
+
+// UseHeaderVerifyGas charges the verification of count side chain headers
+func (this *NativeService) UseHeaderVerifyGas(count int) error {
+	return this.UseGas(VERIFY_HEADER_GAS * uint64(count))
+}
+
+// UseProofVerifyGas charges the verification of a cross chain proof
+func (this *NativeService) UseProofVerifyGas() error {
+	return this.UseGas(VERIFY_PROOF_GAS)
+}
+
+// GasConsumed returns the gas used by the transaction so far
+func (this *NativeService) GasConsumed() uint64 {
+	return this.gasConsumed
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package native
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/states"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+var gasTestContract = common.Address{0xff}
+
+func init() {
+	Contracts[gasTestContract] = func(native *NativeService) {
+		native.Register("write", func(native *NativeService) ([]byte, error) {
+			for i := byte(0); i < 100; i++ {
+				native.GetCacheDB().Put([]byte{i}, []byte{i})
+			}
+			return []byte{1}, nil
+		})
+	}
+}
+
+func withGasMetering(t *testing.T, height uint32) {
+	networkID, genesis := config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis
+	config.DefConfig.P2PNode.NetworkId = 100
+	config.DefConfig.Genesis = &config.GenesisConfig{
+		ActivationHeights: map[string]uint32{string(config.FEATURE_GAS_METERING): height},
+	}
+	t.Cleanup(func() {
+		config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis = networkID, genesis
+	})
+}
+
+func newGasTestService(t *testing.T, tx *types.Transaction, height uint32, preExec bool) *NativeService {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	assert.NoError(t, err)
+	sink := common.NewZeroCopySink(nil)
+	(&states.ContractInvokeParam{Address: gasTestContract, Method: "write"}).Serialization(sink)
+	service, err := NewNativeService(storage.NewCacheDB(overlaydb.NewOverlayDB(store)), tx, 0, height,
+		common.Uint256{}, 0, sink.Bytes(), preExec)
+	assert.NoError(t, err)
+	return service
+}
+
+func newGasTestTx(gasLimit, gasPrice uint64) *types.Transaction {
+	return &types.Transaction{
+		GasLimit: gasLimit,
+		GasPrice: gasPrice,
+		Sigs:     []types.Sig{{SigData: [][]byte{{1}}}},
+	}
+}
+
+func TestNativeServiceOutOfGas(t *testing.T) {
+	withGasMetering(t, 10)
+
+	service := newGasTestService(t, newGasTestTx(5000, 2), 10, false)
+	_, err := service.Invoke()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrOutOfGas.Error())
+	assert.True(t, service.GasConsumed() > 5000)
+	assert.Equal(t, ErrOutOfGas, service.UseGas(1))
+
+	service = newGasTestService(t, newGasTestTx(1000000, 2), 10, false)
+	_, err = service.Invoke()
+	assert.NoError(t, err)
+}
+
+func TestNativeServiceGasNotLimited(t *testing.T) {
+	withGasMetering(t, 10)
+
+	// before the activation height
+	service := newGasTestService(t, newGasTestTx(5000, 2), 9, false)
+	_, err := service.Invoke()
+	assert.NoError(t, err)
+	assert.True(t, service.GasConsumed() > 5000)
+
+	// pre-execution
+	service = newGasTestService(t, newGasTestTx(5000, 2), 10, true)
+	_, err = service.Invoke()
+	assert.NoError(t, err)
+
+	// unsigned transaction built by the node
+	service = newGasTestService(t, &types.Transaction{GasLimit: 5000, GasPrice: 2}, 10, false)
+	_, err = service.Invoke()
+	assert.NoError(t, err)
+}
+
+func TestGasMeteringUnscheduled(t *testing.T) {
+	networkID := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkID }()
+	for _, id := range []uint32{config.NETWORK_ID_MAIN_NET, config.NETWORK_ID_TEST_NET, 100} {
+		config.DefConfig.P2PNode.NetworkId = id
+		assert.False(t, gasLimited(newGasTestTx(5000, 2), 1000000, false))
+	}
+}
@@ -56,6 +56,10 @@ type NativeService struct {
 	crossHashes   []common.Uint256
 	contexts      []common.Address
 	preExec       bool
+	gasConsumed   uint64
+	gasLimited    bool
+	outOfGas      bool
+	blockHashes   func(height uint32) common.Uint256
 }
 
 func NewNativeService(cacheDB *storage.CacheDB, tx *types.Transaction,
@@ -73,7 +77,10 @@ func NewNativeService(cacheDB *storage.CacheDB, tx *types.Transaction,
 		input:      input,
 		chainID:    chainID,
 		preExec:    preExec,
+		gasLimited: gasLimited(tx, height, preExec),
 	}
+	cacheDB.SetGasMeter(service)
+	service.gasConsumed = intrinsicGas(tx)
 
 	return service, nil
 }
@@ -87,6 +94,9 @@ func (this *NativeService) Invoke() (interface{}, error) {
 	if err := invokeParam.Deserialization(common.NewZeroCopySource(this.input)); err != nil {
 		return nil, err
 	}
+	if err := this.UseGas(NATIVE_INVOKE_GAS); err != nil {
+		return false, fmt.Errorf("[Invoke] %s, gas consumed: %d", err, this.gasConsumed)
+	}
 	services, ok := Contracts[invokeParam.Address]
 	if !ok {
 		return false, fmt.Errorf("[Invoke] Native contract address %x haven't been registered.", invokeParam.Address)
@@ -110,6 +120,9 @@ func (this *NativeService) Invoke() (interface{}, error) {
 	if err != nil {
 		return result, fmt.Errorf("[Invoke] Native serivce function execute error:%s", err)
 	}
+	if this.outOfGas {
+		return false, fmt.Errorf("[Invoke] %s, gas consumed: %d", ErrOutOfGas, this.gasConsumed)
+	}
 	this.PopContext()
 	this.notifications = append(notifications, this.notifications...)
 	this.crossHashes = append(this.crossHashes, hashes...)
@@ -190,10 +203,48 @@ func (this *NativeService) CheckWitness(address common.Address) bool {
 	return false
 }
 
+// Snapshot is the execution state of native service, which could be restored when a
+// part of the execution fails but the whole transaction should go on
+type Snapshot struct {
+	cache         int
+	notifications int
+	crossHashes   int
+}
+
+// Snapshot returns the current execution state
+func (this *NativeService) Snapshot() *Snapshot {
+	return &Snapshot{
+		cache:         this.cacheDB.Snapshot(),
+		notifications: len(this.notifications),
+		crossHashes:   len(this.crossHashes),
+	}
+}
+
+// RevertToSnapshot discards the storage changes, notifies and cross chain hashes made after snapshot,
+// gas consumed is kept
+func (this *NativeService) RevertToSnapshot(snapshot *Snapshot) {
+	this.cacheDB.RevertToSnapshot(snapshot.cache)
+	this.notifications = this.notifications[:snapshot.notifications]
+	this.crossHashes = this.crossHashes[:snapshot.crossHashes]
+}
+
 func (this *NativeService) AddNotify(notify *event.NotifyEventInfo) {
 	this.notifications = append(this.notifications, notify)
 }
 
+// SetBlockHashes sets the lookup of block hashes on chain, used by contracts checking block headers
+func (this *NativeService) SetBlockHashes(blockHashes func(height uint32) common.Uint256) {
+	this.blockHashes = blockHashes
+}
+
+// GetBlockHash returns the hash of block at height on chain, empty hash is returned when it is unknown
+func (this *NativeService) GetBlockHash(height uint32) common.Uint256 {
+	if this.blockHashes == nil {
+		return common.UINT256_EMPTY
+	}
+	return this.blockHashes(height)
+}
+
 func (this *NativeService) GetCacheDB() *storage.CacheDB {
 	return this.cacheDB
 }
@@ -202,6 +253,11 @@ func (this *NativeService) GetInput() []byte {
 	return this.input
 }
 
+// SetInput replaces the input of current method, which is used to run a handler on a part of the input
+func (this *NativeService) SetInput(input []byte) {
+	this.input = input
+}
+
 func (this *NativeService) GetTx() *types.Transaction {
 	return this.tx
 }
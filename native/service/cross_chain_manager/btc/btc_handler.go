@@ -41,6 +41,10 @@ func NewBTCHandler() *BTCHandler {
 	return &BTCHandler{}
 }
 
+func init() {
+	crosscommon.RegisterChainHandler(utils.BTC_ROUTER, 0, func() crosscommon.ChainHandler { return NewBTCHandler() })
+}
+
 func (this *BTCHandler) MultiSign(service *native.NativeService) error {
 	params := new(crosscommon.MultiSignParam)
 	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
@@ -93,6 +97,9 @@ func (this *BTCHandler) MultiSign(service *native.NativeService) error {
 	if err != nil {
 		return fmt.Errorf("MultiSign, failed to get stxos: %v", err)
 	}
+	if err = service.UseSigVerifyGas(len(params.Signs)); err != nil {
+		return fmt.Errorf("MultiSign, %v", err)
+	}
 	err = verifySigs(params.Signs, params.Address, addrs, redeemScript, mtx, pkScripts, amts)
 	if err != nil {
 		return fmt.Errorf("MultiSign, failed to verify: %v", err)
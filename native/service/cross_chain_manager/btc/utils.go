@@ -91,21 +91,24 @@ func verifyFromBtcTx(native *native.NativeService, proof, tx []byte, fromChainID
 		return nil, fmt.Errorf("VerifyFromBtcProof, not crosschain btc tx, since failed to resolve parameter: %v", err)
 	}
 
-	// make sure the header with height is already synced, meaning the tx is already confirmed in btc block chain
-	bestHeader, err := btc.GetBestBlockHeader(native, fromChainID)
-	if err != nil {
-		return nil, fmt.Errorf("VerifyFromBtcProof, get best block header error:%s", err)
-	}
-	sideChain, err := side_chain_manager.GetSideChain(native, fromChainID)
-	if err != nil {
-		return nil, fmt.Errorf("VerifyFromBtcProof, side_chain_manager.GetSideChain error: %v", err)
-	}
-	if sideChain == nil {
-		return nil, fmt.Errorf("VerifyFromBtcProof, side chain is not registered")
-	}
-	bestHeight := bestHeader.Height
-	if bestHeight < height || bestHeight-height < uint32(sideChain.BlocksToWait-1) {
-		return nil, fmt.Errorf("verifyFromBtcTx, transaction is not confirmed, current height: %d, input height: %d", bestHeight, height)
+	// make sure the header with height is already synced, meaning the tx is already confirmed in btc block chain,
+	// confirmations of the tx are checked against the finality policy of btc once policies are active
+	if !side_chain_manager.FinalityPolicyActive(native.GetHeight()) {
+		bestHeader, err := btc.GetBestBlockHeader(native, fromChainID)
+		if err != nil {
+			return nil, fmt.Errorf("VerifyFromBtcProof, get best block header error:%s", err)
+		}
+		sideChain, err := side_chain_manager.GetSideChain(native, fromChainID)
+		if err != nil {
+			return nil, fmt.Errorf("VerifyFromBtcProof, side_chain_manager.GetSideChain error: %v", err)
+		}
+		if sideChain == nil {
+			return nil, fmt.Errorf("VerifyFromBtcProof, side chain is not registered")
+		}
+		bestHeight := bestHeader.Height
+		if bestHeight < height || bestHeight-height < uint32(sideChain.BlocksToWait-1) {
+			return nil, fmt.Errorf("verifyFromBtcTx, transaction is not confirmed, current height: %d, input height: %d", bestHeight, height)
+		}
 	}
 
 	// verify btc merkle proof
@@ -29,7 +29,8 @@ var (
 	REQUEST             = "request"
 	DONE_TX             = "doneTx"
 
-	NOTIFY_MAKE_PROOF = "makeProof"
+	NOTIFY_MAKE_PROOF    = "makeProof"
+	NOTIFY_IMPORT_RESULT = "importResult"
 )
 
 type ChainHandler interface {
@@ -107,6 +108,34 @@ func (this *EntranceParam) Deserialization(source *common.ZeroCopySource) error
 	return nil
 }
 
+type EntranceBatchParam struct {
+	Params []*EntranceParam
+}
+
+func (this *EntranceBatchParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Params)))
+	for _, param := range this.Params {
+		param.Serialization(sink)
+	}
+}
+
+func (this *EntranceBatchParam) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("EntranceBatchParam deserialize params length error")
+	}
+	params := make([]*EntranceParam, 0)
+	for i := uint64(0); i < n; i++ {
+		param := new(EntranceParam)
+		if err := param.Deserialization(source); err != nil {
+			return fmt.Errorf("EntranceBatchParam deserialize params error: %v", err)
+		}
+		params = append(params, param)
+	}
+	this.Params = params
+	return nil
+}
+
 type MakeTxParam struct {
 	TxHash              []byte
 	CrossChainID        []byte
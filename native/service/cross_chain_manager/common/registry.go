@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package common
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ChainHandlerFactory creates the cross chain handler of a router
+type ChainHandlerFactory func() ChainHandler
+
+type chainHandlerEntry struct {
+	factory      ChainHandlerFactory
+	activeHeight uint32
+}
+
+var (
+	handlersLock sync.RWMutex
+	handlers     = make(map[uint64]*chainHandlerEntry)
+)
+
+// RegisterChainHandler registers the cross chain handler of router, the handler
+// can only be used by blocks at or above activeHeight. It is meant to be called
+// from the init function of a chain package and panics on duplicate routers.
+func RegisterChainHandler(router uint64, activeHeight uint32, factory ChainHandlerFactory) {
+	handlersLock.Lock()
+	defer handlersLock.Unlock()
+	if factory == nil {
+		panic(fmt.Sprintf("RegisterChainHandler, nil factory for router %d", router))
+	}
+	if _, ok := handlers[router]; ok {
+		panic(fmt.Sprintf("RegisterChainHandler, router %d registered twice", router))
+	}
+	handlers[router] = &chainHandlerEntry{factory: factory, activeHeight: activeHeight}
+}
+
+// GetChainHandler returns the cross chain handler of router which is active at height
+func GetChainHandler(router uint64, height uint32) (ChainHandler, error) {
+	handlersLock.RLock()
+	entry, ok := handlers[router]
+	handlersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("not a supported router:%d", router)
+	}
+	if height < entry.activeHeight {
+		return nil, fmt.Errorf("router %d is not active until height %d", router, entry.activeHeight)
+	}
+	return entry.factory(), nil
+}
+
+// RegisteredRouters returns all the routers with a cross chain handler in ascending order
+func RegisteredRouters() []uint64 {
+	handlersLock.RLock()
+	defer handlersLock.RUnlock()
+	routers := make([]uint64, 0, len(handlers))
+	for router := range handlers {
+		routers = append(routers, router)
+	}
+	sort.Slice(routers, func(i, j int) bool { return routers[i] < routers[j] })
+	return routers
+}
+
+// RouterActiveHeight returns the activation height of router
+func RouterActiveHeight(router uint64) (uint32, bool) {
+	handlersLock.RLock()
+	defer handlersLock.RUnlock()
+	entry, ok := handlers[router]
+	if !ok {
+		return 0, false
+	}
+	return entry.activeHeight, true
+}
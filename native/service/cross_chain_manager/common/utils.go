@@ -31,14 +31,29 @@ func Replace0x(s string) string {
 	return strings.Replace(strings.ToLower(s), "0x", "", 1)
 }
 
-func NotifyMakeProof(native *native.NativeService, fromChainID, toChainID uint64, txHash string, key string) {
+func NotifyMakeProof(native *native.NativeService, fromChainID, toChainID uint64, txHash string, key string, crossChainID string) {
 	if !config.DefConfig.Common.EnableEventLog {
 		return
 	}
 	native.AddNotify(
 		&event.NotifyEventInfo{
 			ContractAddress: utils.CrossChainManagerContractAddress,
-			States:          []interface{}{NOTIFY_MAKE_PROOF, fromChainID, toChainID, txHash, native.GetHeight(), key},
+			States:          []interface{}{NOTIFY_MAKE_PROOF, fromChainID, toChainID, txHash, native.GetHeight(), key, crossChainID},
+		})
+}
+
+func NotifyImportResult(native *native.NativeService, index int, sourceChainID uint64, err error) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return
+	}
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{NOTIFY_IMPORT_RESULT, uint64(index), sourceChainID, err == nil, errMsg},
 		})
 }
 
@@ -25,6 +25,7 @@ import (
 	"github.com/polynetwork/poly/native"
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/header_sync/cosmos"
+	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/ed25519"
 	"github.com/tendermint/tendermint/crypto/merkle"
@@ -38,6 +39,10 @@ func NewCosmosHandler() *CosmosHandler {
 	return &CosmosHandler{}
 }
 
+func init() {
+	scom.RegisterChainHandler(utils.COSMOS_ROUTER, 0, func() scom.ChainHandler { return NewCosmosHandler() })
+}
+
 type CosmosProofValue struct {
 	Kp    string
 	Value []byte
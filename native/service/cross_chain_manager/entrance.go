@@ -17,78 +17,54 @@
 package cross_chain_manager
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/native"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/bsc"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/cosmos"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/eth"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/heco"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/msc"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/neo"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/neo3"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/okex"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/ont"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/polygon"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/quorum"
-	"github.com/polynetwork/poly/native/service/cross_chain_manager/zilliqa"
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
 const (
-	IMPORT_OUTER_TRANSFER_NAME = "ImportOuterTransfer"
-	MULTI_SIGN                 = "MultiSign"
-	BLACK_CHAIN                = "BlackChain"
-	WHITE_CHAIN                = "WhiteChain"
+	IMPORT_OUTER_TRANSFER_NAME       = "ImportOuterTransfer"
+	IMPORT_OUTER_TRANSFER_BATCH_NAME = "ImportOuterTransferBatch"
+	MULTI_SIGN                       = "MultiSign"
+	BLACK_CHAIN                      = "BlackChain"
+	WHITE_CHAIN                      = "WhiteChain"
 
 	BLACKED_CHAIN = "BlackedChain"
+
+	//max number of cross chain txs imported by one ImportOuterTransferBatch
+	MAX_IMPORT_BATCH_SIZE = 100
 )
 
 func RegisterCrossChainManagerContract(native *native.NativeService) {
 	native.Register(IMPORT_OUTER_TRANSFER_NAME, ImportExTransfer)
+	if native.GetHeight() >= config.GetActivationHeight(config.FEATURE_BATCH_IMPORT, config.DefConfig.P2PNode.NetworkId) {
+		native.Register(IMPORT_OUTER_TRANSFER_BATCH_NAME, ImportExTransferBatch)
+	}
+	if native.GetHeight() >= config.GetActivationHeight(config.FEATURE_CROSS_CHAIN_PAUSE, config.DefConfig.P2PNode.NetworkId) {
+		native.Register(PAUSE, Pause)
+		native.Register(UNPAUSE, Unpause)
+		native.Register(GET_PAUSES, GetPauses)
+	}
 	native.Register(MULTI_SIGN, MultiSign)
 
 	native.Register(BLACK_CHAIN, BlackChain)
 	native.Register(WHITE_CHAIN, WhiteChain)
 }
 
-func GetChainHandler(router uint64) (scom.ChainHandler, error) {
-	switch router {
-	case utils.BTC_ROUTER:
-		return btc.NewBTCHandler(), nil
-	case utils.ETH_ROUTER:
-		return eth.NewETHHandler(), nil
-	case utils.ONT_ROUTER:
-		return ont.NewONTHandler(), nil
-	case utils.NEO_ROUTER:
-		return neo.NewNEOHandler(), nil
-	case utils.NEO3_ROUTER:
-		return neo3.NewNeo3Handler(), nil
-	case utils.COSMOS_ROUTER:
-		return cosmos.NewCosmosHandler(), nil
-	case utils.QUORUM_ROUTER:
-		return quorum.NewQuorumHandler(), nil
-	case utils.BSC_ROUTER:
-		return bsc.NewHandler(), nil
-	case utils.HECO_ROUTER:
-		return heco.NewHecoHandler(), nil
-	case utils.ZILLIQA_ROUTER:
-		return zilliqa.NewHandler(), nil
-	case utils.MSC_ROUTER:
-		return msc.NewHandler(), nil
-	case utils.OKEX_ROUTER:
-		return okex.NewHandler(), nil
-	case utils.POLYGON_BOR_ROUTER:
-		return polygon.NewHandler(), nil
-	default:
-		return nil, fmt.Errorf("not a supported router:%d", router)
-	}
+// GetChainHandler returns the cross chain handler registered for router at height
+func GetChainHandler(router uint64, height uint32) (scom.ChainHandler, error) {
+	return scom.GetChainHandler(router, height)
 }
 
 func ImportExTransfer(native *native.NativeService) ([]byte, error) {
@@ -96,67 +72,157 @@ func ImportExTransfer(native *native.NativeService) ([]byte, error) {
 	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, contract params deserialize error: %v", err)
 	}
+	if err := relayer_manager.ChargeRelayer(native, 0, 1); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, %v", err)
+	}
+	if err := importExTransfer(native, params, native.GetTx().Hash()); err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+// ImportExTransferBatch imports a list of cross chain txs, each of them is verified independently.
+// A failed one is reverted and reported by notify instead of failing the whole transaction
+func ImportExTransferBatch(native *native.NativeService) ([]byte, error) {
+	params := new(scom.EntranceBatchParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransferBatch, contract params deserialize error: %v", err)
+	}
+	if len(params.Params) == 0 || len(params.Params) > MAX_IMPORT_BATCH_SIZE {
+		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransferBatch, batch size should be in [1, %d], got %d",
+			MAX_IMPORT_BATCH_SIZE, len(params.Params))
+	}
+	if err := relayer_manager.ChargeRelayer(native, 0, uint64(len(params.Params))); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransferBatch, %v", err)
+	}
+
+	input := native.GetInput()
+	defer native.SetInput(input)
+	txHash := native.GetTx().Hash()
+	for i, param := range params.Params {
+		//chain handlers read the entrance param from input
+		sink := common.NewZeroCopySink(nil)
+		param.Serialization(sink)
+		native.SetInput(sink.Bytes())
+
+		snapshot := native.Snapshot()
+		err := importExTransfer(native, param, batchRequestID(txHash, i))
+		if err != nil {
+			native.RevertToSnapshot(snapshot)
+		}
+		scom.NotifyImportResult(native, i, param.SourceChainID, err)
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+// batchRequestID returns the id of the request made by the index-th cross chain tx of a batch import,
+// requests to the same target chain are stored by id so they must differ in one poly tx
+func batchRequestID(txHash common.Uint256, index int) common.Uint256 {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteHash(txHash)
+	sink.WriteUint32(uint32(index))
+	return sha256.Sum256(sink.Bytes())
+}
+
+func importExTransfer(native *native.NativeService, params *scom.EntranceParam, requestID common.Uint256) error {
+	if err := native.UseProofVerifyGas(); err != nil {
+		return fmt.Errorf("ImportExTransfer, %v", err)
+	}
 
 	chainID := params.SourceChainID
 	blacked, err := CheckIfChainBlacked(native, chainID)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, CheckIfChainBlacked error: %v", err)
+		return fmt.Errorf("ImportExTransfer, CheckIfChainBlacked error: %v", err)
 	}
 	if blacked {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, source chain is blacked")
+		return fmt.Errorf("ImportExTransfer, source chain is blacked")
 	}
 
 	//check if chainid exist
 	sideChain, err := side_chain_manager.GetSideChain(native, chainID)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, side_chain_manager.GetSideChain error: %v", err)
+		return fmt.Errorf("ImportExTransfer, side_chain_manager.GetSideChain error: %v", err)
 	}
 	if sideChain == nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, side chain %d is not registered", chainID)
+		return fmt.Errorf("ImportExTransfer, side chain %d is not registered", chainID)
 	}
 
-	handler, err := GetChainHandler(sideChain.Router)
+	handler, err := GetChainHandler(sideChain.Router, native.GetHeight())
 	if err != nil {
-		return utils.BYTE_FALSE, err
+		return err
+	}
+	if err := checkFinality(native, sideChain, params.Height); err != nil {
+		return fmt.Errorf("ImportExTransfer, %v", err)
 	}
 	//1. verify tx
 	txParam, err := handler.MakeDepositProposal(native)
 	if err != nil {
-		return utils.BYTE_FALSE, err
+		return err
 	}
 
 	//2. make target chain tx
+	if native.GetHeight() >= config.GetActivationHeight(config.FEATURE_CROSS_CHAIN_PAUSE, config.DefConfig.P2PNode.NetworkId) {
+		if err := CheckIfPaused(native, chainID, txParam); err != nil {
+			return fmt.Errorf("ImportExTransfer, %v", err)
+		}
+	}
 	targetid := txParam.ToChainID
 	blacked, err = CheckIfChainBlacked(native, targetid)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, CheckIfChainBlacked error: %v", err)
+		return fmt.Errorf("ImportExTransfer, CheckIfChainBlacked error: %v", err)
 	}
 	if blacked {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, target chain is blacked")
+		return fmt.Errorf("ImportExTransfer, target chain is blacked")
 	}
 
 	//check if chainid exist
 	sideChain, err = side_chain_manager.GetSideChain(native, targetid)
 	if err != nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, side_chain_manager.GetSideChain error: %v", err)
+		return fmt.Errorf("ImportExTransfer, side_chain_manager.GetSideChain error: %v", err)
 	}
 	if sideChain == nil {
-		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, side chain %d is not registered", targetid)
+		return fmt.Errorf("ImportExTransfer, side chain %d is not registered", targetid)
 	}
 	if sideChain.Router == utils.BTC_ROUTER {
-		err := btc.NewBTCHandler().MakeTransaction(native, txParam, chainID)
-		if err != nil {
-			return utils.BYTE_FALSE, err
-		}
-		return utils.BYTE_TRUE, nil
+		err = btc.NewBTCHandler().MakeTransaction(native, txParam, chainID)
+	} else {
+		//NOTE, you need to store the tx in this
+		err = makeTransaction(native, txParam, chainID, requestID)
+	}
+	if err != nil {
+		return err
+	}
+	if err := relayer_manager.RewardDelivery(native, params.RelayerAddress, chainID, targetid); err != nil {
+		return fmt.Errorf("ImportExTransfer, %v", err)
 	}
+	return nil
+}
 
-	//NOTE, you need to store the tx in this
-	err = MakeTransaction(native, txParam, chainID)
+// checkFinality checks the proof height against the finality policy of source chain,
+// chains without a policy only sync finalized headers or are checked by their chain handlers
+func checkFinality(native *native.NativeService, sideChain *side_chain_manager.SideChain, height uint32) error {
+	policy := sideChain.GetFinalityPolicy(native.GetHeight())
+	if policy == nil {
+		return nil
+	}
+	handler, err := hscommon.GetHeaderSyncHandler(sideChain.Router, native.GetHeight())
 	if err != nil {
-		return utils.BYTE_FALSE, err
+		return fmt.Errorf("checkFinality, %v", err)
 	}
-	return utils.BYTE_TRUE, nil
+	queryHandler, ok := handler.(hscommon.HeaderQueryHandler)
+	if !ok {
+		return fmt.Errorf("checkFinality, %v: router %d", hscommon.ErrQueryNotSupported, sideChain.Router)
+	}
+	current, err := queryHandler.GetCurrentHeight(native, sideChain.ChainId)
+	if err != nil {
+		return fmt.Errorf("checkFinality, GetCurrentHeight error: %v", err)
+	}
+	finalized, ok := policy.FinalizedHeight(current)
+	if !ok || uint64(height) > finalized {
+		return fmt.Errorf("checkFinality, transaction at height %d of chain %d is not confirmed, synced height %d, finality mode %d",
+			height, sideChain.ChainId, current, policy.Mode)
+	}
+	return nil
 }
 
 func MultiSign(native *native.NativeService) ([]byte, error) {
@@ -171,23 +237,30 @@ func MultiSign(native *native.NativeService) ([]byte, error) {
 }
 
 func MakeTransaction(service *native.NativeService, params *scom.MakeTxParam, fromChainID uint64) error {
-	txHash := service.GetTx().Hash()
+	return makeTransaction(service, params, fromChainID, service.GetTx().Hash())
+}
+
+// makeTransaction stores the request to target chain by requestID, which is the poly tx hash
+// except for the txs of a batch import. The target chain takes requestID as the poly tx hash
+// of the request, so that each tx of a batch is executed once
+func makeTransaction(service *native.NativeService, params *scom.MakeTxParam, fromChainID uint64, requestID common.Uint256) error {
 	merkleValue := &scom.ToMerkleValue{
-		TxHash:      txHash.ToArray(),
+		TxHash:      requestID.ToArray(),
 		FromChainID: fromChainID,
 		MakeTxParam: params,
 	}
 
 	sink := common.NewZeroCopySink(nil)
 	merkleValue.Serialization(sink)
-	err := PutRequest(service, merkleValue.TxHash, params.ToChainID, sink.Bytes())
+	err := PutRequest(service, requestID.ToArray(), params.ToChainID, sink.Bytes())
 	if err != nil {
 		return fmt.Errorf("MakeTransaction, putRequest error:%s", err)
 	}
 	service.PutMerkleVal(sink.Bytes())
 	chainIDBytes := utils.GetUint64Bytes(params.ToChainID)
-	key := hex.EncodeToString(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(scom.REQUEST), chainIDBytes, merkleValue.TxHash))
-	scom.NotifyMakeProof(service, fromChainID, params.ToChainID, hex.EncodeToString(params.TxHash), key)
+	key := hex.EncodeToString(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(scom.REQUEST), chainIDBytes, requestID.ToArray()))
+	scom.NotifyMakeProof(service, fromChainID, params.ToChainID, hex.EncodeToString(params.TxHash), key,
+		hex.EncodeToString(params.CrossChainID))
 	return nil
 }
 
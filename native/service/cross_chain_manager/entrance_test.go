@@ -0,0 +1,150 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package cross_chain_manager
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/eth"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+const batchTestRouter = 1000
+
+// batchTestHandler writes the proof to storage before failing the params with extra "fail"
+type batchTestHandler struct{}
+
+func (this *batchTestHandler) MakeDepositProposal(service *native.NativeService) (*scom.MakeTxParam, error) {
+	params := new(scom.EntranceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return nil, err
+	}
+	utils.PutBytes(service, utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte("batchTest"), params.Proof), params.Proof)
+	if string(params.Extra) == "fail" {
+		return nil, fmt.Errorf("batch test failure")
+	}
+	return &scom.MakeTxParam{TxHash: params.Proof, CrossChainID: params.Proof, ToChainID: 6, Method: "unlock"}, nil
+}
+
+func init() {
+	scom.RegisterChainHandler(batchTestRouter, 0, func() scom.ChainHandler { return new(batchTestHandler) })
+}
+
+func TestImportExTransferBatchPartialFailure(t *testing.T) {
+	networkID, eventLog := config.DefConfig.P2PNode.NetworkId, config.DefConfig.Common.EnableEventLog
+	config.DefConfig.P2PNode.NetworkId, config.DefConfig.Common.EnableEventLog = config.NETWORK_ID_SOLO_NET, true
+	defer func() {
+		config.DefConfig.P2PNode.NetworkId, config.DefConfig.Common.EnableEventLog = networkID, eventLog
+	}()
+
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	batch := &scom.EntranceBatchParam{Params: []*scom.EntranceParam{
+		{SourceChainID: 5, Proof: []byte{1}},
+		{SourceChainID: 5, Proof: []byte{2}, Extra: []byte("fail")},
+		{SourceChainID: 5, Proof: []byte{3}},
+	}}
+	sink := common.NewZeroCopySink(nil)
+	batch.Serialization(sink)
+	tx := &types.Transaction{Nonce: 1}
+	service, err := native.NewNativeService(db, tx, 0, 100, common.Uint256{}, 0, sink.Bytes(), false)
+	assert.Nil(t, err)
+	assert.Nil(t, side_chain_manager.PutSideChain(service, &side_chain_manager.SideChain{ChainId: 5, Router: batchTestRouter}))
+	assert.Nil(t, side_chain_manager.PutSideChain(service, &side_chain_manager.SideChain{ChainId: 6, Router: utils.ETH_ROUTER}))
+
+	_, err = ImportExTransferBatch(service)
+	assert.Nil(t, err)
+	assert.Equal(t, sink.Bytes(), service.GetInput(), "input should be restored")
+
+	//the failed item is reverted, the others are kept
+	for proof, expect := range map[byte]bool{1: true, 2: false, 3: true} {
+		value, err := db.Get(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte("batchTest"), []byte{proof}))
+		assert.Nil(t, err)
+		assert.Equal(t, expect, value != nil, "storage of item %d", proof)
+	}
+	assert.Equal(t, 2, len(service.GetCrossHashes()))
+
+	results := make([]bool, 0)
+	for _, notify := range service.GetNotify() {
+		states := notify.States.([]interface{})
+		switch states[0] {
+		case scom.NOTIFY_IMPORT_RESULT:
+			results = append(results, states[3].(bool))
+		case scom.NOTIFY_MAKE_PROOF:
+			assert.NotEqual(t, "02", states[3], "notify of failed item should be reverted")
+		}
+	}
+	assert.Equal(t, []bool{true, false, true}, results)
+
+	//each request is stored by its id and carries it as the merkle tx hash, so that the target
+	//chain does not take the other txs of the batch as replays
+	txHash := tx.Hash()
+	merkleTxHashes := make(map[string]bool)
+	for _, index := range []int{0, 2} {
+		requestID := batchRequestID(txHash, index)
+		item, err := utils.GetStorageItem(service, utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(scom.REQUEST),
+			utils.GetUint64Bytes(6), requestID.ToArray()))
+		assert.Nil(t, err)
+		merkleValue := new(scom.ToMerkleValue)
+		assert.Nil(t, merkleValue.Deserialization(common.NewZeroCopySource(item.Value)))
+		assert.Equal(t, requestID.ToArray(), merkleValue.TxHash)
+		assert.NotEqual(t, txHash.ToArray(), merkleValue.TxHash)
+		assert.Equal(t, []byte{byte(index + 1)}, merkleValue.MakeTxParam.TxHash)
+		merkleTxHashes[string(merkleValue.TxHash)] = true
+	}
+	assert.Equal(t, 2, len(merkleTxHashes))
+}
+
+func TestImportExTransferNotConfirmed(t *testing.T) {
+	networkID, genesisConfig := config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	config.DefConfig.Genesis = &config.GenesisConfig{
+		ActivationHeights: map[string]uint32{string(config.FEATURE_FINALITY_POLICY): 0},
+	}
+	defer func() { config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis = networkID, genesisConfig }()
+
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	service, err := native.NewNativeService(db, &types.Transaction{}, 0, 100, common.Uint256{}, 0, nil, false)
+	assert.Nil(t, err)
+	//eth chain registered without a finality policy waits for BlocksToWait confirmations
+	assert.Nil(t, side_chain_manager.PutSideChain(service, &side_chain_manager.SideChain{ChainId: 2, Router: utils.ETH_ROUTER, BlocksToWait: 3}))
+	utils.PutBytes(service, utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.CURRENT_HEADER_HEIGHT),
+		utils.GetUint64Bytes(2)), utils.GetUint64Bytes(7259465))
+
+	for _, height := range []uint32{7259464, 7259465, 7259466} {
+		err = importExTransfer(service, &scom.EntranceParam{SourceChainID: 2, Height: height}, common.Uint256{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "is not confirmed", "height %d", height)
+	}
+	//confirmed txs are left to the chain handler
+	err = importExTransfer(service, &scom.EntranceParam{SourceChainID: 2, Height: 7259463}, common.Uint256{})
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "is not confirmed")
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/polynetwork/poly/native"
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 type ETHHandler struct {
@@ -31,6 +32,10 @@ func NewETHHandler() *ETHHandler {
 	return &ETHHandler{}
 }
 
+func init() {
+	scom.RegisterChainHandler(utils.ETH_ROUTER, 0, func() scom.ChainHandler { return NewETHHandler() })
+}
+
 func (this *ETHHandler) MakeDepositProposal(service *native.NativeService) (*scom.MakeTxParam, error) {
 	params := new(scom.EntranceParam)
 	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
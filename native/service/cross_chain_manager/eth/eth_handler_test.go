@@ -24,6 +24,7 @@ import (
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
 	"github.com/polynetwork/poly/core/genesis"
 	cstates "github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/core/store/leveldbstore"
@@ -31,6 +32,7 @@ import (
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native"
 	ccmcom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	synccom "github.com/polynetwork/poly/native/service/header_sync/common"
 	synceth "github.com/polynetwork/poly/native/service/header_sync/eth"
@@ -87,6 +89,29 @@ func NewNative(args []byte, tx *types.Transaction, db *storage.CacheDB) *native.
 	if db == nil {
 		store, _ := leveldbstore.NewMemLevelDBStore()
 		db = storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+		sink := common.NewZeroCopySink(nil)
+		view := &node_manager.GovernanceView{
+			TxHash: common.UINT256_EMPTY,
+			Height: 0,
+			View:   0,
+		}
+		view.Serialization(sink)
+		db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW)), cstates.GenRawStorageItem(sink.Bytes()))
+
+		peerPoolMap := &node_manager.PeerPoolMap{
+			PeerPoolMap: map[string]*node_manager.PeerPoolItem{
+				vconfig.PubkeyID(acct.PublicKey): {
+					Address:    acct.Address,
+					Status:     node_manager.ConsensusStatus,
+					PeerPubkey: vconfig.PubkeyID(acct.PublicKey),
+					Index:      0,
+				},
+			},
+		}
+		sink.Reset()
+		peerPoolMap.Serialization(sink)
+		db.Put(utils.ConcatKey(utils.NodeManagerContractAddress,
+			[]byte(node_manager.PEER_POOL), utils.GetUint32Bytes(0)), cstates.GenRawStorageItem(sink.Bytes()))
 	}
 	ns, err := native.NewNativeService(db, tx, 0, 0, common.Uint256{0}, 0, args, false)
 	if err != nil {
@@ -197,7 +222,7 @@ func TestProofHandle(t *testing.T) {
 		param.Serialization(sink)
 
 		native = NewNative(sink.Bytes(), &types.Transaction{ChainID: 0}, native.GetCacheDB())
-		err := ethSyncHandler.SyncBlockHeader(native)
+		_, err := ethSyncHandler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err))
 		height := getLatestHeight(native)
 		assert.Equal(t, uint64(7259465), height)
@@ -264,7 +289,7 @@ func TestProofHandle_HeaderNotConfirmed(t *testing.T) {
 		param.Serialization(sink)
 
 		native = NewNative(sink.Bytes(), &types.Transaction{}, native.GetCacheDB())
-		err := ethSyncHandler.SyncBlockHeader(native)
+		_, err := ethSyncHandler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err))
 		height := getLatestHeight(native)
 		assert.Equal(t, uint64(7259465), height)
@@ -329,7 +354,7 @@ func TestProofHandle1(t *testing.T) {
 		param.Serialization(sink)
 
 		native = NewNative(sink.Bytes(), &types.Transaction{}, native.GetCacheDB())
-		err := ethSyncHandler.SyncBlockHeader(native)
+		_, err := ethSyncHandler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err))
 		height := getLatestHeight(native)
 		assert.Equal(t, uint64(7272744), height)
@@ -393,7 +418,7 @@ func TestProofHandle2(t *testing.T) {
 		param.Serialization(sink)
 
 		native = NewNative(sink.Bytes(), &types.Transaction{}, native.GetCacheDB())
-		err := ethSyncHandler.SyncBlockHeader(native)
+		_, err := ethSyncHandler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err))
 		height := getLatestHeight(native)
 		assert.Equal(t, uint64(7272837), height)
@@ -455,7 +480,7 @@ func TestProofHandle_HeaderNotExist(t *testing.T) {
 		param.Serialization(sink)
 
 		native = NewNative(sink.Bytes(), &types.Transaction{}, native.GetCacheDB())
-		err := ethSyncHandler.SyncBlockHeader(native)
+		_, err := ethSyncHandler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err))
 		height := getLatestHeight(native)
 		assert.Equal(t, uint64(7259463), height)
@@ -521,7 +546,7 @@ func TestProofHandle_ProofFormatWrong(t *testing.T) {
 		param.Serialization(sink)
 
 		native = NewNative(sink.Bytes(), &types.Transaction{}, native.GetCacheDB())
-		err := ethSyncHandler.SyncBlockHeader(native)
+		_, err := ethSyncHandler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err))
 		height := getLatestHeight(native)
 		assert.Equal(t, uint64(7259465), height)
@@ -586,7 +611,7 @@ func TestProofHandle_VerifyProofWrong(t *testing.T) {
 		param.Serialization(sink)
 
 		native = NewNative(sink.Bytes(), &types.Transaction{}, native.GetCacheDB())
-		err := ethSyncHandler.SyncBlockHeader(native)
+		_, err := ethSyncHandler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err))
 		height := getLatestHeight(native)
 		assert.Equal(t, uint64(7259465), height)
@@ -653,7 +678,7 @@ func TestProofHandle_CommitTwice(t *testing.T) {
 		param.Serialization(sink)
 
 		native = NewNative(sink.Bytes(), &types.Transaction{}, native.GetCacheDB())
-		err := ethSyncHandler.SyncBlockHeader(native)
+		_, err := ethSyncHandler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err))
 		height := getLatestHeight(native)
 		assert.Equal(t, uint64(7259465), height)
@@ -736,7 +761,7 @@ func TestProofHandle_ZEROPrefix(t *testing.T) {
 		param.Serialization(sink)
 
 		native = NewNative(sink.Bytes(), &types.Transaction{}, native.GetCacheDB())
-		err := ethSyncHandler.SyncBlockHeader(native)
+		_, err := ethSyncHandler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err))
 		height := getLatestHeight(native)
 		assert.Equal(t, uint64(7967936), height)
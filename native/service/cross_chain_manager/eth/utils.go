@@ -39,13 +39,15 @@ import (
 )
 
 func verifyFromEthTx(native *native.NativeService, proof, extra []byte, fromChainID uint64, height uint32, sideChain *cmanager.SideChain) (*scom.MakeTxParam, error) {
-	bestHeader, _, err := eth.GetCurrentHeader(native, fromChainID)
-	if err != nil {
-		return nil, fmt.Errorf("VerifyFromEthProof, get current header fail, error:%s", err)
-	}
-	bestHeight := uint32(bestHeader.Number.Uint64())
-	if bestHeight < height || bestHeight-height < uint32(sideChain.BlocksToWait-1) {
-		return nil, fmt.Errorf("VerifyFromEthProof, transaction is not confirmed, current height: %d, input height: %d", bestHeight, height)
+	if !cmanager.FinalityPolicyActive(native.GetHeight()) {
+		bestHeader, _, err := eth.GetCurrentHeader(native, fromChainID)
+		if err != nil {
+			return nil, fmt.Errorf("VerifyFromEthProof, get current header fail, error:%s", err)
+		}
+		bestHeight := uint32(bestHeader.Number.Uint64())
+		if bestHeight < height || bestHeight-height < uint32(sideChain.BlocksToWait-1) {
+			return nil, fmt.Errorf("VerifyFromEthProof, transaction is not confirmed, current height: %d, input height: %d", bestHeight, height)
+		}
 	}
 
 	blockData, _, err := eth.GetHeaderByHeight(native, uint64(height), fromChainID)
@@ -36,6 +36,7 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/heco"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 // Handler ...
@@ -47,6 +48,10 @@ func NewHecoHandler() *HecoHandler {
 	return &HecoHandler{}
 }
 
+func init() {
+	scom.RegisterChainHandler(utils.HECO_ROUTER, 0, func() scom.ChainHandler { return NewHecoHandler() })
+}
+
 // MakeDepositProposal ...
 func (h *HecoHandler) MakeDepositProposal(service *native.NativeService) (*scom.MakeTxParam, error) {
 	params := new(scom.EntranceParam)
@@ -74,15 +79,17 @@ func (h *HecoHandler) MakeDepositProposal(service *native.NativeService) (*scom.
 }
 
 func verifyFromHecoTx(native *native.NativeService, proof, extra []byte, fromChainID uint64, height uint32, sideChain *side_chain_manager.SideChain) (param *scom.MakeTxParam, err error) {
-	cheight, err := heco.GetCanonicalHeight(native, fromChainID)
-	if err != nil {
-		return
-	}
+	if !side_chain_manager.FinalityPolicyActive(native.GetHeight()) {
+		cheight, err := heco.GetCanonicalHeight(native, fromChainID)
+		if err != nil {
+			return nil, err
+		}
 
-	cheight32 := uint32(cheight)
+		cheight32 := uint32(cheight)
 
-	if cheight32 < height || cheight32-height < uint32(sideChain.BlocksToWait-1) {
-		return nil, fmt.Errorf("verifyFromHecoTx, transaction is not confirmed, current height: %d, input height: %d", cheight, height)
+		if cheight32 < height || cheight32-height < uint32(sideChain.BlocksToWait-1) {
+			return nil, fmt.Errorf("verifyFromHecoTx, transaction is not confirmed, current height: %d, input height: %d", cheight, height)
+		}
 	}
 
 	headerWithSum, err := heco.GetCanonicalHeader(native, fromChainID, uint64(height))
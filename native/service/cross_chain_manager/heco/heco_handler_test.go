@@ -434,7 +434,7 @@ func TestProofHandle(t *testing.T) {
 		}
 		native = NewNative(sink.Bytes(), tx, native.GetCacheDB())
 
-		err := syncHandler.SyncBlockHeader(native)
+		_, err := syncHandler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err), err)
 		latestHeight := getLatestHeight(native)
 		assert.Equal(t, latestHeight, height+headerNumber)
@@ -498,7 +498,7 @@ func TestProofHandle(t *testing.T) {
 			SignedAddr: []common.Address{acct.Address},
 		}
 		native = NewNative(sink.Bytes(), tx, native.GetCacheDB())
-		err := syncHandler.SyncBlockHeader(native)
+		_, err := syncHandler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err))
 
 		sink = common.NewZeroCopySink(nil)
@@ -24,6 +24,7 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/neo"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 type NEOHandler struct {
@@ -33,6 +34,10 @@ func NewNEOHandler() *NEOHandler {
 	return &NEOHandler{}
 }
 
+func init() {
+	scom.RegisterChainHandler(utils.NEO_ROUTER, 0, func() scom.ChainHandler { return NewNEOHandler() })
+}
+
 func (this *NEOHandler) MakeDepositProposal(service *native.NativeService) (*scom.MakeTxParam, error) {
 	params := new(scom.EntranceParam)
 	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
@@ -25,6 +25,7 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/neo3"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 type Neo3Handler struct {
@@ -34,6 +35,10 @@ func NewNeo3Handler() *Neo3Handler {
 	return &Neo3Handler{}
 }
 
+func init() {
+	scom.RegisterChainHandler(utils.NEO3_ROUTER, 0, func() scom.ChainHandler { return NewNeo3Handler() })
+}
+
 func (this *Neo3Handler) MakeDepositProposal(service *native.NativeService) (*scom.MakeTxParam, error) {
 	params := new(scom.EntranceParam)
 	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
@@ -28,6 +28,7 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/okex"
+	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/tendermint/tendermint/crypto/merkle"
 )
 
@@ -37,6 +38,10 @@ func NewHandler() *OKHandler {
 	return &OKHandler{}
 }
 
+func init() {
+	scom.RegisterChainHandler(utils.OKEX_ROUTER, 0, func() scom.ChainHandler { return NewHandler() })
+}
+
 type CosmosProofValue struct {
 	Kp    string
 	Value []byte
@@ -19,6 +19,7 @@ package ont
 
 import (
 	"fmt"
+	"github.com/polynetwork/poly/native/service/utils"
 
 	"github.com/ontio/ontology-crypto/keypair"
 	ocommon "github.com/ontio/ontology/common"
@@ -36,6 +37,10 @@ func NewONTHandler() *ONTHandler {
 	return &ONTHandler{}
 }
 
+func init() {
+	scom.RegisterChainHandler(utils.ONT_ROUTER, 0, func() scom.ChainHandler { return NewONTHandler() })
+}
+
 func (this *ONTHandler) MakeDepositProposal(service *native.NativeService) (*scom.MakeTxParam, error) {
 	params := new(scom.EntranceParam)
 	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
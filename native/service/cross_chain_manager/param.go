@@ -39,3 +39,79 @@ func (this *BlackChainParam) Deserialization(source *common.ZeroCopySource) erro
 	this.ChainID = chainID
 	return nil
 }
+
+// PauseParam pauses the cross chain txs matching all of its non-empty fields until
+// poly reaches ExpireHeight, zero chain ids, empty contract and method match any
+type PauseParam struct {
+	FromChainID       uint64
+	ToChainID         uint64
+	ToContractAddress []byte
+	Method            string
+	ExpireHeight      uint32 //0 means paused until unpaused
+}
+
+func (this *PauseParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.FromChainID)
+	sink.WriteVarUint(this.ToChainID)
+	sink.WriteVarBytes(this.ToContractAddress)
+	sink.WriteString(this.Method)
+	sink.WriteUint32(this.ExpireHeight)
+}
+
+func (this *PauseParam) Deserialization(source *common.ZeroCopySource) error {
+	fromChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("PauseParam deserialize fromChainID error")
+	}
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("PauseParam deserialize toChainID error")
+	}
+	toContractAddress, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("PauseParam deserialize toContractAddress error")
+	}
+	method, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("PauseParam deserialize method error")
+	}
+	expireHeight, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("PauseParam deserialize expireHeight error")
+	}
+	this.FromChainID = fromChainID
+	this.ToChainID = toChainID
+	this.ToContractAddress = toContractAddress
+	this.Method = method
+	this.ExpireHeight = expireHeight
+	return nil
+}
+
+// PauseList is all the pauses stored by cross chain manager
+type PauseList struct {
+	Pauses []*PauseParam
+}
+
+func (this *PauseList) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Pauses)))
+	for _, pause := range this.Pauses {
+		pause.Serialization(sink)
+	}
+}
+
+func (this *PauseList) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("PauseList deserialize length error")
+	}
+	pauses := make([]*PauseParam, 0)
+	for i := uint64(0); i < n; i++ {
+		pause := new(PauseParam)
+		if err := pause.Deserialization(source); err != nil {
+			return fmt.Errorf("PauseList deserialize pause error: %v", err)
+		}
+		pauses = append(pauses, pause)
+	}
+	this.Pauses = pauses
+	return nil
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package cross_chain_manager
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	PAUSE      = "Pause"
+	UNPAUSE    = "Unpause"
+	GET_PAUSES = "GetPauses"
+
+	PAUSED = "Paused"
+)
+
+func Pause(native *native.NativeService) ([]byte, error) {
+	params := new(PauseParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Pause, contract params deserialize error: %v", err)
+	}
+	if err := checkConsensusOperator(native); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Pause, %v", err)
+	}
+	if params.FromChainID == 0 && params.ToChainID == 0 && len(params.ToContractAddress) == 0 && params.Method == "" {
+		return utils.BYTE_FALSE, fmt.Errorf("Pause, at least one of chain ids, contract and method should be given")
+	}
+	if params.ExpireHeight != 0 && params.ExpireHeight <= native.GetHeight() {
+		return utils.BYTE_FALSE, fmt.Errorf("Pause, expire height %d is not above current height", params.ExpireHeight)
+	}
+
+	pauses, err := getActivePauses(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Pause, %v", err)
+	}
+	//pausing the same route again renews its expire height
+	pauses = removePause(pauses, params)
+	putPauses(native, append(pauses, params))
+	notifyPause(native, PAUSE, params)
+	return utils.BYTE_TRUE, nil
+}
+
+func Unpause(native *native.NativeService) ([]byte, error) {
+	params := new(PauseParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Unpause, contract params deserialize error: %v", err)
+	}
+	if err := checkConsensusOperator(native); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Unpause, %v", err)
+	}
+
+	pauses, err := getActivePauses(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("Unpause, %v", err)
+	}
+	left := removePause(pauses, params)
+	if len(left) == len(pauses) {
+		return utils.BYTE_FALSE, fmt.Errorf("Unpause, route is not paused")
+	}
+	putPauses(native, left)
+	notifyPause(native, UNPAUSE, params)
+	return utils.BYTE_TRUE, nil
+}
+
+// GetPauses returns the serialized PauseList of the pauses not expired
+func GetPauses(native *native.NativeService) ([]byte, error) {
+	pauses, err := getActivePauses(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetPauses, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&PauseList{Pauses: pauses}).Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// CheckIfPaused returns an error when the cross chain tx from fromChainID is paused
+func CheckIfPaused(native *native.NativeService, fromChainID uint64, txParam *scom.MakeTxParam) error {
+	pauses, err := getActivePauses(native)
+	if err != nil {
+		return fmt.Errorf("CheckIfPaused, %v", err)
+	}
+	for _, pause := range pauses {
+		if pause.FromChainID != 0 && pause.FromChainID != fromChainID {
+			continue
+		}
+		if pause.ToChainID != 0 && pause.ToChainID != txParam.ToChainID {
+			continue
+		}
+		if len(pause.ToContractAddress) != 0 && !bytes.Equal(pause.ToContractAddress, txParam.ToContractAddress) {
+			continue
+		}
+		if pause.Method != "" && pause.Method != txParam.Method {
+			continue
+		}
+		return fmt.Errorf("CheckIfPaused, cross chain tx from %d to %d contract %s method %s is paused",
+			fromChainID, txParam.ToChainID, hex.EncodeToString(txParam.ToContractAddress), txParam.Method)
+	}
+	return nil
+}
+
+func checkConsensusOperator(native *native.NativeService) error {
+	operatorAddress, err := node_manager.GetCurConOperator(native)
+	if err != nil {
+		return fmt.Errorf("get current consensus operator address error: %v", err)
+	}
+	if err = utils.ValidateOwner(native, operatorAddress); err != nil {
+		return fmt.Errorf("checkWitness error: %v", err)
+	}
+	return nil
+}
+
+// getActivePauses returns the stored pauses without the expired ones
+func getActivePauses(native *native.NativeService) ([]*PauseParam, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(PAUSED)))
+	if err != nil {
+		return nil, fmt.Errorf("get pauses error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("get pauses, deserialize from raw storage item error: %v", err)
+	}
+	list := new(PauseList)
+	if err := list.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("get pauses, %v", err)
+	}
+	pauses := make([]*PauseParam, 0, len(list.Pauses))
+	for _, pause := range list.Pauses {
+		if pause.ExpireHeight == 0 || native.GetHeight() < pause.ExpireHeight {
+			pauses = append(pauses, pause)
+		}
+	}
+	return pauses, nil
+}
+
+func putPauses(native *native.NativeService, pauses []*PauseParam) {
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(PAUSED))
+	if len(pauses) == 0 {
+		native.GetCacheDB().Delete(key)
+		return
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&PauseList{Pauses: pauses}).Serialization(sink)
+	native.GetCacheDB().Put(key, cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// removePause removes the pause of the same route as params
+func removePause(pauses []*PauseParam, params *PauseParam) []*PauseParam {
+	left := make([]*PauseParam, 0, len(pauses))
+	for _, pause := range pauses {
+		if pause.FromChainID == params.FromChainID && pause.ToChainID == params.ToChainID &&
+			bytes.Equal(pause.ToContractAddress, params.ToContractAddress) && pause.Method == params.Method {
+			continue
+		}
+		left = append(left, pause)
+	}
+	return left
+}
+
+func notifyPause(native *native.NativeService, name string, params *PauseParam) {
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{name, params.FromChainID, params.ToChainID,
+				hex.EncodeToString(params.ToContractAddress), params.Method, params.ExpireHeight},
+		})
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package cross_chain_manager
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPauseTestNative(t *testing.T, db *storage.CacheDB, height uint32) *native.NativeService {
+	service, err := native.NewNativeService(db, new(types.Transaction), 0, height, common.Uint256{}, 0, nil, false)
+	assert.Nil(t, err)
+	return service
+}
+
+func TestCheckIfPaused(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	service := newPauseTestNative(t, db, 100)
+
+	putPauses(service, []*PauseParam{
+		{FromChainID: 2, ToChainID: 6},
+		{ToContractAddress: []byte{1, 2, 3}, Method: "unlock", ExpireHeight: 200},
+	})
+	pauses, err := getActivePauses(service)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(pauses))
+
+	assert.NotNil(t, CheckIfPaused(service, 2, &scom.MakeTxParam{ToChainID: 6, Method: "unlock"}))
+	assert.Nil(t, CheckIfPaused(service, 2, &scom.MakeTxParam{ToChainID: 7, Method: "unlock"}))
+	assert.NotNil(t, CheckIfPaused(service, 3, &scom.MakeTxParam{ToChainID: 7, ToContractAddress: []byte{1, 2, 3}, Method: "unlock"}))
+	assert.Nil(t, CheckIfPaused(service, 3, &scom.MakeTxParam{ToChainID: 7, ToContractAddress: []byte{1, 2, 3}, Method: "lock"}))
+
+	//the pause of contract expires at height 200
+	service = newPauseTestNative(t, db, 200)
+	pauses, err = getActivePauses(service)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pauses))
+	assert.Nil(t, CheckIfPaused(service, 3, &scom.MakeTxParam{ToChainID: 7, ToContractAddress: []byte{1, 2, 3}, Method: "unlock"}))
+
+	putPauses(service, removePause(pauses, &PauseParam{FromChainID: 2, ToChainID: 6, ExpireHeight: 300}))
+	pauses, err = getActivePauses(service)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(pauses))
+	assert.Nil(t, CheckIfPaused(service, 2, &scom.MakeTxParam{ToChainID: 6, Method: "unlock"}))
+}
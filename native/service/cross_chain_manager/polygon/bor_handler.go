@@ -35,6 +35,7 @@ import (
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/polygon"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 // BorHandler ...
@@ -46,6 +47,10 @@ func NewHandler() *BorHandler {
 	return &BorHandler{}
 }
 
+func init() {
+	scom.RegisterChainHandler(utils.POLYGON_BOR_ROUTER, 0, func() scom.ChainHandler { return NewHandler() })
+}
+
 // MakeDepositProposal ...
 func (h *BorHandler) MakeDepositProposal(service *native.NativeService) (*scom.MakeTxParam, error) {
 	params := new(scom.EntranceParam)
@@ -73,15 +78,17 @@ func (h *BorHandler) MakeDepositProposal(service *native.NativeService) (*scom.M
 }
 
 func verifyFromTx(native *native.NativeService, proof, extra []byte, fromChainID uint64, height uint32, sideChain *side_chain_manager.SideChain) (param *scom.MakeTxParam, err error) {
-	cheight, err := polygon.GetCanonicalHeight(native, fromChainID)
-	if err != nil {
-		return
-	}
+	if !side_chain_manager.FinalityPolicyActive(native.GetHeight()) {
+		cheight, err := polygon.GetCanonicalHeight(native, fromChainID)
+		if err != nil {
+			return nil, err
+		}
 
-	cheight32 := uint32(cheight)
+		cheight32 := uint32(cheight)
 
-	if cheight32 < height || cheight32-height < uint32(sideChain.BlocksToWait-1) {
-		return nil, fmt.Errorf("verifyFromTx, transaction is not confirmed, current height: %d, input height: %d", cheight, height)
+		if cheight32 < height || cheight32-height < uint32(sideChain.BlocksToWait-1) {
+			return nil, fmt.Errorf("verifyFromTx, transaction is not confirmed, current height: %d, input height: %d", cheight, height)
+		}
 	}
 
 	headerWithSum, err := polygon.GetCanonicalHeader(native, fromChainID, uint64(height))
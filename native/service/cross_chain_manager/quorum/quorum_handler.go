@@ -26,6 +26,7 @@ import (
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/quorum"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
 type QuorumHandler struct{}
@@ -34,6 +35,10 @@ func NewQuorumHandler() *QuorumHandler {
 	return &QuorumHandler{}
 }
 
+func init() {
+	common.RegisterChainHandler(utils.QUORUM_ROUTER, 0, func() common.ChainHandler { return NewQuorumHandler() })
+}
+
 func (this *QuorumHandler) MakeDepositProposal(ns *native.NativeService) (*common.MakeTxParam, error) {
 	params := new(common.EntranceParam)
 	if err := params.Deserialization(pcom.NewZeroCopySource(ns.GetInput())); err != nil {
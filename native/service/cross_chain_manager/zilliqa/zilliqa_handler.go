@@ -25,6 +25,7 @@ import (
 	"github.com/Zilliqa/gozilliqa-sdk/util"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/polynetwork/poly/native/service/header_sync/zilliqa"
+	"github.com/polynetwork/poly/native/service/utils"
 	"strings"
 
 	"github.com/polynetwork/poly/common"
@@ -42,6 +43,10 @@ func NewHandler() *Handler {
 	return &Handler{}
 }
 
+func init() {
+	scom.RegisterChainHandler(utils.ZILLIQA_ROUTER, 0, func() scom.ChainHandler { return NewHandler() })
+}
+
 // MakeDepositProposal ...
 func (h *Handler) MakeDepositProposal(service *native.NativeService) (*scom.MakeTxParam, error) {
 	params := new(scom.EntranceParam)
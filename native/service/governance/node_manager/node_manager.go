@@ -45,6 +45,10 @@ const (
 	QUIT_NODE            = "quitNode"
 	UPDATE_CONFIG        = "updateConfig"
 	COMMIT_DPOS          = "commitDpos"
+	REPORT_EQUIVOCATION  = "reportEquivocation"
+	GET_PEER_POOL        = "getPeerPool"
+	GET_PROPOSAL         = "getProposal"
+	GET_OPEN_PROPOSALS   = "getOpenProposals"
 
 	//key prefix
 	GOVERNANCE_VIEW = "governanceView"
@@ -55,9 +59,19 @@ const (
 	PEER_INDEX      = "peerIndex"
 	BLACK_LIST      = "blackList"
 	CONSENSUS_SIGNS = "consensusSigns"
+	EQUIVOCATION    = "equivocation"
+	PROPOSAL        = "proposal"
+	PROPOSAL_ID     = "proposalID"
+	PROPOSAL_INDEX  = "proposalIndex"
+	OPEN_PROPOSAL   = "openProposal"
 
 	//const
 	MIN_PEER_NUM = 4
+	//an honest peer signs no two headers of one proposer at a height other than the block
+	//and the empty block of the proposal, two conflicting headers prove the equivocation
+	MAX_EVIDENCE_HEADERS = 2
+	//a proposal not executed within this many blocks expires, and its signs are dropped
+	PROPOSAL_EXPIRE_BLOCKS = 600000
 )
 
 //Register methods of node_manager contract
@@ -71,6 +85,16 @@ func RegisterNodeManagerContract(native *native.NativeService) {
 	native.Register(WHITE_NODE, WhiteNode)
 	native.Register(UPDATE_CONFIG, UpdateConfig)
 	native.Register(COMMIT_DPOS, CommitDpos)
+	if native.GetHeight() >= config.GetActivationHeight(config.FEATURE_EQUIVOCATION_SLASH, config.DefConfig.P2PNode.NetworkId) {
+		native.Register(REPORT_EQUIVOCATION, ReportEquivocation)
+	}
+	if native.GetHeight() >= config.GetActivationHeight(config.FEATURE_GOVERNANCE_QUERY, config.DefConfig.P2PNode.NetworkId) {
+		native.Register(GET_PEER_POOL, GetPeerPool)
+	}
+	if native.GetHeight() >= config.GetActivationHeight(config.FEATURE_GOVERNANCE_PROPOSAL, config.DefConfig.P2PNode.NetworkId) {
+		native.Register(GET_PROPOSAL, GetProposal)
+		native.Register(GET_OPEN_PROPOSALS, GetOpenProposals)
+	}
 }
 
 //Init node_manager contract
@@ -363,7 +387,6 @@ func BlackNode(native *native.NativeService) ([]byte, error) {
 	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("blackNode, contract params deserialize error: %v", err)
 	}
-	contract := utils.NodeManagerContractAddress
 
 	//check witness
 	err := utils.ValidateOwner(native, params.Address)
@@ -417,30 +440,15 @@ func BlackNode(native *native.NativeService) ([]byte, error) {
 
 	commit := false
 	for _, peerPubkey := range params.PeerPubkeyList {
-		peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
-		if err != nil {
-			return utils.BYTE_FALSE, fmt.Errorf("blackNode, peerPubkey format error: %v", err)
-		}
 		peerPoolItem, ok := peerPoolMap.PeerPoolMap[peerPubkey]
 		if !ok {
 			return utils.BYTE_FALSE, fmt.Errorf("blackNode, peerPubkey is not in peerPoolMap")
 		}
-
-		blackListItem := &BlackListItem{
-			PeerPubkey: peerPoolItem.PeerPubkey,
-			Address:    peerPoolItem.Address,
-		}
-		sink := common.NewZeroCopySink(nil)
-		blackListItem.Serialization(sink)
-		//put peer into black list
-		native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix), cstates.GenRawStorageItem(sink.Bytes()))
-
-		//change peerPool status
-		if peerPoolItem.Status == ConsensusStatus {
-			commit = true
+		isConsensus, err := blackPeer(native, peerPoolItem)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("blackNode, %v", err)
 		}
-		peerPoolItem.Status = BlackStatus
-		peerPoolMap.PeerPoolMap[peerPubkey] = peerPoolItem
+		commit = commit || isConsensus
 	}
 	putPeerPoolMap(native, peerPoolMap, view)
 
@@ -647,3 +655,127 @@ func UpdateConfig(native *native.NativeService) ([]byte, error) {
 		})
 	return utils.BYTE_TRUE, nil
 }
+
+//Report equivocation evidence of a peer, used by consensus nodes or anyone holding the evidence.
+//Evidence is recorded on chain and the peer is put into black list if enough peers are left
+func ReportEquivocation(native *native.NativeService) ([]byte, error) {
+	params := new(EquivocationEvidence)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, contract params deserialize error: %v", err)
+	}
+
+	//get current view
+	view, err := GetView(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, get view error: %v", err)
+	}
+	//get peerPoolMap
+	peerPoolMap, err := GetPeerPoolMap(native, view)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, get peerPoolMap error: %v", err)
+	}
+	peerPoolItem, ok := peerPoolMap.PeerPoolMap[params.PeerPubkey]
+	if !ok {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, peerPubkey: %s is not in peerPoolMap", params.PeerPubkey)
+	}
+
+	//check evidence
+	height, err := VerifyEquivocation(params, native.GetChainID(), native.GetBlockHash)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, verify evidence error: %v", err)
+	}
+	if height > native.GetHeight() {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, evidence height %d is above current height", height)
+	}
+	evidence, err := GetEquivocation(native, params.PeerPubkey, height)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, get equivocation error: %v", err)
+	}
+	if evidence != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, equivocation of peer %s at height %d is already reported",
+			params.PeerPubkey, height)
+	}
+	if err := putEquivocation(native, params, height); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, put equivocation error: %v", err)
+	}
+
+	//black the peer if it is still active and enough peers are left
+	num := 0
+	for _, item := range peerPoolMap.PeerPoolMap {
+		if item.Status == CandidateStatus || item.Status == ConsensusStatus {
+			num = num + 1
+		}
+	}
+	blacked := false
+	if (peerPoolItem.Status == CandidateStatus || peerPoolItem.Status == ConsensusStatus) && num > MIN_PEER_NUM {
+		commit, err := blackPeer(native, peerPoolItem)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, %v", err)
+		}
+		putPeerPoolMap(native, peerPoolMap, view)
+		if commit {
+			err = executeCommitDpos(native)
+			if err != nil {
+				return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, executeCommitDpos error: %v", err)
+			}
+		}
+		blacked = true
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"reportEquivocation", params.PeerPubkey, height, blacked},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//Get peer pool of a governance view, current view is used if view is 0
+func GetPeerPool(native *native.NativeService) ([]byte, error) {
+	params := new(ViewParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getPeerPool, contract params deserialize error: %v", err)
+	}
+	view := params.View
+	if view == 0 {
+		var err error
+		view, err = GetView(native)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("getPeerPool, get view error: %v", err)
+		}
+	}
+	peerPoolMap, err := GetPeerPoolMap(native, view)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getPeerPool, get peerPoolMap error: %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	peerPoolMap.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+func GetProposal(native *native.NativeService) ([]byte, error) {
+	params := new(ProposalIDParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getProposal, contract params deserialize error: %v", err)
+	}
+	proposal, err := GetProposalByID(native, params.ID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getProposal, %v", err)
+	}
+	if proposal == nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getProposal, proposal %d not found", params.ID)
+	}
+	markProposalExpired(native, proposal)
+	sink := common.NewZeroCopySink(nil)
+	proposal.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+func GetOpenProposals(native *native.NativeService) ([]byte, error) {
+	proposals, err := listOpenProposals(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getOpenProposals, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&ProposalList{Proposals: proposals}).Serialization(sink)
+	return sink.Bytes(), nil
+}
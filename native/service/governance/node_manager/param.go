@@ -19,7 +19,9 @@ package node_manager
 
 import (
 	"fmt"
+
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/types"
 )
 
 type RegisterPeerParam struct {
@@ -137,3 +139,105 @@ func (this *UpdateConfigParam) Deserialization(source *common.ZeroCopySource) er
 	this.Configuration = configuration
 	return nil
 }
+
+type ViewParam struct {
+	View uint32
+}
+
+func (this *ViewParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.View)
+}
+
+func (this *ViewParam) Deserialization(source *common.ZeroCopySource) error {
+	view, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize view error")
+	}
+	this.View = view
+	return nil
+}
+
+type ProposalIDParam struct {
+	ID uint64
+}
+
+func (this *ProposalIDParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ID)
+}
+
+func (this *ProposalIDParam) Deserialization(source *common.ZeroCopySource) error {
+	id, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("source.NextUint64, deserialize id error")
+	}
+	this.ID = id
+	return nil
+}
+
+type SignedHeader struct {
+	Header *types.Header
+	Sig    []byte //signature of the reported peer on header hash
+}
+
+func (this *SignedHeader) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Header.ToArray())
+	sink.WriteVarBytes(this.Sig)
+}
+
+func (this *SignedHeader) Deserialization(source *common.ZeroCopySource) error {
+	raw, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize header error")
+	}
+	header, err := types.HeaderFromRawBytes(raw)
+	if err != nil {
+		return fmt.Errorf("types.HeaderFromRawBytes, deserialize header error: %s", err)
+	}
+	sig, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize sig error")
+	}
+
+	this.Header = header
+	this.Sig = sig
+	return nil
+}
+
+type EquivocationEvidence struct {
+	PeerPubkey string
+	Headers    []*SignedHeader //headers of the same height signed by the peer
+}
+
+func (this *EquivocationEvidence) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.PeerPubkey)
+	sink.WriteVarUint(uint64(len(this.Headers)))
+	for _, v := range this.Headers {
+		v.Serialization(sink)
+	}
+}
+
+func (this *EquivocationEvidence) Deserialization(source *common.ZeroCopySource) error {
+	peerPubkey, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("source.NextString, deserialize peerPubkey error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize Headers length error")
+	}
+	if n > MAX_EVIDENCE_HEADERS {
+		return fmt.Errorf("deserialize Headers error: too many headers %d", n)
+	}
+	headers := make([]*SignedHeader, 0, n)
+	for i := 0; uint64(i) < n; i++ {
+		header := new(SignedHeader)
+		if err := header.Deserialization(source); err != nil {
+			return fmt.Errorf("deserialize Headers error: %v", err)
+		}
+		headers = append(headers, header)
+	}
+
+	this.PeerPubkey = peerPubkey
+	this.Headers = headers
+	return nil
+}
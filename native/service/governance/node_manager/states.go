@@ -261,3 +261,142 @@ func (this *Configuration) Deserialization(source *common.ZeroCopySource) error
 	this.MaxBlockChangeView = maxBlockChangeView
 	return nil
 }
+
+type ProposalStatus uint8
+
+const (
+	ProposalOpen ProposalStatus = iota
+	ProposalExecuted
+	ProposalExpired
+)
+
+// Proposal tracks the consensus signs collected for a governance action,
+// which is identified by the approve method and its input
+type Proposal struct {
+	ID           uint64
+	Proposer     common.Address //the signer opening the proposal
+	Method       string
+	Input        []byte
+	CreateHeight uint32
+	ExpireHeight uint32
+	Signers      []common.Address //in signing order, signs taken over from before proposals come first
+	Status       ProposalStatus
+	EndHeight    uint32 //height the proposal is executed or found expired
+}
+
+func (this *Proposal) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ID)
+	sink.WriteVarBytes(this.Proposer[:])
+	sink.WriteString(this.Method)
+	sink.WriteVarBytes(this.Input)
+	sink.WriteUint32(this.CreateHeight)
+	sink.WriteUint32(this.ExpireHeight)
+	sink.WriteVarUint(uint64(len(this.Signers)))
+	for _, v := range this.Signers {
+		sink.WriteVarBytes(v[:])
+	}
+	sink.WriteUint8(uint8(this.Status))
+	sink.WriteUint32(this.EndHeight)
+}
+
+func (this *Proposal) Deserialization(source *common.ZeroCopySource) error {
+	id, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("source.NextUint64, deserialize id error")
+	}
+	proposer, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize proposer error")
+	}
+	proposerAddr, err := common.AddressParseFromBytes(proposer)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize proposer error: %v", err)
+	}
+	method, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("source.NextString, deserialize method error")
+	}
+	input, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize input error")
+	}
+	createHeight, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize createHeight error")
+	}
+	expireHeight, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize expireHeight error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize length of signers error")
+	}
+	signers := make([]common.Address, 0)
+	for i := 0; uint64(i) < n; i++ {
+		signer, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("source.NextVarBytes, deserialize signer error")
+		}
+		addr, err := common.AddressParseFromBytes(signer)
+		if err != nil {
+			return fmt.Errorf("common.AddressParseFromBytes, deserialize signer error: %v", err)
+		}
+		signers = append(signers, addr)
+	}
+	status, eof := source.NextUint8()
+	if eof {
+		return fmt.Errorf("source.NextUint8, deserialize status error")
+	}
+	endHeight, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize endHeight error")
+	}
+	this.ID = id
+	this.Proposer = proposerAddr
+	this.Method = method
+	this.Input = input
+	this.CreateHeight = createHeight
+	this.ExpireHeight = expireHeight
+	this.Signers = signers
+	this.Status = ProposalStatus(status)
+	this.EndHeight = endHeight
+	return nil
+}
+
+func (this *Proposal) hasSigned(address common.Address) bool {
+	for _, v := range this.Signers {
+		if v == address {
+			return true
+		}
+	}
+	return false
+}
+
+type ProposalList struct {
+	Proposals []*Proposal
+}
+
+func (this *ProposalList) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Proposals)))
+	for _, v := range this.Proposals {
+		v.Serialization(sink)
+	}
+}
+
+func (this *ProposalList) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize length of proposals error")
+	}
+	proposals := make([]*Proposal, 0)
+	for i := 0; uint64(i) < n; i++ {
+		proposal := new(Proposal)
+		if err := proposal.Deserialization(source); err != nil {
+			return fmt.Errorf("deserialize proposal error: %v", err)
+		}
+		proposals = append(proposals, proposal)
+	}
+	this.Proposals = proposals
+	return nil
+}
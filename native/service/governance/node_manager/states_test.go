@@ -38,3 +38,24 @@ func Test_Deserialize_GovernanceView(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, *govView, *govView1)
 }
+
+func TestProposal_Serialization(t *testing.T) {
+	proposal := &Proposal{
+		ID:           3,
+		Proposer:     common.Address{1},
+		Method:       APPROVE_CANDIDATE,
+		Input:        []byte{1, 2},
+		CreateHeight: 10,
+		ExpireHeight: 10 + PROPOSAL_EXPIRE_BLOCKS,
+		Signers:      []common.Address{{1}, {2}},
+		Status:       ProposalExecuted,
+		EndHeight:    12,
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&ProposalList{Proposals: []*Proposal{proposal}}).Serialization(sink)
+
+	list := new(ProposalList)
+	err := list.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, []*Proposal{proposal}, list.Proposals)
+}
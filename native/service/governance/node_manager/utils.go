@@ -22,11 +22,15 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"sort"
+
 	"github.com/polynetwork/poly/native/event"
 
 	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology-crypto/signature"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
 	cstates "github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native"
@@ -271,6 +275,9 @@ func deleteConsensusSigns(native *native.NativeService, key common.Uint256) {
 func CheckConsensusSigns(native *native.NativeService, method string, input []byte, address common.Address) (bool, error) {
 	message := append([]byte(method), input...)
 	key := sha256.Sum256(message)
+	if native.GetHeight() >= config.GetActivationHeight(config.FEATURE_GOVERNANCE_PROPOSAL, config.DefConfig.P2PNode.NetworkId) {
+		return checkProposalSigns(native, method, input, key, address)
+	}
 	consensusSigns, err := getConsensusSigns(native, key)
 	if err != nil {
 		return false, fmt.Errorf("CheckConsensusSigns, GetConsensusSigns error: %v", err)
@@ -281,16 +288,33 @@ func CheckConsensusSigns(native *native.NativeService, method string, input []by
 			ContractAddress: utils.NodeManagerContractAddress,
 			States:          []interface{}{"CheckConsensusSigns", len(consensusSigns.SignsMap)},
 		})
-	//check signs num
+	num, sum, err := countConsensusSigns(native, func(signer common.Address) bool {
+		_, ok := consensusSigns.SignsMap[signer]
+		return ok
+	})
+	if err != nil {
+		return false, fmt.Errorf("CheckConsensusSigns, %v", err)
+	}
+	if num >= (2*sum+2)/3 {
+		deleteConsensusSigns(native, key)
+		return true, nil
+	} else {
+		putConsensusSigns(native, key, consensusSigns)
+		return false, nil
+	}
+}
+
+// countConsensusSigns returns how many of the current consensus peers have signed, and the number of them
+func countConsensusSigns(native *native.NativeService, signed func(common.Address) bool) (int, int, error) {
 	//get view
 	view, err := GetView(native)
 	if err != nil {
-		return false, fmt.Errorf("CheckConsensusSigns, GetView error: %v", err)
+		return 0, 0, fmt.Errorf("countConsensusSigns, GetView error: %v", err)
 	}
 	//get consensus peer
 	peerPoolMap, err := GetPeerPoolMap(native, view)
 	if err != nil {
-		return false, fmt.Errorf("CheckConsensusSigns, GetPeerPoolMap error: %v", err)
+		return 0, 0, fmt.Errorf("countConsensusSigns, GetPeerPoolMap error: %v", err)
 	}
 	num := 0
 	sum := 0
@@ -298,26 +322,231 @@ func CheckConsensusSigns(native *native.NativeService, method string, input []by
 		if v.Status == ConsensusStatus {
 			k, err := hex.DecodeString(key)
 			if err != nil {
-				return false, fmt.Errorf("CheckConsensusSigns, hex.DecodeString public key error: %v", err)
+				return 0, 0, fmt.Errorf("countConsensusSigns, hex.DecodeString public key error: %v", err)
 			}
 			publicKey, err := keypair.DeserializePublicKey(k)
 			if err != nil {
-				return false, fmt.Errorf("CheckConsensusSigns, keypair.DeserializePublicKey error: %v", err)
+				return 0, 0, fmt.Errorf("countConsensusSigns, keypair.DeserializePublicKey error: %v", err)
 			}
-			_, ok := consensusSigns.SignsMap[types.AddressFromPubKey(publicKey)]
-			if ok {
+			if signed(types.AddressFromPubKey(publicKey)) {
 				num = num + 1
 			}
 			sum = sum + 1
 		}
 	}
-	if num >= (2*sum+2)/3 {
-		deleteConsensusSigns(native, key)
+	return num, sum, nil
+}
+
+// checkProposalSigns records the sign of address on the proposal of method and input, opening a new
+// proposal if there is none or the last one expired, and reports whether the proposal is executed
+func checkProposalSigns(native *native.NativeService, method string, input []byte, key common.Uint256,
+	address common.Address) (bool, error) {
+	height := native.GetHeight()
+	proposal, err := getProposalByKey(native, key)
+	if err != nil {
+		return false, fmt.Errorf("checkProposalSigns, %v", err)
+	}
+	if proposal != nil && height > proposal.ExpireHeight {
+		proposal.Status = ProposalExpired
+		proposal.EndHeight = height
+		closeProposal(native, proposal, key)
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.NodeManagerContractAddress,
+				States:          []interface{}{"proposalExpired", proposal.ID, proposal.Method},
+			})
+		proposal = nil
+	}
+	if proposal == nil {
+		proposal, err = openProposal(native, method, input, key, address)
+		if err != nil {
+			return false, fmt.Errorf("checkProposalSigns, %v", err)
+		}
+	}
+	if !proposal.hasSigned(address) {
+		proposal.Signers = append(proposal.Signers, address)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"CheckConsensusSigns", len(proposal.Signers)},
+		})
+	num, sum, err := countConsensusSigns(native, proposal.hasSigned)
+	if err != nil {
+		return false, fmt.Errorf("checkProposalSigns, %v", err)
+	}
+	required := (2*sum + 2) / 3
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"proposalVote", proposal.ID, proposal.Method, address.ToHexString(), num, required},
+		})
+	if num >= required {
+		proposal.Status = ProposalExecuted
+		proposal.EndHeight = height
+		closeProposal(native, proposal, key)
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.NodeManagerContractAddress,
+				States:          []interface{}{"proposalExecuted", proposal.ID, proposal.Method},
+			})
 		return true, nil
-	} else {
-		putConsensusSigns(native, key, consensusSigns)
-		return false, nil
 	}
+	putProposal(native, proposal)
+	return false, nil
+}
+
+// openProposal creates a proposal for method and input opened by proposer, taking over the signs
+// collected before proposals in address order, as their signing order is not kept
+func openProposal(native *native.NativeService, method string, input []byte, key common.Uint256,
+	proposer common.Address) (*Proposal, error) {
+	id, err := getProposalID(native)
+	if err != nil {
+		return nil, fmt.Errorf("openProposal, %v", err)
+	}
+	putProposalID(native, id+1)
+	height := native.GetHeight()
+	proposal := &Proposal{
+		ID:           id,
+		Proposer:     proposer,
+		Method:       method,
+		Input:        input,
+		CreateHeight: height,
+		ExpireHeight: height + PROPOSAL_EXPIRE_BLOCKS,
+		Signers:      make([]common.Address, 0),
+		Status:       ProposalOpen,
+	}
+	consensusSigns, err := getConsensusSigns(native, key)
+	if err != nil {
+		return nil, fmt.Errorf("openProposal, getConsensusSigns error: %v", err)
+	}
+	if len(consensusSigns.SignsMap) != 0 {
+		for signer := range consensusSigns.SignsMap {
+			proposal.Signers = append(proposal.Signers, signer)
+		}
+		sort.Slice(proposal.Signers, func(i, j int) bool {
+			return bytes.Compare(proposal.Signers[i][:], proposal.Signers[j][:]) < 0
+		})
+		deleteConsensusSigns(native, key)
+	}
+	contract := utils.NodeManagerContractAddress
+	idBytes := utils.GetUint64Bytes(id)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(PROPOSAL_INDEX), key[:]), cstates.GenRawStorageItem(idBytes))
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(OPEN_PROPOSAL), idBytes), cstates.GenRawStorageItem(idBytes))
+	return proposal, nil
+}
+
+// closeProposal stores the executed or expired proposal for audit, and frees its method and input
+// for a new proposal
+func closeProposal(native *native.NativeService, proposal *Proposal, key common.Uint256) {
+	contract := utils.NodeManagerContractAddress
+	putProposal(native, proposal)
+	native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(PROPOSAL_INDEX), key[:]))
+	native.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(OPEN_PROPOSAL), utils.GetUint64Bytes(proposal.ID)))
+}
+
+func getProposalID(native *native.NativeService) (uint64, error) {
+	contract := utils.NodeManagerContractAddress
+	proposalIDStore, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(PROPOSAL_ID)))
+	if err != nil {
+		return 0, fmt.Errorf("getProposalID, get proposalIDStore error: %v", err)
+	}
+	var proposalID uint64 = 0
+	if proposalIDStore != nil {
+		proposalIDBytes, err := cstates.GetValueFromRawStorageItem(proposalIDStore)
+		if err != nil {
+			return 0, fmt.Errorf("getProposalID, deserialize from raw storage item err:%v", err)
+		}
+		proposalID = utils.GetBytesUint64(proposalIDBytes)
+	}
+	return proposalID, nil
+}
+
+func putProposalID(native *native.NativeService, proposalID uint64) {
+	contract := utils.NodeManagerContractAddress
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(PROPOSAL_ID)), cstates.GenRawStorageItem(utils.GetUint64Bytes(proposalID)))
+}
+
+func getProposalByKey(native *native.NativeService, key common.Uint256) (*Proposal, error) {
+	contract := utils.NodeManagerContractAddress
+	idStore, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(PROPOSAL_INDEX), key[:]))
+	if err != nil {
+		return nil, fmt.Errorf("getProposalByKey, get proposal index error: %v", err)
+	}
+	if idStore == nil {
+		return nil, nil
+	}
+	idBytes, err := cstates.GetValueFromRawStorageItem(idStore)
+	if err != nil {
+		return nil, fmt.Errorf("getProposalByKey, deserialize from raw storage item err:%v", err)
+	}
+	return GetProposalByID(native, utils.GetBytesUint64(idBytes))
+}
+
+func GetProposalByID(native *native.NativeService, id uint64) (*Proposal, error) {
+	contract := utils.NodeManagerContractAddress
+	proposalStore, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(PROPOSAL), utils.GetUint64Bytes(id)))
+	if err != nil {
+		return nil, fmt.Errorf("GetProposalByID, get proposalStore error: %v", err)
+	}
+	if proposalStore == nil {
+		return nil, nil
+	}
+	proposalBytes, err := cstates.GetValueFromRawStorageItem(proposalStore)
+	if err != nil {
+		return nil, fmt.Errorf("GetProposalByID, deserialize from raw storage item err:%v", err)
+	}
+	proposal := new(Proposal)
+	if err := proposal.Deserialization(common.NewZeroCopySource(proposalBytes)); err != nil {
+		return nil, fmt.Errorf("GetProposalByID, deserialize proposal error: %v", err)
+	}
+	return proposal, nil
+}
+
+func putProposal(native *native.NativeService, proposal *Proposal) {
+	contract := utils.NodeManagerContractAddress
+	sink := common.NewZeroCopySink(nil)
+	proposal.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(PROPOSAL), utils.GetUint64Bytes(proposal.ID)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// markProposalExpired reports an open proposal past its expire height as expired, the expiry
+// is only stored when the next sign on the same action comes
+func markProposalExpired(native *native.NativeService, proposal *Proposal) {
+	if proposal.Status == ProposalOpen && native.GetHeight() > proposal.ExpireHeight {
+		proposal.Status = ProposalExpired
+	}
+}
+
+// listOpenProposals returns the proposals waiting for signs, sorted by id
+func listOpenProposals(native *native.NativeService) ([]*Proposal, error) {
+	key := utils.ConcatKey(utils.NodeManagerContractAddress, []byte(OPEN_PROPOSAL))
+	iter := native.GetCacheDB().NewIterator(key)
+	defer iter.Release()
+
+	proposals := make([]*Proposal, 0)
+	for iter.Next() {
+		proposal, err := GetProposalByID(native, utils.GetBytesUint64(iter.Key()[len(key):]))
+		if err != nil {
+			return nil, fmt.Errorf("listOpenProposals, %v", err)
+		}
+		if proposal == nil {
+			return nil, fmt.Errorf("listOpenProposals, proposal of open index not found")
+		}
+		markProposalExpired(native, proposal)
+		if proposal.Status != ProposalOpen {
+			continue
+		}
+		proposals = append(proposals, proposal)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("listOpenProposals, iterate open proposals error: %v", err)
+	}
+	sort.Slice(proposals, func(i, j int) bool {
+		return proposals[i].ID < proposals[j].ID
+	})
+	return proposals, nil
 }
 
 // Get current epoch operator derived from current epoch consensus book keepers' public keys
@@ -354,3 +583,144 @@ func GetCurConOperator(native *native.NativeService) (common.Address, error) {
 	}
 	return operator, nil
 }
+
+// blackPeer puts the peer into black list and changes its status in pool,
+// returns true if the peer was a consensus peer
+func blackPeer(native *native.NativeService, peerPoolItem *PeerPoolItem) (bool, error) {
+	contract := utils.NodeManagerContractAddress
+	peerPubkeyPrefix, err := hex.DecodeString(peerPoolItem.PeerPubkey)
+	if err != nil {
+		return false, fmt.Errorf("blackPeer, peerPubkey format error: %v", err)
+	}
+
+	blackListItem := &BlackListItem{
+		PeerPubkey: peerPoolItem.PeerPubkey,
+		Address:    peerPoolItem.Address,
+	}
+	sink := common.NewZeroCopySink(nil)
+	blackListItem.Serialization(sink)
+	//put peer into black list
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix), cstates.GenRawStorageItem(sink.Bytes()))
+
+	//change peerPool status
+	isConsensus := peerPoolItem.Status == ConsensusStatus
+	peerPoolItem.Status = BlackStatus
+	return isConsensus, nil
+}
+
+// VerifyEquivocation checks the evidence proves the peer of PeerPubkey signed conflicting headers of one
+// proposer at a height, the headers must be of chainID and follow the block returned by getBlockHash,
+// returns the height of the headers. Headers of different proposers are never conflicting, an honest
+// peer signs the proposals of several proposers as the rounds of a height time out
+func VerifyEquivocation(evidence *EquivocationEvidence, chainID uint64,
+	getBlockHash func(height uint32) common.Uint256) (uint32, error) {
+	if len(evidence.Headers) < 2 || len(evidence.Headers) > MAX_EVIDENCE_HEADERS {
+		return 0, fmt.Errorf("VerifyEquivocation, invalid headers count: %d", len(evidence.Headers))
+	}
+	k, err := hex.DecodeString(evidence.PeerPubkey)
+	if err != nil {
+		return 0, fmt.Errorf("VerifyEquivocation, peerPubkey format error: %v", err)
+	}
+	pk, err := keypair.DeserializePublicKey(k)
+	if err != nil {
+		return 0, fmt.Errorf("VerifyEquivocation, keypair.DeserializePublicKey error: %v", err)
+	}
+
+	height := evidence.Headers[0].Header.Height
+	if height == 0 {
+		return 0, fmt.Errorf("VerifyEquivocation, headers of genesis block")
+	}
+	prevBlockHash := getBlockHash(height - 1)
+	if prevBlockHash == common.UINT256_EMPTY {
+		return 0, fmt.Errorf("VerifyEquivocation, block %d not found", height-1)
+	}
+	hashes := make(map[common.Uint256]bool)
+	var proposer uint32
+	for i, v := range evidence.Headers {
+		if v.Header.Height != height {
+			return 0, fmt.Errorf("VerifyEquivocation, headers of different heights: %d, %d", height, v.Header.Height)
+		}
+		if v.Header.ChainID != chainID {
+			return 0, fmt.Errorf("VerifyEquivocation, header of chain %d, expected %d", v.Header.ChainID, chainID)
+		}
+		if v.Header.PrevBlockHash != prevBlockHash {
+			return 0, fmt.Errorf("VerifyEquivocation, header following %s is not on chain",
+				v.Header.PrevBlockHash.ToHexString())
+		}
+		hash := v.Header.Hash()
+		if hashes[hash] {
+			return 0, fmt.Errorf("VerifyEquivocation, duplicated header %s", hash.ToHexString())
+		}
+		hashes[hash] = true
+		sig, err := signature.Deserialize(v.Sig)
+		if err != nil {
+			return 0, fmt.Errorf("VerifyEquivocation, deserialize sig of header %s error: %v", hash.ToHexString(), err)
+		}
+		if !signature.Verify(pk, hash[:], sig) {
+			return 0, fmt.Errorf("VerifyEquivocation, failed to verify sig of header %s", hash.ToHexString())
+		}
+		blkInfo, err := vconfig.VbftBlock(v.Header)
+		if err != nil {
+			return 0, fmt.Errorf("VerifyEquivocation, header %s: %v", hash.ToHexString(), err)
+		}
+		if i == 0 {
+			proposer = blkInfo.Proposer
+		} else if blkInfo.Proposer != proposer {
+			return 0, fmt.Errorf("VerifyEquivocation, headers of different proposers: %d, %d", proposer, blkInfo.Proposer)
+		}
+	}
+
+	//block and empty block of one proposal only differ in transactions
+	headers := evidence.Headers
+	for i := 0; i < len(headers); i++ {
+		for j := i + 1; j < len(headers); j++ {
+			if !isSiblingHeader(headers[i].Header, headers[j].Header) {
+				return height, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("VerifyEquivocation, no equivocation found in %d headers", len(evidence.Headers))
+}
+
+func isSiblingHeader(a, b *types.Header) bool {
+	return a.PrevBlockHash == b.PrevBlockHash && a.Timestamp == b.Timestamp &&
+		bytes.Equal(a.ConsensusPayload, b.ConsensusPayload)
+}
+
+func GetEquivocation(native *native.NativeService, peerPubkey string, height uint32) (*EquivocationEvidence, error) {
+	contract := utils.NodeManagerContractAddress
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("GetEquivocation, peerPubkey format error: %v", err)
+	}
+	evidenceBytes, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(EQUIVOCATION), peerPubkeyPrefix,
+		utils.GetUint32Bytes(height)))
+	if err != nil {
+		return nil, fmt.Errorf("GetEquivocation, get evidence error: %v", err)
+	}
+	if evidenceBytes == nil {
+		return nil, nil
+	}
+	evidenceStore, err := cstates.GetValueFromRawStorageItem(evidenceBytes)
+	if err != nil {
+		return nil, fmt.Errorf("GetEquivocation, deserialize from raw storage item err:%v", err)
+	}
+	evidence := new(EquivocationEvidence)
+	if err := evidence.Deserialization(common.NewZeroCopySource(evidenceStore)); err != nil {
+		return nil, fmt.Errorf("GetEquivocation, deserialize evidence error: %v", err)
+	}
+	return evidence, nil
+}
+
+func putEquivocation(native *native.NativeService, evidence *EquivocationEvidence, height uint32) error {
+	contract := utils.NodeManagerContractAddress
+	peerPubkeyPrefix, err := hex.DecodeString(evidence.PeerPubkey)
+	if err != nil {
+		return fmt.Errorf("putEquivocation, peerPubkey format error: %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	evidence.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(EQUIVOCATION), peerPubkeyPrefix, utils.GetUint32Bytes(height)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	return nil
+}
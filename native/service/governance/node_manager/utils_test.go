@@ -0,0 +1,242 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node_manager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testChainID       uint64 = 3
+	testPrevBlockHash        = common.Uint256{9}
+)
+
+func testBlockHash(height uint32) common.Uint256 {
+	if height == 99 {
+		return testPrevBlockHash
+	}
+	return common.UINT256_EMPTY
+}
+
+func newSignedHeader(t *testing.T, acct *account.Account, proposer, timestamp uint32, txRoot common.Uint256) *SignedHeader {
+	payload, err := json.Marshal(&vconfig.VbftBlockInfo{Proposer: proposer})
+	assert.Nil(t, err)
+	header := &types.Header{
+		ChainID:          testChainID,
+		PrevBlockHash:    testPrevBlockHash,
+		Height:           100,
+		Timestamp:        timestamp,
+		TransactionsRoot: txRoot,
+		ConsensusPayload: payload,
+	}
+	hash := header.Hash()
+	sig, err := signature.Sign(acct, hash[:])
+	assert.Nil(t, err)
+	return &SignedHeader{Header: header, Sig: sig}
+}
+
+func TestVerifyEquivocation(t *testing.T) {
+	acct := account.NewAccount("")
+	pubkey := hex.EncodeToString(keypair.SerializePublicKey(acct.PublicKey))
+	var index uint32 = 1
+
+	// block and empty block of one proposal
+	evidence := &EquivocationEvidence{
+		PeerPubkey: pubkey,
+		Headers: []*SignedHeader{
+			newSignedHeader(t, acct, index, 10, common.Uint256{1}),
+			newSignedHeader(t, acct, index, 10, common.Uint256{2}),
+		},
+	}
+	_, err := VerifyEquivocation(evidence, testChainID, testBlockHash)
+	assert.NotNil(t, err)
+
+	// two proposals of one height
+	evidence.Headers[1] = newSignedHeader(t, acct, index, 11, common.Uint256{2})
+	height, err := VerifyEquivocation(evidence, testChainID, testBlockHash)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(100), height)
+
+	// endorsements of proposals of several proposers as rounds time out
+	evidence.Headers = []*SignedHeader{
+		newSignedHeader(t, acct, 2, 10, common.Uint256{1}),
+		newSignedHeader(t, acct, 3, 11, common.Uint256{2}),
+	}
+	_, err = VerifyEquivocation(evidence, testChainID, testBlockHash)
+	assert.NotNil(t, err)
+
+	// endorsements of block and empty block of another proposer
+	evidence.Headers = []*SignedHeader{
+		newSignedHeader(t, acct, 2, 10, common.Uint256{1}),
+		newSignedHeader(t, acct, 2, 10, common.Uint256{2}),
+	}
+	_, err = VerifyEquivocation(evidence, testChainID, testBlockHash)
+	assert.NotNil(t, err)
+
+	// endorsements of two proposals of another proposer
+	evidence.Headers = []*SignedHeader{
+		newSignedHeader(t, acct, 3, 11, common.Uint256{3}),
+		newSignedHeader(t, acct, 3, 12, common.Uint256{4}),
+	}
+	_, err = VerifyEquivocation(evidence, testChainID, testBlockHash)
+	assert.Nil(t, err)
+
+	// too many headers
+	_, err = VerifyEquivocation(&EquivocationEvidence{
+		PeerPubkey: pubkey,
+		Headers: append(evidence.Headers[:2:2],
+			newSignedHeader(t, acct, 3, 13, common.Uint256{5})),
+	}, testChainID, testBlockHash)
+	assert.NotNil(t, err)
+
+	// round trip
+	sink := common.NewZeroCopySink(nil)
+	evidence.Serialization(sink)
+	evidence1 := new(EquivocationEvidence)
+	assert.Nil(t, evidence1.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	_, err = VerifyEquivocation(evidence1, testChainID, testBlockHash)
+	assert.Nil(t, err)
+
+	// headers of another chain
+	_, err = VerifyEquivocation(evidence, testChainID+1, testBlockHash)
+	assert.NotNil(t, err)
+
+	// headers not following the block on chain
+	_, err = VerifyEquivocation(evidence, testChainID, func(height uint32) common.Uint256 {
+		return common.Uint256{8}
+	})
+	assert.NotNil(t, err)
+	_, err = VerifyEquivocation(evidence, testChainID, func(height uint32) common.Uint256 {
+		return common.UINT256_EMPTY
+	})
+	assert.NotNil(t, err)
+
+	// signed by another peer
+	evidence.PeerPubkey = hex.EncodeToString(keypair.SerializePublicKey(account.NewAccount("").PublicKey))
+	_, err = VerifyEquivocation(evidence, testChainID, testBlockHash)
+	assert.NotNil(t, err)
+}
+
+func newProposalNative(t *testing.T, db *storage.CacheDB, height uint32) *native.NativeService {
+	ns, err := native.NewNativeService(db, new(types.Transaction), 0, height, common.Uint256{}, 0, nil, false)
+	assert.Nil(t, err)
+	return ns
+}
+
+func TestCheckProposalSigns(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	accts := make([]*account.Account, 0)
+	peerPoolMap := &PeerPoolMap{PeerPoolMap: make(map[string]*PeerPoolItem)}
+	for i := 0; i < 4; i++ {
+		acct := account.NewAccount("")
+		accts = append(accts, acct)
+		pkStr := vconfig.PubkeyID(acct.PublicKey)
+		peerPoolMap.PeerPoolMap[pkStr] = &PeerPoolItem{
+			Index:      uint32(i),
+			PeerPubkey: pkStr,
+			Address:    acct.Address,
+			Status:     ConsensusStatus,
+		}
+	}
+	ns := newProposalNative(t, db, 10)
+	putPeerPoolMap(ns, peerPoolMap, 0)
+	sink := common.NewZeroCopySink(nil)
+	(&GovernanceView{View: 0, Height: 10}).Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(GOVERNANCE_VIEW)), cstates.GenRawStorageItem(sink.Bytes()))
+
+	input := []byte{1}
+	key := common.Uint256(sha256.Sum256(append([]byte(APPROVE_CANDIDATE), input...)))
+	for i := 0; i < 2; i++ {
+		ok, err := checkProposalSigns(ns, APPROVE_CANDIDATE, input, key, accts[i].Address)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+	}
+	// signing twice is not counted
+	ok, err := checkProposalSigns(ns, APPROVE_CANDIDATE, input, key, accts[1].Address)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	proposals, err := listOpenProposals(ns)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(proposals))
+	assert.Equal(t, uint64(0), proposals[0].ID)
+	assert.Equal(t, accts[0].Address, proposals[0].Proposer)
+	assert.Equal(t, []common.Address{accts[0].Address, accts[1].Address}, proposals[0].Signers)
+	assert.Equal(t, uint32(10+PROPOSAL_EXPIRE_BLOCKS), proposals[0].ExpireHeight)
+
+	ok, err = checkProposalSigns(ns, APPROVE_CANDIDATE, input, key, accts[2].Address)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	proposals, err = listOpenProposals(ns)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(proposals))
+	proposal, err := GetProposalByID(ns, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, ProposalExecuted, proposal.Status)
+	assert.Equal(t, uint32(10), proposal.EndHeight)
+
+	// an expired proposal is replaced by a new one on the next sign
+	ok, err = checkProposalSigns(ns, APPROVE_CANDIDATE, input, key, accts[0].Address)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	ns = newProposalNative(t, db, 11+PROPOSAL_EXPIRE_BLOCKS)
+	proposals, err = listOpenProposals(ns)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(proposals))
+	ok, err = checkProposalSigns(ns, APPROVE_CANDIDATE, input, key, accts[1].Address)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	proposal, err = GetProposalByID(ns, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, ProposalExpired, proposal.Status)
+	proposals, err = listOpenProposals(ns)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(proposals))
+	assert.Equal(t, uint64(2), proposals[0].ID)
+	assert.Equal(t, []common.Address{accts[1].Address}, proposals[0].Signers)
+
+	// signs collected before proposals are taken over by the signer opening the proposal
+	input = []byte{2}
+	key = common.Uint256(sha256.Sum256(append([]byte(APPROVE_CANDIDATE), input...)))
+	putConsensusSigns(ns, key, &ConsensusSigns{SignsMap: map[common.Address]bool{accts[0].Address: true}})
+	ok, err = checkProposalSigns(ns, APPROVE_CANDIDATE, input, key, accts[1].Address)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	proposal, err = GetProposalByID(ns, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, accts[1].Address, proposal.Proposer)
+	assert.Equal(t, []common.Address{accts[0].Address, accts[1].Address}, proposal.Signers)
+}
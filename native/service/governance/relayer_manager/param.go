@@ -94,3 +94,171 @@ func (this *ApproveRelayerParam) Deserialization(source *common.ZeroCopySource)
 	this.Address = addr
 	return nil
 }
+
+// RelayerQuotaParam sets the quota of Relayer, the empty address sets the default
+// quota of relayers without their own. A quota of all zeros removes the limit
+type RelayerQuotaParam struct {
+	Relayer common.Address
+	Quota   RelayerQuota
+	Address common.Address
+}
+
+// serializeProposal writes the fields approved by consensus, all but the signer
+func (this *RelayerQuotaParam) serializeProposal(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Relayer[:])
+	this.Quota.Serialization(sink)
+}
+
+func (this *RelayerQuotaParam) Serialization(sink *common.ZeroCopySink) {
+	this.serializeProposal(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *RelayerQuotaParam) Deserialization(source *common.ZeroCopySource) error {
+	relayer, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize relayer error")
+	}
+	relayerAddr, err := common.AddressParseFromBytes(relayer)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize relayer error: %s", err)
+	}
+	if err := this.Quota.Deserialization(source); err != nil {
+		return fmt.Errorf("deserialize quota error: %v", err)
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+	this.Relayer = relayerAddr
+	this.Address = addr
+	return nil
+}
+
+type GetRelayerQuotaParam struct {
+	Relayer common.Address
+}
+
+func (this *GetRelayerQuotaParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Relayer[:])
+}
+
+func (this *GetRelayerQuotaParam) Deserialization(source *common.ZeroCopySource) error {
+	relayer, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize relayer error")
+	}
+	addr, err := common.AddressParseFromBytes(relayer)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize relayer error: %s", err)
+	}
+	this.Relayer = addr
+	return nil
+}
+
+// RelayerFeeParam sets the fee schedule of a chain pair, the pair 0 to 0 sets the default
+// schedule of pairs without their own
+type RelayerFeeParam struct {
+	FromChainID uint64
+	ToChainID   uint64
+	Fee         RelayerFee
+	Address     common.Address
+}
+
+// serializeProposal writes the fields approved by consensus, all but the signer
+func (this *RelayerFeeParam) serializeProposal(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.FromChainID)
+	sink.WriteVarUint(this.ToChainID)
+	this.Fee.Serialization(sink)
+}
+
+func (this *RelayerFeeParam) Serialization(sink *common.ZeroCopySink) {
+	this.serializeProposal(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *RelayerFeeParam) Deserialization(source *common.ZeroCopySource) error {
+	fromChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize FromChainID error")
+	}
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize ToChainID error")
+	}
+	if err := this.Fee.Deserialization(source); err != nil {
+		return fmt.Errorf("deserialize fee error: %v", err)
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+	this.FromChainID = fromChainID
+	this.ToChainID = toChainID
+	this.Address = addr
+	return nil
+}
+
+type GetRelayerRewardsParam struct {
+	Relayer common.Address
+}
+
+func (this *GetRelayerRewardsParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Relayer[:])
+}
+
+func (this *GetRelayerRewardsParam) Deserialization(source *common.ZeroCopySource) error {
+	relayer, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize relayer error")
+	}
+	addr, err := common.AddressParseFromBytes(relayer)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize relayer error: %s", err)
+	}
+	this.Relayer = addr
+	return nil
+}
+
+type ClaimRelayerRewardParam struct {
+	Relayer     common.Address
+	FromChainID uint64
+	ToChainID   uint64
+}
+
+func (this *ClaimRelayerRewardParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Relayer[:])
+	sink.WriteVarUint(this.FromChainID)
+	sink.WriteVarUint(this.ToChainID)
+}
+
+func (this *ClaimRelayerRewardParam) Deserialization(source *common.ZeroCopySource) error {
+	relayer, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize relayer error")
+	}
+	addr, err := common.AddressParseFromBytes(relayer)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize relayer error: %s", err)
+	}
+	fromChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize FromChainID error")
+	}
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize ToChainID error")
+	}
+	this.Relayer = addr
+	this.FromChainID = fromChainID
+	this.ToChainID = toChainID
+	return nil
+}
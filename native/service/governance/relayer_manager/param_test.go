@@ -33,3 +33,36 @@ func TestRelayerListParam_Serialization(t *testing.T) {
 	err := p.Deserialization(source)
 	assert.Nil(t, err)
 }
+
+func TestRelayerQuotaParam_Serialization(t *testing.T) {
+	params := &RelayerQuotaParam{
+		Relayer: common.Address{1, 2, 3},
+		Quota:   RelayerQuota{HeadersPerBlock: 100, ImportsPerBlock: 20, MaxBatchSize: 10},
+		Address: common.Address{4, 5, 6},
+	}
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+
+	source := common.NewZeroCopySource(sink.Bytes())
+	var p RelayerQuotaParam
+	err := p.Deserialization(source)
+	assert.Nil(t, err)
+	assert.Equal(t, params, &p)
+}
+
+func TestRelayerFeeParam_Serialization(t *testing.T) {
+	params := &RelayerFeeParam{
+		FromChainID: 2,
+		ToChainID:   6,
+		Fee:         RelayerFee{DeliveryFee: 100, HeaderFee: 1},
+		Address:     common.Address{4, 5, 6},
+	}
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+
+	source := common.NewZeroCopySource(sink.Bytes())
+	var p RelayerFeeParam
+	err := p.Deserialization(source)
+	assert.Nil(t, err)
+	assert.Equal(t, params, &p)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/polynetwork/poly/native/event"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
 	"github.com/polynetwork/poly/native/service/utils"
@@ -33,6 +34,13 @@ const (
 	APPROVE_REGISTER_RELAYER = "approveRegisterRelayer"
 	REMOVE_RELAYER           = "RemoveRelayer"
 	APPROVE_REMOVE_RELAYER   = "approveRemoveRelayer"
+	GET_RELAYERS             = "getRelayers"
+	GET_RELAYER_REQUESTS     = "getRelayerRequests"
+	SET_RELAYER_QUOTA        = "setRelayerQuota"
+	GET_RELAYER_QUOTA        = "getRelayerQuota"
+	SET_RELAYER_FEE          = "setRelayerFee"
+	GET_RELAYER_REWARDS      = "getRelayerRewards"
+	CLAIM_RELAYER_REWARD     = "claimRelayerReward"
 
 	//key prefix
 	RELAYER        = "relayer"
@@ -40,6 +48,10 @@ const (
 	RELAYER_REMOVE = "relayerRemove"
 	APPLY_ID       = "applyID"
 	REMOVE_ID      = "removeID"
+	RELAYER_QUOTA  = "relayerQuota"
+	RELAYER_USAGE  = "relayerUsage"
+	RELAYER_FEE    = "relayerFee"
+	RELAYER_REWARD = "relayerReward"
 )
 
 //Register methods of node_manager contract
@@ -48,6 +60,19 @@ func RegisterRelayerManagerContract(native *native.NativeService) {
 	native.Register(APPROVE_REGISTER_RELAYER, ApproveRegisterRelayer)
 	native.Register(REMOVE_RELAYER, RemoveRelayer)
 	native.Register(APPROVE_REMOVE_RELAYER, ApproveRemoveRelayer)
+	if native.GetHeight() >= config.GetActivationHeight(config.FEATURE_GOVERNANCE_QUERY, config.DefConfig.P2PNode.NetworkId) {
+		native.Register(GET_RELAYERS, GetRelayers)
+		native.Register(GET_RELAYER_REQUESTS, GetRelayerRequests)
+	}
+	if native.GetHeight() >= config.GetActivationHeight(config.FEATURE_RELAYER_QUOTA, config.DefConfig.P2PNode.NetworkId) {
+		native.Register(SET_RELAYER_QUOTA, SetRelayerQuota)
+		native.Register(GET_RELAYER_QUOTA, GetRelayerQuota)
+	}
+	if native.GetHeight() >= config.GetActivationHeight(config.FEATURE_RELAYER_REWARD, config.DefConfig.P2PNode.NetworkId) {
+		native.Register(SET_RELAYER_FEE, SetRelayerFee)
+		native.Register(GET_RELAYER_REWARDS, GetRelayerRewards)
+		native.Register(CLAIM_RELAYER_REWARD, ClaimRelayerReward)
+	}
 }
 
 func RegisterRelayer(native *native.NativeService) ([]byte, error) {
@@ -158,3 +183,176 @@ func ApproveRemoveRelayer(native *native.NativeService) ([]byte, error) {
 		})
 	return utils.BYTE_TRUE, nil
 }
+
+func GetRelayers(native *native.NativeService) ([]byte, error) {
+	relayers, err := listRelayers(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayers, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&RelayerList{Relayers: relayers}).Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+func GetRelayerRequests(native *native.NativeService) ([]byte, error) {
+	registers, err := listRelayerRequests(native, RELAYER_APPLY)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerRequests, %v", err)
+	}
+	removes, err := listRelayerRequests(native, RELAYER_REMOVE)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerRequests, %v", err)
+	}
+	//approved remove requests are kept in storage, only those with relayers left are pending
+	pendingRemoves := make([]*RelayerRequest, 0, len(removes))
+	for _, request := range removes {
+		for _, address := range request.Request.AddressList {
+			relayer, err := native.GetCacheDB().Get(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER), address[:]))
+			if err != nil {
+				return utils.BYTE_FALSE, fmt.Errorf("GetRelayerRequests, get relayer error: %v", err)
+			}
+			if relayer != nil {
+				pendingRemoves = append(pendingRemoves, request)
+				break
+			}
+		}
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&RelayerRequests{Registers: registers, Removes: pendingRemoves}).Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// SetRelayerQuota sets the quota of a relayer, or the default one, once approved by consensus
+func SetRelayerQuota(native *native.NativeService) ([]byte, error) {
+	params := new(RelayerQuotaParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRelayerQuota, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRelayerQuota, checkWitness error: %v", err)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.serializeProposal(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_RELAYER_QUOTA, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRelayerQuota, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	putRelayerQuota(native, params.Relayer, &params.Quota)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.RelayerManagerContractAddress,
+			States: []interface{}{"SetRelayerQuota", params.Relayer.ToBase58(), params.Quota.HeadersPerBlock,
+				params.Quota.ImportsPerBlock, params.Quota.MaxBatchSize},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// GetRelayerQuota returns the quota in effect for a relayer and its usage in the latest block it submitted
+func GetRelayerQuota(native *native.NativeService) ([]byte, error) {
+	params := new(GetRelayerQuotaParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerQuota, contract params deserialize error: %v", err)
+	}
+	quota, err := getRelayerQuota(native, params.Relayer)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerQuota, %v", err)
+	}
+	usage, err := getRelayerUsage(native, params.Relayer)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerQuota, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&RelayerQuotaInfo{Relayer: params.Relayer, Quota: quota, Usage: usage}).Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// SetRelayerFee sets the fee schedule of a chain pair, or the default one, once approved by consensus
+func SetRelayerFee(native *native.NativeService) ([]byte, error) {
+	params := new(RelayerFeeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRelayerFee, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRelayerFee, checkWitness error: %v", err)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.serializeProposal(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_RELAYER_FEE, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetRelayerFee, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	putRelayerFee(native, params.FromChainID, params.ToChainID, &params.Fee)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.RelayerManagerContractAddress,
+			States: []interface{}{"SetRelayerFee", params.FromChainID, params.ToChainID, params.Fee.DeliveryFee,
+				params.Fee.HeaderFee},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// GetRelayerRewards returns the work and rewards of a relayer for every chain pair
+func GetRelayerRewards(native *native.NativeService) ([]byte, error) {
+	params := new(GetRelayerRewardsParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerRewards, contract params deserialize error: %v", err)
+	}
+	rewards, err := getRelayerRewards(native, params.Relayer)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetRelayerRewards, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	rewards.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// ClaimRelayerReward claims the unclaimed reward of a relayer for a chain pair. Poly has no native
+// asset to pay with, the claim is settled on the chains of the pair by watching its notify
+func ClaimRelayerReward(native *native.NativeService) ([]byte, error) {
+	params := new(ClaimRelayerRewardParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ClaimRelayerReward, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	if err := utils.ValidateOwner(native, params.Relayer); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ClaimRelayerReward, checkWitness error: %v", err)
+	}
+
+	rewards, err := getRelayerRewards(native, params.Relayer)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("ClaimRelayerReward, %v", err)
+	}
+	reward := rewards.Get(params.FromChainID, params.ToChainID)
+	amount := reward.Unclaimed()
+	if amount == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("ClaimRelayerReward, no reward of relayer %s from chain %d to chain %d to claim",
+			params.Relayer.ToBase58(), params.FromChainID, params.ToChainID)
+	}
+	reward.Claimed = reward.Earned
+	putRelayerRewards(native, rewards)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.RelayerManagerContractAddress,
+			States:          []interface{}{"ClaimRelayerReward", params.Relayer.ToBase58(), params.FromChainID, params.ToChainID, amount},
+		})
+	return utils.BYTE_TRUE, nil
+}
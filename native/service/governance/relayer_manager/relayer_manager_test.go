@@ -20,6 +20,7 @@ import (
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
 	"github.com/polynetwork/poly/core/genesis"
 	cstates "github.com/polynetwork/poly/core/states"
@@ -31,6 +32,7 @@ import (
 	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/polynetwork/poly/native/storage"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"strconv"
 	"testing"
 )
@@ -197,3 +199,154 @@ func TestRemoveRelayer(t *testing.T) {
 		}
 	}
 }
+
+func TestRelayerQuota(t *testing.T) {
+	networkId, genesisConfig := config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	config.DefConfig.Genesis = &config.GenesisConfig{
+		ActivationHeights: map[string]uint32{string(config.FEATURE_RELAYER_QUOTA): 0},
+	}
+	defer func() { config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis = networkId, genesisConfig }()
+
+	relayer := account.NewAccount("")
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	consensus := conAccts()
+	putPeerMapPoolAndView(db, consensus)
+	assert.Nil(t, putRelayer(NewNative(nil, new(types.Transaction), db), relayer.Address))
+
+	newRelayerNative := func(height uint32) *native.NativeService {
+		tx := &types.Transaction{SignedAddr: []common.Address{relayer.Address}}
+		ns, _ := native.NewNativeService(db, tx, 0, height, common.Uint256{}, 0, nil, false)
+		return ns
+	}
+
+	// not limited before a quota is set, usage is still counted
+	ns := newRelayerNative(1)
+	assert.Nil(t, ChargeRelayer(ns, 100, 100))
+	usage, err := getRelayerUsage(ns, relayer.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, &RelayerUsage{Height: 1, Headers: 100, Imports: 100}, usage)
+
+	// the default quota takes effect once approved by consensus
+	for i, conAcct := range consensus {
+		params := &RelayerQuotaParam{
+			Quota:   RelayerQuota{HeadersPerBlock: 10, ImportsPerBlock: 2, MaxBatchSize: 8},
+			Address: conAcct.Address,
+		}
+		sink := common.NewZeroCopySink(nil)
+		params.Serialization(sink)
+		tx := &types.Transaction{SignedAddr: []common.Address{conAcct.Address}}
+		res, err := SetRelayerQuota(NewNative(sink.Bytes(), tx, db))
+		assert.Nil(t, err)
+		assert.Equal(t, utils.BYTE_TRUE, res)
+		quota, err := getRelayerQuota(ns, relayer.Address)
+		assert.Nil(t, err)
+		if i+1 < (2*len(consensus)+2)/3 {
+			assert.Nil(t, quota)
+		} else {
+			assert.Equal(t, &params.Quota, quota)
+		}
+	}
+
+	ns = newRelayerNative(2)
+	assert.NotNil(t, ChargeRelayer(ns, 9, 0))
+	assert.Nil(t, ChargeRelayer(ns, 8, 0))
+	assert.NotNil(t, ChargeRelayer(ns, 3, 0))
+	assert.Nil(t, ChargeRelayer(ns, 2, 2))
+	assert.NotNil(t, ChargeRelayer(ns, 0, 1))
+
+	sink := common.NewZeroCopySink(nil)
+	(&GetRelayerQuotaParam{Relayer: relayer.Address}).Serialization(sink)
+	res, err := GetRelayerQuota(NewNative(sink.Bytes(), new(types.Transaction), db))
+	assert.Nil(t, err)
+	info := new(RelayerQuotaInfo)
+	assert.Nil(t, info.Deserialization(common.NewZeroCopySource(res)))
+	assert.Equal(t, &RelayerQuota{HeadersPerBlock: 10, ImportsPerBlock: 2, MaxBatchSize: 8}, info.Quota)
+	assert.Equal(t, &RelayerUsage{Height: 2, Headers: 10, Imports: 2}, info.Usage)
+
+	// usage is reset every block
+	assert.Nil(t, ChargeRelayer(newRelayerNative(3), 8, 2))
+
+	// other signers are not relayers and not limited
+	tx := &types.Transaction{SignedAddr: []common.Address{acct.Address}}
+	ns, _ = native.NewNativeService(db, tx, 0, 3, common.Uint256{}, 0, nil, false)
+	assert.Nil(t, ChargeRelayer(ns, 100, 100))
+}
+
+func TestRelayerReward(t *testing.T) {
+	networkId, genesisConfig := config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	config.DefConfig.Genesis = &config.GenesisConfig{
+		ActivationHeights: map[string]uint32{string(config.FEATURE_RELAYER_REWARD): 0},
+	}
+	defer func() { config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis = networkId, genesisConfig }()
+
+	relayer := account.NewAccount("")
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	consensus := conAccts()
+	putPeerMapPoolAndView(db, consensus)
+
+	setFee := func(fromChainID, toChainID uint64, fee RelayerFee) {
+		for _, conAcct := range consensus {
+			params := &RelayerFeeParam{FromChainID: fromChainID, ToChainID: toChainID, Fee: fee, Address: conAcct.Address}
+			sink := common.NewZeroCopySink(nil)
+			params.Serialization(sink)
+			tx := &types.Transaction{SignedAddr: []common.Address{conAcct.Address}}
+			res, err := SetRelayerFee(NewNative(sink.Bytes(), tx, db))
+			assert.Nil(t, err)
+			assert.Equal(t, utils.BYTE_TRUE, res)
+		}
+	}
+	setFee(0, 0, RelayerFee{DeliveryFee: 10, HeaderFee: 1})
+	setFee(2, 6, RelayerFee{DeliveryFee: 50, HeaderFee: 5})
+
+	signedBy := func(address common.Address) *native.NativeService {
+		return NewNative(nil, &types.Transaction{SignedAddr: []common.Address{address}}, db)
+	}
+	assert.Nil(t, RewardDelivery(signedBy(relayer.Address), relayer.Address[:], 2, 6))
+	assert.Nil(t, RewardDelivery(signedBy(relayer.Address), relayer.Address[:], 2, 7))
+	assert.Nil(t, RewardHeaders(signedBy(relayer.Address), relayer.Address, 2, 30))
+	// not credited if no header is stored
+	assert.Nil(t, RewardHeaders(signedBy(relayer.Address), relayer.Address, 3, 0))
+	// not credited if the relayer didn't sign the tx, or the address is not a poly address
+	assert.Nil(t, RewardDelivery(signedBy(acct.Address), relayer.Address[:], 2, 6))
+	assert.Nil(t, RewardHeaders(signedBy(acct.Address), relayer.Address, 2, 30))
+	assert.Nil(t, RewardDelivery(signedBy(relayer.Address), []byte{1, 2, 3}, 2, 6))
+
+	sink := common.NewZeroCopySink(nil)
+	(&GetRelayerRewardsParam{Relayer: relayer.Address}).Serialization(sink)
+	res, err := GetRelayerRewards(NewNative(sink.Bytes(), new(types.Transaction), db))
+	assert.Nil(t, err)
+	rewards := new(RelayerRewards)
+	assert.Nil(t, rewards.Deserialization(common.NewZeroCopySource(res)))
+	assert.Equal(t, []*RelayerReward{
+		{FromChainID: 2, ToChainID: 0, Headers: 30, Earned: 30},
+		{FromChainID: 2, ToChainID: 6, Deliveries: 1, Earned: 50},
+		{FromChainID: 2, ToChainID: 7, Deliveries: 1, Earned: 10},
+	}, rewards.Rewards)
+
+	claim := func(signer common.Address, fromChainID, toChainID uint64) error {
+		sink := common.NewZeroCopySink(nil)
+		(&ClaimRelayerRewardParam{Relayer: relayer.Address, FromChainID: fromChainID, ToChainID: toChainID}).Serialization(sink)
+		_, err := ClaimRelayerReward(NewNative(sink.Bytes(), &types.Transaction{SignedAddr: []common.Address{signer}}, db))
+		return err
+	}
+	assert.NotNil(t, claim(acct.Address, 2, 6))
+	assert.Nil(t, claim(relayer.Address, 2, 6))
+	assert.NotNil(t, claim(relayer.Address, 2, 6))
+	assert.NotNil(t, claim(relayer.Address, 3, 6))
+
+	assert.Nil(t, RewardDelivery(signedBy(relayer.Address), relayer.Address[:], 2, 6))
+	rewards, err = getRelayerRewards(signedBy(relayer.Address), relayer.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, &RelayerReward{FromChainID: 2, ToChainID: 6, Deliveries: 2, Earned: 100, Claimed: 50}, rewards.Get(2, 6))
+	assert.Equal(t, uint64(50), rewards.Get(2, 6).Unclaimed())
+
+	// fees overflowing uint64 are rejected
+	setFee(4, 0, RelayerFee{HeaderFee: math.MaxUint64 / 2})
+	assert.Nil(t, RewardHeaders(signedBy(relayer.Address), relayer.Address, 4, 2))
+	assert.NotNil(t, RewardHeaders(signedBy(relayer.Address), relayer.Address, 4, 3))
+	assert.NotNil(t, RewardHeaders(signedBy(relayer.Address), relayer.Address, 4, 1))
+}
@@ -0,0 +1,382 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relayer_manager
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/polynetwork/poly/common"
+)
+
+type RelayerList struct {
+	Relayers []common.Address
+}
+
+func (this *RelayerList) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Relayers)))
+	for _, v := range this.Relayers {
+		sink.WriteVarBytes(v[:])
+	}
+}
+
+func (this *RelayerList) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize Relayers length error")
+	}
+	relayers := make([]common.Address, 0)
+	for i := 0; uint64(i) < n; i++ {
+		address, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("source.NextVarBytes, deserialize relayer error")
+		}
+		addr, err := common.AddressParseFromBytes(address)
+		if err != nil {
+			return fmt.Errorf("common.AddressParseFromBytes, deserialize relayer error: %s", err)
+		}
+		relayers = append(relayers, addr)
+	}
+	this.Relayers = relayers
+	return nil
+}
+
+type RelayerRequest struct {
+	ID      uint64
+	Request *RelayerListParam
+}
+
+type RelayerRequests struct {
+	Registers []*RelayerRequest
+	Removes   []*RelayerRequest
+}
+
+func serializeRelayerRequests(sink *common.ZeroCopySink, requests []*RelayerRequest) {
+	sink.WriteVarUint(uint64(len(requests)))
+	for _, v := range requests {
+		sink.WriteVarUint(v.ID)
+		v.Request.Serialization(sink)
+	}
+}
+
+func deserializeRelayerRequests(source *common.ZeroCopySource) ([]*RelayerRequest, error) {
+	n, eof := source.NextVarUint()
+	if eof {
+		return nil, fmt.Errorf("source.NextVarUint, deserialize requests length error")
+	}
+	requests := make([]*RelayerRequest, 0)
+	for i := 0; uint64(i) < n; i++ {
+		id, eof := source.NextVarUint()
+		if eof {
+			return nil, fmt.Errorf("source.NextVarUint, deserialize ID error")
+		}
+		request := new(RelayerListParam)
+		if err := request.Deserialization(source); err != nil {
+			return nil, fmt.Errorf("deserialize request %d error: %v", id, err)
+		}
+		requests = append(requests, &RelayerRequest{ID: id, Request: request})
+	}
+	return requests, nil
+}
+
+func (this *RelayerRequests) Serialization(sink *common.ZeroCopySink) {
+	serializeRelayerRequests(sink, this.Registers)
+	serializeRelayerRequests(sink, this.Removes)
+}
+
+func (this *RelayerRequests) Deserialization(source *common.ZeroCopySource) error {
+	registers, err := deserializeRelayerRequests(source)
+	if err != nil {
+		return fmt.Errorf("deserialize Registers error: %v", err)
+	}
+	removes, err := deserializeRelayerRequests(source)
+	if err != nil {
+		return fmt.Errorf("deserialize Removes error: %v", err)
+	}
+	this.Registers = registers
+	this.Removes = removes
+	return nil
+}
+
+// RelayerQuota limits the submissions of a relayer, zero means no limit
+type RelayerQuota struct {
+	HeadersPerBlock uint64
+	ImportsPerBlock uint64
+	MaxBatchSize    uint64
+}
+
+func (this *RelayerQuota) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.HeadersPerBlock)
+	sink.WriteVarUint(this.ImportsPerBlock)
+	sink.WriteVarUint(this.MaxBatchSize)
+}
+
+func (this *RelayerQuota) Deserialization(source *common.ZeroCopySource) error {
+	headersPerBlock, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize HeadersPerBlock error")
+	}
+	importsPerBlock, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize ImportsPerBlock error")
+	}
+	maxBatchSize, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize MaxBatchSize error")
+	}
+	this.HeadersPerBlock = headersPerBlock
+	this.ImportsPerBlock = importsPerBlock
+	this.MaxBatchSize = maxBatchSize
+	return nil
+}
+
+// Check returns an error if submitting headers and imports on top of usage exceeds the quota
+func (this *RelayerQuota) Check(usage *RelayerUsage, headers, imports uint64) error {
+	if this.MaxBatchSize > 0 && (headers > this.MaxBatchSize || imports > this.MaxBatchSize) {
+		return fmt.Errorf("batch of %d headers and %d imports exceeds max batch size %d", headers, imports, this.MaxBatchSize)
+	}
+	if this.HeadersPerBlock > 0 && usage.Headers+headers > this.HeadersPerBlock {
+		return fmt.Errorf("headers per block quota %d exceeded, used %d, submitting %d", this.HeadersPerBlock, usage.Headers, headers)
+	}
+	if this.ImportsPerBlock > 0 && usage.Imports+imports > this.ImportsPerBlock {
+		return fmt.Errorf("imports per block quota %d exceeded, used %d, submitting %d", this.ImportsPerBlock, usage.Imports, imports)
+	}
+	return nil
+}
+
+// RelayerUsage counts the submissions of a relayer in the block at Height
+type RelayerUsage struct {
+	Height  uint32
+	Headers uint64
+	Imports uint64
+}
+
+func (this *RelayerUsage) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Height)
+	sink.WriteVarUint(this.Headers)
+	sink.WriteVarUint(this.Imports)
+}
+
+func (this *RelayerUsage) Deserialization(source *common.ZeroCopySource) error {
+	height, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize Height error")
+	}
+	headers, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize Headers error")
+	}
+	imports, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize Imports error")
+	}
+	this.Height = height
+	this.Headers = headers
+	this.Imports = imports
+	return nil
+}
+
+// RelayerQuotaInfo is the quota in effect for a relayer and its latest usage,
+// Quota is nil if the relayer is not limited
+type RelayerQuotaInfo struct {
+	Relayer common.Address
+	Quota   *RelayerQuota
+	Usage   *RelayerUsage
+}
+
+func (this *RelayerQuotaInfo) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Relayer[:])
+	sink.WriteBool(this.Quota != nil)
+	if this.Quota != nil {
+		this.Quota.Serialization(sink)
+	}
+	this.Usage.Serialization(sink)
+}
+
+func (this *RelayerQuotaInfo) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize relayer error")
+	}
+	relayer, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize relayer error: %s", err)
+	}
+	limited, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("source.NextBool, deserialize quota flag error")
+	}
+	var quota *RelayerQuota
+	if limited {
+		quota = new(RelayerQuota)
+		if err := quota.Deserialization(source); err != nil {
+			return fmt.Errorf("deserialize Quota error: %v", err)
+		}
+	}
+	usage := new(RelayerUsage)
+	if err := usage.Deserialization(source); err != nil {
+		return fmt.Errorf("deserialize Usage error: %v", err)
+	}
+	this.Relayer = relayer
+	this.Quota = quota
+	this.Usage = usage
+	return nil
+}
+
+// RelayerFee is the fee schedule of a chain pair, credited to relayers
+// for each cross chain tx delivered and each header synced
+type RelayerFee struct {
+	DeliveryFee uint64
+	HeaderFee   uint64
+}
+
+func (this *RelayerFee) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.DeliveryFee)
+	sink.WriteVarUint(this.HeaderFee)
+}
+
+func (this *RelayerFee) Deserialization(source *common.ZeroCopySource) error {
+	deliveryFee, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize DeliveryFee error")
+	}
+	headerFee, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize HeaderFee error")
+	}
+	this.DeliveryFee = deliveryFee
+	this.HeaderFee = headerFee
+	return nil
+}
+
+// RelayerReward is the work a relayer did for a chain pair and the fees credited for it,
+// headers synced from a chain are accounted to the pair of that chain and poly, chain 0
+type RelayerReward struct {
+	FromChainID uint64
+	ToChainID   uint64
+	Deliveries  uint64
+	Headers     uint64
+	Earned      uint64
+	Claimed     uint64
+}
+
+// Unclaimed returns the fees credited but not claimed yet
+func (this *RelayerReward) Unclaimed() uint64 {
+	return this.Earned - this.Claimed
+}
+
+func (this *RelayerReward) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.FromChainID)
+	sink.WriteVarUint(this.ToChainID)
+	sink.WriteVarUint(this.Deliveries)
+	sink.WriteVarUint(this.Headers)
+	sink.WriteVarUint(this.Earned)
+	sink.WriteVarUint(this.Claimed)
+}
+
+func (this *RelayerReward) Deserialization(source *common.ZeroCopySource) error {
+	fromChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize FromChainID error")
+	}
+	toChainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize ToChainID error")
+	}
+	deliveries, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize Deliveries error")
+	}
+	headers, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize Headers error")
+	}
+	earned, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize Earned error")
+	}
+	claimed, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize Claimed error")
+	}
+	this.FromChainID = fromChainID
+	this.ToChainID = toChainID
+	this.Deliveries = deliveries
+	this.Headers = headers
+	this.Earned = earned
+	this.Claimed = claimed
+	return nil
+}
+
+// RelayerRewards is the rewards of a relayer for every chain pair it relayed for
+type RelayerRewards struct {
+	Relayer common.Address
+	Rewards []*RelayerReward
+}
+
+// Get returns the reward of the chain pair, creating it if absent
+func (this *RelayerRewards) Get(fromChainID, toChainID uint64) *RelayerReward {
+	for _, reward := range this.Rewards {
+		if reward.FromChainID == fromChainID && reward.ToChainID == toChainID {
+			return reward
+		}
+	}
+	reward := &RelayerReward{FromChainID: fromChainID, ToChainID: toChainID}
+	this.Rewards = append(this.Rewards, reward)
+	sort.SliceStable(this.Rewards, func(i, j int) bool {
+		if this.Rewards[i].FromChainID != this.Rewards[j].FromChainID {
+			return this.Rewards[i].FromChainID < this.Rewards[j].FromChainID
+		}
+		return this.Rewards[i].ToChainID < this.Rewards[j].ToChainID
+	})
+	return reward
+}
+
+func (this *RelayerRewards) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.Relayer[:])
+	sink.WriteVarUint(uint64(len(this.Rewards)))
+	for _, reward := range this.Rewards {
+		reward.Serialization(sink)
+	}
+}
+
+func (this *RelayerRewards) Deserialization(source *common.ZeroCopySource) error {
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize relayer error")
+	}
+	relayer, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize relayer error: %s", err)
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize rewards length error")
+	}
+	rewards := make([]*RelayerReward, 0)
+	for i := uint64(0); i < n; i++ {
+		reward := new(RelayerReward)
+		if err := reward.Deserialization(source); err != nil {
+			return fmt.Errorf("deserialize reward error: %v", err)
+		}
+		rewards = append(rewards, reward)
+	}
+	this.Relayer = relayer
+	this.Rewards = rewards
+	return nil
+}
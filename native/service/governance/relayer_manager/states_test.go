@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relayer_manager
+
+import (
+	"github.com/polynetwork/poly/common"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRelayerList_Serialization(t *testing.T) {
+	paramSerialize := &RelayerList{Relayers: []common.Address{{1, 2, 4, 6}, {1, 4, 5, 7}}}
+	sink := common.NewZeroCopySink(nil)
+	paramSerialize.Serialization(sink)
+
+	paramDeserialize := new(RelayerList)
+	err := paramDeserialize.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, paramDeserialize, paramSerialize)
+}
+
+func TestRelayerRequests_Serialization(t *testing.T) {
+	paramSerialize := &RelayerRequests{
+		Registers: []*RelayerRequest{
+			{ID: 1, Request: &RelayerListParam{AddressList: []common.Address{{1, 2}, {3, 4}}, Address: common.Address{5}}},
+			{ID: 4, Request: &RelayerListParam{AddressList: []common.Address{{6}}, Address: common.Address{7}}},
+		},
+		Removes: []*RelayerRequest{
+			{ID: 2, Request: &RelayerListParam{AddressList: []common.Address{{1, 2}}, Address: common.Address{8}}},
+		},
+	}
+	sink := common.NewZeroCopySink(nil)
+	paramSerialize.Serialization(sink)
+
+	paramDeserialize := new(RelayerRequests)
+	err := paramDeserialize.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, paramDeserialize, paramSerialize)
+}
+
+func TestRelayerQuotaInfo_Serialization(t *testing.T) {
+	for _, quota := range []*RelayerQuota{nil, {HeadersPerBlock: 10, ImportsPerBlock: 5, MaxBatchSize: 3}} {
+		paramSerialize := &RelayerQuotaInfo{
+			Relayer: common.Address{1, 2},
+			Quota:   quota,
+			Usage:   &RelayerUsage{Height: 100, Headers: 4, Imports: 2},
+		}
+		sink := common.NewZeroCopySink(nil)
+		paramSerialize.Serialization(sink)
+
+		paramDeserialize := new(RelayerQuotaInfo)
+		err := paramDeserialize.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+		assert.Nil(t, err)
+		assert.Equal(t, paramDeserialize, paramSerialize)
+	}
+}
+
+func TestRelayerQuota_Check(t *testing.T) {
+	quota := &RelayerQuota{HeadersPerBlock: 10, ImportsPerBlock: 5, MaxBatchSize: 4}
+	usage := &RelayerUsage{Headers: 6, Imports: 4}
+	assert.Nil(t, quota.Check(usage, 4, 0))
+	assert.NotNil(t, quota.Check(usage, 5, 0))
+	assert.Nil(t, quota.Check(usage, 0, 1))
+	assert.NotNil(t, quota.Check(usage, 0, 2))
+	assert.NotNil(t, quota.Check(&RelayerUsage{}, 0, 5))
+
+	// zero means no limit
+	assert.Nil(t, (&RelayerQuota{}).Check(usage, 100, 100))
+}
+
+func TestRelayerRewards_Serialization(t *testing.T) {
+	paramSerialize := &RelayerRewards{Relayer: common.Address{1, 2}}
+	paramSerialize.Get(2, 3).Deliveries = 5
+	paramSerialize.Get(2, 0).Headers = 100
+	paramSerialize.Get(1, 2).Earned = 8
+	assert.Equal(t, []uint64{1, 2, 2}, []uint64{paramSerialize.Rewards[0].FromChainID,
+		paramSerialize.Rewards[1].FromChainID, paramSerialize.Rewards[2].FromChainID})
+	assert.Equal(t, uint64(0), paramSerialize.Rewards[1].ToChainID)
+	assert.Equal(t, uint64(5), paramSerialize.Get(2, 3).Deliveries)
+
+	sink := common.NewZeroCopySink(nil)
+	paramSerialize.Serialization(sink)
+	paramDeserialize := new(RelayerRewards)
+	err := paramDeserialize.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, paramSerialize, paramDeserialize)
+}
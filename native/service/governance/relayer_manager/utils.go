@@ -19,8 +19,11 @@ package relayer_manager
 
 import (
 	"fmt"
+	"math"
+	"sort"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	cstates "github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/event"
@@ -170,3 +173,321 @@ func putRemoveID(native *native.NativeService, removeID uint64) error {
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(REMOVE_ID)), cstates.GenRawStorageItem(removeIDByte))
 	return nil
 }
+
+func listRelayers(native *native.NativeService) ([]common.Address, error) {
+	key := utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER))
+	iter := native.GetCacheDB().NewIterator(key)
+	defer iter.Release()
+
+	relayers := make([]common.Address, 0)
+	for iter.Next() {
+		// skip keys of other prefixes starting with relayer, like relayerApply
+		if len(iter.Key()) != len(key)+common.ADDR_LEN {
+			continue
+		}
+		relayer, err := common.AddressParseFromBytes(iter.Key()[len(key):])
+		if err != nil {
+			return nil, fmt.Errorf("listRelayers, parse relayer error: %v", err)
+		}
+		relayers = append(relayers, relayer)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("listRelayers, iterate relayers error: %v", err)
+	}
+	return relayers, nil
+}
+
+// listRelayerRequests returns the requests stored under key prefix, sorted by id
+func listRelayerRequests(native *native.NativeService, prefix string) ([]*RelayerRequest, error) {
+	key := utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(prefix))
+	iter := native.GetCacheDB().NewIterator(key)
+	defer iter.Release()
+
+	requests := make([]*RelayerRequest, 0)
+	for iter.Next() {
+		if len(iter.Key()) != len(key)+8 {
+			continue
+		}
+		requestBytes, err := cstates.GetValueFromRawStorageItem(iter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("listRelayerRequests, deserialize from raw storage item err:%v", err)
+		}
+		request := new(RelayerListParam)
+		if err := request.Deserialization(common.NewZeroCopySource(requestBytes)); err != nil {
+			return nil, fmt.Errorf("listRelayerRequests, deserialize request error: %v", err)
+		}
+		requests = append(requests, &RelayerRequest{
+			ID:      utils.GetBytesUint64(iter.Key()[len(key):]),
+			Request: request,
+		})
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("listRelayerRequests, iterate %s error: %v", prefix, err)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].ID < requests[j].ID
+	})
+	return requests, nil
+}
+
+// ChargeRelayer counts the headers and cross chain txs submitted by the tx against the quota
+// of its relayer, txs not signed by a relayer are not limited
+func ChargeRelayer(native *native.NativeService, headers, imports uint64) error {
+	if native.GetHeight() < config.GetActivationHeight(config.FEATURE_RELAYER_QUOTA, config.DefConfig.P2PNode.NetworkId) {
+		return nil
+	}
+	relayer, ok, err := txRelayer(native)
+	if err != nil {
+		return fmt.Errorf("ChargeRelayer, %v", err)
+	}
+	if !ok {
+		return nil
+	}
+	quota, err := getRelayerQuota(native, relayer)
+	if err != nil {
+		return fmt.Errorf("ChargeRelayer, %v", err)
+	}
+	usage, err := getRelayerUsage(native, relayer)
+	if err != nil {
+		return fmt.Errorf("ChargeRelayer, %v", err)
+	}
+	if usage.Height != native.GetHeight() {
+		usage = &RelayerUsage{Height: native.GetHeight()}
+	}
+	if quota != nil {
+		if err := quota.Check(usage, headers, imports); err != nil {
+			return fmt.Errorf("ChargeRelayer, relayer %s: %v", relayer.ToBase58(), err)
+		}
+	}
+	usage.Headers += headers
+	usage.Imports += imports
+	putRelayerUsage(native, relayer, usage)
+	return nil
+}
+
+// txRelayer returns the first signer of the tx which is a registered relayer
+func txRelayer(native *native.NativeService) (common.Address, bool, error) {
+	signers, err := native.GetTx().GetSignatureAddresses()
+	if err != nil {
+		return common.ADDRESS_EMPTY, false, fmt.Errorf("txRelayer, GetSignatureAddresses error: %v", err)
+	}
+	for _, signer := range signers {
+		relayer, err := native.GetCacheDB().Get(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER), signer[:]))
+		if err != nil {
+			return common.ADDRESS_EMPTY, false, fmt.Errorf("txRelayer, get relayer error: %v", err)
+		}
+		if relayer != nil {
+			return signer, true, nil
+		}
+	}
+	return common.ADDRESS_EMPTY, false, nil
+}
+
+func putRelayerQuota(native *native.NativeService, relayer common.Address, quota *RelayerQuota) {
+	key := relayerQuotaKey(relayer)
+	if *quota == (RelayerQuota{}) {
+		native.GetCacheDB().Delete(key)
+		return
+	}
+	sink := common.NewZeroCopySink(nil)
+	quota.Serialization(sink)
+	native.GetCacheDB().Put(key, cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// getRelayerQuota returns the quota of relayer, the default quota if it has none,
+// or nil if neither is set
+func getRelayerQuota(native *native.NativeService, relayer common.Address) (*RelayerQuota, error) {
+	for _, key := range [][]byte{relayerQuotaKey(relayer), relayerQuotaKey(common.ADDRESS_EMPTY)} {
+		store, err := native.GetCacheDB().Get(key)
+		if err != nil {
+			return nil, fmt.Errorf("getRelayerQuota, get quota store error: %v", err)
+		}
+		if store == nil {
+			continue
+		}
+		quotaBytes, err := cstates.GetValueFromRawStorageItem(store)
+		if err != nil {
+			return nil, fmt.Errorf("getRelayerQuota, deserialize from raw storage item err:%v", err)
+		}
+		quota := new(RelayerQuota)
+		if err := quota.Deserialization(common.NewZeroCopySource(quotaBytes)); err != nil {
+			return nil, fmt.Errorf("getRelayerQuota, deserialize quota error: %v", err)
+		}
+		return quota, nil
+	}
+	return nil, nil
+}
+
+// relayerQuotaKey returns the storage key of the quota of relayer, the empty address keys the default quota
+func relayerQuotaKey(relayer common.Address) []byte {
+	if relayer == common.ADDRESS_EMPTY {
+		return utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_QUOTA))
+	}
+	return utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_QUOTA), relayer[:])
+}
+
+func putRelayerUsage(native *native.NativeService, relayer common.Address, usage *RelayerUsage) {
+	sink := common.NewZeroCopySink(nil)
+	usage.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_USAGE), relayer[:]),
+		cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func getRelayerUsage(native *native.NativeService, relayer common.Address) (*RelayerUsage, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_USAGE), relayer[:]))
+	if err != nil {
+		return nil, fmt.Errorf("getRelayerUsage, get usage store error: %v", err)
+	}
+	usage := new(RelayerUsage)
+	if store == nil {
+		return usage, nil
+	}
+	usageBytes, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("getRelayerUsage, deserialize from raw storage item err:%v", err)
+	}
+	if err := usage.Deserialization(common.NewZeroCopySource(usageBytes)); err != nil {
+		return nil, fmt.Errorf("getRelayerUsage, deserialize usage error: %v", err)
+	}
+	return usage, nil
+}
+
+// RewardDelivery credits the relayer of a cross chain tx delivered from fromChainID to toChainID,
+// relayerAddress is credited only if it signed the tx
+func RewardDelivery(native *native.NativeService, relayerAddress []byte, fromChainID, toChainID uint64) error {
+	if native.GetHeight() < config.GetActivationHeight(config.FEATURE_RELAYER_REWARD, config.DefConfig.P2PNode.NetworkId) {
+		return nil
+	}
+	relayer, err := common.AddressParseFromBytes(relayerAddress)
+	if err != nil || !native.CheckWitness(relayer) {
+		return nil
+	}
+	if err := creditRelayer(native, relayer, fromChainID, toChainID, 1, 0); err != nil {
+		return fmt.Errorf("RewardDelivery, %v", err)
+	}
+	return nil
+}
+
+// RewardHeaders credits the relayer of the headers stored from chainID, relayer is credited only if it signed the tx
+func RewardHeaders(native *native.NativeService, relayer common.Address, chainID uint64, headers uint64) error {
+	if native.GetHeight() < config.GetActivationHeight(config.FEATURE_RELAYER_REWARD, config.DefConfig.P2PNode.NetworkId) {
+		return nil
+	}
+	if headers == 0 || !native.CheckWitness(relayer) {
+		return nil
+	}
+	if err := creditRelayer(native, relayer, chainID, 0, 0, headers); err != nil {
+		return fmt.Errorf("RewardHeaders, %v", err)
+	}
+	return nil
+}
+
+func creditRelayer(native *native.NativeService, relayer common.Address, fromChainID, toChainID uint64,
+	deliveries, headers uint64) error {
+	fee, err := getRelayerFee(native, fromChainID, toChainID)
+	if err != nil {
+		return fmt.Errorf("creditRelayer, %v", err)
+	}
+	rewards, err := getRelayerRewards(native, relayer)
+	if err != nil {
+		return fmt.Errorf("creditRelayer, %v", err)
+	}
+	amount, ok := relayerFeeAmount(fee, deliveries, headers)
+	if !ok {
+		return fmt.Errorf("creditRelayer, fee of %d deliveries and %d headers overflows", deliveries, headers)
+	}
+	reward := rewards.Get(fromChainID, toChainID)
+	if reward.Earned+amount < reward.Earned {
+		return fmt.Errorf("creditRelayer, fee earned by %s overflows", relayer.ToBase58())
+	}
+	reward.Deliveries += deliveries
+	reward.Headers += headers
+	reward.Earned += amount
+	putRelayerRewards(native, rewards)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.RelayerManagerContractAddress,
+			States:          []interface{}{"CreditRelayer", relayer.ToBase58(), fromChainID, toChainID, amount},
+		})
+	return nil
+}
+
+// relayerFeeAmount returns deliveries*fee.DeliveryFee + headers*fee.HeaderFee, false if it overflows
+func relayerFeeAmount(fee *RelayerFee, deliveries, headers uint64) (uint64, bool) {
+	if fee.DeliveryFee != 0 && deliveries > math.MaxUint64/fee.DeliveryFee {
+		return 0, false
+	}
+	if fee.HeaderFee != 0 && headers > math.MaxUint64/fee.HeaderFee {
+		return 0, false
+	}
+	deliveryAmount, headerAmount := deliveries*fee.DeliveryFee, headers*fee.HeaderFee
+	if deliveryAmount+headerAmount < deliveryAmount {
+		return 0, false
+	}
+	return deliveryAmount + headerAmount, true
+}
+
+func putRelayerFee(native *native.NativeService, fromChainID, toChainID uint64, fee *RelayerFee) {
+	sink := common.NewZeroCopySink(nil)
+	fee.Serialization(sink)
+	native.GetCacheDB().Put(relayerFeeKey(fromChainID, toChainID), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// getRelayerFee returns the fee schedule of the chain pair, the default schedule if it has none,
+// or a zero schedule if neither is set
+func getRelayerFee(native *native.NativeService, fromChainID, toChainID uint64) (*RelayerFee, error) {
+	for _, key := range [][]byte{relayerFeeKey(fromChainID, toChainID), relayerFeeKey(0, 0)} {
+		store, err := native.GetCacheDB().Get(key)
+		if err != nil {
+			return nil, fmt.Errorf("getRelayerFee, get fee store error: %v", err)
+		}
+		if store == nil {
+			continue
+		}
+		feeBytes, err := cstates.GetValueFromRawStorageItem(store)
+		if err != nil {
+			return nil, fmt.Errorf("getRelayerFee, deserialize from raw storage item err:%v", err)
+		}
+		fee := new(RelayerFee)
+		if err := fee.Deserialization(common.NewZeroCopySource(feeBytes)); err != nil {
+			return nil, fmt.Errorf("getRelayerFee, deserialize fee error: %v", err)
+		}
+		return fee, nil
+	}
+	return new(RelayerFee), nil
+}
+
+// relayerFeeKey returns the storage key of the fee schedule of a chain pair, the pair 0 to 0 keys the default schedule
+func relayerFeeKey(fromChainID, toChainID uint64) []byte {
+	if fromChainID == 0 && toChainID == 0 {
+		return utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_FEE))
+	}
+	return utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_FEE), utils.GetUint64Bytes(fromChainID),
+		utils.GetUint64Bytes(toChainID))
+}
+
+func putRelayerRewards(native *native.NativeService, rewards *RelayerRewards) {
+	sink := common.NewZeroCopySink(nil)
+	rewards.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_REWARD), rewards.Relayer[:]),
+		cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func getRelayerRewards(native *native.NativeService, relayer common.Address) (*RelayerRewards, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER_REWARD), relayer[:]))
+	if err != nil {
+		return nil, fmt.Errorf("getRelayerRewards, get rewards store error: %v", err)
+	}
+	rewards := &RelayerRewards{Relayer: relayer}
+	if store == nil {
+		return rewards, nil
+	}
+	rewardsBytes, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("getRelayerRewards, deserialize from raw storage item err:%v", err)
+	}
+	if err := rewards.Deserialization(common.NewZeroCopySource(rewardsBytes)); err != nil {
+		return nil, fmt.Errorf("getRelayerRewards, deserialize rewards error: %v", err)
+	}
+	return rewards, nil
+}
@@ -25,13 +25,14 @@ import (
 )
 
 type RegisterSideChainParam struct {
-	Address      common.Address
-	ChainId      uint64
-	Router       uint64
-	Name         string
-	BlocksToWait uint64
-	CCMCAddress  []byte
-	ExtraInfo    []byte
+	Address        common.Address
+	ChainId        uint64
+	Router         uint64
+	Name           string
+	BlocksToWait   uint64
+	CCMCAddress    []byte
+	ExtraInfo      []byte
+	FinalityPolicy *FinalityPolicy
 }
 
 func (this *RegisterSideChainParam) Serialization(sink *common.ZeroCopySink) error {
@@ -45,6 +46,9 @@ func (this *RegisterSideChainParam) Serialization(sink *common.ZeroCopySink) err
 	height := config.GetExtraInfoHeight(config.DefConfig.P2PNode.NetworkId)
 	if !config.EXTRA_INFO_HEIGHT_FORK_CHECK || ledger.DefLedger.GetCurrentBlockHeight() >= height {
 		sink.WriteVarBytes(this.ExtraInfo)
+		if this.FinalityPolicy != nil {
+			this.FinalityPolicy.Serialization(sink)
+		}
 	}
 
 	return nil
@@ -90,9 +94,27 @@ func (this *RegisterSideChainParam) Deserialization(source *common.ZeroCopySourc
 	this.BlocksToWait = blocksToWait
 	this.CCMCAddress = CCMCAddress
 	this.ExtraInfo = ExtraInfo
+	finalityPolicy, err := deserializeFinalityPolicy(source)
+	if err != nil {
+		return err
+	}
+	this.FinalityPolicy = finalityPolicy
 	return nil
 }
 
+// deserializeFinalityPolicy reads the optional finality policy following ExtraInfo,
+// nil is returned when it is absent to keep the former params valid
+func deserializeFinalityPolicy(source *common.ZeroCopySource) (*FinalityPolicy, error) {
+	if source.Len() == 0 {
+		return nil, nil
+	}
+	policy := new(FinalityPolicy)
+	if err := policy.Deserialization(source); err != nil {
+		return nil, fmt.Errorf("deserialize finality policy error: %v", err)
+	}
+	return policy, nil
+}
+
 type ChainidParam struct {
 	Chainid uint64
 	Address common.Address
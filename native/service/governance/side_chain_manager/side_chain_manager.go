@@ -24,6 +24,7 @@ import (
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcutil"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/event"
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
@@ -40,6 +41,8 @@ const (
 	APPROVE_QUIT_SIDE_CHAIN     = "approveQuitSideChain"
 	REGISTER_REDEEM             = "registerRedeem"
 	SET_BTC_TX_PARAM            = "setBtcTxParam"
+	GET_SIDE_CHAINS             = "getSideChains"
+	GET_SIDE_CHAIN_REQUESTS     = "getSideChainRequests"
 
 	//key prefix
 	SIDE_CHAIN_APPLY          = "sideChainApply"
@@ -63,6 +66,10 @@ func RegisterSideChainManagerContract(native *native.NativeService) {
 
 	native.Register(REGISTER_REDEEM, RegisterRedeem)
 	native.Register(SET_BTC_TX_PARAM, SetBtcTxParam)
+	if native.GetHeight() >= config.GetActivationHeight(config.FEATURE_GOVERNANCE_QUERY, config.DefConfig.P2PNode.NetworkId) {
+		native.Register(GET_SIDE_CHAINS, GetSideChains)
+		native.Register(GET_SIDE_CHAIN_REQUESTS, GetSideChainRequests)
+	}
 }
 
 func RegisterSideChain(native *native.NativeService) ([]byte, error) {
@@ -76,6 +83,10 @@ func RegisterSideChain(native *native.NativeService) ([]byte, error) {
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("RegisterSideChain, checkWitness error: %v", err)
 	}
+	params.FinalityPolicy, err = checkFinalityPolicy(native, params.FinalityPolicy)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterSideChain, checkFinalityPolicy error: %v", err)
+	}
 
 	registerSideChain, err := getSideChainApply(native, params.ChainId)
 	if err != nil {
@@ -92,13 +103,14 @@ func RegisterSideChain(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("RegisterSideChain, chainid already registered")
 	}
 	sideChain = &SideChain{
-		Address:      params.Address,
-		ChainId:      params.ChainId,
-		Router:       params.Router,
-		Name:         params.Name,
-		BlocksToWait: params.BlocksToWait,
-		CCMCAddress:  params.CCMCAddress,
-		ExtraInfo:    params.ExtraInfo,
+		Address:        params.Address,
+		ChainId:        params.ChainId,
+		Router:         params.Router,
+		Name:           params.Name,
+		BlocksToWait:   params.BlocksToWait,
+		CCMCAddress:    params.CCMCAddress,
+		ExtraInfo:      params.ExtraInfo,
+		FinalityPolicy: params.FinalityPolicy,
 	}
 	err = putSideChainApply(native, sideChain)
 	if err != nil {
@@ -166,6 +178,10 @@ func UpdateSideChain(native *native.NativeService) ([]byte, error) {
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("UpdateSideChain, checkWitness error: %v", err)
 	}
+	params.FinalityPolicy, err = checkFinalityPolicy(native, params.FinalityPolicy)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("UpdateSideChain, checkFinalityPolicy error: %v", err)
+	}
 
 	sideChain, err := GetSideChain(native, params.ChainId)
 	if err != nil {
@@ -178,13 +194,14 @@ func UpdateSideChain(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("UpdateSideChain, side chain owner is wrong")
 	}
 	updateSideChain := &SideChain{
-		Address:      params.Address,
-		ChainId:      params.ChainId,
-		Router:       params.Router,
-		Name:         params.Name,
-		BlocksToWait: params.BlocksToWait,
-		CCMCAddress:  params.CCMCAddress,
-		ExtraInfo:    params.ExtraInfo,
+		Address:        params.Address,
+		ChainId:        params.ChainId,
+		Router:         params.Router,
+		Name:           params.Name,
+		BlocksToWait:   params.BlocksToWait,
+		CCMCAddress:    params.CCMCAddress,
+		ExtraInfo:      params.ExtraInfo,
+		FinalityPolicy: params.FinalityPolicy,
 	}
 	err = putUpdateSideChain(native, updateSideChain)
 	if err != nil {
@@ -336,6 +353,9 @@ func RegisterRedeem(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("RegisterRedeem, previous version is %d and your version should "+
 			"be %d not %d", contract.Ver, contract.Ver+1, params.CVersion)
 	}
+	if err := native.UseSigVerifyGas(len(params.Signs)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("RegisterRedeem, %v", err)
+	}
 	verified, err := verifyRedeemRegister(params, addrs)
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("RegisterRedeem, failed to verify: %v", err)
@@ -408,6 +428,9 @@ func SetBtcTxParam(native *native.NativeService) ([]byte, error) {
 	if len(info.BindSignInfo) >= m {
 		return utils.BYTE_FALSE, fmt.Errorf("SetBtcTxParam, the signatures are already enough")
 	}
+	if err := native.UseSigVerifyGas(len(params.Sigs)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetBtcTxParam, %v", err)
+	}
 	verified, err := verifyBtcTxParam(params, addrs)
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("SetBtcTxParam, failed to verify: %v", err)
@@ -431,3 +454,40 @@ func SetBtcTxParam(native *native.NativeService) ([]byte, error) {
 	}
 	return utils.BYTE_TRUE, nil
 }
+
+func GetSideChains(native *native.NativeService) ([]byte, error) {
+	sideChains, err := listSideChains(native, SIDE_CHAIN)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetSideChains, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	if err := (&SideChainList{SideChains: sideChains}).Serialization(sink); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetSideChains, serialize side chains error: %v", err)
+	}
+	return sink.Bytes(), nil
+}
+
+func GetSideChainRequests(native *native.NativeService) ([]byte, error) {
+	registers, err := listSideChains(native, SIDE_CHAIN_APPLY)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetSideChainRequests, %v", err)
+	}
+	updates, err := listSideChains(native, UPDATE_SIDE_CHAIN_REQUEST)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetSideChainRequests, %v", err)
+	}
+	quits, err := listQuitSideChains(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetSideChainRequests, %v", err)
+	}
+	requests := &SideChainRequests{
+		Registers: &SideChainList{SideChains: registers},
+		Updates:   &SideChainList{SideChains: updates},
+		Quits:     quits,
+	}
+	sink := common.NewZeroCopySink(nil)
+	if err := requests.Serialization(sink); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetSideChainRequests, serialize requests error: %v", err)
+	}
+	return sink.Bytes(), nil
+}
@@ -19,21 +19,104 @@ package side_chain_manager
 
 import (
 	"fmt"
+	"math"
 	"sort"
 
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/core/ledger"
+	"github.com/polynetwork/poly/native/service/utils"
 )
 
+const (
+	//proof height should be confirmed by Confirmations blocks including itself
+	FINALITY_CONFIRMATIONS uint8 = 1
+	//proof height should be covered by a checkpoint, which is a multiple of CheckpointInterval
+	//confirmed by Confirmations blocks
+	FINALITY_CHECKPOINT uint8 = 2
+	//headers are synced only after finalized by side chain, proof height should be synced
+	FINALITY_FINALIZED uint8 = 3
+)
+
+// FinalityPolicy decides whether a proof height of side chain is final according to the synced header height
+type FinalityPolicy struct {
+	Mode               uint8
+	Confirmations      uint64
+	CheckpointInterval uint64
+}
+
+func (this *FinalityPolicy) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint8(this.Mode)
+	sink.WriteVarUint(this.Confirmations)
+	sink.WriteVarUint(this.CheckpointInterval)
+}
+
+func (this *FinalityPolicy) Deserialization(source *common.ZeroCopySource) error {
+	mode, eof := source.NextUint8()
+	if eof {
+		return fmt.Errorf("source.NextUint8, deserialize mode error")
+	}
+	confirmations, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize confirmations error")
+	}
+	checkpointInterval, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize checkpointInterval error")
+	}
+	this.Mode = mode
+	this.Confirmations = confirmations
+	this.CheckpointInterval = checkpointInterval
+	return nil
+}
+
+// Validate checks the parameters required by mode
+func (this *FinalityPolicy) Validate() error {
+	switch this.Mode {
+	case FINALITY_CONFIRMATIONS:
+		if this.Confirmations == 0 {
+			return fmt.Errorf("minimal value of Confirmations is 1")
+		}
+	case FINALITY_CHECKPOINT:
+		if this.Confirmations == 0 || this.CheckpointInterval == 0 {
+			return fmt.Errorf("minimal value of Confirmations and CheckpointInterval is 1")
+		}
+	case FINALITY_FINALIZED:
+	default:
+		return fmt.Errorf("unknown finality mode %d", this.Mode)
+	}
+	return nil
+}
+
+// FinalizedHeight returns the highest final height when current is the height of latest synced header,
+// false is returned when no height is final yet
+func (this *FinalityPolicy) FinalizedHeight(current uint64) (uint64, bool) {
+	switch this.Mode {
+	case FINALITY_CONFIRMATIONS:
+		if current+1 < this.Confirmations {
+			return 0, false
+		}
+		return current + 1 - this.Confirmations, true
+	case FINALITY_CHECKPOINT:
+		if current+1 < this.Confirmations {
+			return 0, false
+		}
+		return (current + 1 - this.Confirmations) / this.CheckpointInterval * this.CheckpointInterval, true
+	case FINALITY_FINALIZED:
+		return current, true
+	}
+	return 0, false
+}
+
 type SideChain struct {
-	Address      common.Address
-	ChainId      uint64
-	Router       uint64
-	Name         string
-	BlocksToWait uint64
-	CCMCAddress  []byte
-	ExtraInfo    []byte
+	Address        common.Address
+	ChainId        uint64
+	Router         uint64
+	Name           string
+	BlocksToWait   uint64
+	CCMCAddress    []byte
+	ExtraInfo      []byte
+	FinalityPolicy *FinalityPolicy
 }
 
 func (this *SideChain) Serialization(sink *common.ZeroCopySink) error {
@@ -46,6 +129,10 @@ func (this *SideChain) Serialization(sink *common.ZeroCopySink) error {
 	height := config.GetExtraInfoHeight(config.DefConfig.P2PNode.NetworkId)
 	if !config.EXTRA_INFO_HEIGHT_FORK_CHECK || ledger.DefLedger.GetCurrentBlockHeight() >= height {
 		sink.WriteVarBytes(this.ExtraInfo)
+		//only set from FINALITY_POLICY_HEIGHT, records without it keep the former encoding
+		if this.FinalityPolicy != nil {
+			this.FinalityPolicy.Serialization(sink)
+		}
 	}
 	return nil
 }
@@ -88,6 +175,119 @@ func (this *SideChain) Deserialization(source *common.ZeroCopySource) error {
 	this.BlocksToWait = blocksToWait
 	this.CCMCAddress = CCMCAddress
 	this.ExtraInfo = ExtraInfo
+	finalityPolicy, err := deserializeFinalityPolicy(source)
+	if err != nil {
+		return err
+	}
+	this.FinalityPolicy = finalityPolicy
+	return nil
+}
+
+// GetFinalityPolicy returns the finality policy of side chain at height. Once policies are active,
+// chains registered without a policy whose handlers used to wait for BlocksToWait get the equivalent
+// confirmations policy. nil is returned for chains finalized by their header sync, and for all chains
+// before the activation, whose handlers still check the confirmations
+func (this *SideChain) GetFinalityPolicy(height uint32) *FinalityPolicy {
+	if !FinalityPolicyActive(height) {
+		return nil
+	}
+	if this.FinalityPolicy != nil {
+		return this.FinalityPolicy
+	}
+	switch this.Router {
+	case utils.BTC_ROUTER, utils.ETH_ROUTER, utils.BSC_ROUTER, utils.HECO_ROUTER, utils.MSC_ROUTER, utils.POLYGON_BOR_ROUTER:
+		confirmations := this.BlocksToWait
+		if confirmations == 0 {
+			//former handlers never confirmed a transaction when BlocksToWait is 0
+			confirmations = math.MaxUint64
+		}
+		return &FinalityPolicy{Mode: FINALITY_CONFIRMATIONS, Confirmations: confirmations}
+	}
+	return nil
+}
+
+type SideChainList struct {
+	SideChains []*SideChain
+}
+
+func (this *SideChainList) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteVarUint(uint64(len(this.SideChains)))
+	for _, v := range this.SideChains {
+		//side chain has optional trailing fields, so each one is length prefixed
+		s := common.NewZeroCopySink(nil)
+		if err := v.Serialization(s); err != nil {
+			return err
+		}
+		sink.WriteVarBytes(s.Bytes())
+	}
+	return nil
+}
+
+func (this *SideChainList) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize side chains length error")
+	}
+	sideChains := make([]*SideChain, 0)
+	for i := uint64(0); i < n; i++ {
+		raw, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("source.NextVarBytes, deserialize side chain error")
+		}
+		sideChain := new(SideChain)
+		if err := sideChain.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+			return fmt.Errorf("deserialize side chain error: %v", err)
+		}
+		sideChains = append(sideChains, sideChain)
+	}
+	this.SideChains = sideChains
+	return nil
+}
+
+type SideChainRequests struct {
+	Registers *SideChainList
+	Updates   *SideChainList
+	Quits     []uint64
+}
+
+func (this *SideChainRequests) Serialization(sink *common.ZeroCopySink) error {
+	if err := this.Registers.Serialization(sink); err != nil {
+		return err
+	}
+	if err := this.Updates.Serialization(sink); err != nil {
+		return err
+	}
+	sink.WriteVarUint(uint64(len(this.Quits)))
+	for _, v := range this.Quits {
+		sink.WriteVarUint(v)
+	}
+	return nil
+}
+
+func (this *SideChainRequests) Deserialization(source *common.ZeroCopySource) error {
+	registers := new(SideChainList)
+	if err := registers.Deserialization(source); err != nil {
+		return fmt.Errorf("deserialize registers error: %v", err)
+	}
+	updates := new(SideChainList)
+	if err := updates.Deserialization(source); err != nil {
+		return fmt.Errorf("deserialize updates error: %v", err)
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize quits length error")
+	}
+	quits := make([]uint64, 0)
+	for i := uint64(0); i < n; i++ {
+		chainID, eof := source.NextVarUint()
+		if eof {
+			return fmt.Errorf("source.NextVarUint, deserialize quit chain id error")
+		}
+		quits = append(quits, chainID)
+	}
+	this.Registers = registers
+	this.Updates = updates
+	this.Quits = quits
 	return nil
 }
 
@@ -19,6 +19,8 @@ package side_chain_manager
 
 import (
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -38,3 +40,156 @@ func TestSideChain_Serialization(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, paramDeserialize, paramSerialize)
 }
+
+func TestSideChainFinalityPolicy_Serialization(t *testing.T) {
+	paramSerialize := &SideChain{
+		Name:         "own",
+		Router:       7,
+		ChainId:      8,
+		BlocksToWait: 10,
+		CCMCAddress:  []byte{2},
+		ExtraInfo:    []byte{1},
+		FinalityPolicy: &FinalityPolicy{
+			Mode:               FINALITY_CHECKPOINT,
+			Confirmations:      12,
+			CheckpointInterval: 256,
+		},
+	}
+	sink := common.NewZeroCopySink(nil)
+	err := paramSerialize.Serialization(sink)
+	assert.Nil(t, err)
+
+	paramDeserialize := new(SideChain)
+	err = paramDeserialize.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, paramDeserialize, paramSerialize)
+}
+
+func TestSideChainRequests_Serialization(t *testing.T) {
+	paramSerialize := &SideChainRequests{
+		Registers: &SideChainList{SideChains: []*SideChain{
+			{
+				Address:      common.Address{1},
+				ChainId:      8,
+				Router:       7,
+				Name:         "own",
+				BlocksToWait: 10,
+				CCMCAddress:  []byte{2},
+				ExtraInfo:    []byte{1},
+			},
+			{
+				Address:      common.Address{2},
+				ChainId:      9,
+				Router:       7,
+				Name:         "other",
+				BlocksToWait: 1,
+				CCMCAddress:  []byte{3},
+				ExtraInfo:    []byte{},
+				FinalityPolicy: &FinalityPolicy{
+					Mode:          FINALITY_CONFIRMATIONS,
+					Confirmations: 12,
+				},
+			},
+		}},
+		Updates: &SideChainList{SideChains: []*SideChain{}},
+		Quits:   []uint64{3, 5},
+	}
+	sink := common.NewZeroCopySink(nil)
+	err := paramSerialize.Serialization(sink)
+	assert.Nil(t, err)
+
+	paramDeserialize := new(SideChainRequests)
+	err = paramDeserialize.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, paramDeserialize, paramSerialize)
+}
+
+func TestFinalityPolicy_FinalizedHeight(t *testing.T) {
+	policy := &FinalityPolicy{Mode: FINALITY_CONFIRMATIONS, Confirmations: 10}
+	assert.Nil(t, policy.Validate())
+	_, ok := policy.FinalizedHeight(8)
+	assert.False(t, ok)
+	height, ok := policy.FinalizedHeight(100)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(91), height)
+
+	policy = &FinalityPolicy{Mode: FINALITY_CHECKPOINT, Confirmations: 10, CheckpointInterval: 64}
+	assert.Nil(t, policy.Validate())
+	height, ok = policy.FinalizedHeight(200)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(128), height)
+
+	policy = &FinalityPolicy{Mode: FINALITY_FINALIZED}
+	assert.Nil(t, policy.Validate())
+	height, ok = policy.FinalizedHeight(200)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(200), height)
+
+	assert.NotNil(t, (&FinalityPolicy{Mode: FINALITY_CONFIRMATIONS}).Validate())
+	assert.NotNil(t, (&FinalityPolicy{Mode: FINALITY_CHECKPOINT, Confirmations: 1}).Validate())
+	assert.NotNil(t, (&FinalityPolicy{Mode: 0}).Validate())
+}
+
+func TestSideChainFinalityPolicy_Malformed(t *testing.T) {
+	paramSerialize := &SideChain{
+		Name:         "own",
+		Router:       7,
+		ChainId:      8,
+		BlocksToWait: 10,
+		CCMCAddress:  []byte{2},
+		ExtraInfo:    []byte{1},
+	}
+	sink := common.NewZeroCopySink(nil)
+	err := paramSerialize.Serialization(sink)
+	assert.Nil(t, err)
+	//mode without confirmations and checkpoint interval
+	sink.WriteUint8(FINALITY_CONFIRMATIONS)
+
+	paramDeserialize := new(SideChain)
+	err = paramDeserialize.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NotNil(t, err)
+
+	register := &RegisterSideChainParam{
+		Address:      common.Address{1, 2, 3},
+		ChainId:      123,
+		Name:         "123456",
+		BlocksToWait: 1234,
+	}
+	sink = common.NewZeroCopySink(nil)
+	err = register.Serialization(sink)
+	assert.Nil(t, err)
+	sink.WriteUint8(FINALITY_CHECKPOINT)
+	sink.WriteVarUint(12)
+	err = new(RegisterSideChainParam).Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NotNil(t, err)
+}
+
+func TestSideChain_GetFinalityPolicy(t *testing.T) {
+	networkId, genesisConfig := config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	config.DefConfig.Genesis = &config.GenesisConfig{
+		ActivationHeights: map[string]uint32{string(config.FEATURE_FINALITY_POLICY): 10},
+	}
+	defer func() { config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis = networkId, genesisConfig }()
+
+	policy := &FinalityPolicy{Mode: FINALITY_FINALIZED}
+	sideChain := &SideChain{Router: utils.ETH_ROUTER, BlocksToWait: 12, FinalityPolicy: policy}
+	assert.Equal(t, policy, sideChain.GetFinalityPolicy(10))
+
+	sideChain = &SideChain{Router: utils.ETH_ROUTER, BlocksToWait: 12}
+	assert.Equal(t, &FinalityPolicy{Mode: FINALITY_CONFIRMATIONS, Confirmations: 12}, sideChain.GetFinalityPolicy(10))
+	height, ok := sideChain.GetFinalityPolicy(10).FinalizedHeight(100)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(89), height)
+
+	sideChain = &SideChain{Router: utils.BTC_ROUTER}
+	_, ok = sideChain.GetFinalityPolicy(10).FinalizedHeight(100)
+	assert.False(t, ok)
+
+	sideChain = &SideChain{Router: utils.NEO_ROUTER, BlocksToWait: 1}
+	assert.Nil(t, sideChain.GetFinalityPolicy(10))
+
+	//the chain handlers check the confirmations before the activation
+	sideChain = &SideChain{Router: utils.ETH_ROUTER, BlocksToWait: 12}
+	assert.Nil(t, sideChain.GetFinalityPolicy(9))
+}
@@ -19,12 +19,14 @@ package side_chain_manager
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcutil"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	cstates "github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/service/utils"
@@ -173,6 +175,83 @@ func putQuitSideChain(native *native.NativeService, chainid uint64) error {
 	return nil
 }
 
+// listSideChains returns the side chains stored under key prefix, sorted by chain id
+func listSideChains(native *native.NativeService, prefix string) ([]*SideChain, error) {
+	key := utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(prefix))
+	iter := native.GetCacheDB().NewIterator(key)
+	defer iter.Release()
+
+	sideChains := make([]*SideChain, 0)
+	for iter.Next() {
+		// skip keys of other prefixes starting with prefix, like sideChainApply for sideChain
+		if len(iter.Key()) != len(key)+8 {
+			continue
+		}
+		sideChainBytes, err := cstates.GetValueFromRawStorageItem(iter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("listSideChains, deserialize from raw storage item err:%v", err)
+		}
+		sideChain := new(SideChain)
+		if err := sideChain.Deserialization(common.NewZeroCopySource(sideChainBytes)); err != nil {
+			return nil, fmt.Errorf("listSideChains, deserialize sideChain error: %v", err)
+		}
+		sideChains = append(sideChains, sideChain)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("listSideChains, iterate %s error: %v", prefix, err)
+	}
+	sort.Slice(sideChains, func(i, j int) bool {
+		return sideChains[i].ChainId < sideChains[j].ChainId
+	})
+	return sideChains, nil
+}
+
+// listQuitSideChains returns the chain ids requested to quit, the side chains already quit are skipped
+func listQuitSideChains(native *native.NativeService) ([]uint64, error) {
+	key := utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(QUIT_SIDE_CHAIN_REQUEST))
+	iter := native.GetCacheDB().NewIterator(key)
+	defer iter.Release()
+
+	chainIDs := make([]uint64, 0)
+	for iter.Next() {
+		if len(iter.Key()) != len(key)+8 {
+			continue
+		}
+		chainID := utils.GetBytesUint64(iter.Key()[len(key):])
+		sideChain, err := GetSideChain(native, chainID)
+		if err != nil {
+			return nil, fmt.Errorf("listQuitSideChains, %v", err)
+		}
+		if sideChain != nil {
+			chainIDs = append(chainIDs, chainID)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("listQuitSideChains, iterate quit requests error: %v", err)
+	}
+	sort.Slice(chainIDs, func(i, j int) bool {
+		return chainIDs[i] < chainIDs[j]
+	})
+	return chainIDs, nil
+}
+
+// FinalityPolicyActive reports whether side chain finality policies are in force at height,
+// before that the chain handlers check the confirmations of txs themselves
+func FinalityPolicyActive(height uint32) bool {
+	return height >= config.GetActivationHeight(config.FEATURE_FINALITY_POLICY, config.DefConfig.P2PNode.NetworkId)
+}
+
+// checkFinalityPolicy drops the finality policy before FINALITY_POLICY_HEIGHT and validates it after
+func checkFinalityPolicy(native *native.NativeService, policy *FinalityPolicy) (*FinalityPolicy, error) {
+	if !FinalityPolicyActive(native.GetHeight()) || policy == nil {
+		return nil, nil
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
 func GetContractBind(native *native.NativeService, redeemChainID, contractChainID uint64,
 	redeemKey []byte) (*ContractBinded, error) {
 	contract := utils.SideChainManagerContractAddress
@@ -49,6 +49,10 @@ func NewHandler() *Handler {
 	return &Handler{}
 }
 
+func init() {
+	scom.RegisterHeaderSyncHandler(utils.BSC_ROUTER, 0, func() scom.HeaderSyncHandler { return NewHandler() })
+}
+
 // GenesisHeader ...
 type GenesisHeader struct {
 	Header         types.Header
@@ -205,35 +209,36 @@ type HeaderWithDifficultySum struct {
 }
 
 // SyncBlockHeader ...
-func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
+func (h *Handler) SyncBlockHeader(native *native.NativeService) (uint64, error) {
 	headerParams := new(scom.SyncBlockHeaderParam)
 	if err := headerParams.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
-		return fmt.Errorf("bsc Handler SyncBlockHeader, contract params deserialize error: %v", err)
+		return 0, fmt.Errorf("bsc Handler SyncBlockHeader, contract params deserialize error: %v", err)
 	}
 
 	side, err := side_chain_manager.GetSideChain(native, headerParams.ChainID)
 	if err != nil {
-		return fmt.Errorf("bsc Handler SyncBlockHeader, GetSideChain error: %v", err)
+		return 0, fmt.Errorf("bsc Handler SyncBlockHeader, GetSideChain error: %v", err)
 	}
 	var extraInfo ExtraInfo
 	err = json.Unmarshal(side.ExtraInfo, &extraInfo)
 	if err != nil {
-		return fmt.Errorf("bsc Handler SyncBlockHeader, ExtraInfo Unmarshal error: %v", err)
+		return 0, fmt.Errorf("bsc Handler SyncBlockHeader, ExtraInfo Unmarshal error: %v", err)
 	}
 
 	ctx := &Context{ExtraInfo: extraInfo, ChainID: headerParams.ChainID}
 
+	var stored uint64
 	for _, v := range headerParams.Headers {
 		var header types.Header
 		err := json.Unmarshal(v, &header)
 		if err != nil {
-			return fmt.Errorf("bsc Handler SyncBlockHeader, deserialize header err: %v", err)
+			return 0, fmt.Errorf("bsc Handler SyncBlockHeader, deserialize header err: %v", err)
 		}
 		headerHash := header.Hash()
 
 		exist, err := isHeaderExist(native, headerHash, ctx)
 		if err != nil {
-			return fmt.Errorf("bsc Handler SyncBlockHeader, isHeaderExist headerHash err: %v", err)
+			return 0, fmt.Errorf("bsc Handler SyncBlockHeader, isHeaderExist headerHash err: %v", err)
 		}
 		if exist {
 			log.Warnf("bsc Handler SyncBlockHeader, header has exist. Header: %s", string(v))
@@ -242,7 +247,7 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 
 		parentExist, err := isHeaderExist(native, header.ParentHash, ctx)
 		if err != nil {
-			return fmt.Errorf("bsc Handler SyncBlockHeader, isHeaderExist ParentHash err: %v", err)
+			return 0, fmt.Errorf("bsc Handler SyncBlockHeader, isHeaderExist ParentHash err: %v", err)
 		}
 		if !parentExist {
 			log.Warnf("bsc Handler SyncBlockHeader, parent header not exist. Header: %s", string(v))
@@ -251,13 +256,13 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 
 		signer, err := verifySignature(native, &header, ctx)
 		if err != nil {
-			return fmt.Errorf("bsc Handler SyncBlockHeader, verifySignature err: %v", err)
+			return 0, fmt.Errorf("bsc Handler SyncBlockHeader, verifySignature err: %v", err)
 		}
 
 		// get prev epochs, also checking recent limit
 		phv, pphv, lastSeenHeight, err := getPrevHeightAndValidators(native, &header, ctx)
 		if err != nil {
-			return fmt.Errorf("bsc Handler SyncBlockHeader, getPrevHeightAndValidators err: %v", err)
+			return 0, fmt.Errorf("bsc Handler SyncBlockHeader, getPrevHeightAndValidators err: %v", err)
 		}
 
 		var (
@@ -276,13 +281,13 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 		if lastSeenHeight > 0 {
 			limit := int64(len(inTurnHV.Validators) / 2)
 			if header.Number.Int64() <= lastSeenHeight+limit {
-				return fmt.Errorf("bsc Handler SyncBlockHeader, RecentlySigned, lastSeenHeight:%d currentHeight:%d #V:%d", lastSeenHeight, header.Number.Int64(), len(inTurnHV.Validators))
+				return 0, fmt.Errorf("bsc Handler SyncBlockHeader, RecentlySigned, lastSeenHeight:%d currentHeight:%d #V:%d", lastSeenHeight, header.Number.Int64(), len(inTurnHV.Validators))
 			}
 		}
 
 		indexInTurn := int(header.Number.Uint64()) % len(inTurnHV.Validators)
 		if indexInTurn < 0 {
-			return fmt.Errorf("indexInTurn is negative:%d inTurnHV.Height:%d header.Number:%d", indexInTurn, inTurnHV.Height.Int64(), header.Number.Int64())
+			return 0, fmt.Errorf("indexInTurn is negative:%d inTurnHV.Height:%d header.Number:%d", indexInTurn, inTurnHV.Height.Int64(), header.Number.Int64())
 		}
 		valid := false
 		for idx, v := range inTurnHV.Validators {
@@ -290,27 +295,28 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 				valid = true
 				if indexInTurn == idx {
 					if header.Difficulty.Cmp(diffInTurn) != 0 {
-						return fmt.Errorf("invalid difficulty, got %v expect %v index:%v", header.Difficulty.Int64(), diffInTurn.Int64(), int(indexInTurn)%len(inTurnHV.Validators))
+						return 0, fmt.Errorf("invalid difficulty, got %v expect %v index:%v", header.Difficulty.Int64(), diffInTurn.Int64(), int(indexInTurn)%len(inTurnHV.Validators))
 					}
 				} else {
 					if header.Difficulty.Cmp(diffNoTurn) != 0 {
-						return fmt.Errorf("invalid difficulty, got %v expect %v index:%v", header.Difficulty.Int64(), diffNoTurn.Int64(), int(indexInTurn)%len(inTurnHV.Validators))
+						return 0, fmt.Errorf("invalid difficulty, got %v expect %v index:%v", header.Difficulty.Int64(), diffNoTurn.Int64(), int(indexInTurn)%len(inTurnHV.Validators))
 					}
 				}
 			}
 		}
 		if !valid {
-			return fmt.Errorf("bsc Handler SyncBlockHeader, invalid signer")
+			return 0, fmt.Errorf("bsc Handler SyncBlockHeader, invalid signer")
 		}
 
 		err = addHeader(native, &header, phv, ctx)
 		if err != nil {
-			return fmt.Errorf("bsc Handler SyncBlockHeader, addHeader err: %v", err)
+			return 0, fmt.Errorf("bsc Handler SyncBlockHeader, addHeader err: %v", err)
 		}
 
 		scom.NotifyPutHeader(native, headerParams.ChainID, header.Number.Uint64(), header.Hash().Hex())
+		stored++
 	}
-	return nil
+	return stored, nil
 }
 
 func isHeaderExist(native *native.NativeService, headerHash ecommon.Hash, ctx *Context) (bool, error) {
@@ -450,6 +456,9 @@ func addHeader(native *native.NativeService, header *types.Header, phv *HeightAn
 				break
 			}
 
+			if err = scom.ReplaceMainChainHash(native, ctx.ChainID, i, nil); err != nil {
+				return
+			}
 			deleteCanonicalHash(native, ctx.ChainID, i)
 		}
 
@@ -470,6 +479,9 @@ func addHeader(native *native.NativeService, header *types.Header, phv *HeightAn
 				break
 			}
 
+			if err = scom.ReplaceMainChainHash(native, ctx.ChainID, cheight, headHash.Bytes()); err != nil {
+				return
+			}
 			putCanonicalHash(native, ctx.ChainID, cheight, headHash)
 			headHeader, err = getHeader(native, headHash, ctx.ChainID)
 			if err != nil {
@@ -480,11 +492,15 @@ func addHeader(native *native.NativeService, header *types.Header, phv *HeightAn
 		}
 
 		// Extend the canonical chain with the new header
+		if err = scom.ReplaceMainChainHash(native, ctx.ChainID, header.Number.Uint64(), header.Hash().Bytes()); err != nil {
+			return
+		}
 		putCanonicalHash(native, ctx.ChainID, header.Number.Uint64(), header.Hash())
 		putCanonicalHeight(native, ctx.ChainID, header.Number.Uint64())
+		return scom.PruneSideHeaders(native, ctx.ChainID, header.Number.Uint64())
 	}
 
-	return nil
+	return scom.MarkSideHeader(native, ctx.ChainID, header.Number.Uint64(), header.Hash().Bytes())
 }
 
 // HeightAndValidators ...
@@ -842,6 +858,6 @@ func ParseValidators(validatorsBytes []byte) ([]ecommon.Address, error) {
 }
 
 // SyncCrossChainMsg ...
-func (h *Handler) SyncCrossChainMsg(native *native.NativeService) error {
-	return nil
+func (h *Handler) SyncCrossChainMsg(native *native.NativeService) (uint64, error) {
+	return 0, nil
 }
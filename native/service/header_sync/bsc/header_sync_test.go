@@ -444,7 +444,7 @@ func TestSyncBlockHeader(t *testing.T) {
 		}
 
 		// fmt.Println("gHeight", height)
-		err := handler.SyncBlockHeader(native)
+		_, err := handler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err), err)
 		latestHeight := getLatestHeight(native)
 		assert.Equal(t, latestHeight, height+4)
@@ -527,7 +527,7 @@ func TestSyncBlockHeaderForked(t *testing.T) {
 		}
 
 		// fmt.Println("gHeight", height)
-		err := handler.SyncBlockHeader(native)
+		_, err := handler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err), err)
 		latestHeight := getLatestHeight(native)
 		assert.Equal(t, latestHeight, height+9)
@@ -558,7 +558,7 @@ func TestSyncBlockHeaderForked(t *testing.T) {
 		}
 		native, _ = NewNative(sink.Bytes(), tx, native.GetCacheDB())
 
-		err = handler.SyncBlockHeader(native)
+		_, err = handler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err), err)
 		latestHeight := getLatestHeight(native)
 		assert.Equal(t, latestHeight, interestedHeight)
@@ -586,7 +586,7 @@ func TestSyncBlockHeaderForked(t *testing.T) {
 		}
 		native, _ = NewNative(sink.Bytes(), tx, native.GetCacheDB())
 
-		err = handler.SyncBlockHeader(native)
+		_, err = handler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err), err)
 		latestHeight = getLatestHeight(native)
 		assert.Equal(t, latestHeight, interestedHeight)
@@ -613,7 +613,7 @@ func TestSyncBlockHeaderForked(t *testing.T) {
 		}
 		native, _ = NewNative(sink.Bytes(), tx, native.GetCacheDB())
 
-		err = handler.SyncBlockHeader(native)
+		_, err = handler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err), err)
 		latestHeight = getLatestHeight(native)
 		assert.Equal(t, latestHeight, interestedHeight+1)
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bsc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+)
+
+func (h *Handler) GetCurrentHeight(native *native.NativeService, chainID uint64) (uint64, error) {
+	return GetCanonicalHeight(native, chainID)
+}
+
+// GetHeaderByHeight returns the json of HeaderWithDifficultySum
+func (h *Handler) GetHeaderByHeight(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	return scom.GetMainChainHeader(native, chainID, height)
+}
+
+// GetEpoch returns the json of HeightAndValidators which verifies the header at height
+func (h *Handler) GetEpoch(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	if height == 0 {
+		return nil, fmt.Errorf("bsc Handler GetEpoch, no epoch before height 1")
+	}
+	parentHash, err := getCanonicalHash(native, chainID, height-1)
+	if err != nil {
+		return nil, fmt.Errorf("bsc Handler GetEpoch, getCanonicalHash error: %v", err)
+	}
+	if parentHash == (ecommon.Hash{}) {
+		return nil, fmt.Errorf("bsc Handler GetEpoch, no canonical header at height %d", height-1)
+	}
+	phv, _, _, err := getPrevHeightAndValidators(native, &types.Header{ParentHash: parentHash}, &Context{ChainID: chainID})
+	if err != nil {
+		return nil, fmt.Errorf("bsc Handler GetEpoch, getPrevHeightAndValidators error: %v", err)
+	}
+	return json.Marshal(phv)
+}
+
+// GetGenesisHeader returns the json of GenesisHeader
+func (h *Handler) GetGenesisHeader(native *native.NativeService, chainID uint64) ([]byte, error) {
+	return scom.GetStoredGenesisHeader(native, chainID)
+}
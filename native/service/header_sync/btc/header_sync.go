@@ -41,6 +41,10 @@ func NewBTCHandler() *BTCHandler {
 	return &BTCHandler{}
 }
 
+func init() {
+	scom.RegisterHeaderSyncHandler(utils.BTC_ROUTER, 0, func() scom.HeaderSyncHandler { return NewBTCHandler() })
+}
+
 func (this *BTCHandler) SyncGenesisHeader(native *native.NativeService) error {
 	params := new(scom.SyncGenesisHeaderParam)
 	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
@@ -70,16 +74,17 @@ func (this *BTCHandler) SyncGenesisHeader(native *native.NativeService) error {
 	return nil
 }
 
-func (this *BTCHandler) SyncBlockHeader(native *native.NativeService) error {
+func (this *BTCHandler) SyncBlockHeader(native *native.NativeService) (uint64, error) {
 	headerParams := new(scom.SyncBlockHeaderParam)
 	if err := headerParams.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
-		return fmt.Errorf("SyncBlockHeader, contract params deserialize error: %v", err)
+		return 0, fmt.Errorf("SyncBlockHeader, contract params deserialize error: %v", err)
 	}
+	var stored uint64
 	for _, v := range headerParams.Headers {
 		var blockHeader wire.BlockHeader
 		err := blockHeader.Deserialize(bytes.NewBuffer(v))
 		if err != nil {
-			return fmt.Errorf("SyncBlockHeader, deserialize header err: %v", err)
+			return 0, fmt.Errorf("SyncBlockHeader, deserialize header err: %v", err)
 		}
 
 		_, err = GetHeaderByHash(native, headerParams.ChainID, blockHeader.BlockHash())
@@ -90,15 +95,16 @@ func (this *BTCHandler) SyncBlockHeader(native *native.NativeService) error {
 		//isBestHeader, commonAncestor, heightOfHeader, err := commitHeader(native, headerParams.ChainID, blockHeader)
 		_, _, _, err = commitHeader(native, headerParams.ChainID, blockHeader)
 		if err != nil {
-			return fmt.Errorf("SyncBlockHeader, commit header err: %v", err)
+			return 0, fmt.Errorf("SyncBlockHeader, commit header err: %v", err)
 		}
+		stored++
 
 	}
-	return nil
+	return stored, nil
 }
 
-func (this *BTCHandler) SyncCrossChainMsg(native *native.NativeService) error {
-	return nil
+func (this *BTCHandler) SyncCrossChainMsg(native *native.NativeService) (uint64, error) {
+	return 0, nil
 }
 
 func getGenesisHeader(input []byte) (*wire.BlockHeader, uint32, error) {
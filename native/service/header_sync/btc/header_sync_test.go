@@ -112,7 +112,7 @@ var (
 		sink := common.NewZeroCopySink(nil)
 		param.Serialization(sink)
 		ns := getNativeFunc(sink.Bytes(), db)
-		_ = handler.SyncBlockHeader(ns)
+		_, _ = handler.SyncBlockHeader(ns)
 	}
 
 	getForkInBytes = func() [][]byte {
@@ -159,7 +159,7 @@ func TestBTCHandler_SyncBlockHeader(t *testing.T) {
 	sink := common.NewZeroCopySink(nil)
 	param.Serialization(sink)
 	ns = getNativeFunc(sink.Bytes(), ns.GetCacheDB())
-	err := handler.SyncBlockHeader(ns)
+	_, err := handler.SyncBlockHeader(ns)
 	assert.NoError(t, err)
 
 	normal := getHeaders()
@@ -175,7 +175,7 @@ func TestBTCHandler_SyncBlockHeader(t *testing.T) {
 	sink.Reset()
 	param.Serialization(sink)
 	ns = getNativeFunc(sink.Bytes(), ns.GetCacheDB())
-	err = handler.SyncBlockHeader(ns)
+	_, err = handler.SyncBlockHeader(ns)
 	assert.NoError(t, err)
 	best, _ := GetBestBlockHeader(ns, 0)
 	assert.Equal(t, normal[len(normal)-1].BlockHash().String(), best.Header.BlockHash().String(), "wrong best")
@@ -192,7 +192,7 @@ func TestBTCHandler_SyncBlockHeader(t *testing.T) {
 	sink.Reset()
 	param.Serialization(sink)
 	ns = getNativeFunc(sink.Bytes(), ns.GetCacheDB())
-	err = handler.SyncBlockHeader(ns)
+	_, err = handler.SyncBlockHeader(ns)
 	assert.NoError(t, err)
 	best, _ = GetBestBlockHeader(ns, 0)
 	assert.Equal(t, forks[5].BlockHash().String(), best.Header.BlockHash().String(), "wrong best")
@@ -202,7 +202,7 @@ func TestBTCHandler_SyncBlockHeader(t *testing.T) {
 	}
 
 	// add replicated header
-	err = handler.SyncBlockHeader(ns)
+	_, err = handler.SyncBlockHeader(ns)
 	assert.NoError(t, err)
 
 	// orphan
@@ -211,6 +211,6 @@ func TestBTCHandler_SyncBlockHeader(t *testing.T) {
 	sink.Reset()
 	param.Serialization(sink)
 	ns = getNativeFunc(sink.Bytes(), ns.GetCacheDB())
-	err = handler.SyncBlockHeader(ns)
+	_, err = handler.SyncBlockHeader(ns)
 	assert.Error(t, err, "should be error")
 }
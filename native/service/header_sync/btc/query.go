@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+)
+
+func (this *BTCHandler) GetCurrentHeight(native *native.NativeService, chainID uint64) (uint64, error) {
+	bestHeader, err := GetBestBlockHeader(native, chainID)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(bestHeader.Height), nil
+}
+
+// GetHeaderByHeight returns the serialized StoredHeader
+func (this *BTCHandler) GetHeaderByHeight(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	if height > uint64(^uint32(0)) {
+		return nil, fmt.Errorf("GetHeaderByHeight, height %d out of range", height)
+	}
+	sh, err := GetHeaderByHeight(native, chainID, uint32(height))
+	if err != nil {
+		return nil, err
+	}
+	sink := common.NewZeroCopySink(nil)
+	sh.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// GetEpoch is not supported, btc headers are verified by proof of work
+func (this *BTCHandler) GetEpoch(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	return nil, scom.ErrQueryNotSupported
+}
+
+// GetGenesisHeader returns the serialized StoredHeader
+func (this *BTCHandler) GetGenesisHeader(native *native.NativeService, chainID uint64) ([]byte, error) {
+	return scom.GetStoredGenesisHeader(native, chainID)
+}
@@ -44,12 +44,15 @@ const (
 	SYNC_HEADER_NAME            = "syncHeader"
 	SYNC_CROSSCHAIN_MSG         = "syncCrossChainMsg"
 	POLYGON_SPAN                = "polygonSpan"
+	SIDE_HEADER                 = "sideHeader"
 )
 
+// HeaderSyncHandler syncs the headers of a side chain. SyncBlockHeader and SyncCrossChainMsg
+// return the number of headers or cross chain msgs stored, the ones skipped are not counted
 type HeaderSyncHandler interface {
 	SyncGenesisHeader(service *native.NativeService) error
-	SyncBlockHeader(service *native.NativeService) error
-	SyncCrossChainMsg(service *native.NativeService) error
+	SyncBlockHeader(service *native.NativeService) (uint64, error)
+	SyncCrossChainMsg(service *native.NativeService) (uint64, error)
 }
 
 type SyncGenesisHeaderParam struct {
@@ -162,6 +165,30 @@ func (this *SyncCrossChainMsgParam) Deserialization(source *common.ZeroCopySourc
 	return nil
 }
 
+type HeaderQueryParam struct {
+	ChainID uint64
+	Height  uint64
+}
+
+func (this *HeaderQueryParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.ChainID)
+	sink.WriteUint64(this.Height)
+}
+
+func (this *HeaderQueryParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("HeaderQueryParam deserialize chainID error")
+	}
+	height, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("HeaderQueryParam deserialize height error")
+	}
+	this.ChainID = chainID
+	this.Height = height
+	return nil
+}
+
 func NotifyPutHeader(native *native.NativeService, chainID uint64, height uint64, blockHash string) {
 	if !config.DefConfig.Common.EnableEventLog {
 		return
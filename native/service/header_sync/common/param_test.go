@@ -56,3 +56,19 @@ func TestSyncBlockHeaderParam(t *testing.T) {
 
 	assert.Equal(t, p, param)
 }
+
+func TestHeaderQueryParam(t *testing.T) {
+	param := HeaderQueryParam{
+		ChainID: 123,
+		Height:  456,
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+
+	var p HeaderQueryParam
+	err := p.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+
+	assert.Equal(t, p, param)
+}
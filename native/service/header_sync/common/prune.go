@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// SIDE_HEADER_PRUNE_DEPTH is the count of main chain headers synced above a height
+// before the superseded headers at that height are deleted
+const SIDE_HEADER_PRUNE_DEPTH = 1000
+
+// SideHeaderPruneActive tells whether superseded side chain headers are recorded and pruned
+func SideHeaderPruneActive(service *native.NativeService) bool {
+	return service.GetHeight() >= config.GetActivationHeight(config.FEATURE_SIDE_HEADER_PRUNE, config.DefConfig.P2PNode.NetworkId)
+}
+
+// MarkSideHeader records hash as a header at height which is not on the main chain
+func MarkSideHeader(service *native.NativeService, chainID uint64, height uint64, hash []byte) error {
+	if !SideHeaderPruneActive(service) {
+		return nil
+	}
+	hashes, err := getSideHeaders(service, chainID, height)
+	if err != nil {
+		return fmt.Errorf("MarkSideHeader, %v", err)
+	}
+	for _, v := range hashes {
+		if bytes.Equal(v, hash) {
+			return nil
+		}
+	}
+	hashes = append(hashes, hash)
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(uint64(len(hashes)))
+	for _, v := range hashes {
+		sink.WriteVarBytes(v)
+	}
+	service.GetCacheDB().Put(sideHeaderKey(chainID, height), cstates.GenRawStorageItem(sink.Bytes()))
+	return nil
+}
+
+// ReplaceMainChainHash marks the main chain header at height as superseded when it is not
+// hash, it is called before MAIN_CHAIN is overwritten or deleted (hash is nil)
+func ReplaceMainChainHash(service *native.NativeService, chainID uint64, height uint64, hash []byte) error {
+	if !SideHeaderPruneActive(service) {
+		return nil
+	}
+	old, err := GetStorageValue(service, mainChainKey(chainID, height))
+	if err != nil {
+		return fmt.Errorf("ReplaceMainChainHash, %v", err)
+	}
+	if old == nil || bytes.Equal(old, hash) {
+		return nil
+	}
+	return MarkSideHeader(service, chainID, height, old)
+}
+
+// PruneSideHeaders deletes the superseded headers recorded SIDE_HEADER_PRUNE_DEPTH below
+// height, a header which went back to the main chain is kept
+func PruneSideHeaders(service *native.NativeService, chainID uint64, height uint64) error {
+	if !SideHeaderPruneActive(service) || height < SIDE_HEADER_PRUNE_DEPTH {
+		return nil
+	}
+	height -= SIDE_HEADER_PRUNE_DEPTH
+	hashes, err := getSideHeaders(service, chainID, height)
+	if err != nil {
+		return fmt.Errorf("PruneSideHeaders, %v", err)
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	main, err := GetStorageValue(service, mainChainKey(chainID, height))
+	if err != nil {
+		return fmt.Errorf("PruneSideHeaders, %v", err)
+	}
+	contract := utils.HeaderSyncContractAddress
+	for _, hash := range hashes {
+		if bytes.Equal(hash, main) {
+			continue
+		}
+		service.GetCacheDB().Delete(utils.ConcatKey(contract, []byte(HEADER_INDEX), utils.GetUint64Bytes(chainID), hash))
+	}
+	service.GetCacheDB().Delete(sideHeaderKey(chainID, height))
+	return nil
+}
+
+func getSideHeaders(service *native.NativeService, chainID uint64, height uint64) ([][]byte, error) {
+	value, err := GetStorageValue(service, sideHeaderKey(chainID, height))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	source := common.NewZeroCopySource(value)
+	n, eof := source.NextVarUint()
+	if eof {
+		return nil, fmt.Errorf("getSideHeaders, deserialize count error")
+	}
+	hashes := make([][]byte, 0, n)
+	for i := uint64(0); i < n; i++ {
+		hash, eof := source.NextVarBytes()
+		if eof {
+			return nil, fmt.Errorf("getSideHeaders, deserialize hash error")
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+func sideHeaderKey(chainID uint64, height uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(SIDE_HEADER), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height))
+}
+
+func mainChainKey(chainID uint64, height uint64) []byte {
+	return utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height))
+}
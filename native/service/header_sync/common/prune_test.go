@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPruneNative(t *testing.T, height uint32) *native.NativeService {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	assert.NoError(t, err)
+	ns, err := native.NewNativeService(storage.NewCacheDB(overlaydb.NewOverlayDB(store)), new(types.Transaction),
+		0, height, common.Uint256{}, 0, nil, false)
+	assert.NoError(t, err)
+	return ns
+}
+
+func putPruneHeader(ns *native.NativeService, chainID uint64, hash []byte, main bool, height uint64) {
+	ns.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_INDEX), utils.GetUint64Bytes(chainID), hash),
+		cstates.GenRawStorageItem([]byte("header")))
+	if main {
+		ns.GetCacheDB().Put(mainChainKey(chainID, height), cstates.GenRawStorageItem(hash))
+	}
+}
+
+func hasPruneHeader(t *testing.T, ns *native.NativeService, chainID uint64, hash []byte) bool {
+	value, err := GetStorageValue(ns, utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(HEADER_INDEX),
+		utils.GetUint64Bytes(chainID), hash))
+	assert.NoError(t, err)
+	return value != nil
+}
+
+func TestPruneSideHeaders(t *testing.T) {
+	networkId, genesisConfig := config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	config.DefConfig.Genesis = &config.GenesisConfig{
+		ActivationHeights: map[string]uint32{string(config.FEATURE_SIDE_HEADER_PRUNE): 0},
+	}
+	defer func() { config.DefConfig.P2PNode.NetworkId, config.DefConfig.Genesis = networkId, genesisConfig }()
+
+	ns := newPruneNative(t, 1)
+	chainID := uint64(2)
+	main, side, reorged := []byte("main"), []byte("side"), []byte("reorged")
+	putPruneHeader(ns, chainID, main, true, 10)
+	putPruneHeader(ns, chainID, side, false, 10)
+	putPruneHeader(ns, chainID, reorged, false, 10)
+
+	assert.NoError(t, MarkSideHeader(ns, chainID, 10, side))
+	assert.NoError(t, MarkSideHeader(ns, chainID, 10, side))
+	// the main chain header is marked once it is replaced by reorged
+	assert.NoError(t, ReplaceMainChainHash(ns, chainID, 10, main))
+	assert.NoError(t, ReplaceMainChainHash(ns, chainID, 10, reorged))
+	hashes, err := getSideHeaders(ns, chainID, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{side, main}, hashes)
+
+	// main goes back to the main chain and is kept
+	ns.GetCacheDB().Put(mainChainKey(chainID, 10), cstates.GenRawStorageItem(main))
+
+	assert.NoError(t, PruneSideHeaders(ns, chainID, 10+SIDE_HEADER_PRUNE_DEPTH-1))
+	assert.True(t, hasPruneHeader(t, ns, chainID, side))
+
+	assert.NoError(t, PruneSideHeaders(ns, chainID, 10+SIDE_HEADER_PRUNE_DEPTH))
+	assert.False(t, hasPruneHeader(t, ns, chainID, side))
+	assert.True(t, hasPruneHeader(t, ns, chainID, main))
+	assert.True(t, hasPruneHeader(t, ns, chainID, reorged))
+	hashes, err = getSideHeaders(ns, chainID, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(hashes))
+
+	// headers of other chains are untouched
+	putPruneHeader(ns, 3, side, false, 10)
+	assert.NoError(t, MarkSideHeader(ns, 3, 10, side))
+	assert.NoError(t, PruneSideHeaders(ns, chainID, 10+SIDE_HEADER_PRUNE_DEPTH))
+	assert.True(t, hasPruneHeader(t, ns, 3, side))
+}
+
+func TestPruneSideHeadersInactive(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_MAIN_NET
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+
+	ns := newPruneNative(t, 1)
+	side := []byte("side")
+	putPruneHeader(ns, 2, side, false, 10)
+	assert.NoError(t, MarkSideHeader(ns, 2, 10, side))
+	hashes, err := getSideHeaders(ns, 2, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(hashes))
+
+	assert.NoError(t, PruneSideHeaders(ns, 2, 10+SIDE_HEADER_PRUNE_DEPTH))
+	assert.True(t, hasPruneHeader(t, ns, 2, side))
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"errors"
+	"fmt"
+
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// ErrQueryNotSupported is returned when a router does not keep the requested state
+var ErrQueryNotSupported = errors.New("query not supported by this router")
+
+// HeaderQueryHandler is implemented by the header sync handlers which serve the
+// read only methods of the header sync contract. All results are the bytes the
+// router keeps in storage, the current height is encoded as uint64 little endian.
+type HeaderQueryHandler interface {
+	// GetCurrentHeight returns the height of the latest synced header
+	GetCurrentHeight(service *native.NativeService, chainID uint64) (uint64, error)
+	// GetHeaderByHeight returns the stored canonical header at height
+	GetHeaderByHeight(service *native.NativeService, chainID uint64, height uint64) ([]byte, error)
+	// GetEpoch returns the validator set used to verify a header at height
+	GetEpoch(service *native.NativeService, chainID uint64, height uint64) ([]byte, error)
+	// GetGenesisHeader returns the stored genesis header
+	GetGenesisHeader(service *native.NativeService, chainID uint64) ([]byte, error)
+}
+
+// GetStorageValue returns the value stored under key of the header sync contract,
+// nil is returned when nothing is stored.
+func GetStorageValue(service *native.NativeService, key []byte) ([]byte, error) {
+	store, err := service.GetCacheDB().Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("GetStorageValue, get store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetStorageValue, deserialize from raw storage item err:%v", err)
+	}
+	return value, nil
+}
+
+// GetCurrentHeaderHeight reads CURRENT_HEADER_HEIGHT of the routers keeping it as uint64
+func GetCurrentHeaderHeight(service *native.NativeService, chainID uint64) (uint64, error) {
+	value, err := GetStorageValue(service, utils.ConcatKey(utils.HeaderSyncContractAddress,
+		[]byte(CURRENT_HEADER_HEIGHT), utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return 0, fmt.Errorf("GetCurrentHeaderHeight, %v", err)
+	}
+	if value == nil {
+		return 0, fmt.Errorf("GetCurrentHeaderHeight, no header synced for chain %d", chainID)
+	}
+	return utils.GetBytesUint64(value), nil
+}
+
+// GetMainChainHeader reads the canonical header of the routers indexing headers
+// by MAIN_CHAIN height and HEADER_INDEX hash
+func GetMainChainHeader(service *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	contract := utils.HeaderSyncContractAddress
+	hash, err := GetStorageValue(service, utils.ConcatKey(contract, []byte(MAIN_CHAIN),
+		utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)))
+	if err != nil {
+		return nil, fmt.Errorf("GetMainChainHeader, %v", err)
+	}
+	if hash == nil {
+		return nil, fmt.Errorf("GetMainChainHeader, no canonical header at height %d", height)
+	}
+	header, err := GetStorageValue(service, utils.ConcatKey(contract, []byte(HEADER_INDEX), utils.GetUint64Bytes(chainID), hash))
+	if err != nil {
+		return nil, fmt.Errorf("GetMainChainHeader, %v", err)
+	}
+	if header == nil {
+		return nil, fmt.Errorf("GetMainChainHeader, can not find header %x", hash)
+	}
+	return header, nil
+}
+
+// GetStoredGenesisHeader reads GENESIS_HEADER of chainID
+func GetStoredGenesisHeader(service *native.NativeService, chainID uint64) ([]byte, error) {
+	value, err := GetStorageValue(service, utils.ConcatKey(utils.HeaderSyncContractAddress,
+		[]byte(GENESIS_HEADER), utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return nil, fmt.Errorf("GetStoredGenesisHeader, %v", err)
+	}
+	if value == nil {
+		return nil, fmt.Errorf("GetStoredGenesisHeader, genesis header of chain %d is not synced", chainID)
+	}
+	return value, nil
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+package common
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// HeaderSyncHandlerFactory creates the header sync handler of a router
+type HeaderSyncHandlerFactory func() HeaderSyncHandler
+
+type headerSyncHandlerEntry struct {
+	factory      HeaderSyncHandlerFactory
+	activeHeight uint32
+}
+
+var (
+	handlersLock sync.RWMutex
+	handlers     = make(map[uint64]*headerSyncHandlerEntry)
+)
+
+// RegisterHeaderSyncHandler registers the header sync handler of router, the handler
+// can only be used by blocks at or above activeHeight. It is meant to be called
+// from the init function of a chain package and panics on duplicate routers.
+func RegisterHeaderSyncHandler(router uint64, activeHeight uint32, factory HeaderSyncHandlerFactory) {
+	handlersLock.Lock()
+	defer handlersLock.Unlock()
+	if factory == nil {
+		panic(fmt.Sprintf("RegisterHeaderSyncHandler, nil factory for router %d", router))
+	}
+	if _, ok := handlers[router]; ok {
+		panic(fmt.Sprintf("RegisterHeaderSyncHandler, router %d registered twice", router))
+	}
+	handlers[router] = &headerSyncHandlerEntry{factory: factory, activeHeight: activeHeight}
+}
+
+// GetHeaderSyncHandler returns the header sync handler of router which is active at height
+func GetHeaderSyncHandler(router uint64, height uint32) (HeaderSyncHandler, error) {
+	handlersLock.RLock()
+	entry, ok := handlers[router]
+	handlersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("not a supported router:%d", router)
+	}
+	if height < entry.activeHeight {
+		return nil, fmt.Errorf("router %d is not active until height %d", router, entry.activeHeight)
+	}
+	return entry.factory(), nil
+}
+
+// RegisteredRouters returns all the routers with a header sync handler in ascending order
+func RegisteredRouters() []uint64 {
+	handlersLock.RLock()
+	defer handlersLock.RUnlock()
+	routers := make([]uint64, 0, len(handlers))
+	for router := range handlers {
+		routers = append(routers, router)
+	}
+	sort.Slice(routers, func(i, j int) bool { return routers[i] < routers[j] })
+	return routers
+}
+
+// RouterActiveHeight returns the activation height of router
+func RouterActiveHeight(router uint64) (uint32, bool) {
+	handlersLock.RLock()
+	defer handlersLock.RUnlock()
+	entry, ok := handlers[router]
+	if !ok {
+		return 0, false
+	}
+	return entry.activeHeight, true
+}
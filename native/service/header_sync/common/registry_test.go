@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/native"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHandler struct{}
+
+func (h *mockHandler) SyncGenesisHeader(service *native.NativeService) error           { return nil }
+func (h *mockHandler) SyncBlockHeader(service *native.NativeService) (uint64, error)   { return 0, nil }
+func (h *mockHandler) SyncCrossChainMsg(service *native.NativeService) (uint64, error) { return 0, nil }
+
+func TestHeaderSyncHandlerRegistry(t *testing.T) {
+	factory := func() HeaderSyncHandler { return &mockHandler{} }
+	RegisterHeaderSyncHandler(1001, 0, factory)
+	RegisterHeaderSyncHandler(1000, 100, factory)
+
+	assert.Panics(t, func() { RegisterHeaderSyncHandler(1000, 0, factory) })
+	assert.Equal(t, []uint64{1000, 1001}, RegisteredRouters())
+
+	_, err := GetHeaderSyncHandler(1000, 99)
+	assert.Error(t, err)
+	handler, err := GetHeaderSyncHandler(1000, 100)
+	assert.NoError(t, err)
+	assert.NotNil(t, handler)
+	_, err = GetHeaderSyncHandler(1002, 100)
+	assert.Error(t, err)
+
+	height, ok := RouterActiveHeight(1000)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(100), height)
+}
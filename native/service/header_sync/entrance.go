@@ -20,26 +20,11 @@ package header_sync
 import (
 	"fmt"
 
-	"github.com/polynetwork/poly/native/service/header_sync/neo"
-	"github.com/polynetwork/poly/native/service/header_sync/neo3"
-	"github.com/polynetwork/poly/native/service/header_sync/neo3legacy"
-
-	"github.com/polynetwork/poly/native/service/header_sync/heco"
-	"github.com/polynetwork/poly/native/service/header_sync/msc"
-	"github.com/polynetwork/poly/native/service/header_sync/okex"
-	"github.com/polynetwork/poly/native/service/header_sync/polygon"
-
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
-	"github.com/polynetwork/poly/native/service/header_sync/bsc"
-	"github.com/polynetwork/poly/native/service/header_sync/btc"
 	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
-	"github.com/polynetwork/poly/native/service/header_sync/cosmos"
-	"github.com/polynetwork/poly/native/service/header_sync/eth"
-	"github.com/polynetwork/poly/native/service/header_sync/ont"
-	"github.com/polynetwork/poly/native/service/header_sync/quorum"
-	"github.com/polynetwork/poly/native/service/header_sync/zilliqa"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
@@ -47,6 +32,11 @@ const (
 	SYNC_GENESIS_HEADER  = "syncGenesisHeader"
 	SYNC_BLOCK_HEADER    = "syncBlockHeader"
 	SYNC_CROSS_CHAIN_MSG = "syncCrossChainMsg"
+
+	GET_CURRENT_HEIGHT   = "getCurrentHeight"
+	GET_HEADER_BY_HEIGHT = "getHeaderByHeight"
+	GET_EPOCH            = "getEpoch"
+	GET_GENESIS_HEADER   = "getGenesisHeader"
 )
 
 //Register methods of node_manager contract
@@ -54,43 +44,16 @@ func RegisterHeaderSyncContract(native *native.NativeService) {
 	native.Register(SYNC_GENESIS_HEADER, SyncGenesisHeader)
 	native.Register(SYNC_BLOCK_HEADER, SyncBlockHeader)
 	native.Register(SYNC_CROSS_CHAIN_MSG, SyncCrossChainMsg)
+
+	native.Register(GET_CURRENT_HEIGHT, GetCurrentHeight)
+	native.Register(GET_HEADER_BY_HEIGHT, GetHeaderByHeight)
+	native.Register(GET_EPOCH, GetEpoch)
+	native.Register(GET_GENESIS_HEADER, GetGenesisHeader)
 }
 
-func GetChainHandler(router uint64) (hscommon.HeaderSyncHandler, error) {
-	switch router {
-	case utils.BTC_ROUTER:
-		return btc.NewBTCHandler(), nil
-	case utils.ETH_ROUTER:
-		return eth.NewETHHandler(), nil
-	case utils.ONT_ROUTER:
-		return ont.NewONTHandler(), nil
-	case utils.NEO_ROUTER:
-		return neo.NewNEOHandler(), nil
-	case utils.NEO3_ROUTER:
-		return neo3.NewNeo3Handler(), nil
-	case utils.NEO3_LEGACY_ROUTER:
-		return neo3legacy.NewNeo3Handler(), nil
-	case utils.COSMOS_ROUTER:
-		return cosmos.NewCosmosHandler(), nil
-	case utils.QUORUM_ROUTER:
-		return quorum.NewQuorumHandler(), nil
-	case utils.BSC_ROUTER:
-		return bsc.NewHandler(), nil
-	case utils.HECO_ROUTER:
-		return heco.NewHecoHandler(), nil
-	case utils.ZILLIQA_ROUTER:
-		return zilliqa.NewHandler(), nil
-	case utils.MSC_ROUTER:
-		return msc.NewHandler(), nil
-	case utils.OKEX_ROUTER:
-		return okex.NewHandler(), nil
-	case utils.POLYGON_HEIMDALL_ROUTER:
-		return polygon.NewHeimdallHandler(), nil
-	case utils.POLYGON_BOR_ROUTER:
-		return polygon.NewBorHandler(), nil
-	default:
-		return nil, fmt.Errorf("not a supported router:%d", router)
-	}
+// GetChainHandler returns the header sync handler registered for router at height
+func GetChainHandler(router uint64, height uint32) (hscommon.HeaderSyncHandler, error) {
+	return hscommon.GetHeaderSyncHandler(router, height)
 }
 
 func SyncGenesisHeader(native *native.NativeService) ([]byte, error) {
@@ -98,6 +61,9 @@ func SyncGenesisHeader(native *native.NativeService) ([]byte, error) {
 	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("SyncGenesisHeader, contract params deserialize error: %v", err)
 	}
+	if err := native.UseHeaderVerifyGas(1); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SyncGenesisHeader, %v", err)
+	}
 	chainID := params.ChainID
 
 	//check if chainid exist
@@ -109,7 +75,7 @@ func SyncGenesisHeader(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("SyncGenesisHeader, side chain is not registered")
 	}
 
-	handler, err := GetChainHandler(sideChain.Router)
+	handler, err := GetChainHandler(sideChain.Router, native.GetHeight())
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
@@ -126,6 +92,12 @@ func SyncBlockHeader(native *native.NativeService) ([]byte, error) {
 	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("SyncBlockHeader, contract params deserialize error: %v", err)
 	}
+	if err := native.UseHeaderVerifyGas(len(params.Headers)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SyncBlockHeader, %v", err)
+	}
+	if err := relayer_manager.ChargeRelayer(native, uint64(len(params.Headers)), 0); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SyncBlockHeader, %v", err)
+	}
 	chainID := params.ChainID
 
 	//check if chainid exist
@@ -137,15 +109,18 @@ func SyncBlockHeader(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("SyncBlockHeader, side chain is not registered")
 	}
 
-	handler, err := GetChainHandler(sideChain.Router)
+	handler, err := GetChainHandler(sideChain.Router, native.GetHeight())
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
 
-	err = handler.SyncBlockHeader(native)
+	stored, err := handler.SyncBlockHeader(native)
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
+	if err := relayer_manager.RewardHeaders(native, params.Address, chainID, stored); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SyncBlockHeader, %v", err)
+	}
 	return utils.BYTE_TRUE, nil
 }
 
@@ -154,6 +129,12 @@ func SyncCrossChainMsg(native *native.NativeService) ([]byte, error) {
 	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("SyncCrossChainMsg, contract params deserialize error: %v", err)
 	}
+	if err := native.UseHeaderVerifyGas(len(params.CrossChainMsgs)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SyncCrossChainMsg, %v", err)
+	}
+	if err := relayer_manager.ChargeRelayer(native, uint64(len(params.CrossChainMsgs)), 0); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SyncCrossChainMsg, %v", err)
+	}
 	chainID := params.ChainID
 
 	//check if chainid exist
@@ -165,14 +146,91 @@ func SyncCrossChainMsg(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, fmt.Errorf("SyncCrossChainMsg, side chain is not registered")
 	}
 
-	handler, err := GetChainHandler(sideChain.Router)
+	handler, err := GetChainHandler(sideChain.Router, native.GetHeight())
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
 
-	err = handler.SyncCrossChainMsg(native)
+	stored, err := handler.SyncCrossChainMsg(native)
 	if err != nil {
 		return utils.BYTE_FALSE, err
 	}
+	if err := relayer_manager.RewardHeaders(native, params.Address, chainID, stored); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SyncCrossChainMsg, %v", err)
+	}
 	return utils.BYTE_TRUE, nil
 }
+
+func getQueryHandler(native *native.NativeService, method string) (*hscommon.HeaderQueryParam, hscommon.HeaderQueryHandler, error) {
+	params := new(hscommon.HeaderQueryParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return nil, nil, fmt.Errorf("%s, contract params deserialize error: %v", method, err)
+	}
+
+	//check if chainid exist
+	sideChain, err := side_chain_manager.GetSideChain(native, params.ChainID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s, side_chain_manager.GetSideChain error: %v", method, err)
+	}
+	if sideChain == nil {
+		return nil, nil, fmt.Errorf("%s, side chain is not registered", method)
+	}
+
+	handler, err := GetChainHandler(sideChain.Router, native.GetHeight())
+	if err != nil {
+		return nil, nil, err
+	}
+	queryHandler, ok := handler.(hscommon.HeaderQueryHandler)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s, %v: router %d", method, hscommon.ErrQueryNotSupported, sideChain.Router)
+	}
+	return params, queryHandler, nil
+}
+
+func GetCurrentHeight(native *native.NativeService) ([]byte, error) {
+	params, handler, err := getQueryHandler(native, "GetCurrentHeight")
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	height, err := handler.GetCurrentHeight(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetCurrentHeight, %v", err)
+	}
+	return utils.GetUint64Bytes(height), nil
+}
+
+func GetHeaderByHeight(native *native.NativeService) ([]byte, error) {
+	params, handler, err := getQueryHandler(native, "GetHeaderByHeight")
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	header, err := handler.GetHeaderByHeight(native, params.ChainID, params.Height)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetHeaderByHeight, %v", err)
+	}
+	return header, nil
+}
+
+func GetEpoch(native *native.NativeService) ([]byte, error) {
+	params, handler, err := getQueryHandler(native, "GetEpoch")
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	epoch, err := handler.GetEpoch(native, params.ChainID, params.Height)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetEpoch, %v", err)
+	}
+	return epoch, nil
+}
+
+func GetGenesisHeader(native *native.NativeService) ([]byte, error) {
+	params, handler, err := getQueryHandler(native, "GetGenesisHeader")
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	genesis, err := handler.GetGenesisHeader(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("GetGenesisHeader, %v", err)
+	}
+	return genesis, nil
+}
@@ -58,6 +58,10 @@ func NewETHHandler() *ETHHandler {
 	return &ETHHandler{}
 }
 
+func init() {
+	scom.RegisterHeaderSyncHandler(utils.ETH_ROUTER, 0, func() scom.HeaderSyncHandler { return NewETHHandler() })
+}
+
 func (this *ETHHandler) SyncGenesisHeader(native *native.NativeService) error {
 	params := new(scom.SyncGenesisHeaderParam)
 	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
@@ -97,22 +101,23 @@ func (this *ETHHandler) SyncGenesisHeader(native *native.NativeService) error {
 	return nil
 }
 
-func (this *ETHHandler) SyncBlockHeader(native *native.NativeService) error {
+func (this *ETHHandler) SyncBlockHeader(native *native.NativeService) (uint64, error) {
 	headerParams := new(scom.SyncBlockHeaderParam)
 	if err := headerParams.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
-		return fmt.Errorf("SyncBlockHeader, contract params deserialize error: %v", err)
+		return 0, fmt.Errorf("SyncBlockHeader, contract params deserialize error: %v", err)
 	}
 	caches := NewCaches(3, native)
+	var stored uint64
 	for _, v := range headerParams.Headers {
 		var header Header
 		err := json.Unmarshal(v, &header)
 		if err != nil {
-			return fmt.Errorf("SyncBlockHeader, deserialize header err: %v", err)
+			return 0, fmt.Errorf("SyncBlockHeader, deserialize header err: %v", err)
 		}
 		headerHash := header.Hash()
 		exist, err := IsHeaderExist(native, headerHash.Bytes(), headerParams.ChainID)
 		if err != nil {
-			return fmt.Errorf("SyncBlockHeader, check header exist err: %v", err)
+			return 0, fmt.Errorf("SyncBlockHeader, check header exist err: %v", err)
 		}
 		if exist == true {
 			log.Warnf("SyncBlockHeader, header has exist. Header: %s", string(v))
@@ -121,7 +126,7 @@ func (this *ETHHandler) SyncBlockHeader(native *native.NativeService) error {
 		// get pre header
 		parentHeader, parentDifficultySum, err := GetHeaderByHash(native, header.ParentHash.Bytes(), headerParams.ChainID)
 		if err != nil {
-			return fmt.Errorf("SyncBlockHeader, get the parent block failed. Error:%s, header: %s", err, string(v))
+			return 0, fmt.Errorf("SyncBlockHeader, get the parent block failed. Error:%s, header: %s", err, string(v))
 		}
 		parentHeaderHash := parentHeader.Hash()
 		/**
@@ -133,28 +138,28 @@ func (this *ETHHandler) SyncBlockHeader(native *native.NativeService) error {
 		*/
 		//verify whether parent hash validity
 		if !bytes.Equal(parentHeaderHash.Bytes(), header.ParentHash.Bytes()) {
-			return fmt.Errorf("SyncBlockHeader, parent header is not right. Header: %s", string(v))
+			return 0, fmt.Errorf("SyncBlockHeader, parent header is not right. Header: %s", string(v))
 		}
 		//verify whether extra size validity
 		if uint64(len(header.Extra)) > params.MaximumExtraDataSize {
-			return fmt.Errorf("SyncBlockHeader, SyncBlockHeader extra-data too long: %d > %d, header: %s", len(header.Extra), params.MaximumExtraDataSize, string(v))
+			return 0, fmt.Errorf("SyncBlockHeader, SyncBlockHeader extra-data too long: %d > %d, header: %s", len(header.Extra), params.MaximumExtraDataSize, string(v))
 		}
 		//verify current time validity
 		if header.Time > uint64(time.Now().Add(allowedFutureBlockTime).Unix()) {
-			return fmt.Errorf("SyncBlockHeader,  verify header time error:%s, checktime: %d, header: %s", consensus.ErrFutureBlock, time.Now().Add(allowedFutureBlockTime).Unix(), string(v))
+			return 0, fmt.Errorf("SyncBlockHeader,  verify header time error:%s, checktime: %d, header: %s", consensus.ErrFutureBlock, time.Now().Add(allowedFutureBlockTime).Unix(), string(v))
 		}
 		//verify whether current header time and prevent header time validity
 		if header.Time <= parentHeader.Time {
-			return fmt.Errorf("SyncBlockHeader, verify header time fail. Header: %s", string(v))
+			return 0, fmt.Errorf("SyncBlockHeader, verify header time fail. Header: %s", string(v))
 		}
 		// Verify that the gas limit is <= 2^63-1
 		cap := uint64(0x7fffffffffffffff)
 		if header.GasLimit > cap {
-			return fmt.Errorf("SyncBlockHeader, invalid gasLimit: have %v, max %v, header: %s", header.GasLimit, cap, string(v))
+			return 0, fmt.Errorf("SyncBlockHeader, invalid gasLimit: have %v, max %v, header: %s", header.GasLimit, cap, string(v))
 		}
 		// Verify that the gasUsed is <= gasLimit
 		if header.GasUsed > header.GasLimit {
-			return fmt.Errorf("SyncBlockHeader, invalid gasUsed: have %d, gasLimit %d, header: %s", header.GasUsed, header.GasLimit, string(v))
+			return 0, fmt.Errorf("SyncBlockHeader, invalid gasUsed: have %d, gasLimit %d, header: %s", header.GasUsed, header.GasLimit, string(v))
 		}
 		if isLondon(&header) {
 			err = VerifyEip1559Header(parentHeader, &header)
@@ -162,7 +167,7 @@ func (this *ETHHandler) SyncBlockHeader(native *native.NativeService) error {
 			err = VerifyGaslimit(parentHeader.GasLimit, header.GasLimit)
 		}
 		if err != nil {
-			return fmt.Errorf("SyncBlockHeader, err:%v", err)
+			return 0, fmt.Errorf("SyncBlockHeader, err:%v", err)
 		}
 
 		//verify difficulty
@@ -173,39 +178,45 @@ func (this *ETHHandler) SyncBlockHeader(native *native.NativeService) error {
 			expected = difficultyCalculator(new(big.Int).SetUint64(header.Time), parentHeader)
 		}
 		if expected.Cmp(header.Difficulty) != 0 {
-			return fmt.Errorf("SyncBlockHeader, invalid difficulty: have %v, want %v, header: %s", header.Difficulty, expected, string(v))
+			return 0, fmt.Errorf("SyncBlockHeader, invalid difficulty: have %v, want %v, header: %s", header.Difficulty, expected, string(v))
 		}
 		// verfify header
 		err = this.verifyHeader(&header, caches)
 		if err != nil {
-			return fmt.Errorf("SyncBlockHeader, verify header error: %v, header: %s", err, string(v))
+			return 0, fmt.Errorf("SyncBlockHeader, verify header error: %v, header: %s", err, string(v))
 		}
 		//block header storage
 		hederDifficultySum := new(big.Int).Add(header.Difficulty, parentDifficultySum)
 		err = putBlockHeader(native, header, hederDifficultySum, headerParams.ChainID)
 		if err != nil {
-			return fmt.Errorf("SyncGenesisHeader, put blockHeader error: %v, header: %s", err, string(v))
+			return 0, fmt.Errorf("SyncGenesisHeader, put blockHeader error: %v, header: %s", err, string(v))
 		}
 		// get current header of main
 		currentHeader, currentDifficultySum, err := GetCurrentHeader(native, headerParams.ChainID)
 		if err != nil {
-			return fmt.Errorf("SyncBlockHeader, get the current block failed. error:%s", err)
+			return 0, fmt.Errorf("SyncBlockHeader, get the current block failed. error:%s", err)
 		}
 		if bytes.Equal(currentHeader.Hash().Bytes(), header.ParentHash.Bytes()) {
-			appendHeader2Main(native, header.Number.Uint64(), headerHash, headerParams.ChainID)
+			err = appendHeader2Main(native, header.Number.Uint64(), headerHash, headerParams.ChainID)
+			if err != nil {
+				return 0, fmt.Errorf("SyncBlockHeader, %v", err)
+			}
 		} else {
 			//
 			if hederDifficultySum.Cmp(currentDifficultySum) > 0 {
 				RestructChain(native, currentHeader, &header, headerParams.ChainID)
+			} else if err = scom.MarkSideHeader(native, headerParams.ChainID, header.Number.Uint64(), headerHash.Bytes()); err != nil {
+				return 0, fmt.Errorf("SyncBlockHeader, %v", err)
 			}
 		}
+		stored++
 	}
 	caches.deleteCaches()
-	return nil
+	return stored, nil
 }
 
-func (this *ETHHandler) SyncCrossChainMsg(native *native.NativeService) error {
-	return nil
+func (this *ETHHandler) SyncCrossChainMsg(native *native.NativeService) (uint64, error) {
+	return 0, nil
 }
 
 func getGenesisHeader(input []byte) (Header, error) {
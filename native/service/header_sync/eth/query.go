@@ -0,0 +1,41 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eth
+
+import (
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+)
+
+func (this *ETHHandler) GetCurrentHeight(native *native.NativeService, chainID uint64) (uint64, error) {
+	return scom.GetCurrentHeaderHeight(native, chainID)
+}
+
+// GetHeaderByHeight returns the json of HeaderWithDifficultySum
+func (this *ETHHandler) GetHeaderByHeight(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	return scom.GetMainChainHeader(native, chainID, height)
+}
+
+// GetEpoch is not supported, eth headers are verified by proof of work
+func (this *ETHHandler) GetEpoch(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	return nil, scom.ErrQueryNotSupported
+}
+
+func (this *ETHHandler) GetGenesisHeader(native *native.NativeService, chainID uint64) ([]byte, error) {
+	return scom.GetStoredGenesisHeader(native, chainID)
+}
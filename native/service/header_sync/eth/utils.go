@@ -87,12 +87,15 @@ func putBlockHeader(native *native.NativeService, blockHeader Header, difficulty
 }
 func appendHeader2Main(native *native.NativeService, height uint64, txhash common.Hash, chainID uint64) error {
 	contract := utils.HeaderSyncContractAddress
+	if err := scom.ReplaceMainChainHash(native, chainID, height, txhash.Bytes()); err != nil {
+		return fmt.Errorf("appendHeader2Main, %v", err)
+	}
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.MAIN_CHAIN), utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)),
 		cstates.GenRawStorageItem(txhash.Bytes()))
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(scom.CURRENT_HEADER_HEIGHT),
 		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(utils.GetUint64Bytes(height)))
 	scom.NotifyPutHeader(native, chainID, height, txhash.String())
-	return nil
+	return scom.PruneSideHeaders(native, chainID, height)
 }
 func GetCurrentHeader(native *native.NativeService, chainID uint64) (*Header, *big.Int, error) {
 	height, err := GetCurrentHeaderHeight(native, chainID)
@@ -209,7 +212,9 @@ func RestructChain(native *native.NativeService, current, new *Header, chainID u
 	}
 	newHashs = append(newHashs, new.Hash())
 	for i := len(newHashs) - 1; i >= 0; i-- {
-		appendHeader2Main(native, ti, newHashs[i], chainID)
+		if err = appendHeader2Main(native, ti, newHashs[i], chainID); err != nil {
+			return fmt.Errorf("RestructChain %v", err)
+		}
 		ti++
 	}
 	return nil
@@ -548,7 +548,7 @@ func TestSyncBlockHeader(t *testing.T) {
 		native, _ = NewNative(sink.Bytes(), tx, native.GetCacheDB())
 
 		// fmt.Println("gHeight", height)
-		err = handler.SyncBlockHeader(native)
+		_, err = handler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err), err)
 		latestHeight := getLatestHeight(native)
 		assert.Equal(t, latestHeight, height+4)
@@ -582,7 +582,7 @@ func TestSyncBlockHeader(t *testing.T) {
 		}
 		native, _ = NewNative(sink.Bytes(), tx, native.GetCacheDB())
 
-		err := handler.SyncBlockHeader(native)
+		_, err := handler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err), err)
 		latestHeight := getLatestHeight(native)
 		assert.Equal(t, latestHeight, height+headerNumber)
@@ -679,7 +679,7 @@ func TestSyncForkBlockHeader(t *testing.T) {
 		}
 		native, _ = NewNative(sink.Bytes(), tx, native.GetCacheDB())
 
-		err := handler.SyncBlockHeader(native)
+		_, err := handler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err), err)
 		latestHeight := getLatestHeight(native)
 		assert.Equal(t, latestHeight, height+headerNumber)
@@ -731,7 +731,7 @@ func TestSyncForkBlockHeader(t *testing.T) {
 			SignedAddr: []common.Address{acct.Address},
 		}, native.GetCacheDB())
 
-		err := handler.SyncBlockHeader(native)
+		_, err := handler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err), err)
 		latestHeight := getLatestHeight(native)
 		assert.Equal(t, latestHeight, height+headerNumber)
@@ -767,7 +767,7 @@ func TestSyncForkBlockHeader(t *testing.T) {
 			SignedAddr: []common.Address{acct.Address},
 		}, native.GetCacheDB())
 
-		err = handler.SyncBlockHeader(native)
+		_, err = handler.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err), err)
 		latestHeight = getLatestHeight(native)
 		assert.Equal(t, latestHeight, height+headerNumber+newHeaderNum)
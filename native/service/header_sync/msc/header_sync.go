@@ -48,6 +48,10 @@ func NewHandler() *Handler {
 	return &Handler{}
 }
 
+func init() {
+	scom.RegisterHeaderSyncHandler(utils.MSC_ROUTER, 0, func() scom.HeaderSyncHandler { return NewHandler() })
+}
+
 // SyncGenesisHeader ...
 func (h *Handler) SyncGenesisHeader(native *native.NativeService) (err error) {
 	params := new(scom.SyncGenesisHeaderParam)
@@ -200,35 +204,36 @@ type HeaderWithDifficultySum struct {
 }
 
 // SyncBlockHeader ...
-func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
+func (h *Handler) SyncBlockHeader(native *native.NativeService) (uint64, error) {
 	headerParams := new(scom.SyncBlockHeaderParam)
 	if err := headerParams.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
-		return fmt.Errorf("msc Handler SyncBlockHeader, contract params deserialize error: %v", err)
+		return 0, fmt.Errorf("msc Handler SyncBlockHeader, contract params deserialize error: %v", err)
 	}
 
 	side, err := side_chain_manager.GetSideChain(native, headerParams.ChainID)
 	if err != nil {
-		return fmt.Errorf("msc Handler SyncBlockHeader, GetSideChain error: %v", err)
+		return 0, fmt.Errorf("msc Handler SyncBlockHeader, GetSideChain error: %v", err)
 	}
 	var extraInfo ExtraInfo
 	err = json.Unmarshal(side.ExtraInfo, &extraInfo)
 	if err != nil {
-		return fmt.Errorf("msc Handler SyncBlockHeader, ExtraInfo Unmarshal error: %v", err)
+		return 0, fmt.Errorf("msc Handler SyncBlockHeader, ExtraInfo Unmarshal error: %v", err)
 	}
 
 	ctx := &Context{ExtraInfo: extraInfo, ChainID: headerParams.ChainID}
 
+	var stored uint64
 	for _, v := range headerParams.Headers {
 		var header types.Header
 		err := json.Unmarshal(v, &header)
 		if err != nil {
-			return fmt.Errorf("msc Handler SyncBlockHeader, deserialize header err: %v", err)
+			return 0, fmt.Errorf("msc Handler SyncBlockHeader, deserialize header err: %v", err)
 		}
 		headerHash := header.Hash()
 
 		exist, err := isHeaderExist(native, headerHash, ctx)
 		if err != nil {
-			return fmt.Errorf("msc Handler SyncBlockHeader, isHeaderExist headerHash err: %v", err)
+			return 0, fmt.Errorf("msc Handler SyncBlockHeader, isHeaderExist headerHash err: %v", err)
 		}
 		if exist {
 			log.Warnf("msc Handler SyncBlockHeader, header has exist. Header: %s", string(v))
@@ -237,7 +242,7 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 
 		parentExist, err := isHeaderExist(native, header.ParentHash, ctx)
 		if err != nil {
-			return fmt.Errorf("msc Handler SyncBlockHeader, isHeaderExist ParentHash err: %v", err)
+			return 0, fmt.Errorf("msc Handler SyncBlockHeader, isHeaderExist ParentHash err: %v", err)
 		}
 		if !parentExist {
 			log.Warnf("msc Handler SyncBlockHeader, parent header not exist. Header: %s", string(v))
@@ -246,17 +251,18 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 
 		err = verifyHeader(native, &header, ctx)
 		if err != nil {
-			return fmt.Errorf("msc Handler SyncBlockHeader, verifyHeader err: %v", err)
+			return 0, fmt.Errorf("msc Handler SyncBlockHeader, verifyHeader err: %v", err)
 		}
 
 		err = addHeader(native, &header, ctx)
 		if err != nil {
-			return fmt.Errorf("msc Handler SyncBlockHeader, addHeader err: %v", err)
+			return 0, fmt.Errorf("msc Handler SyncBlockHeader, addHeader err: %v", err)
 		}
 
 		scom.NotifyPutHeader(native, headerParams.ChainID, header.Number.Uint64(), header.Hash().Hex())
+		stored++
 	}
-	return nil
+	return stored, nil
 }
 
 func isHeaderExist(native *native.NativeService, headerHash ecommon.Hash, ctx *Context) (bool, error) {
@@ -405,6 +411,9 @@ func addHeader(native *native.NativeService, header *types.Header, ctx *Context)
 				break
 			}
 
+			if err = scom.ReplaceMainChainHash(native, ctx.ChainID, i, nil); err != nil {
+				return
+			}
 			deleteCanonicalHash(native, ctx.ChainID, i)
 		}
 
@@ -425,6 +434,9 @@ func addHeader(native *native.NativeService, header *types.Header, ctx *Context)
 				break
 			}
 
+			if err = scom.ReplaceMainChainHash(native, ctx.ChainID, cheight, headHash.Bytes()); err != nil {
+				return
+			}
 			putCanonicalHash(native, ctx.ChainID, cheight, headHash)
 			headHeader, err = getHeader(native, headHash, ctx.ChainID)
 			if err != nil {
@@ -435,11 +447,15 @@ func addHeader(native *native.NativeService, header *types.Header, ctx *Context)
 		}
 
 		// Extend the canonical chain with the new header
+		if err = scom.ReplaceMainChainHash(native, ctx.ChainID, header.Number.Uint64(), header.Hash().Bytes()); err != nil {
+			return
+		}
 		putCanonicalHash(native, ctx.ChainID, header.Number.Uint64(), header.Hash())
 		putCanonicalHeight(native, ctx.ChainID, header.Number.Uint64())
+		return scom.PruneSideHeaders(native, ctx.ChainID, header.Number.Uint64())
 	}
 
-	return nil
+	return scom.MarkSideHeader(native, ctx.ChainID, header.Number.Uint64(), header.Hash().Bytes())
 }
 
 func snapshot(native *native.NativeService, number uint64, hash ecommon.Hash, targetSigner ecommon.Address, ctx *Context) (snap *Snapshot, lastSeenHeight uint64, err error) {
@@ -806,6 +822,6 @@ func ecrecover(header *types.Header) (ecommon.Address, error) {
 }
 
 // SyncCrossChainMsg ...
-func (h *Handler) SyncCrossChainMsg(native *native.NativeService) error {
-	return nil
+func (h *Handler) SyncCrossChainMsg(native *native.NativeService) (uint64, error) {
+	return 0, nil
 }
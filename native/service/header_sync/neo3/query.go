@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package neo3
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+)
+
+// GetCurrentHeight returns the height of the latest header changing the next
+// consensus, headers in between are not kept
+func (this *Neo3Handler) GetCurrentHeight(native *native.NativeService, chainID uint64) (uint64, error) {
+	neoConsensus, err := getConsensusValByChainId(native, chainID)
+	if err != nil {
+		return 0, fmt.Errorf("neo3 GetCurrentHeight, %v", err)
+	}
+	return uint64(neoConsensus.Height), nil
+}
+
+func (this *Neo3Handler) GetHeaderByHeight(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	return nil, hscommon.ErrQueryNotSupported
+}
+
+// GetEpoch returns the serialized NeoConsensus, only the latest one is kept
+func (this *Neo3Handler) GetEpoch(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	neoConsensus, err := getConsensusValByChainId(native, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("neo3 GetEpoch, %v", err)
+	}
+	if height < uint64(neoConsensus.Height) {
+		return nil, fmt.Errorf("neo3 GetEpoch, consensus at height %d is replaced by the one at %d", height, neoConsensus.Height)
+	}
+	sink := common.NewZeroCopySink(nil)
+	neoConsensus.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+func (this *Neo3Handler) GetGenesisHeader(native *native.NativeService, chainID uint64) ([]byte, error) {
+	return nil, hscommon.ErrQueryNotSupported
+}
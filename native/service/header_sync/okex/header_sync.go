@@ -47,6 +47,10 @@ func NewHandler() *Handler {
 	return &Handler{}
 }
 
+func init() {
+	hscommon.RegisterHeaderSyncHandler(utils.OKEX_ROUTER, 0, func() hscommon.HeaderSyncHandler { return NewHandler() })
+}
+
 // NewCDC ...
 func NewCDC() *codec.Codec {
 	cdc := codec.New()
@@ -98,22 +102,22 @@ func (h *Handler) SyncGenesisHeader(native *native.NativeService) (err error) {
 	return nil
 }
 
-func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
+func (h *Handler) SyncBlockHeader(native *native.NativeService) (uint64, error) {
 	params := new(hscommon.SyncBlockHeaderParam)
 	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
-		return fmt.Errorf("SyncBlockHeader, contract params deserialize error: %v", err)
+		return 0, fmt.Errorf("SyncBlockHeader, contract params deserialize error: %v", err)
 	}
 	cdc := NewCDC()
 	cnt := 0
 	info, err := GetEpochSwitchInfo(native, params.ChainID)
 	if err != nil {
-		return fmt.Errorf("SyncBlockHeader, get epoch switching height failed: %v", err)
+		return 0, fmt.Errorf("SyncBlockHeader, get epoch switching height failed: %v", err)
 	}
 	for _, v := range params.Headers {
 		var myHeader CosmosHeader
 		err := cdc.UnmarshalBinaryBare(v, &myHeader)
 		if err != nil {
-			return fmt.Errorf("SyncBlockHeader failed to unmarshal header: %v", err)
+			return 0, fmt.Errorf("SyncBlockHeader failed to unmarshal header: %v", err)
 		}
 		if bytes.Equal(myHeader.Header.NextValidatorsHash, myHeader.Header.ValidatorsHash) {
 			continue
@@ -124,7 +128,7 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			continue
 		}
 		if err = VerifyCosmosHeader(&myHeader, info); err != nil {
-			return fmt.Errorf("SyncBlockHeader, failed to verify header: %v", err)
+			return 0, fmt.Errorf("SyncBlockHeader, failed to verify header: %v", err)
 		}
 		info.NextValidatorsHash = myHeader.Header.NextValidatorsHash
 		info.Height = myHeader.Header.Height
@@ -132,15 +136,15 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 		cnt++
 	}
 	if cnt == 0 {
-		return fmt.Errorf("no header you commited is useful")
+		return 0, fmt.Errorf("no header you commited is useful")
 	}
 	PutEpochSwitchInfo(native, params.ChainID, info)
-	return nil
+	return uint64(cnt), nil
 }
 
 // SyncCrossChainMsg ...
-func (h *Handler) SyncCrossChainMsg(native *native.NativeService) error {
-	return nil
+func (h *Handler) SyncCrossChainMsg(native *native.NativeService) (uint64, error) {
+	return 0, nil
 }
 
 func GetEpochSwitchInfo(service *native.NativeService, chainId uint64) (*CosmosEpochSwitchInfo, error) {
@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ont
+
+import (
+	"fmt"
+
+	ocommon "github.com/ontio/ontology/common"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func (this *ONTHandler) GetCurrentHeight(native *native.NativeService, chainID uint64) (uint64, error) {
+	value, err := hscommon.GetStorageValue(native, utils.ConcatKey(utils.HeaderSyncContractAddress,
+		[]byte(hscommon.CURRENT_HEADER_HEIGHT), utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return 0, fmt.Errorf("ONTHandler GetCurrentHeight, %v", err)
+	}
+	if value == nil {
+		return 0, fmt.Errorf("ONTHandler GetCurrentHeight, no header synced for chain %d", chainID)
+	}
+	return uint64(utils.GetBytesUint32(value)), nil
+}
+
+// GetHeaderByHeight returns the serialized ontology header
+func (this *ONTHandler) GetHeaderByHeight(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	if height > uint64(^uint32(0)) {
+		return nil, fmt.Errorf("ONTHandler GetHeaderByHeight, height %d out of range", height)
+	}
+	header, err := GetHeaderByHeight(native, chainID, uint32(height))
+	if err != nil {
+		return nil, err
+	}
+	sink := ocommon.NewZeroCopySink(nil)
+	header.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// GetEpoch returns the serialized ConsensusPeers which verifies the header at height
+func (this *ONTHandler) GetEpoch(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	if height > uint64(^uint32(0)) {
+		return nil, fmt.Errorf("ONTHandler GetEpoch, height %d out of range", height)
+	}
+	keyHeight, err := FindKeyHeight(native, uint32(height), chainID)
+	if err != nil {
+		return nil, fmt.Errorf("ONTHandler GetEpoch, %v", err)
+	}
+	consensusPeers, err := getConsensusPeersByHeight(native, chainID, keyHeight)
+	if err != nil {
+		return nil, fmt.Errorf("ONTHandler GetEpoch, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	consensusPeers.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// GetGenesisHeader returns the serialized header at the first key height
+func (this *ONTHandler) GetGenesisHeader(native *native.NativeService, chainID uint64) ([]byte, error) {
+	keyHeights, err := GetKeyHeights(native, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("ONTHandler GetGenesisHeader, %v", err)
+	}
+	if len(keyHeights.HeightList) == 0 {
+		return nil, fmt.Errorf("ONTHandler GetGenesisHeader, genesis header of chain %d is not synced", chainID)
+	}
+	return this.GetHeaderByHeight(native, chainID, uint64(keyHeights.HeightList[0]))
+}
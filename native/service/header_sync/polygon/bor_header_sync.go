@@ -55,6 +55,10 @@ func NewBorHandler() *BorHandler {
 	return &BorHandler{}
 }
 
+func init() {
+	scom.RegisterHeaderSyncHandler(utils.POLYGON_BOR_ROUTER, 0, func() scom.HeaderSyncHandler { return NewBorHandler() })
+}
+
 // HeaderWithOptionalSnap ...
 type HeaderWithOptionalSnap struct {
 	Header   types.Header
@@ -207,35 +211,36 @@ type HeaderWithOptionalProof struct {
 }
 
 // SyncBlockHeader ...
-func (h *BorHandler) SyncBlockHeader(native *native.NativeService) error {
+func (h *BorHandler) SyncBlockHeader(native *native.NativeService) (uint64, error) {
 	headerParams := new(scom.SyncBlockHeaderParam)
 	if err := headerParams.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
-		return fmt.Errorf("bor Handler SyncBlockHeader, contract params deserialize error: %v", err)
+		return 0, fmt.Errorf("bor Handler SyncBlockHeader, contract params deserialize error: %v", err)
 	}
 
 	side, err := side_chain_manager.GetSideChain(native, headerParams.ChainID)
 	if err != nil {
-		return fmt.Errorf("bor Handler SyncBlockHeader, GetSideChain error: %v", err)
+		return 0, fmt.Errorf("bor Handler SyncBlockHeader, GetSideChain error: %v", err)
 	}
 	var extraInfo ExtraInfo
 	err = json.Unmarshal(side.ExtraInfo, &extraInfo)
 	if err != nil {
-		return fmt.Errorf("bor Handler SyncBlockHeader, ExtraInfo Unmarshal error: %v", err)
+		return 0, fmt.Errorf("bor Handler SyncBlockHeader, ExtraInfo Unmarshal error: %v", err)
 	}
 
 	ctx := &Context{ExtraInfo: extraInfo, ChainID: headerParams.ChainID, Cdc: polygonTypes.NewCDC()}
 
+	var stored uint64
 	for _, v := range headerParams.Headers {
 		var headerWOP HeaderWithOptionalProof
 		err := json.Unmarshal(v, &headerWOP)
 		if err != nil {
-			return fmt.Errorf("bor Handler SyncBlockHeader, deserialize header err: %v", err)
+			return 0, fmt.Errorf("bor Handler SyncBlockHeader, deserialize header err: %v", err)
 		}
 		headerHash := headerWOP.Header.Hash()
 
 		exist, err := isHeaderExist(native, headerHash, ctx)
 		if err != nil {
-			return fmt.Errorf("bor Handler SyncBlockHeader, isHeaderExist headerHash err: %v", err)
+			return 0, fmt.Errorf("bor Handler SyncBlockHeader, isHeaderExist headerHash err: %v", err)
 		}
 		if exist {
 			log.Warnf("bor Handler SyncBlockHeader, header has exist. Header: %s", string(v))
@@ -244,7 +249,7 @@ func (h *BorHandler) SyncBlockHeader(native *native.NativeService) error {
 
 		parentExist, err := isHeaderExist(native, headerWOP.Header.ParentHash, ctx)
 		if err != nil {
-			return fmt.Errorf("bor Handler SyncBlockHeader, isHeaderExist ParentHash err: %v", err)
+			return 0, fmt.Errorf("bor Handler SyncBlockHeader, isHeaderExist ParentHash err: %v", err)
 		}
 		if !parentExist {
 			log.Warnf("bor Handler SyncBlockHeader, parent header not exist. Header: %s", string(v))
@@ -254,17 +259,18 @@ func (h *BorHandler) SyncBlockHeader(native *native.NativeService) error {
 		var snap *Snapshot
 		snap, err = verifyHeader(native, &headerWOP, ctx)
 		if err != nil {
-			return fmt.Errorf("bor Handler SyncBlockHeader, verifyHeader err: %v", err)
+			return 0, fmt.Errorf("bor Handler SyncBlockHeader, verifyHeader err: %v", err)
 		}
 
 		err = addHeader(native, &headerWOP.Header, snap, ctx)
 		if err != nil {
-			return fmt.Errorf("bor Handler SyncBlockHeader, addHeader err: %v", err)
+			return 0, fmt.Errorf("bor Handler SyncBlockHeader, addHeader err: %v", err)
 		}
 
 		scom.NotifyPutHeader(native, headerParams.ChainID, headerWOP.Header.Number.Uint64(), headerWOP.Header.Hash().Hex())
+		stored++
 	}
-	return nil
+	return stored, nil
 }
 
 func isHeaderExist(native *native.NativeService, headerHash ecommon.Hash, ctx *Context) (bool, error) {
@@ -405,6 +411,9 @@ func addHeader(native *native.NativeService, header *types.Header, snap *Snapsho
 				break
 			}
 
+			if err = scom.ReplaceMainChainHash(native, ctx.ChainID, i, nil); err != nil {
+				return
+			}
 			deleteCanonicalHash(native, ctx.ChainID, i)
 		}
 
@@ -425,6 +434,9 @@ func addHeader(native *native.NativeService, header *types.Header, snap *Snapsho
 				break
 			}
 
+			if err = scom.ReplaceMainChainHash(native, ctx.ChainID, cheight, headHash.Bytes()); err != nil {
+				return
+			}
 			putCanonicalHash(native, ctx.ChainID, cheight, headHash)
 			headHeader, err = getHeader(native, headHash, ctx.ChainID)
 			if err != nil {
@@ -435,11 +447,15 @@ func addHeader(native *native.NativeService, header *types.Header, snap *Snapsho
 		}
 
 		// Extend the canonical chain with the new header
+		if err = scom.ReplaceMainChainHash(native, ctx.ChainID, header.Number.Uint64(), header.Hash().Bytes()); err != nil {
+			return
+		}
 		putCanonicalHash(native, ctx.ChainID, header.Number.Uint64(), header.Hash())
 		putCanonicalHeight(native, ctx.ChainID, header.Number.Uint64())
+		return scom.PruneSideHeaders(native, ctx.ChainID, header.Number.Uint64())
 	}
 
-	return nil
+	return scom.MarkSideHeader(native, ctx.ChainID, header.Number.Uint64(), header.Hash().Bytes())
 }
 
 func getHeader(native *native.NativeService, hash ecommon.Hash, chainID uint64) (headerWithSum *HeaderWithDifficultySum, err error) {
@@ -867,6 +883,6 @@ func encodeSigHeader(w io.Writer, header *types.Header) {
 }
 
 // SyncCrossChainMsg ...
-func (h *BorHandler) SyncCrossChainMsg(native *native.NativeService) error {
-	return nil
+func (h *BorHandler) SyncCrossChainMsg(native *native.NativeService) (uint64, error) {
+	return 0, nil
 }
@@ -143,7 +143,7 @@ func TestHeimdall(t *testing.T) {
 		if err != nil {
 			t.Fatal("NewNative fail", err)
 		}
-		err = handler.SyncBlockHeader(native)
+		_, err = handler.SyncBlockHeader(native)
 		if err != nil {
 			t.Fatal("SyncBlockHeader fail", err)
 		}
@@ -273,7 +273,7 @@ func TestBor(t *testing.T) {
 		if err != nil {
 			t.Fatal("NewNative fail", err)
 		}
-		err = borHandler.SyncBlockHeader(native)
+		_, err = borHandler.SyncBlockHeader(native)
 		if err != nil {
 			t.Fatal("SyncBlockHeader fail", err)
 		}
@@ -304,10 +304,13 @@ func TestBor(t *testing.T) {
 		if err != nil {
 			t.Fatal("NewNative fail", err)
 		}
-		err = borHandler.SyncBlockHeader(native)
+		stored, err := borHandler.SyncBlockHeader(native)
 		if err != nil {
 			t.Fatal("SyncBlockHeader fail", err)
 		}
+		if stored != 1 {
+			t.Fatalf("SyncBlockHeader stored %d headers, expect 1", stored)
+		}
 
 		headerFromStore, err := GetCanonicalHeader(native, borChainID, 256)
 		if err != nil {
@@ -316,6 +319,19 @@ func TestBor(t *testing.T) {
 		if headerFromStore.HeaderWithOptionalSnap.Header.Hash() != header.Hash() {
 			t.Fatal("header mismatch after store")
 		}
+
+		// the header synced again is skipped
+		native, err = NewNative(sink.Bytes(), tx, native.GetCacheDB())
+		if err != nil {
+			t.Fatal("NewNative fail", err)
+		}
+		stored, err = borHandler.SyncBlockHeader(native)
+		if err != nil {
+			t.Fatal("SyncBlockHeader fail", err)
+		}
+		if stored != 0 {
+			t.Fatalf("SyncBlockHeader stored %d headers synced twice, expect 0", stored)
+		}
 	}
 
 	{
@@ -456,7 +472,7 @@ func TestBorSnap(t *testing.T) {
 			t.Fatal("NewNative fail", err)
 		}
 		skipVerifySpan = true
-		err = borHandler.SyncBlockHeader(native)
+		_, err = borHandler.SyncBlockHeader(native)
 		skipVerifySpan = false
 		if err != nil {
 			t.Fatal("SyncBlockHeader fail", err)
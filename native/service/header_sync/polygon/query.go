@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package polygon
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+)
+
+func (h *BorHandler) GetCurrentHeight(native *native.NativeService, chainID uint64) (uint64, error) {
+	return GetCanonicalHeight(native, chainID)
+}
+
+// GetHeaderByHeight returns the json of HeaderWithDifficultySum
+func (h *BorHandler) GetHeaderByHeight(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	return scom.GetMainChainHeader(native, chainID, height)
+}
+
+// GetEpoch returns the json of the bor Snapshot which verifies the header at height
+func (h *BorHandler) GetEpoch(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	if height == 0 {
+		return nil, fmt.Errorf("bor Handler GetEpoch, no epoch before height 1")
+	}
+	parentHash, err := getCanonicalHash(native, chainID, height-1)
+	if err != nil {
+		return nil, fmt.Errorf("bor Handler GetEpoch, getCanonicalHash error: %v", err)
+	}
+	if parentHash == (ecommon.Hash{}) {
+		return nil, fmt.Errorf("bor Handler GetEpoch, no canonical header at height %d", height-1)
+	}
+	parent, err := getHeader(native, parentHash, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("bor Handler GetEpoch, getHeader error: %v", err)
+	}
+	snap, err := getSnapshot(native, parent, &Context{ChainID: chainID})
+	if err != nil {
+		return nil, fmt.Errorf("bor Handler GetEpoch, getSnapshot error: %v", err)
+	}
+	return json.Marshal(snap)
+}
+
+// GetGenesisHeader returns the json of HeaderWithOptionalSnap
+func (h *BorHandler) GetGenesisHeader(native *native.NativeService, chainID uint64) ([]byte, error) {
+	return scom.GetStoredGenesisHeader(native, chainID)
+}
+
+// GetCurrentHeight returns the height of the latest heimdall epoch switch,
+// headers in between are not kept
+func (h *HeimdallHandler) GetCurrentHeight(native *native.NativeService, chainID uint64) (uint64, error) {
+	info, err := GetEpochSwitchInfo(native, chainID)
+	if err != nil {
+		return 0, fmt.Errorf("heimdall Handler GetCurrentHeight, %v", err)
+	}
+	return uint64(info.Height), nil
+}
+
+func (h *HeimdallHandler) GetHeaderByHeight(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	return nil, scom.ErrQueryNotSupported
+}
+
+// GetEpoch returns the serialized CosmosEpochSwitchInfo, only the latest one is kept
+func (h *HeimdallHandler) GetEpoch(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	info, err := GetEpochSwitchInfo(native, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("heimdall Handler GetEpoch, %v", err)
+	}
+	if height < uint64(info.Height) {
+		return nil, fmt.Errorf("heimdall Handler GetEpoch, epoch at height %d is replaced by the one at %d", height, info.Height)
+	}
+	sink := common.NewZeroCopySink(nil)
+	info.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+func (h *HeimdallHandler) GetGenesisHeader(native *native.NativeService, chainID uint64) ([]byte, error) {
+	return nil, scom.ErrQueryNotSupported
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package header_sync
+
+import (
+	"testing"
+
+	ecom "github.com/ethereum/go-ethereum/common"
+	"github.com/joeqian10/neo-gogogo/helper"
+	ocommon "github.com/ontio/ontology/common"
+	otypes "github.com/ontio/ontology/core/types"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/header_sync/cosmos"
+	_ "github.com/polynetwork/poly/native/service/header_sync/eth"
+	"github.com/polynetwork/poly/native/service/header_sync/neo"
+	"github.com/polynetwork/poly/native/service/header_sync/ont"
+	"github.com/polynetwork/poly/native/service/header_sync/quorum"
+	_ "github.com/polynetwork/poly/native/service/header_sync/zilliqa"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+type queryMethod func(*native.NativeService) ([]byte, error)
+
+func newQueryDB(t *testing.T) *storage.CacheDB {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	assert.NoError(t, err)
+	return storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+}
+
+func newQueryNative(t *testing.T, db *storage.CacheDB, chainID, height uint64) *native.NativeService {
+	sink := common.NewZeroCopySink(nil)
+	(&hscommon.HeaderQueryParam{ChainID: chainID, Height: height}).Serialization(sink)
+	ns, err := native.NewNativeService(db, &types.Transaction{}, 0, 0, common.Uint256{0}, 0, sink.Bytes(), true)
+	assert.NoError(t, err)
+	return ns
+}
+
+func registerQueryChain(t *testing.T, db *storage.CacheDB, chainID, router uint64) {
+	ns := newQueryNative(t, db, chainID, 0)
+	assert.NoError(t, side_chain_manager.PutSideChain(ns, &side_chain_manager.SideChain{
+		ChainId: chainID,
+		Router:  router,
+		Name:    "query test",
+	}))
+}
+
+func putRaw(db *storage.CacheDB, value []byte, keys ...[]byte) {
+	db.Put(utils.ConcatKey(utils.HeaderSyncContractAddress, keys...), states.GenRawStorageItem(value))
+}
+
+func query(t *testing.T, db *storage.CacheDB, method queryMethod, chainID, height uint64) ([]byte, error) {
+	return method(newQueryNative(t, db, chainID, height))
+}
+
+func TestQueryUnregisteredChain(t *testing.T) {
+	db := newQueryDB(t)
+	for _, method := range []queryMethod{GetCurrentHeight, GetHeaderByHeight, GetEpoch, GetGenesisHeader} {
+		_, err := query(t, db, method, 100, 0)
+		assert.Error(t, err)
+	}
+}
+
+func TestQueryMalformedParam(t *testing.T) {
+	db := newQueryDB(t)
+	ns, err := native.NewNativeService(db, &types.Transaction{}, 0, 0, common.Uint256{0}, 0, []byte{1, 2}, true)
+	assert.NoError(t, err)
+	_, err = GetCurrentHeight(ns)
+	assert.Error(t, err)
+}
+
+// eth and zilliqa share the MAIN_CHAIN and HEADER_INDEX layout
+func TestQueryMainChainRouters(t *testing.T) {
+	for chainID, router := range map[uint64]uint64{2: utils.ETH_ROUTER, 9: utils.ZILLIQA_ROUTER} {
+		db := newQueryDB(t)
+		registerQueryChain(t, db, chainID, router)
+		rawChainID := utils.GetUint64Bytes(chainID)
+
+		_, err := query(t, db, GetCurrentHeight, chainID, 0)
+		assert.Error(t, err, "router %d", router)
+		_, err = query(t, db, GetGenesisHeader, chainID, 0)
+		assert.Error(t, err, "router %d", router)
+
+		hash := []byte("hash of header 10")
+		putRaw(db, utils.GetUint64Bytes(10), []byte(hscommon.CURRENT_HEADER_HEIGHT), rawChainID)
+		putRaw(db, hash, []byte(hscommon.MAIN_CHAIN), rawChainID, utils.GetUint64Bytes(10))
+		putRaw(db, []byte("header 10"), []byte(hscommon.HEADER_INDEX), rawChainID, hash)
+		putRaw(db, []byte("genesis"), []byte(hscommon.GENESIS_HEADER), rawChainID)
+
+		res, err := query(t, db, GetCurrentHeight, chainID, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, utils.GetUint64Bytes(10), res)
+
+		res, err = query(t, db, GetHeaderByHeight, chainID, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("header 10"), res)
+		_, err = query(t, db, GetHeaderByHeight, chainID, 11)
+		assert.Error(t, err, "router %d", router)
+
+		res, err = query(t, db, GetGenesisHeader, chainID, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("genesis"), res)
+	}
+}
+
+func TestQueryEthEpochNotSupported(t *testing.T) {
+	db := newQueryDB(t)
+	registerQueryChain(t, db, 2, utils.ETH_ROUTER)
+	_, err := query(t, db, GetEpoch, 2, 10)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), hscommon.ErrQueryNotSupported.Error())
+}
+
+func TestQueryCosmos(t *testing.T) {
+	db := newQueryDB(t)
+	registerQueryChain(t, db, 5, utils.COSMOS_ROUTER)
+
+	_, err := query(t, db, GetCurrentHeight, 5, 0)
+	assert.Error(t, err)
+
+	info := &cosmos.CosmosEpochSwitchInfo{
+		Height:             20,
+		BlockHash:          []byte{1},
+		NextValidatorsHash: []byte{2},
+		ChainID:            "cosmos",
+	}
+	cosmos.PutEpochSwitchInfo(newQueryNative(t, db, 5, 0), 5, info)
+
+	res, err := query(t, db, GetCurrentHeight, 5, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, utils.GetUint64Bytes(20), res)
+
+	res, err = query(t, db, GetEpoch, 5, 25)
+	assert.NoError(t, err)
+	sink := common.NewZeroCopySink(nil)
+	info.Serialization(sink)
+	assert.Equal(t, sink.Bytes(), res)
+	_, err = query(t, db, GetEpoch, 5, 19)
+	assert.Error(t, err)
+
+	for _, method := range []queryMethod{GetHeaderByHeight, GetGenesisHeader} {
+		_, err = query(t, db, method, 5, 20)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), hscommon.ErrQueryNotSupported.Error())
+	}
+}
+
+func TestQueryNeo(t *testing.T) {
+	db := newQueryDB(t)
+	registerQueryChain(t, db, 4, utils.NEO_ROUTER)
+
+	_, err := query(t, db, GetEpoch, 4, 0)
+	assert.Error(t, err)
+
+	consensus := &neo.NeoConsensus{ChainID: 4, Height: 30, NextConsensus: helper.UInt160{1}}
+	sink := common.NewZeroCopySink(nil)
+	consensus.Serialization(sink)
+	putRaw(db, sink.Bytes(), []byte(hscommon.CONSENSUS_PEER), utils.GetUint64Bytes(4))
+
+	res, err := query(t, db, GetCurrentHeight, 4, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, utils.GetUint64Bytes(30), res)
+
+	res, err = query(t, db, GetEpoch, 4, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, sink.Bytes(), res)
+	_, err = query(t, db, GetEpoch, 4, 29)
+	assert.Error(t, err)
+
+	_, err = query(t, db, GetHeaderByHeight, 4, 30)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), hscommon.ErrQueryNotSupported.Error())
+}
+
+func TestQueryQuorum(t *testing.T) {
+	db := newQueryDB(t)
+	registerQueryChain(t, db, 8, utils.QUORUM_ROUTER)
+
+	vs := quorum.QuorumValSet([]ecom.Address{{1}, {2}})
+	sink := common.NewZeroCopySink(nil)
+	vs.Serialize(sink)
+	rawChainID := utils.GetUint64Bytes(8)
+	putRaw(db, sink.Bytes(), []byte(hscommon.CONSENSUS_PEER), rawChainID)
+	putRaw(db, utils.GetUint64Bytes(40), []byte(hscommon.CONSENSUS_PEER_BLOCK_HEIGHT), rawChainID)
+
+	res, err := query(t, db, GetCurrentHeight, 8, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, utils.GetUint64Bytes(40), res)
+
+	res, err = query(t, db, GetEpoch, 8, 41)
+	assert.NoError(t, err)
+	assert.Equal(t, sink.Bytes(), res)
+	_, err = query(t, db, GetEpoch, 8, 39)
+	assert.Error(t, err)
+
+	_, err = query(t, db, GetGenesisHeader, 8, 0)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), hscommon.ErrQueryNotSupported.Error())
+}
+
+func TestQueryOnt(t *testing.T) {
+	db := newQueryDB(t)
+	registerQueryChain(t, db, 3, utils.ONT_ROUTER)
+
+	header := &otypes.Header{Version: 0, Height: 50, Timestamp: 1}
+	ns := newQueryNative(t, db, 3, 0)
+	assert.NoError(t, ont.PutBlockHeader(ns, 3, header))
+	assert.NoError(t, ont.PutKeyHeights(ns, 3, &ont.KeyHeights{HeightList: []uint32{50}}))
+	sink := ocommon.NewZeroCopySink(nil)
+	header.Serialization(sink)
+
+	res, err := query(t, db, GetCurrentHeight, 3, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, utils.GetUint64Bytes(50), res)
+
+	res, err = query(t, db, GetHeaderByHeight, 3, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, sink.Bytes(), res)
+	_, err = query(t, db, GetHeaderByHeight, 3, 1<<32)
+	assert.Error(t, err)
+
+	res, err = query(t, db, GetGenesisHeader, 3, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, sink.Bytes(), res)
+}
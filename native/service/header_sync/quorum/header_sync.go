@@ -34,6 +34,10 @@ func NewQuorumHandler() *QuorumHandler {
 	return &QuorumHandler{}
 }
 
+func init() {
+	common.RegisterHeaderSyncHandler(utils.QUORUM_ROUTER, 0, func() common.HeaderSyncHandler { return NewQuorumHandler() })
+}
+
 func (h *QuorumHandler) SyncGenesisHeader(ns *native.NativeService) error {
 	params := new(common.SyncGenesisHeaderParam)
 	if err := params.Deserialization(pcom.NewZeroCopySource(ns.GetInput())); err != nil {
@@ -63,46 +67,48 @@ func (h *QuorumHandler) SyncGenesisHeader(ns *native.NativeService) error {
 	return nil
 }
 
-func (h *QuorumHandler) SyncBlockHeader(ns *native.NativeService) error {
+func (h *QuorumHandler) SyncBlockHeader(ns *native.NativeService) (uint64, error) {
 	params := new(common.SyncBlockHeaderParam)
 	err := params.Deserialization(pcom.NewZeroCopySource(ns.GetInput()))
 	if err != nil {
-		return fmt.Errorf("QuorumHandler SyncBlockHeader, contract params deserialize error: %v", err)
+		return 0, fmt.Errorf("QuorumHandler SyncBlockHeader, contract params deserialize error: %v", err)
 	}
 	if len(params.Headers) == 0 {
-		return errors.New("QuorumHandler SyncBlockHeader, none headers in input")
+		return 0, errors.New("QuorumHandler SyncBlockHeader, none headers in input")
 	}
 
 	currh, err := GetCurrentValHeight(ns, params.ChainID)
 	if err != nil {
-		return fmt.Errorf("QuorumHandler SyncBlockHeader, failed to get current validator height: %v", err)
+		return 0, fmt.Errorf("QuorumHandler SyncBlockHeader, failed to get current validator height: %v", err)
 	}
 	vs, err := GetValSet(ns, params.ChainID)
 	if err != nil {
-		return fmt.Errorf("QuorumHandler SyncBlockHeader, failed to get validators: %v", err)
+		return 0, fmt.Errorf("QuorumHandler SyncBlockHeader, failed to get validators: %v", err)
 	}
 	header := &types.Header{}
+	var stored uint64
 	for i, v := range params.Headers {
 		if err := json.Unmarshal(v, header); err != nil {
-			return fmt.Errorf("QuorumHandler SyncBlockHeader, deserialize No.%d header err: %v", i, err)
+			return 0, fmt.Errorf("QuorumHandler SyncBlockHeader, deserialize No.%d header err: %v", i, err)
 		}
 		h := header.Number.Uint64()
 		if currh >= h {
-			return fmt.Errorf("QuorumHandler SyncBlockHeader, wrong height of No.%d header: (curr: %d, commit: %d)", i, currh, h)
+			return 0, fmt.Errorf("QuorumHandler SyncBlockHeader, wrong height of No.%d header: (curr: %d, commit: %d)", i, currh, h)
 		}
 
 		extra, err := VerifyQuorumHeader(vs, header, true)
 		if err != nil {
-			return fmt.Errorf("QuorumHandler SyncBlockHeader, failed to verify No.%d quorum header %s: %v", i, GetQuorumHeaderHash(header).String(), err)
+			return 0, fmt.Errorf("QuorumHandler SyncBlockHeader, failed to verify No.%d quorum header %s: %v", i, GetQuorumHeaderHash(header).String(), err)
 		}
 
 		currh, vs = h, extra.Validators
+		stored++
 	}
 
 	putValSet(ns, params.ChainID, currh, vs)
-	return nil
+	return stored, nil
 }
 
-func (h *QuorumHandler) SyncCrossChainMsg(ns *native.NativeService) error {
-	return nil
+func (h *QuorumHandler) SyncCrossChainMsg(ns *native.NativeService) (uint64, error) {
+	return 0, nil
 }
@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package quorum
+
+import (
+	"fmt"
+
+	pcom "github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/header_sync/common"
+)
+
+// GetCurrentHeight returns the height of the latest header changing the
+// validators, headers in between are not kept
+func (h *QuorumHandler) GetCurrentHeight(ns *native.NativeService, chainID uint64) (uint64, error) {
+	return GetCurrentValHeight(ns, chainID)
+}
+
+func (h *QuorumHandler) GetHeaderByHeight(ns *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	return nil, common.ErrQueryNotSupported
+}
+
+// GetEpoch returns the serialized QuorumValSet, only the latest one is kept
+func (h *QuorumHandler) GetEpoch(ns *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	valHeight, err := GetCurrentValHeight(ns, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("quorum GetEpoch, %v", err)
+	}
+	if height < valHeight {
+		return nil, fmt.Errorf("quorum GetEpoch, validators at height %d are replaced by the ones at %d", height, valHeight)
+	}
+	vs, err := GetValSet(ns, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("quorum GetEpoch, %v", err)
+	}
+	sink := pcom.NewZeroCopySink(nil)
+	vs.Serialize(sink)
+	return sink.Bytes(), nil
+}
+
+func (h *QuorumHandler) GetGenesisHeader(ns *native.NativeService, chainID uint64) ([]byte, error) {
+	return nil, common.ErrQueryNotSupported
+}
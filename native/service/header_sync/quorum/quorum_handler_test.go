@@ -153,7 +153,7 @@ func TestQuorumHandler_SyncBlockHeader(t *testing.T) {
 	sink = common.NewZeroCopySink(nil)
 	p1.Serialization(sink)
 	ns = getNativeFunc(sink.Bytes(), ns.GetCacheDB())
-	if err := h.SyncBlockHeader(ns); err != nil {
+	if _, err := h.SyncBlockHeader(ns); err != nil {
 		t.Fatal(err)
 	}
 
@@ -177,7 +177,7 @@ func TestQuorumHandler_SyncBlockHeader(t *testing.T) {
 	sink = common.NewZeroCopySink(nil)
 	p2.Serialization(sink)
 	ns = getNativeFunc(sink.Bytes(), ns.GetCacheDB())
-	if err := h.SyncBlockHeader(ns); err != nil {
+	if _, err := h.SyncBlockHeader(ns); err != nil {
 		t.Fatal(err)
 	}
 
@@ -41,6 +41,10 @@ func NewHandler() *Handler {
 	return &Handler{}
 }
 
+func init() {
+	scom.RegisterHeaderSyncHandler(utils.ZILLIQA_ROUTER, 0, func() scom.HeaderSyncHandler { return NewHandler() })
+}
+
 // SyncGenesisHeader ...
 func (h *Handler) SyncGenesisHeader(native *native.NativeService) (err error) {
 	params := new(scom.SyncGenesisHeaderParam)
@@ -81,21 +85,21 @@ func (h *Handler) SyncGenesisHeader(native *native.NativeService) (err error) {
 }
 
 // SyncBlockHeader ...
-func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
+func (h *Handler) SyncBlockHeader(native *native.NativeService) (uint64, error) {
 	headerParams := new(scom.SyncBlockHeaderParam)
 	if err := headerParams.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
-		return fmt.Errorf("SyncBlockHeader, contract params deserialize error: %v", err)
+		return 0, fmt.Errorf("SyncBlockHeader, contract params deserialize error: %v", err)
 	}
 
 	side, err := side_chain_manager.GetSideChain(native, headerParams.ChainID)
 	if err != nil {
-		return fmt.Errorf("zil Handler SyncBlockHeader, GetSideChain error: %v", err)
+		return 0, fmt.Errorf("zil Handler SyncBlockHeader, GetSideChain error: %v", err)
 	}
 
 	var extraInfo ExtraInfo
 	err = json.Unmarshal(side.ExtraInfo, &extraInfo)
 	if err != nil {
-		return fmt.Errorf("zil Handler SyncBlockHeader, ExtraInfo Unmarshal error: %v", err)
+		return 0, fmt.Errorf("zil Handler SyncBlockHeader, ExtraInfo Unmarshal error: %v", err)
 	}
 
 	verifier := &verifier2.Verifier{
@@ -103,11 +107,12 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 	}
 
 	// ...txblock1-1,txblock1-2...dsblock2,txblock2-1,txblock2-2...
+	var stored uint64
 	for _, v := range headerParams.Headers {
 		var txBlockAndDsComm core.TxBlockOrDsBlock
 		err := json.Unmarshal(v, &txBlockAndDsComm)
 		if err != nil {
-			return fmt.Errorf("SyncBlockHeader, deserialize header err: %v", err)
+			return 0, fmt.Errorf("SyncBlockHeader, deserialize header err: %v", err)
 		}
 
 		txBlock := txBlockAndDsComm.TxBlock
@@ -120,7 +125,7 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			blockHash := dsBlock.BlockHash
 			exist, err := IsHeaderExist(native, blockHash[:], headerParams.ChainID)
 			if err != nil {
-				return fmt.Errorf("SyncDsBlockHeader, check header exist err: %v", err)
+				return 0, fmt.Errorf("SyncDsBlockHeader, check header exist err: %v", err)
 			}
 			if exist == true {
 				log.Warnf("SyncDsBlockHeader, header has exist. Header: %s", string(v))
@@ -131,28 +136,28 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			preHash := util.DecodeHex(dsBlock.PrevDSHash)
 			_, err = GetDsHeaderByHash(native, preHash[:], headerParams.ChainID)
 			if err != nil {
-				return fmt.Errorf("SyncDsBlockHeader, get the parent block failed. parent hash is: %s, Error:%s, header: %s", dsBlock.PrevDSHash, err, string(v))
+				return 0, fmt.Errorf("SyncDsBlockHeader, get the parent block failed. parent hash is: %s, Error:%s, header: %s", dsBlock.PrevDSHash, err, string(v))
 			}
 
 			// 3. get old ds comm list
 			dsBlockNum := dsBlock.BlockHeader.BlockNum
 			dscomm, err := getDsComm(native, dsBlockNum-1, headerParams.ChainID)
 			if err != nil {
-				return fmt.Errorf("SyncDsBlockHeader, get dscomm err: %v", err)
+				return 0, fmt.Errorf("SyncDsBlockHeader, get dscomm err: %v", err)
 			}
 			dsList := dsCommListFromArray(dscomm)
 
 			// 4. verify ds block, generate new ds comm list
 			newDsList, err2 := verifier.VerifyDsBlock(dsBlock, dsList)
 			if err2 != nil {
-				return fmt.Errorf("SyncDsBlockHeader, verify ds block err: %v", err2)
+				return 0, fmt.Errorf("SyncDsBlockHeader, verify ds block err: %v", err2)
 			}
 
 			// 5. update ds comm list, put ds block
 			putDsComm(native, dsBlockNum, dsCommArrayFromList(newDsList), headerParams.ChainID)
 			err = putDsBlockHeader(native, dsBlock, headerParams.ChainID)
 			if err != nil {
-				return fmt.Errorf("SyncDsBlockHeader, put blockHeader failed. Error:%s, header: %s", err, string(v))
+				return 0, fmt.Errorf("SyncDsBlockHeader, put blockHeader failed. Error:%s, header: %s", err, string(v))
 			}
 		}
 
@@ -161,7 +166,7 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			blockHash := txBlock.BlockHash
 			exist, err := IsHeaderExist(native, blockHash[:], headerParams.ChainID)
 			if err != nil {
-				return fmt.Errorf("SyncTxBlockHeader, check header exist err: %v", err)
+				return 0, fmt.Errorf("SyncTxBlockHeader, check header exist err: %v", err)
 			}
 			if exist == true {
 				log.Warnf("SyncTxBlockHeader, header has exist. Header: %s", string(v))
@@ -172,37 +177,38 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			preHash := txBlock.BlockHeader.BlockHeaderBase.PrevHash
 			_, err = GetTxHeaderByHash(native, preHash[:], headerParams.ChainID)
 			if err != nil {
-				return fmt.Errorf("SyncTxBlockHeader, get the parent block failed. Error:%s, header: %s", err, string(v))
+				return 0, fmt.Errorf("SyncTxBlockHeader, get the parent block failed. Error:%s, header: %s", err, string(v))
 			}
 
 			// 3. get comm list
 			dscomm, err := getDsComm(native, txBlock.BlockHeader.DSBlockNum, headerParams.ChainID)
 			if err != nil {
-				return fmt.Errorf("SyncTxBlockHeader, get dscomm for tx block err: %s", err.Error())
+				return 0, fmt.Errorf("SyncTxBlockHeader, get dscomm for tx block err: %s", err.Error())
 			}
 
 			// 4. verify tx block and store it
 			err = verifier.VerifyTxBlock(txBlock, dsCommListFromArray(dscomm))
 			if err != nil {
-				return fmt.Errorf("SyncTxBlockHeader, verify block failed. Error:%s, header: %s", err, string(v))
+				return 0, fmt.Errorf("SyncTxBlockHeader, verify block failed. Error:%s, header: %s", err, string(v))
 			}
 
 			err = putTxBlockHeader(native, txBlock, headerParams.ChainID)
 			if err != nil {
-				return fmt.Errorf("SyncTxBlockHeader, put blockHeader failed. Error:%s, header: %s", err, string(v))
+				return 0, fmt.Errorf("SyncTxBlockHeader, put blockHeader failed. Error:%s, header: %s", err, string(v))
 			}
 
 			// 5. update header of main
 			AppendHeader2Main(native, txBlock.BlockHeader.BlockNum, txBlock.BlockHash[:], headerParams.ChainID)
 		}
+		stored++
 	}
 
-	return nil
+	return stored, nil
 }
 
 // SyncCrossChainMsg ...
-func (h *Handler) SyncCrossChainMsg(native *native.NativeService) error {
-	return nil
+func (h *Handler) SyncCrossChainMsg(native *native.NativeService) (uint64, error) {
+	return 0, nil
 }
 
 type TxBlockAndDsComm struct {
@@ -461,7 +461,7 @@ func TestSyncBlockHeader(t *testing.T) {
 		}
 		native, _ := NewNative(sink.Bytes(), tx, n.GetCacheDB())
 
-		err := zilHeader.SyncBlockHeader(native)
+		_, err := zilHeader.SyncBlockHeader(native)
 		assert.Equal(t, SUCCESS, typeOfError(err), err)
 
 	}
@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package zilliqa
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func (this *Handler) GetCurrentHeight(native *native.NativeService, chainID uint64) (uint64, error) {
+	return scom.GetCurrentHeaderHeight(native, chainID)
+}
+
+// GetHeaderByHeight returns the json encoded tx block
+func (this *Handler) GetHeaderByHeight(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	return scom.GetMainChainHeader(native, chainID, height)
+}
+
+// GetEpoch returns the json encoded ds committee of ds block height, only the
+// latest committee is kept
+func (this *Handler) GetEpoch(native *native.NativeService, chainID uint64, height uint64) ([]byte, error) {
+	value, err := scom.GetStorageValue(native, utils.ConcatKey(utils.HeaderSyncContractAddress,
+		utils.GetUint64Bytes(chainID), []byte(dsCommKey), utils.GetUint64Bytes(height)))
+	if err != nil {
+		return nil, fmt.Errorf("GetEpoch, %v", err)
+	}
+	if value == nil {
+		return nil, fmt.Errorf("GetEpoch, ds committee of ds block %d is not kept", height)
+	}
+	return value, nil
+}
+
+// GetGenesisHeader returns the json encoded tx block
+func (this *Handler) GetGenesisHeader(native *native.NativeService, chainID uint64) ([]byte, error) {
+	return scom.GetStoredGenesisHeader(native, chainID)
+}
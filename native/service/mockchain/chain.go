@@ -0,0 +1,550 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package mockchain simulates the PoA side chains of poly, it seals valid headers,
+// switches validator sets and builds the storage proofs of the cross chain data contract,
+// so that the whole path from header sync to ImportOuterTransfer can be tested without
+// a live chain. Only the bsc and heco routers are simulated, the other eth family
+// routers are split out of the package, see NotSimulated.
+package mockchain
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/polynetwork/poly/common"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/eth"
+	"github.com/polynetwork/poly/native/service/header_sync/bsc"
+	"github.com/polynetwork/poly/native/service/header_sync/heco"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	extraVanity = 32
+	extraSeal   = crypto.SignatureLength
+
+	GENESIS_HEIGHT    = 1000
+	DEFAULT_PERIOD    = 3
+	DEFAULT_GAS_LIMIT = 30000000
+
+	// slot of the mapping from tx index to tx param hash in the cross chain data contract
+	txParamSlot = "01"
+)
+
+// NotSimulated lists the eth family routers split out of this package, with the proof
+// their header sync handlers check which can't be produced here
+var NotSimulated = map[uint64]string{
+	utils.ETH_ROUTER:         "ethash seals need a proof of work of at least the minimum difficulty",
+	utils.OKEX_ROUTER:        "headers are checked against tendermint commits of the okex validators",
+	utils.POLYGON_BOR_ROUTER: "headers are checked against the spans of heimdall, synced with tendermint commits",
+}
+
+var (
+	diffInTurn = big.NewInt(2)
+	diffNoTurn = big.NewInt(1)
+	emptyCode  = crypto.Keccak256Hash(nil)
+)
+
+// Epoch is a validator set which takes effect from the header at Height, it is
+// encoded as the HeightAndValidators of the bsc and heco header sync handlers
+type Epoch struct {
+	Height     *big.Int
+	Validators []ecommon.Address
+}
+
+// CrossChainTx is a cross chain tx recorded in the cross chain data contract of a mock chain
+type CrossChainTx struct {
+	Index *big.Int
+	Param *scom.MakeTxParam
+	// Value is the serialized Param, which is the Extra of EntranceParam
+	Value []byte
+	// Height is the number of the block including the tx, 0 before it is mined
+	Height uint64
+}
+
+type block struct {
+	header      *types.Header
+	parent      *block
+	storageRoot ecommon.Hash
+	nextIndex   int64
+	txs         []*CrossChainTx
+	// epochs known at this block, the last one is the latest
+	epochs []Epoch
+}
+
+// Chain is an in memory bsc or heco chain
+type Chain struct {
+	Router     uint64
+	ChainID    uint64
+	EvmChainID *big.Int
+	Period     uint64
+	// CCD is the address of the cross chain data contract, registered as CCMCAddress
+	CCD ecommon.Address
+
+	keys    map[ecommon.Address]*ecdsa.PrivateKey
+	triedb  *trie.Database
+	genesis *block
+	head    *block
+	fork    byte
+
+	pendingTxs        []*CrossChainTx
+	pendingValidators []ecommon.Address
+}
+
+// NewChain creates a chain of router registered as chainID in poly, its genesis is sealed
+// by the given number of validators with freshly generated keys
+func NewChain(router, chainID uint64, validators int) (*Chain, error) {
+	if reason, ok := NotSimulated[router]; ok {
+		return nil, fmt.Errorf("NewChain, router %d is not simulated: %s", router, reason)
+	}
+	if router != utils.BSC_ROUTER && router != utils.HECO_ROUTER {
+		return nil, fmt.Errorf("NewChain, router %d is not supported", router)
+	}
+	if validators <= 0 {
+		return nil, fmt.Errorf("NewChain, at least one validator is needed")
+	}
+	c := &Chain{
+		Router:     router,
+		ChainID:    chainID,
+		EvmChainID: new(big.Int).SetUint64(chainID),
+		Period:     DEFAULT_PERIOD,
+		CCD:        ecommon.BytesToAddress(crypto.Keccak256(utils.GetUint64Bytes(chainID))),
+		keys:       make(map[ecommon.Address]*ecdsa.PrivateKey),
+		triedb:     trie.NewDatabase(memorydb.New()),
+	}
+	set, err := c.newValidators(validators)
+	if err != nil {
+		return nil, fmt.Errorf("NewChain, %v", err)
+	}
+	number := big.NewInt(GENESIS_HEIGHT)
+	genesis := &block{
+		storageRoot: types.EmptyRootHash,
+		epochs:      []Epoch{{Height: big.NewInt(GENESIS_HEIGHT - 200), Validators: set}, {Height: number, Validators: set}},
+	}
+	root, err := c.accountRoot(genesis.storageRoot)
+	if err != nil {
+		return nil, fmt.Errorf("NewChain, %v", err)
+	}
+	// leave enough time before now for the headers mined later, handlers reject headers in the future
+	genesis.header = &types.Header{
+		UncleHash:   types.EmptyUncleHash,
+		Coinbase:    set[GENESIS_HEIGHT%len(set)],
+		Root:        root,
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+		Difficulty:  new(big.Int).Set(diffInTurn),
+		Number:      number,
+		GasLimit:    DEFAULT_GAS_LIMIT,
+		Time:        uint64(time.Now().Add(-24 * time.Hour).Unix()),
+		Extra:       makeExtra(0, set),
+	}
+	if err := c.seal(genesis.header); err != nil {
+		return nil, fmt.Errorf("NewChain, %v", err)
+	}
+	c.genesis, c.head = genesis, genesis
+	return c, nil
+}
+
+// ExtraInfo returns the ExtraInfo to register the chain with
+func (c *Chain) ExtraInfo() ([]byte, error) {
+	if c.Router == utils.BSC_ROUTER {
+		return json.Marshal(&bsc.ExtraInfo{ChainID: c.EvmChainID})
+	}
+	return json.Marshal(&heco.ExtraInfo{ChainID: c.EvmChainID, Period: c.Period})
+}
+
+// GenesisHeader returns the json of GenesisHeader to sync into poly
+func (c *Chain) GenesisHeader() ([]byte, error) {
+	return json.Marshal(&struct {
+		Header         *types.Header
+		PrevValidators []Epoch
+	}{
+		Header:         c.genesis.header,
+		PrevValidators: c.genesis.epochs[:1],
+	})
+}
+
+// Head returns the head of the canonical chain
+func (c *Chain) Head() *types.Header {
+	return c.head.header
+}
+
+// Header returns the canonical header at number, nil if there is none
+func (c *Chain) Header(number uint64) *types.Header {
+	if b := c.blockAt(number); b != nil {
+		return b.header
+	}
+	return nil
+}
+
+// Validators returns the validators in turn to seal the next header
+func (c *Chain) Validators() []ecommon.Address {
+	return c.inTurnValidators(c.head, c.head.header.Number.Uint64()+1)
+}
+
+// SwitchValidators creates a set of n new validators which is announced by the next mined header
+func (c *Chain) SwitchValidators(n int) ([]ecommon.Address, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("SwitchValidators, at least one validator is needed")
+	}
+	set, err := c.newValidators(n)
+	if err != nil {
+		return nil, fmt.Errorf("SwitchValidators, %v", err)
+	}
+	c.pendingValidators = set
+	return set, nil
+}
+
+// AddCrossChainTx records param in the cross chain data contract by the next mined header,
+// TxHash and CrossChainID of param are filled as the contract does when they are empty
+func (c *Chain) AddCrossChainTx(param *scom.MakeTxParam) *CrossChainTx {
+	index := big.NewInt(c.head.nextIndex + int64(len(c.pendingTxs)))
+	if len(param.TxHash) == 0 {
+		param.TxHash = index.Bytes()
+	}
+	if len(param.CrossChainID) == 0 {
+		id := sha256.Sum256(append(c.CCD.Bytes(), param.TxHash...))
+		param.CrossChainID = id[:]
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	tx := &CrossChainTx{Index: index, Param: param, Value: sink.Bytes()}
+	c.pendingTxs = append(c.pendingTxs, tx)
+	return tx
+}
+
+// NextHeader seals the header following head without adding it to the chain
+func (c *Chain) NextHeader() (*types.Header, error) {
+	b, err := c.next()
+	if err != nil {
+		return nil, err
+	}
+	return b.header, nil
+}
+
+// Mine seals n headers on top of head and returns them in order
+func (c *Chain) Mine(n int) ([]*types.Header, error) {
+	headers := make([]*types.Header, 0, n)
+	for i := 0; i < n; i++ {
+		b, err := c.next()
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range b.txs {
+			tx.Height = b.header.Number.Uint64()
+		}
+		c.head = b
+		c.pendingTxs = nil
+		c.pendingValidators = nil
+		headers = append(headers, b.header)
+	}
+	return headers, nil
+}
+
+// SetHead rewinds the chain to the canonical header at number, headers mined after it
+// start a new branch. Pending txs and validators are dropped
+func (c *Chain) SetHead(number uint64) error {
+	b := c.blockAt(number)
+	if b == nil {
+		return fmt.Errorf("SetHead, no canonical header at %d", number)
+	}
+	c.head = b
+	c.fork++
+	c.pendingTxs = nil
+	c.pendingValidators = nil
+	return nil
+}
+
+// Reseal returns a copy of header sealed by signer, which must be a validator of the chain
+func (c *Chain) Reseal(header *types.Header, signer ecommon.Address) (*types.Header, error) {
+	h := types.CopyHeader(header)
+	h.Coinbase = signer
+	if err := c.seal(h); err != nil {
+		return nil, fmt.Errorf("Reseal, %v", err)
+	}
+	return h, nil
+}
+
+// Proof returns the eth_getProof json of tx against the state of the canonical header at height,
+// which is the Proof of EntranceParam. It proves the absence of tx if the state doesn't hold it
+func (c *Chain) Proof(tx *CrossChainTx, height uint64) ([]byte, error) {
+	b := c.blockAt(height)
+	if b == nil {
+		return nil, fmt.Errorf("Proof, no canonical header at %d", height)
+	}
+	slot, err := eth.MappingKeyAt(hex.EncodeToString(tx.Index.Bytes()), txParamSlot)
+	if err != nil {
+		return nil, fmt.Errorf("Proof, MappingKeyAt error: %v", err)
+	}
+	storage, err := trie.New(b.storageRoot, c.triedb)
+	if err != nil {
+		return nil, fmt.Errorf("Proof, open storage trie error: %v", err)
+	}
+	storageNodes := new(light.NodeList)
+	if err := storage.Prove(crypto.Keccak256(slot), 0, storageNodes); err != nil {
+		return nil, fmt.Errorf("Proof, prove storage error: %v", err)
+	}
+	state, err := trie.New(b.header.Root, c.triedb)
+	if err != nil {
+		return nil, fmt.Errorf("Proof, open state trie error: %v", err)
+	}
+	accountNodes := new(light.NodeList)
+	if err := state.Prove(crypto.Keccak256(c.CCD.Bytes()), 0, accountNodes); err != nil {
+		return nil, fmt.Errorf("Proof, prove account error: %v", err)
+	}
+	return json.Marshal(&proof{
+		Address:      c.CCD.Hex(),
+		Balance:      "0x0",
+		CodeHash:     emptyCode.Hex(),
+		Nonce:        "0x0",
+		StorageHash:  b.storageRoot.Hex(),
+		AccountProof: encodeNodes(*accountNodes),
+		StorageProofs: []storageProof{{
+			Key:   hexutil.Encode(slot),
+			Value: hexutil.Encode(crypto.Keccak256(tx.Value)),
+			Proof: encodeNodes(*storageNodes),
+		}},
+	})
+}
+
+// proof is the json of eth_getProof accepted by the eth family chain handlers
+type proof struct {
+	Address       string         `json:"address"`
+	Balance       string         `json:"balance"`
+	CodeHash      string         `json:"codeHash"`
+	Nonce         string         `json:"nonce"`
+	StorageHash   string         `json:"storageHash"`
+	AccountProof  []string       `json:"accountProof"`
+	StorageProofs []storageProof `json:"storageProof"`
+}
+
+type storageProof struct {
+	Key   string   `json:"key"`
+	Value string   `json:"value"`
+	Proof []string `json:"proof"`
+}
+
+type stateAccount struct {
+	Nonce    *big.Int
+	Balance  *big.Int
+	Root     ecommon.Hash
+	CodeHash ecommon.Hash
+}
+
+func encodeNodes(nodes light.NodeList) []string {
+	encoded := make([]string, len(nodes))
+	for i, node := range nodes {
+		encoded[i] = hexutil.Encode(node)
+	}
+	return encoded
+}
+
+func makeExtra(fork byte, validators []ecommon.Address) []byte {
+	extra := make([]byte, extraVanity, extraVanity+len(validators)*ecommon.AddressLength+extraSeal)
+	// headers of different branches differ in vanity
+	extra[0] = fork
+	for _, v := range validators {
+		extra = append(extra, v.Bytes()...)
+	}
+	return append(extra, make([]byte, extraSeal)...)
+}
+
+func (c *Chain) newValidators(n int) ([]ecommon.Address, error) {
+	set := make([]ecommon.Address, n)
+	for i := range set {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, fmt.Errorf("generate validator key error: %v", err)
+		}
+		set[i] = crypto.PubkeyToAddress(key.PublicKey)
+		c.keys[set[i]] = key
+	}
+	return set, nil
+}
+
+func (c *Chain) blockAt(number uint64) *block {
+	for b := c.head; b != nil; b = b.parent {
+		if n := b.header.Number.Uint64(); n == number {
+			return b
+		} else if n < number {
+			break
+		}
+	}
+	return nil
+}
+
+// inTurnValidators returns the validators allowed to seal the header at number on top of parent,
+// bsc keeps the former set in turn for half of its size after an epoch while heco switches at once
+func (c *Chain) inTurnValidators(parent *block, number uint64) []ecommon.Address {
+	last, prev := parent.epochs[len(parent.epochs)-1], parent.epochs[len(parent.epochs)-2]
+	if c.Router == utils.BSC_ROUTER && int64(number)-last.Height.Int64() <= int64(len(prev.Validators)/2) {
+		return prev.Validators
+	}
+	return last.Validators
+}
+
+// recentlySigned mirrors the RecentlySigned check of the handlers, signer may not seal
+// again within half the size of the validators in turn
+func (c *Chain) recentlySigned(parent *block, number uint64, signer ecommon.Address) bool {
+	last, prev := parent.epochs[len(parent.epochs)-1], parent.epochs[len(parent.epochs)-2]
+	lookBack := len(last.Validators)
+	if len(prev.Validators) > lookBack {
+		lookBack = len(prev.Validators)
+	}
+	lookBack /= 2
+	if lookBack < 1 {
+		lookBack = 1
+	}
+	limit := uint64(len(c.inTurnValidators(parent, number)) / 2)
+	for b := parent; b != nil && lookBack > 0; b, lookBack = b.parent, lookBack-1 {
+		if b.header.Coinbase == signer {
+			return number <= b.header.Number.Uint64()+limit
+		}
+	}
+	return false
+}
+
+func (c *Chain) next() (*block, error) {
+	parent := c.head
+	number := parent.header.Number.Uint64() + 1
+	validators := c.inTurnValidators(parent, number)
+	var signer ecommon.Address
+	found := false
+	for i := 0; i < len(validators) && !found; i++ {
+		signer = validators[(int(number)+i)%len(validators)]
+		found = !c.recentlySigned(parent, number, signer)
+	}
+	if !found {
+		return nil, fmt.Errorf("no validator is allowed to seal header %d", number)
+	}
+
+	b := &block{
+		parent:      parent,
+		storageRoot: parent.storageRoot,
+		nextIndex:   parent.nextIndex + int64(len(c.pendingTxs)),
+		txs:         c.pendingTxs,
+		epochs:      parent.epochs,
+	}
+	if len(c.pendingTxs) > 0 {
+		storage, err := trie.New(parent.storageRoot, c.triedb)
+		if err != nil {
+			return nil, fmt.Errorf("open storage trie error: %v", err)
+		}
+		for _, tx := range c.pendingTxs {
+			slot, err := eth.MappingKeyAt(hex.EncodeToString(tx.Index.Bytes()), txParamSlot)
+			if err != nil {
+				return nil, fmt.Errorf("MappingKeyAt error: %v", err)
+			}
+			value, err := rlp.EncodeToBytes(ecommon.TrimLeftZeroes(crypto.Keccak256(tx.Value)))
+			if err != nil {
+				return nil, fmt.Errorf("encode storage value error: %v", err)
+			}
+			if err := storage.TryUpdate(crypto.Keccak256(slot), value); err != nil {
+				return nil, fmt.Errorf("update storage trie error: %v", err)
+			}
+		}
+		if b.storageRoot, err = storage.Commit(nil); err != nil {
+			return nil, fmt.Errorf("commit storage trie error: %v", err)
+		}
+	}
+	root, err := c.accountRoot(b.storageRoot)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.pendingValidators) > 0 {
+		b.epochs = append(append([]Epoch{}, parent.epochs...), Epoch{Height: new(big.Int).SetUint64(number), Validators: c.pendingValidators})
+	}
+
+	b.header = &types.Header{
+		ParentHash:  parent.header.Hash(),
+		UncleHash:   types.EmptyUncleHash,
+		Coinbase:    signer,
+		Root:        root,
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+		Number:      new(big.Int).SetUint64(number),
+		GasLimit:    DEFAULT_GAS_LIMIT,
+		Time:        parent.header.Time + c.Period,
+		Extra:       makeExtra(c.fork, c.pendingValidators),
+	}
+	if err := c.seal(b.header); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *Chain) accountRoot(storageRoot ecommon.Hash) (ecommon.Hash, error) {
+	state, err := trie.New(ecommon.Hash{}, c.triedb)
+	if err != nil {
+		return ecommon.Hash{}, fmt.Errorf("open state trie error: %v", err)
+	}
+	account, err := rlp.EncodeToBytes(&stateAccount{Nonce: new(big.Int), Balance: new(big.Int), Root: storageRoot, CodeHash: emptyCode})
+	if err != nil {
+		return ecommon.Hash{}, fmt.Errorf("encode account error: %v", err)
+	}
+	if err := state.TryUpdate(crypto.Keccak256(c.CCD.Bytes()), account); err != nil {
+		return ecommon.Hash{}, fmt.Errorf("update state trie error: %v", err)
+	}
+	return state.Commit(nil)
+}
+
+// seal sets the difficulty of header by the turn of its coinbase and signs it with the key of coinbase
+func (c *Chain) seal(header *types.Header) error {
+	key, ok := c.keys[header.Coinbase]
+	if !ok {
+		return fmt.Errorf("%s is not a validator of the chain", header.Coinbase.Hex())
+	}
+	header.Difficulty = new(big.Int).Set(diffNoTurn)
+	if number := header.Number.Uint64(); number > GENESIS_HEIGHT {
+		parent := c.blockAt(number - 1)
+		if parent != nil && parent.header.Hash() == header.ParentHash {
+			validators := c.inTurnValidators(parent, number)
+			if validators[number%uint64(len(validators))] == header.Coinbase {
+				header.Difficulty.Set(diffInTurn)
+			}
+		}
+	} else {
+		header.Difficulty.Set(diffInTurn)
+	}
+	header.Extra = append([]byte{}, header.Extra...)
+	var sealHash ecommon.Hash
+	if c.Router == utils.BSC_ROUTER {
+		sealHash = bsc.SealHash(header, c.EvmChainID)
+	} else {
+		sealHash = heco.SealHash(header, c.EvmChainID)
+	}
+	sig, err := crypto.Sign(sealHash.Bytes(), key)
+	if err != nil {
+		return fmt.Errorf("sign header error: %v", err)
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+	return nil
+}
@@ -0,0 +1,203 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mockchain
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/genesis"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/header_sync"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+
+	// chain handlers of the simulated routers
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/bsc"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/heco"
+)
+
+// Env is an in memory poly state to sync mock chains and import their cross chain txs,
+// every invocation runs in a new native service and is committed only when it succeeds
+type Env struct {
+	// Operator is the only consensus node, it signs all invocations
+	Operator *account.Account
+
+	store *overlaydb.OverlayDB
+	nonce uint32
+}
+
+// NewEnv creates an Env whose governance has a single consensus node
+func NewEnv() (*Env, error) {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	if err != nil {
+		return nil, fmt.Errorf("NewEnv, NewMemLevelDBStore error: %v", err)
+	}
+	env := &Env{Operator: account.NewAccount(""), store: overlaydb.NewOverlayDB(store)}
+	db := storage.NewCacheDB(env.store)
+
+	sink := common.NewZeroCopySink(nil)
+	view := &node_manager.GovernanceView{TxHash: common.UINT256_EMPTY}
+	view.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW)), cstates.GenRawStorageItem(sink.Bytes()))
+
+	peerPubkey := vconfig.PubkeyID(env.Operator.PublicKey)
+	peerPoolMap := &node_manager.PeerPoolMap{
+		PeerPoolMap: map[string]*node_manager.PeerPoolItem{
+			peerPubkey: {
+				Address:    env.Operator.Address,
+				Status:     node_manager.ConsensusStatus,
+				PeerPubkey: peerPubkey,
+			},
+		},
+	}
+	sink.Reset()
+	peerPoolMap.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.PEER_POOL), utils.GetUint32Bytes(0)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	db.Commit()
+	return env, nil
+}
+
+// Invoke runs method with input as the operator
+func (env *Env) Invoke(method func(*native.NativeService) ([]byte, error), input []byte) ([]byte, error) {
+	env.nonce++
+	tx := genesis.NewInvokeTransaction(input, env.nonce)
+	tx.SignedAddr = []common.Address{env.Operator.Address}
+	db := storage.NewCacheDB(env.store)
+	service, err := native.NewNativeService(db, tx, 0, 0, common.Uint256{}, tx.ChainID, input, false)
+	if err != nil {
+		return nil, fmt.Errorf("Invoke, NewNativeService error: %v", err)
+	}
+	result, err := method(service)
+	if err != nil {
+		return nil, err
+	}
+	db.Commit()
+	return result, nil
+}
+
+// RegisterSideChain stores side as an approved side chain
+func (env *Env) RegisterSideChain(side *side_chain_manager.SideChain) error {
+	sink := common.NewZeroCopySink(nil)
+	if err := side.Serialization(sink); err != nil {
+		return fmt.Errorf("RegisterSideChain, serialize side chain error: %v", err)
+	}
+	db := storage.NewCacheDB(env.store)
+	db.Put(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(side_chain_manager.SIDE_CHAIN), utils.GetUint64Bytes(side.ChainId)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	db.Commit()
+	return nil
+}
+
+// RegisterChain registers c as a side chain whose txs are confirmed after blocksToWait headers
+func (env *Env) RegisterChain(c *Chain, blocksToWait uint64) error {
+	extraInfo, err := c.ExtraInfo()
+	if err != nil {
+		return fmt.Errorf("RegisterChain, ExtraInfo error: %v", err)
+	}
+	return env.RegisterSideChain(&side_chain_manager.SideChain{
+		Address:      env.Operator.Address,
+		ChainId:      c.ChainID,
+		Router:       c.Router,
+		Name:         fmt.Sprintf("mock-%d", c.ChainID),
+		BlocksToWait: blocksToWait,
+		CCMCAddress:  c.CCD.Bytes(),
+		ExtraInfo:    extraInfo,
+	})
+}
+
+// SyncGenesisHeader syncs the genesis header of c
+func (env *Env) SyncGenesisHeader(c *Chain) error {
+	genesisHeader, err := c.GenesisHeader()
+	if err != nil {
+		return fmt.Errorf("SyncGenesisHeader, GenesisHeader error: %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	(&hscommon.SyncGenesisHeaderParam{ChainID: c.ChainID, GenesisHeader: genesisHeader}).Serialization(sink)
+	_, err = env.Invoke(header_sync.SyncGenesisHeader, sink.Bytes())
+	return err
+}
+
+// SyncBlockHeaders syncs headers of c in one invocation
+func (env *Env) SyncBlockHeaders(c *Chain, headers ...*types.Header) error {
+	param := &hscommon.SyncBlockHeaderParam{ChainID: c.ChainID, Address: env.Operator.Address}
+	for _, header := range headers {
+		raw, err := json.Marshal(header)
+		if err != nil {
+			return fmt.Errorf("SyncBlockHeaders, marshal header error: %v", err)
+		}
+		param.Headers = append(param.Headers, raw)
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	_, err := env.Invoke(header_sync.SyncBlockHeader, sink.Bytes())
+	return err
+}
+
+// ImportOuterTransfer imports tx of c with its proof against the header at height
+func (env *Env) ImportOuterTransfer(c *Chain, tx *CrossChainTx, height uint64) error {
+	proof, err := c.Proof(tx, height)
+	if err != nil {
+		return fmt.Errorf("ImportOuterTransfer, %v", err)
+	}
+	return env.ImportProof(c, tx, height, proof)
+}
+
+// ImportProof imports tx of c with a given proof, e.g. one taken before a reorg
+func (env *Env) ImportProof(c *Chain, tx *CrossChainTx, height uint64, proof []byte) error {
+	sink := common.NewZeroCopySink(nil)
+	(&scom.EntranceParam{
+		SourceChainID:  c.ChainID,
+		Height:         uint32(height),
+		Proof:          proof,
+		RelayerAddress: env.Operator.Address[:],
+		Extra:          tx.Value,
+	}).Serialization(sink)
+	_, err := env.Invoke(cross_chain_manager.ImportExTransfer, sink.Bytes())
+	return err
+}
+
+// CurrentHeight returns the height of c synced in poly
+func (env *Env) CurrentHeight(c *Chain) (height uint64, err error) {
+	handler, err := hscommon.GetHeaderSyncHandler(c.Router, 0)
+	if err != nil {
+		return 0, fmt.Errorf("CurrentHeight, %v", err)
+	}
+	queryHandler, ok := handler.(hscommon.HeaderQueryHandler)
+	if !ok {
+		return 0, fmt.Errorf("CurrentHeight, %v: router %d", hscommon.ErrQueryNotSupported, c.Router)
+	}
+	_, err = env.Invoke(func(service *native.NativeService) ([]byte, error) {
+		height, err = queryHandler.GetCurrentHeight(service, c.ChainID)
+		return nil, err
+	}, nil)
+	return height, err
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mockchain
+
+import (
+	"fmt"
+	"testing"
+
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testChainID   = 100
+	targetChainID = 2
+	blocksToWait  = 3
+)
+
+var routers = map[string]uint64{"bsc": utils.BSC_ROUTER, "heco": utils.HECO_ROUTER}
+
+func newTestEnv(t *testing.T, router uint64) (*Env, *Chain) {
+	env, err := NewEnv()
+	require.NoError(t, err)
+	c, err := NewChain(router, testChainID, 4)
+	require.NoError(t, err)
+	require.NoError(t, env.RegisterChain(c, blocksToWait))
+	require.NoError(t, env.RegisterSideChain(&side_chain_manager.SideChain{
+		Address:      env.Operator.Address,
+		ChainId:      targetChainID,
+		Router:       utils.ETH_ROUTER,
+		Name:         "target",
+		BlocksToWait: 1,
+		CCMCAddress:  []byte{1},
+	}))
+	require.NoError(t, env.SyncGenesisHeader(c))
+	return env, c
+}
+
+func newTxParam(method string) *scom.MakeTxParam {
+	return &scom.MakeTxParam{
+		FromContractAddress: []byte{1, 2, 3},
+		ToChainID:           targetChainID,
+		ToContractAddress:   []byte{4, 5, 6},
+		Method:              method,
+		Args:                []byte{7, 8, 9},
+	}
+}
+
+func mineAndSync(t *testing.T, env *Env, c *Chain, n int) {
+	headers, err := c.Mine(n)
+	require.NoError(t, err)
+	require.NoError(t, env.SyncBlockHeaders(c, headers...))
+	height, err := env.CurrentHeight(c)
+	require.NoError(t, err)
+	require.Equal(t, c.Head().Number.Uint64(), height)
+}
+
+func TestImportOuterTransfer(t *testing.T) {
+	for name, router := range routers {
+		t.Run(name, func(t *testing.T) {
+			env, c := newTestEnv(t, router)
+			assert.Error(t, env.SyncGenesisHeader(c))
+
+			tx := c.AddCrossChainTx(newTxParam("unlock"))
+			mineAndSync(t, env, c, 1)
+			err := env.ImportOuterTransfer(c, tx, tx.Height)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "not confirmed")
+
+			mineAndSync(t, env, c, blocksToWait-1)
+			assert.NoError(t, env.ImportOuterTransfer(c, tx, tx.Height))
+
+			// replayed proofs are rejected, at any height
+			err = env.ImportOuterTransfer(c, tx, tx.Height)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "check done transaction")
+
+			// a tx not in the data contract only has a proof of absence
+			forged := &CrossChainTx{Index: tx.Index, Param: tx.Param, Value: append([]byte{0}, tx.Value...)}
+			assert.Error(t, env.ImportOuterTransfer(c, forged, tx.Height))
+		})
+	}
+}
+
+func TestReorg(t *testing.T) {
+	for name, router := range routers {
+		t.Run(name, func(t *testing.T) {
+			env, c := newTestEnv(t, router)
+			mineAndSync(t, env, c, 2)
+
+			orphaned := c.AddCrossChainTx(newTxParam("orphaned"))
+			mineAndSync(t, env, c, blocksToWait+2)
+			height := orphaned.Height + blocksToWait
+			proof, err := c.Proof(orphaned, height)
+			require.NoError(t, err)
+
+			require.NoError(t, c.SetHead(orphaned.Height-1))
+			mineAndSync(t, env, c, 2*blocksToWait+4)
+
+			// the header at height is replaced so the proof taken on the orphaned branch fails
+			err = env.ImportProof(c, orphaned, height, proof)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "verify account proof")
+
+			tx := c.AddCrossChainTx(newTxParam("canonical"))
+			mineAndSync(t, env, c, blocksToWait)
+			assert.NoError(t, env.ImportOuterTransfer(c, tx, tx.Height))
+		})
+	}
+}
+
+func TestSwitchValidators(t *testing.T) {
+	for name, router := range routers {
+		t.Run(name, func(t *testing.T) {
+			env, c := newTestEnv(t, router)
+			mineAndSync(t, env, c, 2)
+
+			old := c.Validators()
+			_, err := c.SwitchValidators(5)
+			require.NoError(t, err)
+			mineAndSync(t, env, c, 6)
+
+			header, err := c.NextHeader()
+			require.NoError(t, err)
+			resealed, err := c.Reseal(header, old[0])
+			require.NoError(t, err)
+			err = env.SyncBlockHeaders(c, resealed)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid signer")
+
+			tx := c.AddCrossChainTx(newTxParam("unlock"))
+			mineAndSync(t, env, c, blocksToWait)
+			assert.NoError(t, env.ImportOuterTransfer(c, tx, tx.Height))
+		})
+	}
+}
+
+func TestNewChain(t *testing.T) {
+	_, err := NewChain(utils.NEO_ROUTER, testChainID, 4)
+	assert.Error(t, err)
+	for router, reason := range NotSimulated {
+		_, err := NewChain(router, testChainID, 4)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), reason)
+	}
+	for name, router := range routers {
+		c, err := NewChain(router, testChainID, 1)
+		require.NoError(t, err, name)
+		_, err = c.Mine(3)
+		assert.NoError(t, err, fmt.Sprintf("%s with a single validator", name))
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+
+	// chain packages register their handlers under their router in init
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/bsc"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/cosmos"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/eth"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/heco"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/msc"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/neo"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/neo3"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/okex"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/ont"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/polygon"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/quorum"
+	_ "github.com/polynetwork/poly/native/service/cross_chain_manager/zilliqa"
+	_ "github.com/polynetwork/poly/native/service/header_sync/bsc"
+	_ "github.com/polynetwork/poly/native/service/header_sync/btc"
+	_ "github.com/polynetwork/poly/native/service/header_sync/cosmos"
+	_ "github.com/polynetwork/poly/native/service/header_sync/eth"
+	_ "github.com/polynetwork/poly/native/service/header_sync/heco"
+	_ "github.com/polynetwork/poly/native/service/header_sync/msc"
+	_ "github.com/polynetwork/poly/native/service/header_sync/neo"
+	_ "github.com/polynetwork/poly/native/service/header_sync/neo3"
+	_ "github.com/polynetwork/poly/native/service/header_sync/neo3legacy"
+	_ "github.com/polynetwork/poly/native/service/header_sync/okex"
+	_ "github.com/polynetwork/poly/native/service/header_sync/ont"
+	_ "github.com/polynetwork/poly/native/service/header_sync/polygon"
+	_ "github.com/polynetwork/poly/native/service/header_sync/quorum"
+	_ "github.com/polynetwork/poly/native/service/header_sync/zilliqa"
+)
+
+// UnpairedRouters reports the routers which only have one of the two handlers
+// registered, noCrossChain have a header sync handler but no cross chain handler
+// and noHeaderSync the other way round.
+func UnpairedRouters() (noCrossChain []uint64, noHeaderSync []uint64) {
+	crossChain := make(map[uint64]bool)
+	for _, router := range scom.RegisteredRouters() {
+		crossChain[router] = true
+	}
+	headerSync := make(map[uint64]bool)
+	for _, router := range hscommon.RegisteredRouters() {
+		headerSync[router] = true
+		if !crossChain[router] {
+			noCrossChain = append(noCrossChain, router)
+		}
+	}
+	for _, router := range scom.RegisteredRouters() {
+		if !headerSync[router] {
+			noHeaderSync = append(noHeaderSync, router)
+		}
+	}
+	return noCrossChain, noHeaderSync
+}
@@ -30,6 +30,16 @@ type CacheDB struct {
 	memdb      *overlaydb.MemDB
 	backend    *overlaydb.OverlayDB
 	keyScratch []byte
+	gasMeter   GasMeter
+	journaling bool           //record the overwritten values once a snapshot is taken
+	journal    []journalEntry //overwritten values of transaction cache, undone by RevertToSnapshot
+}
+
+// journalEntry is the value of key in transaction cache before it's overwritten
+type journalEntry struct {
+	key     []byte
+	value   []byte
+	unknown bool //key was absent from transaction cache, it is erased on revert
 }
 
 const initCap = 16 * 1024
@@ -45,6 +55,20 @@ func NewCacheDB(store *overlaydb.OverlayDB) *CacheDB {
 
 func (self *CacheDB) Reset() {
 	self.memdb.Reset()
+	self.journaling = false
+	self.journal = self.journal[:0]
+}
+
+// SetGasMeter sets the meter charged for the storage access, nil disables charging
+func (self *CacheDB) SetGasMeter(meter GasMeter) {
+	self.gasMeter = meter
+}
+
+func (self *CacheDB) useGas(gas uint64) error {
+	if self.gasMeter == nil {
+		return nil
+	}
+	return self.gasMeter.UseGas(gas)
 }
 
 func ensureBuffer(b []byte, n int) []byte {
@@ -72,16 +96,55 @@ func (self *CacheDB) Commit() {
 	})
 }
 
+// Snapshot returns the id of current transaction cache state, which is restored by RevertToSnapshot
+// to discard the changes made after it. Writes are journaled from the first snapshot till Reset
+func (self *CacheDB) Snapshot() int {
+	self.journaling = true
+	return len(self.journal)
+}
+
+// RevertToSnapshot restores the transaction cache to the state of snapshot id
+func (self *CacheDB) RevertToSnapshot(id int) {
+	for i := len(self.journal) - 1; i >= id; i-- {
+		entry := self.journal[i]
+		if entry.unknown {
+			self.memdb.Erase(entry.key)
+		} else {
+			self.memdb.Put(entry.key, entry.value)
+		}
+	}
+	self.journal = self.journal[:id]
+}
+
+func (self *CacheDB) record(key []byte) {
+	if !self.journaling {
+		return
+	}
+	value, unknown := self.memdb.Get(key)
+	self.journal = append(self.journal, journalEntry{
+		key:     append([]byte{}, key...),
+		value:   append([]byte{}, value...),
+		unknown: unknown,
+	})
+}
+
+// Put charges the gas meter before writing, running out of gas is kept by the meter
+// and fails the execution when it returns
 func (self *CacheDB) Put(key []byte, value []byte) {
+	self.useGas(STORAGE_PUT_GAS + STORAGE_BYTE_GAS*uint64(len(key)+len(value)))
 	self.put(common.ST_STORAGE, key, value)
 }
 
 func (self *CacheDB) put(prefix common.DataEntryPrefix, key []byte, value []byte) {
 	self.keyScratch = makePrefixedKey(self.keyScratch, byte(prefix), key)
+	self.record(self.keyScratch)
 	self.memdb.Put(self.keyScratch, value)
 }
 
 func (self *CacheDB) Get(key []byte) ([]byte, error) {
+	if err := self.useGas(STORAGE_GET_GAS); err != nil {
+		return nil, err
+	}
 	return self.get(common.ST_STORAGE, key)
 }
 
@@ -100,16 +163,19 @@ func (self *CacheDB) get(prefix common.DataEntryPrefix, key []byte) ([]byte, err
 }
 
 func (self *CacheDB) Delete(key []byte) {
+	self.useGas(STORAGE_DELETE_GAS)
 	self.delete(common.ST_STORAGE, key)
 }
 
 // Delete item from cache
 func (self *CacheDB) delete(prefix common.DataEntryPrefix, key []byte) {
 	self.keyScratch = makePrefixedKey(self.keyScratch, byte(prefix), key)
+	self.record(self.keyScratch)
 	self.memdb.Delete(self.keyScratch)
 }
 
 func (self *CacheDB) NewIterator(key []byte) common.StoreIterator {
+	self.useGas(STORAGE_ITERATOR_GAS)
 	pkey := make([]byte, 1+len(key))
 	pkey[0] = byte(common.ST_STORAGE)
 	copy(pkey[1:], key)
@@ -18,6 +18,7 @@
 package storage
 
 import (
+	"errors"
 	"github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/store/leveldbstore"
 	"github.com/polynetwork/poly/core/store/overlaydb"
@@ -82,3 +83,98 @@ func TestCacheDB(t *testing.T) {
 	}
 
 }
+
+type testGasMeter struct {
+	used  uint64
+	limit uint64
+}
+
+func (self *testGasMeter) UseGas(gas uint64) error {
+	self.used += gas
+	if self.used > self.limit {
+		return errors.New("out of gas")
+	}
+	return nil
+}
+
+func TestCacheDBGasMeter(t *testing.T) {
+	memback, _ := leveldbstore.NewMemLevelDBStore()
+	cache := NewCacheDB(overlaydb.NewOverlayDB(memback))
+	meter := &testGasMeter{limit: STORAGE_PUT_GAS + STORAGE_BYTE_GAS*2 + STORAGE_GET_GAS}
+	cache.SetGasMeter(meter)
+
+	cache.Put([]byte{1}, []byte{2})
+	assert.Equal(t, STORAGE_PUT_GAS+STORAGE_BYTE_GAS*2, meter.used)
+	value, err := cache.Get([]byte{1})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{2}, value)
+
+	_, err = cache.Get([]byte{1})
+	assert.NotNil(t, err)
+
+	cache.SetGasMeter(nil)
+	_, err = cache.Get([]byte{1})
+	assert.Nil(t, err)
+}
+
+func TestCacheDBSnapshot(t *testing.T) {
+	memback, _ := leveldbstore.NewMemLevelDBStore()
+	cache := NewCacheDB(overlaydb.NewOverlayDB(memback))
+	cache.Put([]byte{1}, []byte{1})
+	cache.Put([]byte{2}, []byte{2})
+	cache.Delete([]byte{3})
+
+	snapshot := cache.Snapshot()
+	cache.Put([]byte{1}, []byte{10})
+	cache.Delete([]byte{2})
+	cache.Put([]byte{3}, []byte{3})
+	cache.Put([]byte{4}, []byte{4})
+	cache.RevertToSnapshot(snapshot)
+
+	value, err := cache.Get([]byte{1})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{1}, value)
+	value, err = cache.Get([]byte{2})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{2}, value)
+	value, err = cache.Get([]byte{3})
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+	value, err = cache.Get([]byte{4})
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+
+	//keys absent from transaction cache before the snapshot are not written by the revert
+	_, unknown := cache.memdb.Get([]byte{byte(common.ST_STORAGE), 4})
+	assert.True(t, unknown)
+	assert.Equal(t, 3, cache.memdb.Len())
+}
+
+func TestCacheDBNestedSnapshot(t *testing.T) {
+	memback, _ := leveldbstore.NewMemLevelDBStore()
+	overlay := overlaydb.NewOverlayDB(memback)
+	cache := NewCacheDB(overlay)
+	cache.Put([]byte{1}, []byte{1})
+	cache.Commit()
+	cache.Reset()
+
+	outer := cache.Snapshot()
+	cache.Put([]byte{1}, []byte{10})
+	inner := cache.Snapshot()
+	cache.Put([]byte{1}, []byte{20})
+	cache.Put([]byte{2}, []byte{2})
+	cache.RevertToSnapshot(inner)
+
+	value, err := cache.Get([]byte{1})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{10}, value)
+	value, err = cache.Get([]byte{2})
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+
+	//key untouched before the snapshot reads the backend value again
+	cache.RevertToSnapshot(outer)
+	value, err = cache.Get([]byte{1})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{1}, value)
+}
@@ -0,0 +1,33 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package storage
+
+// gas cost of storage access
+const (
+	STORAGE_GET_GAS      uint64 = 100
+	STORAGE_PUT_GAS      uint64 = 500
+	STORAGE_BYTE_GAS     uint64 = 1 // for every byte of key and value written
+	STORAGE_DELETE_GAS   uint64 = 100
+	STORAGE_ITERATOR_GAS uint64 = 500
+)
+
+// GasMeter is charged by CacheDB for every storage access
+type GasMeter interface {
+	UseGas(gas uint64) error
+}
@@ -86,6 +86,12 @@ func (this *P2PActor) Receive(ctx actor.Context) {
 		this.handleGetNodeTypeReq(ctx, msg)
 	case *TransmitConsensusMsgReq:
 		this.handleTransmitConsensusMsgReq(ctx, msg)
+	case *GetPeersReq:
+		this.handleGetPeersReq(ctx, msg)
+	case *BanPeerReq:
+		this.handleBanPeerReq(ctx, msg)
+	case *UnbanPeerReq:
+		this.handleUnbanPeerReq(ctx, msg)
 	case *common.AppendPeerID:
 		this.server.OnAddNode(msg.ID)
 	case *common.RemovePeerID:
@@ -94,6 +100,10 @@ func (this *P2PActor) Receive(ctx actor.Context) {
 		this.server.OnHeaderReceive(msg.FromID, msg.Headers)
 	case *common.AppendBlock:
 		this.server.OnBlockReceive(msg.FromID, msg.BlockSize, msg.Block, msg.MerkleRoot)
+	case *common.AppendSnapshot:
+		this.server.OnSnapshotReceive(msg.FromID, msg.Height, msg.Index, msg.Data)
+	case *common.NotFoundData:
+		this.server.OnBlockNotFound(msg.FromID, msg.Hash)
 	default:
 		err := this.server.Xmit(ctx.Message())
 		if nil != err {
@@ -235,6 +245,52 @@ func (this *P2PActor) handleGetNodeTypeReq(ctx actor.Context, req *GetNodeTypeRe
 	}
 }
 
+//nbr peers and bans handler
+func (this *P2PActor) handleGetPeersReq(ctx actor.Context, req *GetPeersReq) {
+	network := this.server.GetNetWork()
+	nbrs := network.GetNeighbors()
+	peers := make([]PeerInfo, 0, len(nbrs))
+	for _, p := range nbrs {
+		peers = append(peers, PeerInfo{
+			Id:            p.GetID(),
+			Addr:          p.GetAddr(),
+			SoftVersion:   p.GetSoftVersion(),
+			Height:        p.GetHeight(),
+			Authenticated: p.GetPubKey() != nil,
+			Score:         network.GetPeerScore(p.GetID(), p.GetAddr()),
+		})
+	}
+	if ctx.Sender() != nil {
+		resp := &GetPeersRsp{
+			Peers: peers,
+			Bans:  network.GetBanList(),
+		}
+		ctx.Sender().Request(resp, ctx.Self())
+	}
+}
+
+//ban peer handler
+func (this *P2PActor) handleBanPeerReq(ctx actor.Context, req *BanPeerReq) {
+	err := this.server.GetNetWork().BanPeer(req.Id, req.IP, req.Duration, req.Reason)
+	if ctx.Sender() != nil {
+		resp := &BanPeerRsp{
+			Error: err,
+		}
+		ctx.Sender().Request(resp, ctx.Self())
+	}
+}
+
+//unban peer handler
+func (this *P2PActor) handleUnbanPeerReq(ctx actor.Context, req *UnbanPeerReq) {
+	found := this.server.GetNetWork().UnbanPeer(req.Id, req.IP)
+	if ctx.Sender() != nil {
+		resp := &UnbanPeerRsp{
+			Found: found,
+		}
+		ctx.Sender().Request(resp, ctx.Self())
+	}
+}
+
 func (this *P2PActor) handleTransmitConsensusMsgReq(ctx actor.Context, req *TransmitConsensusMsgReq) {
 	peer := this.server.GetNetWork().GetPeer(req.Target)
 	if peer != nil {
@@ -19,8 +19,11 @@
 package server
 
 import (
+	"time"
+
 	types "github.com/polynetwork/poly/p2pserver/common"
 	ptypes "github.com/polynetwork/poly/p2pserver/message/types"
+	"github.com/polynetwork/poly/p2pserver/peer"
 )
 
 //stop net server
@@ -128,6 +131,50 @@ type GetNeighborAddrsRsp struct {
 	Addrs []types.PeerAddr
 }
 
+//reputation of a nbr peer
+type PeerInfo struct {
+	Id            uint64
+	Addr          string
+	SoftVersion   string
+	Height        uint64
+	Authenticated bool //whether the peer id is bound to a node key
+	Score         int  //misbehavior score, banned at BAN_SCORE
+}
+
+//get nbr peers and bans request
+type GetPeersReq struct {
+}
+
+//response of nbr peers and bans
+type GetPeersRsp struct {
+	Peers []PeerInfo
+	Bans  []peer.BanEntry
+}
+
+//ban peer by id, or by ip if id is 0
+type BanPeerReq struct {
+	Id       uint64
+	IP       string
+	Duration time.Duration
+	Reason   string
+}
+
+//response of ban peer request
+type BanPeerRsp struct {
+	Error error
+}
+
+//unban peer by id, or by ip if id is 0
+type UnbanPeerReq struct {
+	Id uint64
+	IP string
+}
+
+//response of unban peer request
+type UnbanPeerRsp struct {
+	Found bool
+}
+
 type TransmitConsensusMsgReq struct {
 	Target uint64
 	Msg    ptypes.Message
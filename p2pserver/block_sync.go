@@ -19,7 +19,9 @@
 package p2pserver
 
 import (
+	"fmt"
 	"math"
+	"runtime"
 	"sort"
 	"sync"
 	"time"
@@ -28,6 +30,7 @@ import (
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/ledger"
 	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/core/validation"
 	p2pComm "github.com/polynetwork/poly/p2pserver/common"
 	"github.com/polynetwork/poly/p2pserver/message/msg_pack"
 	"github.com/polynetwork/poly/p2pserver/peer"
@@ -36,7 +39,7 @@ import (
 const (
 	SYNC_MAX_HEADER_FORWARD_SIZE = 5000       //keep CurrentHeaderHeight - CurrentBlockHeight <= SYNC_MAX_HEADER_FORWARD_SIZE
 	SYNC_MAX_FLIGHT_HEADER_SIZE  = 1          //Number of headers on flight
-	SYNC_MAX_FLIGHT_BLOCK_SIZE   = 50         //Number of blocks on flight
+	SYNC_MAX_FLIGHT_BLOCK_SIZE   = 50         //Number of blocks on flight per sync node
 	SYNC_MAX_BLOCK_CACHE_SIZE    = 500        //Cache size of block wait to commit to ledger
 	SYNC_HEADER_REQUEST_TIMEOUT  = 2          //s, Request header timeout time. If header haven't receive after SYNC_HEADER_REQUEST_TIMEOUT second, retry
 	SYNC_BLOCK_REQUEST_TIMEOUT   = 2          //s, Request block timeout time. If block haven't received after SYNC_BLOCK_REQUEST_TIMEOUT second, retry
@@ -47,6 +50,7 @@ const (
 	SYNC_NODE_SPEED_INIT         = 100 * 1024 //Init a big speed (100MB/s) for every node in first round
 	SYNC_MAX_ERROR_RESP_TIMES    = 5          //Max error headers/blocks response times, if reaches, delete it
 	SYNC_MAX_HEIGHT_OFFSET       = 5          //Offset of the max height and current height
+	SYNC_PROGRESS_INTERVAL       = 10         //s, Interval of logging the block sync progress
 )
 
 //NodeWeight record some params of node, using for sort
@@ -56,6 +60,8 @@ type NodeWeight struct {
 	timeoutCnt   int       //Node response timeout count
 	errorRespCnt int       //Node response error data count
 	reqTime      []int64   //Record request time, using for calc the avg req time interval, unit millisecond
+	rangeRecvCnt int       //Blocks received from node by ranged requests
+	noRange      bool      //Node doesn't answer ranged blocks requests, request blocks by hash
 }
 
 //NewNodeWeight new a nodeweight
@@ -93,6 +99,23 @@ func (this *NodeWeight) GetErrorRespCnt() int {
 	return this.errorRespCnt
 }
 
+//AddRangeRecvCnt incre the count of blocks received by ranged requests
+func (this *NodeWeight) AddRangeRecvCnt() {
+	this.rangeRecvCnt++
+}
+
+//SetRangeUnsupported mark node not answering ranged blocks requests, if it hasn't answered any
+func (this *NodeWeight) SetRangeUnsupported() {
+	if this.rangeRecvCnt == 0 {
+		this.noRange = true
+	}
+}
+
+//IsRangeSupported return whether blocks can be requested from node by range
+func (this *NodeWeight) IsRangeSupported() bool {
+	return !this.noRange
+}
+
 //AppendNewReqTime append new request time
 func (this *NodeWeight) AppendNewReqtime() {
 	copy(this.reqTime[0:SYNC_NODE_RECORD_TIME_CNT-1], this.reqTime[1:])
@@ -146,6 +169,7 @@ type SyncFlightInfo struct {
 	startTime   time.Time      //Request start time
 	failedNodes map[uint64]int //Map nodeId => timeout times
 	totalFailed int            //Total timeout times
+	ranged      bool           //Requested in a ranged blocks request
 	lock        sync.RWMutex
 }
 
@@ -166,11 +190,19 @@ func (this *SyncFlightInfo) GetNodeId() uint64 {
 	return this.nodeId
 }
 
-//SetNodeId set a new node id
+//SetNodeId set a new node id, the retried request is sent by hash
 func (this *SyncFlightInfo) SetNodeId(nodeId uint64) {
 	this.lock.Lock()
 	defer this.lock.Unlock()
 	this.nodeId = nodeId
+	this.ranged = false
+}
+
+//IsRanged return whether the flight is requested in a ranged blocks request
+func (this *SyncFlightInfo) IsRanged() bool {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.ranged
 }
 
 //MarkFailedNode mark node failed, after request timeout
@@ -218,6 +250,7 @@ type BlockInfo struct {
 	nodeID     uint64
 	block      *types.Block
 	merkleRoot common.Uint256
+	verified   bool
 }
 
 //BlockSyncMgr is the manager class to deal with block sync
@@ -233,6 +266,9 @@ type BlockSyncMgr struct {
 	ledger         *ledger.Ledger                       //ledger
 	lock           sync.RWMutex                         //lock
 	nodeWeights    map[uint64]*NodeWeight               //Map NodeID => NodeStatus, using for getNextNode
+	verifyCh       chan *BlockInfo                      //Blocks waiting for verification before commit
+	progressTime   time.Time                            //Start time of the current progress interval
+	progressHeight uint32                               //Block height at the start of the current progress interval
 }
 
 //NewBlockSyncMgr return a BlockSyncMgr instance
@@ -245,11 +281,19 @@ func NewBlockSyncMgr(server *P2PServer) *BlockSyncMgr {
 		ledger:        server.ledger,
 		exitCh:        make(chan interface{}, 1),
 		nodeWeights:   make(map[uint64]*NodeWeight, 0),
+		verifyCh:      make(chan *BlockInfo, SYNC_MAX_BLOCK_CACHE_SIZE),
 	}
 }
 
-//Start to sync
+//Start to sync. Sync resumes from the block height of the ledger, headers are
+//synced first, blocks are fetched by ranges from the nodes in parallel, verified
+//by a pool of workers and committed to the ledger in order
 func (this *BlockSyncMgr) Start() {
+	log.Infof("[p2p]block sync start at block height:%d header height:%d",
+		this.ledger.GetCurrentBlockHeight(), this.ledger.GetCurrentHeaderHeight())
+	for i := 0; i < runtime.NumCPU(); i++ {
+		go this.verifyBlocks()
+	}
 	go this.sync()
 	ticker := time.NewTicker(time.Second)
 	for {
@@ -313,6 +357,9 @@ func (this *BlockSyncMgr) checkTimeout() {
 	for blockHash, flightInfos := range blockTimeoutFlights {
 		for _, flightInfo := range flightInfos {
 			this.addTimeoutCnt(flightInfo.GetNodeId())
+			if flightInfo.IsRanged() {
+				this.setRangeUnsupported(flightInfo.GetNodeId())
+			}
 			if flightInfo.Height <= curBlockHeight {
 				this.delFlightBlock(blockHash)
 				continue
@@ -387,7 +434,7 @@ func (this *BlockSyncMgr) syncBlock() {
 	}
 	defer this.releaseSyncBlockLock()
 
-	availCount := SYNC_MAX_FLIGHT_BLOCK_SIZE - this.getFlightBlockCount()
+	availCount := this.getMaxFlightBlockCount() - this.getFlightBlockCount()
 	if availCount <= 0 {
 		return
 	}
@@ -407,7 +454,7 @@ func (this *BlockSyncMgr) syncBlock() {
 
 	counter := 1
 	i := uint32(0)
-	reqTimes := 1
+	rangeHeights := make([]uint32, 0, count)
 	for {
 		if counter > count {
 			break
@@ -416,40 +463,81 @@ func (this *BlockSyncMgr) syncBlock() {
 		nextBlockHeight := curBlockHeight + i
 		nextBlockHash := this.ledger.GetBlockHash(nextBlockHeight)
 		if nextBlockHash == common.UINT256_EMPTY {
-			return
+			break
 		}
-		if this.isBlockOnFlight(nextBlockHash) {
-			if nextBlockHeight <= curBlockHeight+SYNC_NEXT_BLOCKS_HEIGHT {
-				//request more nodes for next block height
-				reqTimes = SYNC_NEXT_BLOCK_TIMES
-			} else {
-				continue
-			}
+		isNextBlock := nextBlockHeight <= curBlockHeight+SYNC_NEXT_BLOCKS_HEIGHT
+		if !isNextBlock && this.isBlockOnFlight(nextBlockHash) {
+			continue
 		}
 		if this.isInBlockCache(nextBlockHeight) {
 			continue
 		}
-		if nextBlockHeight <= curBlockHeight+SYNC_NEXT_BLOCKS_HEIGHT {
-			reqTimes = SYNC_NEXT_BLOCK_TIMES
+		counter++
+		if !isNextBlock {
+			rangeHeights = append(rangeHeights, nextBlockHeight)
+			continue
 		}
-		for t := 0; t < reqTimes; t++ {
+		//request more nodes for next block height
+		for t := 0; t < SYNC_NEXT_BLOCK_TIMES; t++ {
 			reqNode := this.getNextNode(nextBlockHeight)
 			if reqNode == nil {
 				return
 			}
-			this.addFlightBlock(reqNode.GetID(), nextBlockHeight, nextBlockHash)
-			msg := msgpack.NewBlkDataReq(nextBlockHash)
-			err := this.server.Send(reqNode, msg, false)
+			err := this.reqBlock(reqNode, nextBlockHeight, nextBlockHash)
 			if err != nil {
 				log.Warnf("[p2p]syncBlock Height:%d ReqBlkData error:%s", nextBlockHeight, err)
 				return
-			} else {
-				this.appendReqTime(reqNode.GetID())
 			}
 		}
-		counter++
-		reqTimes = 1
 	}
+	for _, r := range splitBlockRanges(rangeHeights, p2pComm.MAX_REQ_BLK_ONCE) {
+		if !this.reqBlockRange(r[0], r[1]) {
+			return
+		}
+	}
+}
+
+//reqBlock request a block by hash from the node
+func (this *BlockSyncMgr) reqBlock(reqNode *peer.Peer, height uint32, blockHash common.Uint256) error {
+	this.addFlightBlock(reqNode.GetID(), height, blockHash)
+	msg := msgpack.NewBlkDataReq(blockHash)
+	err := this.server.Send(reqNode, msg, false)
+	if err != nil {
+		return err
+	}
+	this.appendReqTime(reqNode.GetID())
+	return nil
+}
+
+//reqBlockRange request the blocks from startHeight to stopHeight from the node with the
+//highest weight. Blocks are requested by hash if the node doesn't answer ranged requests
+func (this *BlockSyncMgr) reqBlockRange(startHeight, stopHeight uint32) bool {
+	reqNode := this.getNextNode(stopHeight)
+	if reqNode == nil {
+		return false
+	}
+	n := this.getNodeWeight(reqNode.GetID())
+	if startHeight == stopHeight || n == nil || !n.IsRangeSupported() {
+		for height := startHeight; height <= stopHeight; height++ {
+			err := this.reqBlock(reqNode, height, this.ledger.GetBlockHash(height))
+			if err != nil {
+				log.Warnf("[p2p]syncBlock Height:%d ReqBlkData error:%s", height, err)
+				return false
+			}
+		}
+		return true
+	}
+	startHash := this.ledger.GetBlockHash(startHeight)
+	stopHash := this.ledger.GetBlockHash(stopHeight)
+	this.addRangeFlightBlocks(reqNode.GetID(), startHeight, stopHeight)
+	msg := msgpack.NewBlocksReq(startHash, stopHash, uint8(stopHeight-startHeight+1))
+	err := this.server.Send(reqNode, msg, false)
+	if err != nil {
+		log.Warnf("[p2p]syncBlock Height:%d - %d ReqBlocks error:%s", startHeight, stopHeight, err)
+		return false
+	}
+	this.appendReqTime(reqNode.GetID())
+	return true
 }
 
 //OnHeaderReceive receive header from net
@@ -472,6 +560,7 @@ func (this *BlockSyncMgr) OnHeaderReceive(fromID uint64, headers []*types.Header
 	this.delFlightHeader(height)
 	if err != nil {
 		this.addErrorRespCnt(fromID)
+		this.penalize(fromID, "invalid headers")
 		n := this.getNodeWeight(fromID)
 		if n != nil && n.GetErrorRespCnt() >= SYNC_MAX_ERROR_RESP_TIMES {
 			this.delNode(fromID)
@@ -493,6 +582,9 @@ func (this *BlockSyncMgr) OnBlockReceive(fromID uint64, blockSize uint32, block
 		t := (time.Now().UnixNano() - flightInfo.GetStartTime().UnixNano()) / int64(time.Millisecond)
 		s := float32(blockSize) / float32(t) * 1000.0 / 1024.0
 		this.addNewSpeed(fromID, s)
+		if flightInfo.IsRanged() {
+			this.addRangeRecvCnt(fromID)
+		}
 	}
 
 	this.delFlightBlock(blockHash)
@@ -506,11 +598,96 @@ func (this *BlockSyncMgr) OnBlockReceive(fromID uint64, blockSize uint32, block
 		return
 	}
 
-	this.addBlockCache(fromID, block, merkleRoot)
-	go this.saveBlock()
+	blockInfo := this.addBlockCache(fromID, block, merkleRoot)
+	if blockInfo == nil {
+		return
+	}
+	select {
+	case this.verifyCh <- blockInfo:
+	default:
+		//verification falls behind, drop the block to request it again
+		this.delBlockInfo(blockInfo)
+	}
 	this.syncBlock()
 }
 
+// OnBlockNotFound handles a node refusing to serve a requested block, as a pruned node does for
+// the blocks it doesn't keep. The block is requested from another node without waiting for the timeout
+func (this *BlockSyncMgr) OnBlockNotFound(fromID uint64, blockHash common.Uint256) {
+	flightInfo := this.getFlightBlock(blockHash, fromID)
+	if flightInfo == nil {
+		return
+	}
+	curBlockHeight := this.ledger.GetCurrentBlockHeight()
+	if flightInfo.Height <= curBlockHeight {
+		this.delFlightBlock(blockHash)
+		return
+	}
+	flightInfo.ResetStartTime()
+	flightInfo.MarkFailedNode()
+	log.Debugf("[p2p]OnBlockNotFound height:%d block:0x%x refused by id:%d", flightInfo.Height, blockHash, fromID)
+	reqNode := this.getNodeWithMinFailedTimes(flightInfo, curBlockHeight)
+	if reqNode == nil || reqNode.GetID() == fromID {
+		return
+	}
+	flightInfo.SetNodeId(reqNode.GetID())
+	err := this.server.Send(reqNode, msgpack.NewBlkDataReq(blockHash), false)
+	if err != nil {
+		log.Warnf("[p2p]OnBlockNotFound reqNode ID:%d Send error:%s", reqNode.GetID(), err)
+		return
+	}
+	this.appendReqTime(reqNode.GetID())
+}
+
+//verifyBlocks verify the received blocks before they are committed to ledger.
+//Several workers run it concurrently
+func (this *BlockSyncMgr) verifyBlocks() {
+	for {
+		select {
+		case <-this.exitCh:
+			return
+		case blockInfo := <-this.verifyCh:
+			err := verifyBlock(this.ledger, blockInfo.block)
+			if err != nil {
+				height := blockInfo.block.Header.Height
+				this.delBlockInfo(blockInfo)
+				log.Warnf("[p2p]verifyBlocks Height:%d verify block error:%s", height, err)
+				this.onInvalidBlock(blockInfo.nodeID, height, this.ledger.GetBlockHash(height))
+				continue
+			}
+			this.setBlockVerified(blockInfo)
+			this.saveBlock()
+		}
+	}
+}
+
+//verifyBlock checks the block matches the synced header, whose signatures are verified
+//by ledger, and checks the transactions root and the signatures of the transactions
+func verifyBlock(ld *ledger.Ledger, block *types.Block) error {
+	blockHash := block.Hash()
+	headerHash := ld.GetBlockHash(block.Header.Height)
+	if headerHash != common.UINT256_EMPTY && headerHash != blockHash {
+		return fmt.Errorf("block hash %s mismatch header hash %s", blockHash.ToHexString(),
+			headerHash.ToHexString())
+	}
+	hashes := make([]common.Uint256, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		hashes = append(hashes, tx.Hash())
+	}
+	root := common.ComputeMerkleRoot(hashes)
+	if block.Header.TransactionsRoot != root {
+		return fmt.Errorf("mismatched transaction root %s and %s",
+			block.Header.TransactionsRoot.ToHexString(), root.ToHexString())
+	}
+	for i, tx := range block.Transactions {
+		err := validation.VerifyTransactionSignatures(tx)
+		if err != nil {
+			return fmt.Errorf("transaction %s verify signatures error:%s", hashes[i].ToHexString(), err)
+		}
+	}
+	return nil
+}
+
 //OnAddNode to node list when a new node added
 func (this *BlockSyncMgr) OnAddNode(nodeId uint64) {
 	log.Debugf("[p2p]OnAddNode:%d", nodeId)
@@ -570,30 +747,51 @@ func (this *BlockSyncMgr) releaseSyncBlockLock() {
 	this.syncBlockLock = false
 }
 
+//addBlockCache add the block to cache, return nil if a block of the height is cached
 func (this *BlockSyncMgr) addBlockCache(nodeID uint64, block *types.Block,
-	merkleRoot common.Uint256) bool {
+	merkleRoot common.Uint256) *BlockInfo {
 	this.lock.Lock()
 	defer this.lock.Unlock()
+	if _, ok := this.blocksCache[block.Header.Height]; ok {
+		return nil
+	}
 	blockInfo := &BlockInfo{
 		nodeID:     nodeID,
 		block:      block,
 		merkleRoot: merkleRoot,
 	}
 	this.blocksCache[block.Header.Height] = blockInfo
-	return true
+	return blockInfo
 }
 
+//getBlockCache return the verified block in cache
 func (this *BlockSyncMgr) getBlockCache(blockHeight uint32) (uint64, *types.Block,
 	common.Uint256) {
 	this.lock.RLock()
 	defer this.lock.RUnlock()
 	blockInfo, ok := this.blocksCache[blockHeight]
-	if !ok {
+	if !ok || !blockInfo.verified {
 		return 0, nil, common.UINT256_EMPTY
 	}
 	return blockInfo.nodeID, blockInfo.block, blockInfo.merkleRoot
 }
 
+func (this *BlockSyncMgr) setBlockVerified(blockInfo *BlockInfo) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	blockInfo.verified = true
+}
+
+//delBlockInfo remove the block from cache if it isn't replaced
+func (this *BlockSyncMgr) delBlockInfo(blockInfo *BlockInfo) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	height := blockInfo.block.Header.Height
+	if this.blocksCache[height] == blockInfo {
+		delete(this.blocksCache, height)
+	}
+}
+
 func (this *BlockSyncMgr) delBlockCache(blockHeight uint32) {
 	this.lock.Lock()
 	defer this.lock.Unlock()
@@ -638,32 +836,59 @@ func (this *BlockSyncMgr) saveBlock() {
 		err := this.ledger.AddBlock(nextBlock, merkleRoot)
 		this.delBlockCache(nextBlockHeight)
 		if err != nil {
-			this.addErrorRespCnt(fromID)
-			n := this.getNodeWeight(fromID)
-			if n != nil && n.GetErrorRespCnt() >= SYNC_MAX_ERROR_RESP_TIMES {
-				this.delNode(fromID)
-			}
 			log.Warnf("[p2p]saveBlock Height:%d AddBlock error:%s", nextBlockHeight, err)
-			reqNode := this.getNextNode(nextBlockHeight)
-			if reqNode == nil {
-				return
-			}
-			this.addFlightBlock(reqNode.GetID(), nextBlockHeight, nextBlock.Hash())
-			msg := msgpack.NewBlkDataReq(nextBlock.Hash())
-			err := this.server.Send(reqNode, msg, false)
-			if err != nil {
-				log.Warn("[p2p]require new block error:", err)
-				return
-			} else {
-				this.appendReqTime(reqNode.GetID())
-			}
+			this.onInvalidBlock(fromID, nextBlockHeight, nextBlock.Hash())
 			return
 		}
+		this.logProgress(nextBlockHeight)
 		nextBlockHeight++
 		this.pingOutsyncNodes(nextBlockHeight - 1)
 	}
 }
 
+//onInvalidBlock punish the node sending an invalid block, and request the block from another node
+func (this *BlockSyncMgr) onInvalidBlock(fromID uint64, height uint32, blockHash common.Uint256) {
+	this.addErrorRespCnt(fromID)
+	this.penalize(fromID, "invalid block")
+	n := this.getNodeWeight(fromID)
+	if n != nil && n.GetErrorRespCnt() >= SYNC_MAX_ERROR_RESP_TIMES {
+		this.delNode(fromID)
+	}
+	if blockHash == common.UINT256_EMPTY {
+		return
+	}
+	reqNode := this.getNextNode(height)
+	if reqNode == nil {
+		return
+	}
+	err := this.reqBlock(reqNode, height, blockHash)
+	if err != nil {
+		log.Warn("[p2p]require new block error:", err)
+	}
+}
+
+//logProgress log the block sync speed and the estimated time to catch up with the nodes
+func (this *BlockSyncMgr) logProgress(curBlockHeight uint32) {
+	now := time.Now()
+	if this.progressTime.IsZero() {
+		this.progressTime = now
+		this.progressHeight = curBlockHeight
+		return
+	}
+	elapsed := now.Sub(this.progressTime)
+	if elapsed < SYNC_PROGRESS_INTERVAL*time.Second {
+		return
+	}
+	maxHeight := this.getMaxNodeHeight()
+	if maxHeight > curBlockHeight {
+		speed, eta := syncETA(curBlockHeight-this.progressHeight, elapsed, maxHeight-curBlockHeight)
+		log.Infof("Block sync progress height:%d/%d %.2f%% speed:%.2f blocks/s ETA:%s", curBlockHeight,
+			maxHeight, float64(curBlockHeight)*100/float64(maxHeight), speed, eta)
+	}
+	this.progressTime = now
+	this.progressHeight = curBlockHeight
+}
+
 func (this *BlockSyncMgr) isInBlockCache(blockHeight uint32) bool {
 	this.lock.RLock()
 	defer this.lock.RUnlock()
@@ -715,6 +940,18 @@ func (this *BlockSyncMgr) isHeaderOnFlight(height uint32) bool {
 	return flightInfo != nil
 }
 
+//addRangeFlightBlocks add the flights of blocks requested from the node in a ranged request
+func (this *BlockSyncMgr) addRangeFlightBlocks(nodeId uint64, startHeight, stopHeight uint32) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	for height := startHeight; height <= stopHeight; height++ {
+		blockHash := this.ledger.GetBlockHash(height)
+		flightInfo := NewSyncFlightInfo(height, nodeId)
+		flightInfo.ranged = true
+		this.flightBlocks[blockHash] = append(this.flightBlocks[blockHash], flightInfo)
+	}
+}
+
 func (this *BlockSyncMgr) addFlightBlock(nodeId uint64, height uint32, blockHash common.Uint256) {
 	this.lock.Lock()
 	defer this.lock.Unlock()
@@ -767,6 +1004,20 @@ func (this *BlockSyncMgr) getFlightBlockCount() int {
 	return cnt
 }
 
+//getMaxFlightBlockCount return the limit of blocks on flight, which grows with the sync nodes
+func (this *BlockSyncMgr) getMaxFlightBlockCount() int {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	cnt := SYNC_MAX_FLIGHT_BLOCK_SIZE * len(this.nodeWeights)
+	if cnt > SYNC_MAX_BLOCK_CACHE_SIZE {
+		cnt = SYNC_MAX_BLOCK_CACHE_SIZE
+	}
+	if cnt < SYNC_MAX_FLIGHT_BLOCK_SIZE {
+		cnt = SYNC_MAX_FLIGHT_BLOCK_SIZE
+	}
+	return cnt
+}
+
 func (this *BlockSyncMgr) isBlockOnFlight(blockHash common.Uint256) bool {
 	flightInfos := this.getFlightBlocks(blockHash)
 	if len(flightInfos) != 0 {
@@ -873,6 +1124,30 @@ func (this *BlockSyncMgr) addErrorRespCnt(nodeId uint64) {
 	}
 }
 
+//penalize scores a node sending the headers or blocks rejected by the ledger
+func (this *BlockSyncMgr) penalize(nodeId uint64, reason string) {
+	n := this.server.getNode(nodeId)
+	if n != nil {
+		this.server.network.Penalize(nodeId, n.GetAddr(), p2pComm.PENALTY_INVALID_BLOCK, reason)
+	}
+}
+
+//addRangeRecvCnt incre a node's count of blocks received by ranged requests
+func (this *BlockSyncMgr) addRangeRecvCnt(nodeId uint64) {
+	n := this.getNodeWeight(nodeId)
+	if n != nil {
+		n.AddRangeRecvCnt()
+	}
+}
+
+//setRangeUnsupported mark a node not answering ranged requests
+func (this *BlockSyncMgr) setRangeUnsupported(nodeId uint64) {
+	n := this.getNodeWeight(nodeId)
+	if n != nil {
+		n.SetRangeUnsupported()
+	}
+}
+
 //appendReqTime append a node's request time
 func (this *BlockSyncMgr) appendReqTime(nodeId uint64) {
 	n := this.getNodeWeight(nodeId)
@@ -889,6 +1164,20 @@ func (this *BlockSyncMgr) addNewSpeed(nodeId uint64, speed float32) {
 	}
 }
 
+//getMaxNodeHeight return the max block height of the sync nodes
+func (this *BlockSyncMgr) getMaxNodeHeight() uint32 {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	maxHeight := uint32(0)
+	for id := range this.nodeWeights {
+		peer := this.server.getNode(id)
+		if peer != nil && uint32(peer.GetHeight()) > maxHeight {
+			maxHeight = uint32(peer.GetHeight())
+		}
+	}
+	return maxHeight
+}
+
 //pingOutsyncNodes send ping msg to lower height nodes for syncing
 func (this *BlockSyncMgr) pingOutsyncNodes(curHeight uint32) {
 	peers := make([]*peer.Peer, 0)
@@ -926,3 +1215,28 @@ func getNextNodeId(nextNodeIndex int, nodeList []uint64) (int, uint64) {
 	nextNodeIndex++
 	return nextNodeIndex, nodeList[index]
 }
+
+//splitBlockRanges split the ascending heights into ranges of continuous heights,
+//each of which has maxSize heights at most
+func splitBlockRanges(heights []uint32, maxSize uint32) [][2]uint32 {
+	ranges := make([][2]uint32, 0)
+	for _, height := range heights {
+		n := len(ranges)
+		if n > 0 && ranges[n-1][1]+1 == height && height-ranges[n-1][0] < maxSize {
+			ranges[n-1][1] = height
+			continue
+		}
+		ranges = append(ranges, [2]uint32{height, height})
+	}
+	return ranges
+}
+
+//syncETA return the sync speed in blocks per second and the estimated time to sync the remaining blocks
+func syncETA(synced uint32, elapsed time.Duration, remaining uint32) (float64, time.Duration) {
+	if synced == 0 || elapsed <= 0 {
+		return 0, 0
+	}
+	speed := float64(synced) / elapsed.Seconds()
+	eta := time.Duration(float64(remaining) / speed * float64(time.Second))
+	return speed, eta.Round(time.Second)
+}
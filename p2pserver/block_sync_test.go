@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2pserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitBlockRanges(t *testing.T) {
+	ranges := splitBlockRanges([]uint32{3, 4, 5, 6, 7, 9, 10, 12}, 3)
+	assert.Equal(t, [][2]uint32{{3, 5}, {6, 7}, {9, 10}, {12, 12}}, ranges)
+
+	ranges = splitBlockRanges(nil, 3)
+	assert.Equal(t, 0, len(ranges))
+}
+
+func TestSyncETA(t *testing.T) {
+	speed, eta := syncETA(100, 10*time.Second, 1000)
+	assert.Equal(t, float64(10), speed)
+	assert.Equal(t, 100*time.Second, eta)
+
+	speed, eta = syncETA(0, 10*time.Second, 1000)
+	assert.Equal(t, float64(0), speed)
+	assert.Equal(t, time.Duration(0), eta)
+}
+
+func TestNodeWeightRangeSupported(t *testing.T) {
+	n := NewNodeWeight(1)
+	assert.True(t, n.IsRangeSupported())
+	n.AddRangeRecvCnt()
+	n.SetRangeUnsupported()
+	assert.True(t, n.IsRangeSupported())
+
+	n = NewNodeWeight(2)
+	n.SetRangeUnsupported()
+	assert.False(t, n.IsRangeSupported())
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/ontio/ontology-crypto/signature"
+	com "github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/log"
+)
+
+// PeerIdFromPubKey returns the peer id bound to the node key
+func PeerIdFromPubKey(pubKey keypair.PublicKey) uint64 {
+	hash := sha256.Sum256(keypair.SerializePublicKey(pubKey))
+	return binary.LittleEndian.Uint64(hash[:8])
+}
+
+// LoadNodeKey loads the node key from the file, a new key is generated
+// and saved to the file if it does not exist.
+func LoadNodeKey(path string) (keypair.PrivateKey, error) {
+	if com.FileExisted(path) {
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read node key %s error: %s", path, err)
+		}
+		data, err := hex.DecodeString(strings.TrimSpace(string(buf)))
+		if err != nil || len(data) == 0 {
+			return nil, fmt.Errorf("invalid node key %s", path)
+		}
+		return keypair.DeserializePrivateKey(data)
+	}
+
+	priKey, _, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	if err != nil {
+		return nil, fmt.Errorf("generate node key error: %s", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("create node key dir error: %s", err)
+	}
+	data := hex.EncodeToString(keypair.SerializePrivateKey(priKey))
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		return nil, fmt.Errorf("write node key %s error: %s", path, err)
+	}
+	log.Infof("[p2p]new node key saved to %s", path)
+	return priKey, nil
+}
+
+// SignWithNodeKey signs the data with the node key
+func SignWithNodeKey(priKey keypair.PrivateKey, data []byte) ([]byte, error) {
+	if priKey == nil {
+		return nil, errors.New("no node key")
+	}
+	sig, err := signature.Sign(signature.SHA256withECDSA, priKey, data, nil)
+	if err != nil {
+		return nil, err
+	}
+	return signature.Serialize(sig)
+}
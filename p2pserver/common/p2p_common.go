@@ -27,13 +27,13 @@ import (
 	"github.com/polynetwork/poly/core/types"
 )
 
-//peer capability
+// peer capability
 const (
 	VERIFY_NODE  = 1 //peer involved in consensus
 	SERVICE_NODE = 2 //peer only sync with consensus peer
 )
 
-//link and concurrent const
+// link and concurrent const
 const (
 	PER_SEND_LEN        = 1024 * 256 //byte len per conn write
 	MAX_BUF_LEN         = 1024 * 256 //the maximum buffer to receive message
@@ -43,7 +43,7 @@ const (
 	MAX_RESP_CACHE_SIZE = 50         //the maximum response cache
 )
 
-//msg cmd const
+// msg cmd const
 const (
 	MSG_CMD_LEN      = 12               //msg type length in byte
 	CMD_OFFSET       = 4                //cmd type offet in msg hdr
@@ -56,13 +56,13 @@ const (
 	MAX_PAYLOAD_LEN  = MAX_MSG_LEN - MSG_HDR_LEN
 )
 
-//msg type const
+// msg type const
 const (
 	MAX_ADDR_NODE_CNT = 64 //the maximum peer address from msg
 	MAX_INV_BLK_CNT   = 64 //the maximum blk hash cnt of inv msg
 )
 
-//info update const
+// info update const
 const (
 	PROTOCOL_VERSION      = 0     //protocol version
 	UPDATE_RATE_PER_BLOCK = 2     //info update rate in one generate block period
@@ -85,24 +85,49 @@ const (
 	INACTIVITY  = 5 //link broken
 )
 
-//cap flag
+// cap flag
 const (
 	HTTP_INFO_FLAG = 0 //peer`s http info bit in cap field
 )
 
-//actor const
+// actor const
 const (
 	ACTOR_TIMEOUT = 5 //actor request timeout in secs
 )
 
-//recent contact const
+// recent contact const
 const (
 	RECENT_TIMEOUT   = 60
 	RECENT_FILE_NAME = "peers.recent"
 	RECENT_LIMIT     = 10 //recent contact list limit
 )
 
-//PeerAddr represent peer`s net information
+// node key const
+const (
+	NODE_KEY_FILE_NAME    = "nodekey" //node key file in the data dir
+	MAX_VERSION_TIME_SKEW = 10 * 60   //max time skew in sec of a signed version
+)
+
+// peer reputation const
+const (
+	BAN_FILE_NAME         = "peers.banned"
+	BAN_SCORE             = 100          //score at which a peer is banned
+	BAN_DURATION          = 24 * 60 * 60 //time in sec a peer is banned for by default
+	SCORE_DECAY_INTERVAL  = 60           //time in sec for the score of a peer to decay by one
+	PENALTY_MALFORMED_MSG = 25           //penalty of a malformed message
+	PENALTY_INVALID_CONS  = 10           //penalty of a consensus message failing the verification
+	PENALTY_INVALID_BLOCK = 25           //penalty of a header or block rejected by the ledger
+)
+
+// seed discovery const
+const (
+	SEED_FILE_NAME         = "peers.seeds"
+	SEED_ANNOUNCE_INTERVAL = 10 * 60      //time in sec a seed node broadcasts its announcement
+	SEED_ANNOUNCE_TTL      = 24 * 60 * 60 //time in sec an announcement is valid for
+	MAX_SEED_CNT           = 32           //the maximum seed announcements cached and sent in msg
+)
+
+// PeerAddr represent peer`s net information
 type PeerAddr struct {
 	Time          int64    //latest timestamp
 	Services      uint64   //service type
@@ -112,24 +137,26 @@ type PeerAddr struct {
 	ID            uint64   //Unique ID
 }
 
-//const channel msg id and type
+// const channel msg id and type
 const (
-	VERSION_TYPE     = "version"    //peer`s information
-	VERACK_TYPE      = "verack"     //ack msg after version recv
-	GetADDR_TYPE     = "getaddr"    //req nbr address from peer
-	ADDR_TYPE        = "addr"       //nbr address
-	PING_TYPE        = "ping"       //ping  sync height
-	PONG_TYPE        = "pong"       //pong  recv nbr height
-	GET_HEADERS_TYPE = "getheaders" //req blk hdr
-	HEADERS_TYPE     = "headers"    //blk hdr
-	INV_TYPE         = "inv"        //inv payload
-	GET_DATA_TYPE    = "getdata"    //req data from peer
-	BLOCK_TYPE       = "block"      //blk payload
-	TX_TYPE          = "tx"         //transaction
-	CONSENSUS_TYPE   = "consensus"  //consensus payload
-	GET_BLOCKS_TYPE  = "getblocks"  //req blks from peer
-	NOT_FOUND_TYPE   = "notfound"   //peer can`t find blk according to the hash
-	DISCONNECT_TYPE  = "disconnect" //peer disconnect info raise by link
+	VERSION_TYPE      = "version"     //peer`s information
+	VERACK_TYPE       = "verack"      //ack msg after version recv
+	GetADDR_TYPE      = "getaddr"     //req nbr address from peer
+	ADDR_TYPE         = "addr"        //nbr address
+	PING_TYPE         = "ping"        //ping  sync height
+	PONG_TYPE         = "pong"        //pong  recv nbr height
+	GET_HEADERS_TYPE  = "getheaders"  //req blk hdr
+	HEADERS_TYPE      = "headers"     //blk hdr
+	INV_TYPE          = "inv"         //inv payload
+	GET_DATA_TYPE     = "getdata"     //req data from peer
+	BLOCK_TYPE        = "block"       //blk payload
+	TX_TYPE           = "tx"          //transaction
+	CONSENSUS_TYPE    = "consensus"   //consensus payload
+	GET_BLOCKS_TYPE   = "getblocks"   //req blks from peer
+	NOT_FOUND_TYPE    = "notfound"    //peer can`t find blk according to the hash
+	DISCONNECT_TYPE   = "disconnect"  //peer disconnect info raise by link
+	GET_SNAPSHOT_TYPE = "getsnapshot" //req snapshot manifest or chunk
+	SNAPSHOT_TYPE     = "snapshot"    //snapshot manifest or chunk
 )
 
 type AppendPeerID struct {
@@ -152,7 +179,19 @@ type AppendBlock struct {
 	MerkleRoot com.Uint256  // MerkleRoot
 }
 
-//ParseIPAddr return ip address
+type AppendSnapshot struct {
+	FromID uint64 // The peer id
+	Height uint32 // Height of the snapshot
+	Index  uint32 // Index of the chunk, or the manifest
+	Data   []byte // Serialized manifest or chunk
+}
+
+type NotFoundData struct {
+	FromID uint64      // The peer id
+	Hash   com.Uint256 // Hash of the block or transaction the peer can't serve
+}
+
+// ParseIPAddr return ip address
 func ParseIPAddr(s string) (string, error) {
 	i := strings.Index(s, ":")
 	if i < 0 {
@@ -161,7 +200,7 @@ func ParseIPAddr(s string) (string, error) {
 	return s[:i], nil
 }
 
-//ParseIPPort return ip port
+// ParseIPPort return ip port
 func ParseIPPort(s string) (string, error) {
 	i := strings.Index(s, ":")
 	if i < 0 {
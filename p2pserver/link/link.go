@@ -40,6 +40,8 @@ type Link struct {
 	time      time.Time              // The latest time the node activity
 	recvChan  chan *types.MsgPayload //msgpayload channel
 	reqRecord map[string]int64       //Map RequestId to Timestamp, using for rejecting duplicate request in specific time
+	localSig  []byte                 //The signature of the version sent on the link
+	remoteSig []byte                 //The signature of the version received on the link
 }
 
 func NewLink() *Link {
@@ -59,6 +61,26 @@ func (this *Link) GetID() uint64 {
 	return this.id
 }
 
+//SetLocalSig record the signature of the version sent on the link
+func (this *Link) SetLocalSig(sig []byte) {
+	this.localSig = sig
+}
+
+//GetLocalSig return the signature of the version sent on the link
+func (this *Link) GetLocalSig() []byte {
+	return this.localSig
+}
+
+//SetRemoteSig record the signature of the version received on the link
+func (this *Link) SetRemoteSig(sig []byte) {
+	this.remoteSig = sig
+}
+
+//GetRemoteSig return the signature of the version received on the link
+func (this *Link) GetRemoteSig() []byte {
+	return this.remoteSig
+}
+
 //If there is connection return true
 func (this *Link) Valid() bool {
 	return this.conn != nil
@@ -117,10 +139,12 @@ func (this *Link) Rx() {
 
 	reader := bufio.NewReaderSize(conn, common.MAX_BUF_LEN)
 
+	malformed := false
 	for {
 		msg, payloadSize, err := types.ReadMessage(reader)
 		if err != nil {
 			log.Infof("[p2p]error read from %s :%s", this.GetAddr(), err.Error())
+			_, malformed = err.(*types.MalformedMsgError)
 			break
 		}
 
@@ -141,19 +165,18 @@ func (this *Link) Rx() {
 
 	}
 
-	this.disconnectNotify()
+	this.disconnectNotify(malformed)
 }
 
 //disconnectNotify push disconnect msg to channel
-func (this *Link) disconnectNotify() {
+func (this *Link) disconnectNotify(malformed bool) {
 	log.Debugf("[p2p]call disconnectNotify for %s", this.GetAddr())
 	this.CloseConn()
 
-	msg, _ := types.MakeEmptyMessage(common.DISCONNECT_TYPE)
 	discMsg := &types.MsgPayload{
 		Id:      this.id,
 		Addr:    this.addr,
-		Payload: msg,
+		Payload: &types.Disconnected{Malformed: malformed},
 	}
 	this.recvChan <- discMsg
 }
@@ -194,7 +217,7 @@ func (this *Link) SendRaw(rawPacket []byte) error {
 	_, err := conn.Write(rawPacket)
 	if err != nil {
 		log.Infof("[p2p]error sending messge to %s :%s", this.GetAddr(), err.Error())
-		this.disconnectNotify()
+		this.disconnectNotify(false)
 		return err
 	}
 
@@ -203,11 +226,10 @@ func (this *Link) SendRaw(rawPacket []byte) error {
 
 //needSendMsg check whether the msg is needed to push to channel
 func (this *Link) needSendMsg(msg types.Message) bool {
-	if msg.CmdType() != common.GET_DATA_TYPE {
+	reqID, ok := getReqRecordID(msg)
+	if !ok {
 		return true
 	}
-	var dataReq = msg.(*types.DataReq)
-	reqID := fmt.Sprintf("%x%s", dataReq.DataType, dataReq.Hash.ToHexString())
 	now := time.Now().Unix()
 
 	if t, ok := this.reqRecord[reqID]; ok {
@@ -220,7 +242,8 @@ func (this *Link) needSendMsg(msg types.Message) bool {
 
 //addReqRecord add request record by removing outdated request records
 func (this *Link) addReqRecord(msg types.Message) {
-	if msg.CmdType() != common.GET_DATA_TYPE {
+	reqID, ok := getReqRecordID(msg)
+	if !ok {
 		return
 	}
 	now := time.Now().Unix()
@@ -232,7 +255,16 @@ func (this *Link) addReqRecord(msg types.Message) {
 			}
 		}
 	}
-	var dataReq = msg.(*types.DataReq)
-	reqID := fmt.Sprintf("%x%s", dataReq.DataType, dataReq.Hash.ToHexString())
 	this.reqRecord[reqID] = now
 }
+
+//getReqRecordID return the id of data or blocks request, for rejecting duplicate request
+func getReqRecordID(msg types.Message) (string, bool) {
+	switch req := msg.(type) {
+	case *types.DataReq:
+		return fmt.Sprintf("%x%s", req.DataType, req.Hash.ToHexString()), true
+	case *types.BlocksReq:
+		return fmt.Sprintf("%s%d%s", common.GET_BLOCKS_TYPE, req.HeaderHashCount, req.HashStart.ToHexString()), true
+	}
+	return "", false
+}
@@ -19,8 +19,10 @@
 package msgpack
 
 import (
+	"errors"
 	"time"
 
+	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
@@ -31,10 +33,11 @@ import (
 )
 
 //Peer address package
-func NewAddrs(nodeAddrs []msgCommon.PeerAddr) mt.Message {
+func NewAddrs(nodeAddrs []msgCommon.PeerAddr, seeds []*mt.SeedAnnouncement) mt.Message {
 	log.Trace()
 	var addr mt.Addr
 	addr.NodeAddrs = nodeAddrs
+	addr.Seeds = seeds
 
 	return &addr
 }
@@ -112,6 +115,23 @@ func NewNotFound(hash common.Uint256) mt.Message {
 	return &notFound
 }
 
+//snapshot manifest or chunk request package
+func NewSnapshotReq(height uint32, index uint32) mt.Message {
+	return &mt.SnapshotReq{
+		Height: height,
+		Index:  index,
+	}
+}
+
+//snapshot manifest or chunk package
+func NewSnapshot(height uint32, index uint32, data []byte) mt.Message {
+	return &mt.Snapshot{
+		Height: height,
+		Index:  index,
+		Data:   data,
+	}
+}
+
 //ping msg package
 func NewPingMsg(height uint64) *mt.Ping {
 	log.Trace()
@@ -139,11 +159,39 @@ func NewTxn(txn *ct.Transaction) mt.Message {
 	return &trn
 }
 
+//seed announcement package, signed with the node key
+func NewSeedAnnouncement(n p2pnet.P2P, addr string) (*mt.SeedAnnouncement, error) {
+	pubKey := n.GetPubKey()
+	if pubKey == nil {
+		return nil, errors.New("no node key")
+	}
+	seed := &mt.SeedAnnouncement{
+		PubKey: keypair.SerializePublicKey(pubKey),
+		Addr:   addr,
+		Time:   time.Now().Unix(),
+	}
+	sig, err := n.Sign(seed.SignData())
+	if err != nil {
+		return nil, err
+	}
+	seed.Signature = sig
+	return seed, nil
+}
+
 //version ack package
-func NewVerAck(isConsensus bool) mt.Message {
+//the version signature of an authenticated peer is signed to prove the
+//ownership of the node key on the link
+func NewVerAck(n p2pnet.P2P, isConsensus bool, versionSig []byte) mt.Message {
 	log.Trace()
 	var verAck mt.VerACK
 	verAck.IsConsensus = isConsensus
+	if n.GetPubKey() != nil && len(versionSig) != 0 {
+		sig, err := n.Sign(mt.VerAckSignData(versionSig))
+		if err != nil {
+			log.Warnf("[p2p]sign verack failed: %s", err)
+		}
+		verAck.Signature = sig
+	}
 
 	return &verAck
 }
@@ -175,6 +223,15 @@ func NewVersion(n p2pnet.P2P, isCons bool, height uint32) mt.Message {
 	} else {
 		version.P.Cap[msgCommon.HTTP_INFO_FLAG] = 0x00
 	}
+	if pubKey := n.GetPubKey(); pubKey != nil {
+		version.P.PubKey = keypair.SerializePublicKey(pubKey)
+		sig, err := n.Sign(version.SignData())
+		if err != nil {
+			log.Warnf("[p2p]sign version failed: %s", err)
+			version.P.PubKey = nil
+		}
+		version.P.Signature = sig
+	}
 	return &version
 }
 
@@ -198,6 +255,17 @@ func NewBlkDataReq(hash common.Uint256) mt.Message {
 	return &dataReq
 }
 
+//blocks req package
+func NewBlocksReq(startHash, stopHash common.Uint256, count uint8) mt.Message {
+	log.Trace()
+	var blocksReq mt.BlocksReq
+	blocksReq.HeaderHashCount = count
+	blocksReq.HashStart = startHash
+	blocksReq.HashStop = stopHash
+
+	return &blocksReq
+}
+
 //consensus request package
 func NewConsensusDataReq(hash common.Uint256) mt.Message {
 	log.Trace()
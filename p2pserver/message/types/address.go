@@ -19,6 +19,7 @@
 package types
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/polynetwork/poly/common"
@@ -27,6 +28,7 @@ import (
 
 type Addr struct {
 	NodeAddrs []comm.PeerAddr
+	Seeds     []*SeedAnnouncement // Signed seed announcements, ignored by legacy nodes
 }
 
 //Serialize message payload
@@ -42,6 +44,12 @@ func (this Addr) Serialization(sink *common.ZeroCopySink) error {
 		sink.WriteUint16(addr.ConsensusPort)
 		sink.WriteUint64(addr.ID)
 	}
+	if len(this.Seeds) > 0 {
+		sink.WriteUint64(uint64(len(this.Seeds)))
+		for _, seed := range this.Seeds {
+			seed.Serialization(sink)
+		}
+	}
 
 	return nil
 }
@@ -77,5 +85,21 @@ func (this *Addr) Deserialization(source *common.ZeroCopySource) error {
 	}
 	this.NodeAddrs = this.NodeAddrs[:count]
 
+	// legacy nodes send no seed announcements
+	seedCount, eof := source.NextUint64()
+	if eof {
+		return nil
+	}
+	if seedCount > comm.MAX_SEED_CNT {
+		return fmt.Errorf("too many seed announcements: %d", seedCount)
+	}
+	for i := uint64(0); i < seedCount; i++ {
+		seed := new(SeedAnnouncement)
+		if err := seed.Deserialization(source); err != nil {
+			return err
+		}
+		this.Seeds = append(this.Seeds, seed)
+	}
+
 	return nil
 }
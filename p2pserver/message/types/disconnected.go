@@ -23,7 +23,9 @@ import (
 	"github.com/polynetwork/poly/p2pserver/common"
 )
 
-type Disconnected struct{}
+type Disconnected struct {
+	Malformed bool // The link is closed for receiving a malformed message, never sent on the wire
+}
 
 //Serialize message payload
 func (this Disconnected) Serialization(sink *comm.ZeroCopySink) error {
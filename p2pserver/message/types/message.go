@@ -107,6 +107,16 @@ func WriteMessage(sink *comm.ZeroCopySink, msg Message) error {
 	return err
 }
 
+//MalformedMsgError reports a message violating the protocol, which is
+//distinguished from the network errors to penalize the sender
+type MalformedMsgError struct {
+	Err error
+}
+
+func (this *MalformedMsgError) Error() string {
+	return this.Err.Error()
+}
+
 func ReadMessage(reader io.Reader) (Message, uint32, error) {
 	hdr, err := readMessageHeader(reader)
 	if err != nil {
@@ -119,8 +129,8 @@ func ReadMessage(reader io.Reader) (Message, uint32, error) {
 	}
 
 	if hdr.Length > common.MAX_PAYLOAD_LEN {
-		return nil, 0, fmt.Errorf("msg payload length:%d exceed max payload size: %d",
-			hdr.Length, common.MAX_PAYLOAD_LEN)
+		return nil, 0, &MalformedMsgError{fmt.Errorf("msg payload length:%d exceed max payload size: %d",
+			hdr.Length, common.MAX_PAYLOAD_LEN)}
 	}
 
 	buf := make([]byte, hdr.Length)
@@ -131,20 +141,20 @@ func ReadMessage(reader io.Reader) (Message, uint32, error) {
 
 	checksum := common.Checksum(buf)
 	if checksum != hdr.Checksum {
-		return nil, 0, fmt.Errorf("message checksum mismatch: %x != %x ", hdr.Checksum, checksum)
+		return nil, 0, &MalformedMsgError{fmt.Errorf("message checksum mismatch: %x != %x ", hdr.Checksum, checksum)}
 	}
 
-	cmdType := string(bytes.TrimRight(hdr.CMD[:], string(0)))
+	cmdType := string(bytes.TrimRight(hdr.CMD[:], "\x00"))
 	msg, err := MakeEmptyMessage(cmdType)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, &MalformedMsgError{err}
 	}
 
 	// the buf is referenced by msg to avoid reallocation, so can not reused
 	source := comm.NewZeroCopySource(buf)
 	err = msg.Deserialization(source)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, &MalformedMsgError{err}
 	}
 
 	return msg, hdr.Length, nil
@@ -184,6 +194,10 @@ func MakeEmptyMessage(cmdType string) (Message, error) {
 		return &Disconnected{}, nil
 	case common.GET_BLOCKS_TYPE:
 		return &BlocksReq{}, nil
+	case common.GET_SNAPSHOT_TYPE:
+		return &SnapshotReq{}, nil
+	case common.SNAPSHOT_TYPE:
+		return &Snapshot{}, nil
 	default:
 		return nil, errors.New("unsupported cmd type:" + cmdType)
 	}
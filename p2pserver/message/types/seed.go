@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	comm "github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/p2pserver/common"
+)
+
+// SeedAnnouncement is the address of a seed node signed with its node key,
+// which lets the nodes follow a seed across address changes
+type SeedAnnouncement struct {
+	PubKey    []byte // The node key of the seed
+	Addr      string // The address of the seed, host:port
+	Time      int64  // The unix time in sec the announcement is made at
+	Signature []byte
+}
+
+// SignData returns the hash of the announcement signed with the node key
+func (this *SeedAnnouncement) SignData() []byte {
+	sink := comm.NewZeroCopySink(nil)
+	sink.WriteUint32(config.DefConfig.P2PNode.NetworkMagic)
+	sink.WriteVarBytes(this.PubKey)
+	sink.WriteString(this.Addr)
+	sink.WriteInt64(this.Time)
+	hash := sha256.Sum256(sink.Bytes())
+	return hash[:]
+}
+
+// Verify checks the announcement is signed with its node key and unexpired
+// at now, and returns the node key
+func (this *SeedAnnouncement) Verify(now time.Time) (keypair.PublicKey, error) {
+	if _, err := common.ParseIPPort(this.Addr); err != nil {
+		return nil, fmt.Errorf("invalid seed address %s", this.Addr)
+	}
+	if this.Time > now.Unix()+common.MAX_VERSION_TIME_SKEW {
+		return nil, errors.New("seed announcement from the future")
+	}
+	if this.IsExpired(now) {
+		return nil, errors.New("seed announcement expired")
+	}
+	pubKey, err := keypair.DeserializePublicKey(this.PubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seed key: %s", err)
+	}
+	if err := signature.Verify(pubKey, this.SignData(), this.Signature); err != nil {
+		return nil, err
+	}
+	return pubKey, nil
+}
+
+// IsExpired returns true if the announcement is older than SEED_ANNOUNCE_TTL at now
+func (this *SeedAnnouncement) IsExpired(now time.Time) bool {
+	return this.Time+common.SEED_ANNOUNCE_TTL <= now.Unix()
+}
+
+func (this *SeedAnnouncement) Serialization(sink *comm.ZeroCopySink) {
+	sink.WriteVarBytes(this.PubKey)
+	sink.WriteString(this.Addr)
+	sink.WriteInt64(this.Time)
+	sink.WriteVarBytes(this.Signature)
+}
+
+func (this *SeedAnnouncement) Deserialization(source *comm.ZeroCopySource) error {
+	var eof bool
+	this.PubKey, eof = source.NextVarBytes()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Addr, eof = source.NextString()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Time, eof = source.NextInt64()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Signature, eof = source.NextVarBytes()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
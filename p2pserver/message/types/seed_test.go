@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSeedAnnouncement(t *testing.T, priKey keypair.PrivateKey, addr string, time int64) *SeedAnnouncement {
+	seed := &SeedAnnouncement{
+		PubKey: keypair.SerializePublicKey(priKey.Public()),
+		Addr:   addr,
+		Time:   time,
+	}
+	sig, err := common.SignWithNodeKey(priKey, seed.SignData())
+	assert.Nil(t, err)
+	seed.Signature = sig
+	return seed
+}
+
+func TestAddrWithSeedsSerializationDeserialization(t *testing.T) {
+	priKey, _, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	assert.Nil(t, err)
+
+	var msg Addr
+	msg.Seeds = append(msg.Seeds, newSeedAnnouncement(t, priKey, "seed1.poly.network:20338", time.Now().Unix()))
+	MessageTest(t, &msg)
+}
+
+func TestSeedAnnouncementVerify(t *testing.T) {
+	priKey, _, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	assert.Nil(t, err)
+	now := time.Now()
+
+	seed := newSeedAnnouncement(t, priKey, "seed1.poly.network:20338", now.Unix())
+	pubKey, err := seed.Verify(now)
+	assert.Nil(t, err)
+	assert.Equal(t, seed.PubKey, keypair.SerializePublicKey(pubKey))
+
+	// the address is forged
+	seed.Addr = "10.0.0.1:20338"
+	_, err = seed.Verify(now)
+	assert.NotNil(t, err)
+
+	seed = newSeedAnnouncement(t, priKey, "10.0.0.1", now.Unix())
+	_, err = seed.Verify(now)
+	assert.NotNil(t, err)
+
+	seed = newSeedAnnouncement(t, priKey, "10.0.0.1:20338", now.Unix()-common.SEED_ANNOUNCE_TTL)
+	_, err = seed.Verify(now)
+	assert.NotNil(t, err)
+
+	seed = newSeedAnnouncement(t, priKey, "10.0.0.1:20338", now.Unix()+2*common.MAX_VERSION_TIME_SKEW)
+	_, err = seed.Verify(now)
+	assert.NotNil(t, err)
+}
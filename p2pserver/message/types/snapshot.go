@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"io"
+	"math"
+
+	"github.com/polynetwork/poly/common"
+	comm "github.com/polynetwork/poly/p2pserver/common"
+)
+
+// SNAPSHOT_MANIFEST_INDEX is the index used to request or reply the snapshot manifest instead of a chunk
+const SNAPSHOT_MANIFEST_INDEX = math.MaxUint32
+
+// SnapshotReq request the manifest or a chunk of snapshot at height, height 0 means the latest snapshot
+type SnapshotReq struct {
+	Height uint32
+	Index  uint32
+}
+
+// Serialize message payload
+func (this *SnapshotReq) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteUint32(this.Height)
+	sink.WriteUint32(this.Index)
+	return nil
+}
+
+func (this *SnapshotReq) CmdType() string {
+	return comm.GET_SNAPSHOT_TYPE
+}
+
+// Deserialize message payload
+func (this *SnapshotReq) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Height, eof = source.NextUint32()
+	this.Index, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// Snapshot carry the serialized manifest or chunk of snapshot at height
+type Snapshot struct {
+	Height uint32
+	Index  uint32
+	Data   []byte
+}
+
+// Serialize message payload
+func (this *Snapshot) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteUint32(this.Height)
+	sink.WriteUint32(this.Index)
+	sink.WriteVarBytes(this.Data)
+	return nil
+}
+
+func (this *Snapshot) CmdType() string {
+	return comm.SNAPSHOT_TYPE
+}
+
+// Deserialize message payload
+func (this *Snapshot) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Height, eof = source.NextUint32()
+	this.Index, eof = source.NextUint32()
+	this.Data, eof = source.NextVarBytes()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
@@ -19,6 +19,7 @@
 package types
 
 import (
+	"crypto/sha256"
 	"io"
 
 	comm "github.com/polynetwork/poly/common"
@@ -27,14 +28,26 @@ import (
 
 type VerACK struct {
 	IsConsensus bool
+	Signature   []byte // The signature of the version received on the link, empty for legacy nodes
 }
 
 //Serialize message payload
 func (this *VerACK) Serialization(sink *comm.ZeroCopySink) error {
 	sink.WriteBool(this.IsConsensus)
+	sink.WriteVarBytes(this.Signature)
 	return nil
 }
 
+// VerAckSignData returns the hash a verack signs, which proves the node key
+// is owned by the peer answering the version signature of the link.
+func VerAckSignData(versionSig []byte) []byte {
+	sink := comm.NewZeroCopySink(nil)
+	sink.WriteString(common.VERACK_TYPE)
+	sink.WriteVarBytes(versionSig)
+	hash := sha256.Sum256(sink.Bytes())
+	return hash[:]
+}
+
 func (this *VerACK) CmdType() string {
 	return common.VERACK_TYPE
 }
@@ -46,5 +59,9 @@ func (this *VerACK) Deserialization(source *comm.ZeroCopySource) error {
 	if eof {
 		return io.ErrUnexpectedEOF
 	}
+	// legacy nodes send no signature
+	if sig, _ := source.NextVarBytes(); len(sig) != 0 {
+		this.Signature = sig
+	}
 	return nil
 }
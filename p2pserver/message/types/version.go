@@ -19,9 +19,16 @@
 package types
 
 import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
 	"io"
+	"time"
 
+	"github.com/ontio/ontology-crypto/keypair"
 	comm "github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/signature"
 	"github.com/polynetwork/poly/p2pserver/common"
 )
 
@@ -38,6 +45,8 @@ type VersionPayload struct {
 	Relay        uint8
 	IsConsensus  bool
 	SoftVersion  string
+	PubKey       []byte // The node key the peer id is bound to, empty for legacy nodes
+	Signature    []byte // The signature of the payload with the node key
 }
 
 type Version struct {
@@ -46,6 +55,13 @@ type Version struct {
 
 //Serialize message payload
 func (this *Version) Serialization(sink *comm.ZeroCopySink) error {
+	this.serializeUnsigned(sink)
+	sink.WriteVarBytes(this.P.Signature)
+
+	return nil
+}
+
+func (this *Version) serializeUnsigned(sink *comm.ZeroCopySink) {
 	sink.WriteUint32(this.P.Version)
 	sink.WriteUint64(this.P.Services)
 	sink.WriteInt64(this.P.TimeStamp)
@@ -58,8 +74,55 @@ func (this *Version) Serialization(sink *comm.ZeroCopySink) error {
 	sink.WriteUint8(this.P.Relay)
 	sink.WriteBool(this.P.IsConsensus)
 	sink.WriteString(this.P.SoftVersion)
+	sink.WriteVarBytes(this.P.PubKey)
+}
 
-	return nil
+// ErrVersionTimeSkew is returned for a signed version too far from the local
+// time, which is more likely a misconfigured clock than a forgery
+var ErrVersionTimeSkew = errors.New("version timestamp out of range")
+
+// ErrVersionUnsigned is returned for the unsigned version of a legacy node
+// once signed versions are required
+var ErrVersionUnsigned = errors.New("version not signed with a node key")
+
+// SignData returns the hash of the payload signed with the node key
+func (this *Version) SignData() []byte {
+	sink := comm.NewZeroCopySink(nil)
+	sink.WriteUint32(config.DefConfig.P2PNode.NetworkMagic)
+	this.serializeUnsigned(sink)
+	hash := sha256.Sum256(sink.Bytes())
+	return hash[:]
+}
+
+// VerifySignature checks the peer id is bound to the node key the payload
+// is signed with, and returns the node key. It returns nil for the unsigned
+// versions of legacy nodes, which are rejected if the node is configured to
+// require signed versions.
+func (this *Version) VerifySignature() (keypair.PublicKey, error) {
+	if len(this.P.PubKey) == 0 && len(this.P.Signature) == 0 {
+		if config.DefConfig.P2PNode.RequireSignedVersion {
+			return nil, ErrVersionUnsigned
+		}
+		return nil, nil
+	}
+	pubKey, err := keypair.DeserializePublicKey(this.P.PubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node key: %s", err)
+	}
+	if id := common.PeerIdFromPubKey(pubKey); id != this.P.Nonce {
+		return nil, fmt.Errorf("peer id %d is not bound to the node key, expect %d", this.P.Nonce, id)
+	}
+	skew := time.Now().UnixNano() - this.P.TimeStamp
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > int64(common.MAX_VERSION_TIME_SKEW*time.Second) {
+		return nil, ErrVersionTimeSkew
+	}
+	if err := signature.Verify(pubKey, this.SignData(), this.P.Signature); err != nil {
+		return nil, err
+	}
+	return pubKey, nil
 }
 
 func (this *Version) CmdType() string {
@@ -89,6 +152,22 @@ func (this *Version) Deserialization(source *comm.ZeroCopySource) error {
 	this.P.SoftVersion, eof = source.NextString()
 	if eof {
 		this.P.SoftVersion = ""
+		return nil
+	}
+	// legacy nodes send no node key
+	pubKey, eof := source.NextVarBytes()
+	if eof {
+		return nil
+	}
+	sig, eof := source.NextVarBytes()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if len(pubKey) != 0 {
+		this.P.PubKey = pubKey
+	}
+	if len(sig) != 0 {
+		this.P.Signature = sig
 	}
 
 	return nil
@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSignedVersion(t *testing.T, priKey keypair.PrivateKey) *Version {
+	var msg Version
+	msg.P = VersionPayload{
+		Version:     common.PROTOCOL_VERSION,
+		SyncPort:    20338,
+		Nonce:       common.PeerIdFromPubKey(priKey.Public()),
+		StartHeight: 12345,
+		TimeStamp:   time.Now().UnixNano(),
+		SoftVersion: "1.0.0",
+		PubKey:      keypair.SerializePublicKey(priKey.Public()),
+	}
+	sig, err := common.SignWithNodeKey(priKey, msg.SignData())
+	assert.Nil(t, err)
+	msg.P.Signature = sig
+	return &msg
+}
+
+func TestVersionSerializationDeserialization(t *testing.T) {
+	priKey, _, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	assert.Nil(t, err)
+
+	MessageTest(t, newSignedVersion(t, priKey))
+
+	var legacy Version
+	legacy.P.Nonce = 1
+	legacy.P.SoftVersion = "1.0.0"
+	MessageTest(t, &legacy)
+}
+
+func TestVersionSignature(t *testing.T) {
+	requireSigned := config.DefConfig.P2PNode.RequireSignedVersion
+	defer func() { config.DefConfig.P2PNode.RequireSignedVersion = requireSigned }()
+	config.DefConfig.P2PNode.RequireSignedVersion = false
+
+	priKey, _, err := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	assert.Nil(t, err)
+
+	msg := newSignedVersion(t, priKey)
+	pubKey, err := msg.VerifySignature()
+	assert.Nil(t, err)
+	assert.Equal(t, keypair.SerializePublicKey(priKey.Public()), keypair.SerializePublicKey(pubKey))
+
+	// unsigned versions of legacy nodes are accepted unless signed versions are required
+	var legacy Version
+	pubKey, err = legacy.VerifySignature()
+	assert.Nil(t, err)
+	assert.Nil(t, pubKey)
+	config.DefConfig.P2PNode.RequireSignedVersion = true
+	_, err = legacy.VerifySignature()
+	assert.Equal(t, ErrVersionUnsigned, err)
+	pubKey, err = msg.VerifySignature()
+	assert.Nil(t, err)
+	assert.NotNil(t, pubKey)
+	config.DefConfig.P2PNode.RequireSignedVersion = false
+
+	// the id is not bound to the node key
+	msg = newSignedVersion(t, priKey)
+	msg.P.Nonce++
+	_, err = msg.VerifySignature()
+	assert.NotNil(t, err)
+
+	// tampered payload
+	msg = newSignedVersion(t, priKey)
+	msg.P.SyncPort++
+	_, err = msg.VerifySignature()
+	assert.NotNil(t, err)
+
+	// replayed version
+	msg = newSignedVersion(t, priKey)
+	msg.P.TimeStamp -= int64(2 * common.MAX_VERSION_TIME_SKEW * time.Second)
+	sig, err := common.SignWithNodeKey(priKey, msg.SignData())
+	assert.Nil(t, err)
+	msg.P.Signature = sig
+	_, err = msg.VerifySignature()
+	assert.Equal(t, ErrVersionTimeSkew, err)
+}
@@ -19,19 +19,24 @@
 package utils
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/ontio/ontology-crypto/keypair"
 	evtActor "github.com/ontio/ontology-eventbus/actor"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/ledger"
+	"github.com/polynetwork/poly/core/signature"
+	scom "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/types"
 	actor "github.com/polynetwork/poly/p2pserver/actor/req"
 	msgCommon "github.com/polynetwork/poly/p2pserver/common"
@@ -71,7 +76,7 @@ func AddrReqHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 		}
 
 	}
-	msg := msgpack.NewAddrs(addrStr)
+	msg := msgpack.NewAddrs(addrStr, p2p.GetSeedStore().GetSeeds())
 	err := p2p.Send(remotePeer, msg, false)
 	if err != nil {
 		log.Warn(err)
@@ -179,6 +184,7 @@ func ConsensusHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID,
 		var consensus = data.Payload.(*msgTypes.Consensus)
 		if err := consensus.Cons.Verify(); err != nil {
 			log.Warn(err)
+			p2p.Penalize(authenticatedId(p2p, data.Addr), data.Addr, msgCommon.PENALTY_INVALID_CONS, "invalid consensus message")
 			return
 		}
 		consensus.Cons.PeerId = data.Id
@@ -190,6 +196,9 @@ func ConsensusHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID,
 func NotFoundHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
 	var notFound = data.Payload.(*msgTypes.NotFound)
 	log.Debug("[p2p]receive notFound message, hash is ", notFound.Hash)
+	if pid != nil {
+		pid.Tell(&msgCommon.NotFoundData{FromID: data.Id, Hash: notFound.Hash})
+	}
 }
 
 // TransactionHandle handles the transaction message from peer
@@ -240,6 +249,20 @@ func VersionHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 
 	}
 
+	pubKey, err := version.VerifySignature()
+	if err != nil {
+		log.Warnf("[p2p]version of %s failed the authentication: %s", data.Addr, err)
+		remotePeer.CloseSync()
+		remotePeer.CloseCons()
+		return
+	}
+	if p2p.IsBanned(version.P.Nonce, data.Addr) {
+		log.Debugf("[p2p]peer %d %s is banned, close", version.P.Nonce, data.Addr)
+		remotePeer.CloseSync()
+		remotePeer.CloseCons()
+		return
+	}
+
 	if version.P.IsConsensus == true {
 		if config.DefConfig.P2PNode.DualPortSupport == false {
 			log.Warn("[p2p]consensus port not surpport", data.Addr)
@@ -255,9 +278,15 @@ func VersionHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 			remotePeer.CloseSync()
 			return
 		} else {
+			if !sameNodeKey(p.GetPubKey(), pubKey) {
+				log.Warnf("[p2p]node key of consensus link %s mismatches peer %d, close", data.Addr, version.P.Nonce)
+				remotePeer.CloseCons()
+				return
+			}
 			//p synclink must exist,merged
 			p.ConsLink = remotePeer.ConsLink
 			p.ConsLink.SetID(version.P.Nonce)
+			p.ConsLink.SetRemoteSig(version.P.Signature)
 			p.SetConsState(remotePeer.GetConsState())
 			remotePeer = p
 
@@ -288,9 +317,10 @@ func VersionHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 		if s == msgCommon.INIT {
 			remotePeer.SetConsState(msgCommon.HAND_SHAKE)
 			msg = msgpack.NewVersion(p2p, true, ledger.DefLedger.GetCurrentBlockHeight())
+			remotePeer.ConsLink.SetLocalSig(msg.(*msgTypes.Version).P.Signature)
 		} else if s == msgCommon.HAND {
 			remotePeer.SetConsState(msgCommon.HAND_SHAKED)
-			msg = msgpack.NewVerAck(true)
+			msg = msgpack.NewVerAck(p2p, true, version.P.Signature)
 
 		}
 		err := p2p.Send(remotePeer, msg, true)
@@ -318,6 +348,11 @@ func VersionHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 		// Obsolete node
 		p := p2p.GetPeer(version.P.Nonce)
 		if p != nil {
+			if p.GetPubKey() != nil && pubKey == nil {
+				log.Warnf("[p2p]unauthenticated peer %s claims the id of peer %d, close", data.Addr, version.P.Nonce)
+				remotePeer.CloseSync()
+				return
+			}
 			ipOld, err := msgCommon.ParseIPAddr(p.GetAddr())
 			if err != nil {
 				log.Warn("[p2p]exist peer %d ip format is wrong %s", version.P.Nonce, p.GetAddr())
@@ -363,7 +398,9 @@ func VersionHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 			version.P.Services, version.P.SyncPort,
 			version.P.ConsPort, version.P.Nonce,
 			version.P.Relay, version.P.StartHeight, version.P.SoftVersion)
+		remotePeer.SetPubKey(pubKey)
 		remotePeer.SyncLink.SetID(version.P.Nonce)
+		remotePeer.SyncLink.SetRemoteSig(version.P.Signature)
 		p2p.AddNbrNode(remotePeer)
 
 		if pid != nil {
@@ -377,9 +414,10 @@ func VersionHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 		if s == msgCommon.INIT {
 			remotePeer.SetSyncState(msgCommon.HAND_SHAKE)
 			msg = msgpack.NewVersion(p2p, false, ledger.DefLedger.GetCurrentBlockHeight())
+			remotePeer.SyncLink.SetLocalSig(msg.(*msgTypes.Version).P.Signature)
 		} else if s == msgCommon.HAND {
 			remotePeer.SetSyncState(msgCommon.HAND_SHAKED)
-			msg = msgpack.NewVerAck(false)
+			msg = msgpack.NewVerAck(p2p, false, version.P.Signature)
 		}
 		err := p2p.Send(remotePeer, msg, false)
 		if err != nil {
@@ -411,13 +449,18 @@ func VerAckHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, arg
 			log.Warnf("[p2p]unknown status to received verAck,state:%d,%s\n", s, data.Addr)
 			return
 		}
+		if err := verifyVerAck(remotePeer.GetPubKey(), remotePeer.ConsLink.GetLocalSig(), verAck); err != nil {
+			log.Warnf("[p2p]verAck of %s failed the authentication: %s", data.Addr, err)
+			remotePeer.CloseCons()
+			return
+		}
 
 		remotePeer.SetConsState(msgCommon.ESTABLISH)
 		p2p.RemoveFromConnectingList(data.Addr)
 		remotePeer.SetConsConn(remotePeer.GetConsConn())
 
 		if s == msgCommon.HAND_SHAKE {
-			msg := msgpack.NewVerAck(true)
+			msg := msgpack.NewVerAck(p2p, true, remotePeer.ConsLink.GetRemoteSig())
 			p2p.Send(remotePeer, msg, true)
 		}
 	} else {
@@ -426,6 +469,11 @@ func VerAckHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, arg
 			log.Warnf("[p2p]unknown status to received verAck,state:%d,%s\n", s, data.Addr)
 			return
 		}
+		if err := verifyVerAck(remotePeer.GetPubKey(), remotePeer.SyncLink.GetLocalSig(), verAck); err != nil {
+			log.Warnf("[p2p]verAck of %s failed the authentication: %s", data.Addr, err)
+			remotePeer.CloseSync()
+			return
+		}
 
 		remotePeer.SetSyncState(msgCommon.ESTABLISH)
 		p2p.RemoveFromConnectingList(data.Addr)
@@ -434,7 +482,7 @@ func VerAckHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, arg
 		addr := remotePeer.SyncLink.GetAddr()
 
 		if s == msgCommon.HAND_SHAKE {
-			msg := msgpack.NewVerAck(false)
+			msg := msgpack.NewVerAck(p2p, false, remotePeer.SyncLink.GetRemoteSig())
 			p2p.Send(remotePeer, msg, false)
 		} else {
 			//consensus port connect
@@ -456,6 +504,33 @@ func VerAckHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, arg
 
 }
 
+// verifyVerAck checks the verack proves the ownership of the node key by
+// signing the version sent on the link, legacy peers are not checked
+func verifyVerAck(pubKey keypair.PublicKey, versionSig []byte, verAck *msgTypes.VerACK) error {
+	if pubKey == nil || len(versionSig) == 0 {
+		return nil
+	}
+	return signature.Verify(pubKey, msgTypes.VerAckSignData(versionSig), verAck.Signature)
+}
+
+// authenticatedId returns the peer id bound to the node key of the peer
+// connected from addr, or 0 if the peer is not authenticated
+func authenticatedId(p2p p2p.P2P, addr string) uint64 {
+	remotePeer := p2p.GetPeerFromAddr(addr)
+	if remotePeer == nil || remotePeer.GetPubKey() == nil {
+		return 0
+	}
+	return msgCommon.PeerIdFromPubKey(remotePeer.GetPubKey())
+}
+
+// sameNodeKey returns true if both links of a peer are bound to the same node key
+func sameNodeKey(a, b keypair.PublicKey) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return bytes.Equal(keypair.SerializePublicKey(a), keypair.SerializePublicKey(b))
+}
+
 // AddrHandle handles the neighbor address response message from peer
 func AddrHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
 	log.Trace("[p2p]handle addr message", data.Addr, data.Id)
@@ -487,6 +562,74 @@ func AddrHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args
 		log.Debug("[p2p]connect ip address:", address)
 		go p2p.Connect(address, false)
 	}
+
+	// cache the newer trusted seed announcements and relay them to the other peers
+	var seeds []*msgTypes.SeedAnnouncement
+	for _, seed := range msg.Seeds {
+		updated, err := p2p.GetSeedStore().Update(seed)
+		if err != nil {
+			log.Debugf("[p2p]drop seed announcement of %s from %s: %s", seed.Addr, data.Addr, err)
+			continue
+		}
+		if updated {
+			log.Infof("[p2p]seed announced at %s", seed.Addr)
+			seeds = append(seeds, seed)
+		}
+	}
+	if len(seeds) == 0 {
+		return
+	}
+	relay := msgpack.NewAddrs(nil, seeds)
+	for _, p := range p2p.GetNeighbors() {
+		if p.GetID() != data.Id && p2p.IsPeerEstablished(p) {
+			go p2p.Send(p, relay, false)
+		}
+	}
+}
+
+// BlocksReqHandle handles the ranged blocks request from peer, the blocks from
+// HashStart to HashStop are sent one by one in block messages
+func BlocksReqHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]receive blocks request message", data.Addr, data.Id)
+
+	blocksReq := data.Payload.(*msgTypes.BlocksReq)
+	remotePeer := p2p.GetPeer(data.Id)
+	if remotePeer == nil {
+		log.Debugf("[p2p]remotePeer invalid in BlocksReqHandle, peer id: %d", data.Id)
+		return
+	}
+	header, err := ledger.DefLedger.GetHeaderByHash(blocksReq.HashStart)
+	if err != nil || header == nil {
+		log.Debug("[p2p]can't get block header by hash: ", blocksReq.HashStart,
+			" ,send not found message")
+		err = p2p.Send(remotePeer, msgpack.NewNotFound(blocksReq.HashStart), false)
+		if err != nil {
+			log.Warn(err)
+		}
+		return
+	}
+	count := uint32(blocksReq.HeaderHashCount)
+	if count > msgCommon.MAX_REQ_BLK_ONCE {
+		count = msgCommon.MAX_REQ_BLK_ONCE
+	}
+	curHeight := ledger.DefLedger.GetCurrentBlockHeight()
+	for height := header.Height; height < header.Height+count && height <= curHeight; height++ {
+		hash := ledger.DefLedger.GetBlockHash(height)
+		msg := getBlockMsg(hash)
+		if msg == nil {
+			//the block is pruned or missing, refuse it to let the peer request it from another node
+			log.Debug("[p2p]can't get block by hash: ", hash, " ,send not found message")
+			msg = msgpack.NewNotFound(hash)
+		}
+		err = p2p.Send(remotePeer, msg, false)
+		if err != nil {
+			log.Warn(err)
+			return
+		}
+		if hash == blocksReq.HashStop {
+			return
+		}
+	}
 }
 
 // DataReqHandle handles the data req(block/Transaction) from peer
@@ -504,43 +647,11 @@ func DataReqHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 	hash := dataReq.Hash
 	switch reqType {
 	case common.BLOCK:
-		reqID := fmt.Sprintf("%x%s", reqType, hash.ToHexString())
-		data := getRespCacheValue(reqID)
-		var msg msgTypes.Message
-		if data != nil {
-			switch data.(type) {
-			case *msgTypes.Block:
-				msg = data.(*msgTypes.Block)
-			}
-		}
+		msg := getBlockMsg(hash)
 		if msg == nil {
-			var merkleRoot common.Uint256
-			block, err := ledger.DefLedger.GetBlockByHash(hash)
-			if err != nil || block == nil || block.Header == nil {
-				log.Debug("[p2p]can't get block by hash: ", hash,
-					" ,send not found message")
-				msg := msgpack.NewNotFound(hash)
-				err := p2p.Send(remotePeer, msg, false)
-				if err != nil {
-					log.Warn(err)
-					return
-				}
-				return
-			}
-			merkleRoot, err = ledger.DefLedger.GetStateMerkleRoot(block.Header.Height)
-			if err != nil {
-				log.Debugf("[p2p]failed to get state merkel root at height %v, err %v",
-					block.Header.Height, err)
-				msg := msgpack.NewNotFound(hash)
-				err := p2p.Send(remotePeer, msg, false)
-				if err != nil {
-					log.Warn(err)
-					return
-				}
-				return
-			}
-			msg = msgpack.NewBlock(block, merkleRoot)
-			saveRespCache(reqID, msg)
+			log.Debug("[p2p]can't get block by hash: ", hash,
+				" ,send not found message")
+			msg = msgpack.NewNotFound(hash)
 		}
 		err := p2p.Send(remotePeer, msg, false)
 		if err != nil {
@@ -644,6 +755,9 @@ func InvHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args .
 // DisconnectHandle handles the disconnect events
 func DisconnectHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
 	log.Debug("[p2p]receive disconnect message", data.Addr, data.Id)
+	if data.Payload.(*msgTypes.Disconnected).Malformed {
+		p2p.Penalize(authenticatedId(p2p, data.Addr), data.Addr, msgCommon.PENALTY_MALFORMED_MSG, "malformed message")
+	}
 	p2p.RemoveFromInConnRecord(data.Addr)
 	p2p.RemoveFromOutConnRecord(data.Addr)
 	remotePeer := p2p.GetPeer(data.Id)
@@ -665,6 +779,90 @@ func DisconnectHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID,
 	}
 }
 
+// SnapshotReqHandle handles the snapshot manifest or chunk request from peer
+func SnapshotReqHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]receive snapshot request message", data.Addr, data.Id)
+
+	req := data.Payload.(*msgTypes.SnapshotReq)
+	remotePeer := p2p.GetPeer(data.Id)
+	if remotePeer == nil {
+		log.Debugf("[p2p]remotePeer invalid in SnapshotReqHandle, peer id: %d", data.Id)
+		return
+	}
+	base := filepath.Join(config.DefConfig.Common.DataDir, config.DefConfig.P2PNode.NetworkName, scom.SNAPSHOT_BASE_DIR)
+	var dir string
+	var err error
+	if req.Height == 0 {
+		dir, err = scom.GetLatestSnapshotDir(base)
+		if err != nil {
+			log.Debugf("[p2p]no snapshot in SnapshotReqHandle: %s", err)
+			return
+		}
+	} else {
+		dir = scom.GetSnapshotDir(base, req.Height)
+	}
+	manifest, err := scom.LoadSnapshotManifest(dir)
+	if err != nil {
+		log.Debugf("[p2p]load snapshot manifest in SnapshotReqHandle error: %s, dir: %s", err, dir)
+		return
+	}
+	var payload []byte
+	if req.Index == msgTypes.SNAPSHOT_MANIFEST_INDEX {
+		sink := common.NewZeroCopySink(nil)
+		manifest.Serialization(sink)
+		payload = sink.Bytes()
+	} else {
+		payload, err = scom.LoadSnapshotChunk(dir, int(req.Index))
+		if err != nil {
+			log.Debugf("[p2p]load snapshot chunk in SnapshotReqHandle error: %s, height: %d, index: %d",
+				err, manifest.Height, req.Index)
+			return
+		}
+	}
+	msg := msgpack.NewSnapshot(manifest.Height, req.Index, payload)
+	err = p2p.Send(remotePeer, msg, false)
+	if err != nil {
+		log.Warn(err)
+		return
+	}
+}
+
+// SnapshotHandle handles the snapshot manifest or chunk from peer, it is only
+// accepted by the snapshot sync as the reply of an outstanding request
+func SnapshotHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]receive snapshot message", data.Addr, data.Id)
+
+	snapshot := data.Payload.(*msgTypes.Snapshot)
+	pid.Tell(&msgCommon.AppendSnapshot{
+		FromID: data.Id,
+		Height: snapshot.Height,
+		Index:  snapshot.Index,
+		Data:   snapshot.Data,
+	})
+}
+
+//getBlockMsg returns the block message of the hash from response cache or
+//ledger, nil if the block or its state merkle root is not found
+func getBlockMsg(hash common.Uint256) msgTypes.Message {
+	reqID := fmt.Sprintf("%x%s", common.BLOCK, hash.ToHexString())
+	if msg, ok := getRespCacheValue(reqID).(*msgTypes.Block); ok {
+		return msg
+	}
+	block, err := ledger.DefLedger.GetBlockByHash(hash)
+	if err != nil || block == nil || block.Header == nil {
+		return nil
+	}
+	merkleRoot, err := ledger.DefLedger.GetStateMerkleRoot(block.Header.Height)
+	if err != nil {
+		log.Debugf("[p2p]failed to get state merkel root at height %v, err %v",
+			block.Header.Height, err)
+		return nil
+	}
+	msg := msgpack.NewBlock(block, merkleRoot)
+	saveRespCache(reqID, msg)
+	return msg
+}
+
 //get blk hdrs from starthash to stophash
 func GetHeadersFromHash(startHash common.Uint256, stopHash common.Uint256) ([]*types.Header, error) {
 	var count uint32 = 0
@@ -68,11 +68,14 @@ func (this *MessageRouter) init(p2p p2p.P2P) {
 	this.RegisterMsgHandler(msgCommon.HEADERS_TYPE, BlkHeaderHandle)
 	this.RegisterMsgHandler(msgCommon.INV_TYPE, InvHandle)
 	this.RegisterMsgHandler(msgCommon.GET_DATA_TYPE, DataReqHandle)
+	this.RegisterMsgHandler(msgCommon.GET_BLOCKS_TYPE, BlocksReqHandle)
 	this.RegisterMsgHandler(msgCommon.BLOCK_TYPE, BlockHandle)
 	this.RegisterMsgHandler(msgCommon.CONSENSUS_TYPE, ConsensusHandle)
 	this.RegisterMsgHandler(msgCommon.NOT_FOUND_TYPE, NotFoundHandle)
 	this.RegisterMsgHandler(msgCommon.TX_TYPE, TransactionHandle)
 	this.RegisterMsgHandler(msgCommon.DISCONNECT_TYPE, DisconnectHandle)
+	this.RegisterMsgHandler(msgCommon.GET_SNAPSHOT_TYPE, SnapshotReqHandle)
+	this.RegisterMsgHandler(msgCommon.SNAPSHOT_TYPE, SnapshotHandle)
 }
 
 // RegisterMsgHandler registers msg handler with the msg type
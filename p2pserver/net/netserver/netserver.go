@@ -22,10 +22,12 @@ import (
 	"errors"
 	"math/rand"
 	"net"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/ledger"
@@ -64,6 +66,9 @@ type NetServer struct {
 	inConnRecord  InConnectionRecord
 	outConnRecord OutConnectionRecord
 	OwnAddress    string //network`s own address(ip : sync port),which get from version check
+	nodeKey       keypair.PrivateKey
+	reputation    *peer.Reputation
+	seeds         *peer.SeedStore
 }
 
 //InConnectionRecord include all addr connected
@@ -121,10 +126,22 @@ func (this *NetServer) init() error {
 
 	this.base.SetRelay(true)
 
-	rand.Seed(time.Now().UnixNano())
-	id := rand.Uint64()
-
-	this.base.SetID(id)
+	nodeKey, err := common.LoadNodeKey(filepath.Join(config.DefConfig.Common.DataDir, common.NODE_KEY_FILE_NAME))
+	if err != nil {
+		//still work as an unauthenticated legacy node
+		log.Errorf("[p2p]load node key failed: %s", err)
+		rand.Seed(time.Now().UnixNano())
+		this.base.SetID(rand.Uint64())
+	} else {
+		this.nodeKey = nodeKey
+		this.base.SetPubKey(nodeKey.Public())
+		this.base.SetID(common.PeerIdFromPubKey(nodeKey.Public()))
+	}
+	this.reputation = peer.NewReputation(common.BAN_FILE_NAME)
+	this.seeds = peer.NewSeedStore(common.SEED_FILE_NAME, config.DefConfig.P2PNode.SeedPubKeys)
+	if config.DefConfig.P2PNode.SeedAnnounceAddr != "" && this.nodeKey != nil {
+		this.seeds.Trust(this.nodeKey.Public())
+	}
 
 	log.Infof("[p2p]init peer ID to %d", this.base.GetID())
 	this.Np = &peer.NbrPeers{}
@@ -340,6 +357,12 @@ func (this *NetServer) Connect(addr string, isConsensus bool) error {
 		remotePeer.SetConsState(common.HAND)
 	}
 	version := msgpack.NewVersion(this, isConsensus, ledger.DefLedger.GetCurrentBlockHeight())
+	versionSig := version.(*types.Version).P.Signature
+	if isConsensus {
+		remotePeer.ConsLink.SetLocalSig(versionSig)
+	} else {
+		remotePeer.SyncLink.SetLocalSig(versionSig)
+	}
 	err = remotePeer.Send(version, isConsensus)
 	if err != nil {
 		if !isConsensus {
@@ -776,6 +799,10 @@ func (this *NetServer) GetOutConnRecordLen() int {
 
 //AddrValid whether the addr could be connect or accept
 func (this *NetServer) AddrValid(addr string) bool {
+	if this.IsBanned(0, addr) {
+		log.Debugf("[p2p]address %s is banned", addr)
+		return false
+	}
 	if config.DefConfig.P2PNode.ReservedPeersOnly && len(config.DefConfig.P2PNode.ReservedCfg.ReservedPeers) > 0 {
 		for _, ip := range config.DefConfig.P2PNode.ReservedCfg.ReservedPeers {
 			if strings.HasPrefix(addr, ip) {
@@ -804,3 +831,84 @@ func (this *NetServer) SetOwnAddress(addr string) {
 	}
 
 }
+
+//GetPubKey return the node key the peer id is bound to
+func (this *NetServer) GetPubKey() keypair.PublicKey {
+	return this.base.GetPubKey()
+}
+
+//Sign signs the data with the node key
+func (this *NetServer) Sign(data []byte) ([]byte, error) {
+	return common.SignWithNodeKey(this.nodeKey, data)
+}
+
+//authenticatedId returns the id if the peer connected from addr is
+//authenticated by its node key, or 0 otherwise
+func (this *NetServer) authenticatedId(id uint64, addr string) uint64 {
+	if p := this.GetPeerFromAddr(addr); p == nil || p.GetID() != id || p.GetPubKey() == nil {
+		return 0
+	}
+	return id
+}
+
+//Penalize scores the misbehavior of a peer by its authenticated id, and
+//disconnects the peer once banned. Unauthenticated peers are not scored,
+//the ip may be shared by honest peers behind a NAT
+func (this *NetServer) Penalize(id uint64, addr string, penalty int, reason string) {
+	id = this.authenticatedId(id, addr)
+	if !this.reputation.Penalize(id, penalty, reason) {
+		return
+	}
+	this.disconnectBanned(id, "")
+}
+
+//GetPeerScore return the misbehavior score of a peer
+func (this *NetServer) GetPeerScore(id uint64, addr string) int {
+	return this.reputation.GetScore(this.authenticatedId(id, addr))
+}
+
+//IsBanned return whether the peer id or address is banned
+func (this *NetServer) IsBanned(id uint64, addr string) bool {
+	ip, err := common.ParseIPAddr(addr)
+	if err != nil {
+		ip = addr
+	}
+	return this.reputation.IsBanned(id, ip)
+}
+
+//BanPeer bans the peer by id, or by ip if id is 0, and disconnects the matched peers
+func (this *NetServer) BanPeer(id uint64, ip string, duration time.Duration, reason string) error {
+	if err := this.reputation.Ban(id, ip, duration, reason); err != nil {
+		return err
+	}
+	if id != 0 {
+		ip = ""
+	}
+	this.disconnectBanned(id, ip)
+	return nil
+}
+
+//disconnectBanned disconnects the peers with the banned id or ip
+func (this *NetServer) disconnectBanned(id uint64, ip string) {
+	for _, p := range this.GetNeighbors() {
+		if (id != 0 && p.GetID() == id) || (ip != "" && strings.HasPrefix(p.GetAddr(), ip+":")) {
+			p.CloseSync()
+			p.CloseCons()
+		}
+	}
+}
+
+//UnbanPeer lifts the ban of the peer id or ip
+func (this *NetServer) UnbanPeer(id uint64, ip string) bool {
+	return this.reputation.Unban(id, ip)
+}
+
+//GetBanList return the unexpired bans
+func (this *NetServer) GetBanList() []peer.BanEntry {
+	return this.reputation.GetBans()
+}
+
+//GetSeedStore return the announcements of the trusted seeds
+func (this *NetServer) GetSeedStore() *peer.SeedStore {
+	return this.seeds
+}
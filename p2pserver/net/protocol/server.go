@@ -20,6 +20,9 @@
 package p2p
 
 import (
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/p2pserver/common"
 	"github.com/polynetwork/poly/p2pserver/message/types"
 	"github.com/polynetwork/poly/p2pserver/peer"
@@ -66,4 +69,13 @@ type P2P interface {
 	SetOwnAddress(addr string)
 	IsOwnAddress(addr string) bool
 	IsAddrFromConnecting(addr string) bool
+	GetPubKey() keypair.PublicKey
+	Sign(data []byte) ([]byte, error)
+	Penalize(id uint64, addr string, penalty int, reason string)
+	GetPeerScore(id uint64, addr string) int
+	IsBanned(id uint64, addr string) bool
+	BanPeer(id uint64, ip string, duration time.Duration, reason string) error
+	UnbanPeer(id uint64, ip string) bool
+	GetBanList() []peer.BanEntry
+	GetSeedStore() *peer.SeedStore
 }
@@ -21,10 +21,12 @@ package p2pserver
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"net"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
@@ -36,6 +38,7 @@ import (
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/ledger"
+	scom "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/p2pserver/common"
 	"github.com/polynetwork/poly/p2pserver/message/msg_pack"
@@ -48,16 +51,18 @@ import (
 
 //P2PServer control all network activities
 type P2PServer struct {
-	network   p2pnet.P2P
-	msgRouter *utils.MessageRouter
-	pid       *evtActor.PID
-	blockSync *BlockSyncMgr
-	ledger    *ledger.Ledger
+	network      p2pnet.P2P
+	msgRouter    *utils.MessageRouter
+	pid          *evtActor.PID
+	blockSync    *BlockSyncMgr
+	snapshotSync *SnapshotSyncMgr
+	ledger       *ledger.Ledger
 	ReconnectAddrs
 	recentPeers    map[uint32][]string
 	quitSyncRecent chan bool
 	quitOnline     chan bool
 	quitHeartBeat  chan bool
+	quitSeed       chan bool
 }
 
 //ReconnectAddrs contain addr need to reconnect
@@ -91,6 +96,19 @@ func (this *P2PServer) GetConnectionCnt() uint32 {
 
 //Start create all services
 func (this *P2PServer) Start() error {
+	if hash := config.DefConfig.P2PNode.SnapshotSyncStateHash; hash != "" {
+		stateHash, err := comm.Uint256FromHexString(hash)
+		if err != nil {
+			return fmt.Errorf("[p2p]invalid snapshot sync state hash: %s", err)
+		}
+		height := config.DefConfig.P2PNode.SnapshotSyncHeight
+		if height == 0 {
+			return errors.New("[p2p]snapshot sync height is required")
+		}
+		dir := scom.GetSnapshotDir(filepath.Join(config.DefConfig.Common.DataDir, config.DefConfig.P2PNode.NetworkName,
+			scom.SNAPSHOT_DOWNLOAD_DIR), height)
+		this.snapshotSync = NewSnapshotSyncMgr(this, height, stateHash, dir)
+	}
 	if this.network != nil {
 		this.network.Start()
 	} else {
@@ -107,6 +125,13 @@ func (this *P2PServer) Start() error {
 	go this.keepOnlineService()
 	go this.heartBeatService()
 	go this.blockSync.Start()
+	if this.snapshotSync != nil {
+		go this.snapshotSync.Start()
+	}
+	if config.DefConfig.P2PNode.SeedAnnounceAddr != "" {
+		this.quitSeed = make(chan bool)
+		go this.seedAnnounceService()
+	}
 	return nil
 }
 
@@ -116,8 +141,14 @@ func (this *P2PServer) Stop() {
 	this.quitSyncRecent <- true
 	this.quitOnline <- true
 	this.quitHeartBeat <- true
+	if this.quitSeed != nil {
+		this.quitSeed <- true
+	}
 	this.msgRouter.Stop()
 	this.blockSync.Close()
+	if this.snapshotSync != nil {
+		this.snapshotSync.Close()
+	}
 }
 
 // GetNetWork returns the low level netserver
@@ -207,6 +238,20 @@ func (this *P2PServer) OnBlockReceive(fromID uint64, blockSize uint32,
 	this.blockSync.OnBlockReceive(fromID, blockSize, block, merkleRoot)
 }
 
+// OnSnapshotReceive receive the snapshot manifest or chunk from peer
+func (this *P2PServer) OnSnapshotReceive(fromID uint64, height uint32, index uint32, data []byte) {
+	if this.snapshotSync == nil {
+		log.Debugf("[p2p]snapshot sync disabled, drop snapshot from peer id: %d", fromID)
+		return
+	}
+	this.snapshotSync.OnSnapshotReceive(fromID, height, index, data)
+}
+
+// OnBlockNotFound handles the block a peer refused to serve
+func (this *P2PServer) OnBlockNotFound(fromID uint64, blockHash comm.Uint256) {
+	this.blockSync.OnBlockNotFound(fromID, blockHash)
+}
+
 // Todo: remove it if no use
 func (this *P2PServer) GetConnectionState() uint32 {
 	return common.INIT
@@ -279,26 +324,64 @@ func (this *P2PServer) WaitForPeersStart() {
 	}
 }
 
-//connectSeeds connect the seeds in seedlist and call for nbr list
+//resolveSeed resolves the host of a seed address
+func resolveSeed(n string) (string, error) {
+	ip, err := common.ParseIPAddr(n)
+	if err != nil {
+		return "", fmt.Errorf("seed peer %s address format is wrong", n)
+	}
+	ns, err := net.LookupHost(ip)
+	if err != nil {
+		return "", fmt.Errorf("resolve err: %s", err.Error())
+	}
+	port, err := common.ParseIPPort(n)
+	if err != nil {
+		return "", fmt.Errorf("seed peer %s address format is wrong", n)
+	}
+	return ns[0] + port, nil
+}
+
+//trustSeedKeys trusts the node keys of the peers connected at the configured
+//seed addresses on first use, so the seeds are followed by their node keys
+//once their addresses change
+func trustSeedKeys(seedStore *peer.SeedStore, configured []string, connPeers map[string]*peer.Peer) {
+	for _, nodeAddr := range configured {
+		if p, ok := connPeers[nodeAddr]; ok && p.GetPubKey() != nil {
+			seedStore.Trust(p.GetPubKey())
+		}
+	}
+}
+
+//connectSeeds connect the seeds in seedlist and call for nbr list, the
+//announced seeds are the fallback once the configured seeds are dead
 func (this *P2PServer) connectSeeds() {
 	seedNodes := make([]string, 0)
 	for _, n := range config.DefConfig.Genesis.SeedList {
-		ip, err := common.ParseIPAddr(n)
+		addr, err := resolveSeed(n)
 		if err != nil {
-			log.Warnf("[p2p]seed peer %s address format is wrong", n)
+			log.Warnf("[p2p]%s", err)
 			continue
 		}
-		ns, err := net.LookupHost(ip)
+		seedNodes = append(seedNodes, addr)
+	}
+	configured := len(seedNodes)
+	seedStore := this.network.GetSeedStore()
+	for _, seed := range seedStore.GetSeeds() {
+		addr, err := resolveSeed(seed.Addr)
 		if err != nil {
-			log.Warnf("[p2p]resolve err: %s", err.Error())
+			log.Debugf("[p2p]announced %s", err)
 			continue
 		}
-		port, err := common.ParseIPPort(n)
-		if err != nil {
-			log.Warnf("[p2p]seed peer %s address format is wrong", n)
-			continue
+		found := false
+		for _, nodeAddr := range seedNodes {
+			if nodeAddr == addr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			seedNodes = append(seedNodes, addr)
 		}
-		seedNodes = append(seedNodes, ns[0]+port)
 	}
 
 	connPeers := make(map[string]*peer.Peer)
@@ -314,6 +397,8 @@ func (this *P2PServer) connectSeeds() {
 	}
 	np.Unlock()
 
+	trustSeedKeys(seedStore, seedNodes[:configured], connPeers)
+
 	seedConnList := make([]*peer.Peer, 0)
 	seedDisconn := make([]string, 0)
 	isSeed := false
@@ -466,6 +551,36 @@ func (this *P2PServer) connectSeedService() {
 	}
 }
 
+//seedAnnounceService broadcasts the signed address of the seed node periodically
+func (this *P2PServer) seedAnnounceService() {
+	t := time.NewTimer(time.Second * common.CONN_MONITOR)
+	for {
+		select {
+		case <-t.C:
+			this.announceSeed()
+			t.Stop()
+			t.Reset(time.Second * common.SEED_ANNOUNCE_INTERVAL)
+		case <-this.quitSeed:
+			t.Stop()
+			return
+		}
+	}
+}
+
+//announceSeed broadcasts a fresh announcement of the seed node
+func (this *P2PServer) announceSeed() {
+	seed, err := msgpack.NewSeedAnnouncement(this.network, config.DefConfig.P2PNode.SeedAnnounceAddr)
+	if err != nil {
+		log.Warnf("[p2p]make seed announcement failed: %s", err)
+		return
+	}
+	if _, err := this.network.GetSeedStore().Update(seed); err != nil {
+		log.Warnf("[p2p]invalid seed announcement: %s", err)
+		return
+	}
+	this.network.Xmit(msgpack.NewAddrs(nil, []*msgtypes.SeedAnnouncement{seed}), false)
+}
+
 //keepOnline try connect lost peer
 func (this *P2PServer) keepOnlineService() {
 	t := time.NewTimer(time.Second * common.CONN_MONITOR)
@@ -20,10 +20,15 @@ package p2pserver
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/peer"
 )
 
 func init() {
@@ -53,3 +58,48 @@ func TestNewP2PServer(t *testing.T) {
 		t.Error("TestNewP2PServer consensus port error")
 	}
 }
+
+func TestTrustSeedKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "seeds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, common.SEED_FILE_NAME)
+
+	newPeer := func(signed bool) (*peer.Peer, keypair.PublicKey) {
+		_, pubKey, _ := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+		p := peer.NewPeer()
+		if signed {
+			p.SetPubKey(pubKey)
+		}
+		return p, pubKey
+	}
+	seed, seedKey := newPeer(true)
+	legacy, legacyKey := newPeer(false)
+	other, otherKey := newPeer(true)
+	connPeers := map[string]*peer.Peer{
+		"10.0.0.1:20338": seed,
+		"10.0.0.2:20338": legacy,
+		"10.0.0.3:20338": other,
+	}
+
+	store := peer.NewSeedStore(path, nil)
+	trustSeedKeys(store, []string{"10.0.0.1:20338", "10.0.0.2:20338", "10.0.0.4:20338"}, connPeers)
+	if !store.IsTrusted(seedKey) {
+		t.Fatal("the node key of a configured seed is not trusted")
+	}
+	if store.IsTrusted(legacyKey) {
+		t.Fatal("the node key of an unauthenticated peer is trusted")
+	}
+	if store.IsTrusted(otherKey) {
+		t.Fatal("the node key of a peer not at a configured seed address is trusted")
+	}
+
+	// the trust survives the restart, once the seed is no longer connected
+	store = peer.NewSeedStore(path, nil)
+	trustSeedKeys(store, []string{"10.0.0.1:20338"}, map[string]*peer.Peer{})
+	if !store.IsTrusted(seedKey) {
+		t.Fatal("the trusted seed key is not persisted")
+	}
+}
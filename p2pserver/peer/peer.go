@@ -26,6 +26,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/ontio/ontology-crypto/keypair"
 	comm "github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/p2pserver/common"
@@ -44,6 +45,7 @@ type PeerCom struct {
 	consPort     uint16
 	height       uint64
 	softVersion  string
+	pubKey       keypair.PublicKey
 }
 
 // SetID sets a peer's id
@@ -136,6 +138,16 @@ func (this *PeerCom) GetSoftVersion() string {
 	return this.softVersion
 }
 
+//SetPubKey sets a peer's node key
+func (this *PeerCom) SetPubKey(pubKey keypair.PublicKey) {
+	this.pubKey = pubKey
+}
+
+//GetPubKey returns a peer's node key, nil for unauthenticated legacy peers
+func (this *PeerCom) GetPubKey() keypair.PublicKey {
+	return this.pubKey
+}
+
 //Peer represent the node in p2p
 type Peer struct {
 	base      PeerCom
@@ -333,6 +345,16 @@ func (this *Peer) GetSoftVersion() string {
 	return this.base.GetSoftVersion()
 }
 
+//SetPubKey set the node key the peer id is bound to
+func (this *Peer) SetPubKey(pubKey keypair.PublicKey) {
+	this.base.SetPubKey(pubKey)
+}
+
+//GetPubKey return peer`s node key, nil for unauthenticated legacy peers
+func (this *Peer) GetPubKey() keypair.PublicKey {
+	return this.base.GetPubKey()
+}
+
 //AttachSyncChan set msg chan to sync link
 func (this *Peer) AttachSyncChan(msgchan chan *types.MsgPayload) {
 	this.SyncLink.SetChan(msgchan)
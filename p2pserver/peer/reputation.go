@@ -0,0 +1,280 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package peer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	comm "github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/p2pserver/common"
+)
+
+// BanEntry is a banned peer, matched by id if set, otherwise by ip. Bans by ip
+// are only set by the node operator, a NAT or a devnet host shares its ip with
+// honest peers.
+type BanEntry struct {
+	ID     uint64 `json:"id"`
+	IP     string `json:"ip"`
+	Reason string `json:"reason"`
+	Until  int64  `json:"until"` //unix time in sec the ban expires at
+}
+
+func (this *BanEntry) key() string {
+	return reputationKey(this.ID, this.IP)
+}
+
+type peerScore struct {
+	score   int
+	updated time.Time
+}
+
+// Reputation scores the misbehavior of peers and keeps the persistent ban list.
+// Peers are scored by their authenticated id only.
+type Reputation struct {
+	sync.Mutex
+	path   string
+	scores map[string]*peerScore
+	bans   map[string]*BanEntry
+	now    func() time.Time
+}
+
+// NewReputation returns the reputation with the ban list loaded from path
+func NewReputation(path string) *Reputation {
+	this := &Reputation{
+		path:   path,
+		scores: make(map[string]*peerScore),
+		bans:   make(map[string]*BanEntry),
+		now:    time.Now,
+	}
+	this.load()
+	return this
+}
+
+// reputationKey returns the key of a peer, id 0 stands for an unauthenticated peer
+func reputationKey(id uint64, ip string) string {
+	if id != 0 {
+		return "id:" + strconv.FormatUint(id, 10)
+	}
+	return "ip:" + ip
+}
+
+// reputationKeys returns the keys of the id and the ip of a peer, the id is
+// skipped for an unauthenticated peer
+func reputationKeys(id uint64, ip string) []string {
+	var keys []string
+	if id != 0 {
+		keys = append(keys, reputationKey(id, ""))
+	}
+	if ip != "" {
+		keys = append(keys, reputationKey(0, ip))
+	}
+	return keys
+}
+
+// Penalize adds the penalty to the score of the authenticated id, and bans the
+// id once the score reaches BAN_SCORE. It returns true if the peer gets banned,
+// unauthenticated peers are not scored.
+func (this *Reputation) Penalize(id uint64, penalty int, reason string) bool {
+	if id == 0 {
+		return false
+	}
+	this.Lock()
+	defer this.Unlock()
+	key := reputationKey(id, "")
+	s := this.decay(key)
+	if s == nil {
+		s = &peerScore{updated: this.now()}
+		this.scores[key] = s
+	}
+	s.score += penalty
+	log.Debugf("[p2p]penalize peer %s by %d for %s, score %d", key, penalty, reason, s.score)
+	if s.score < common.BAN_SCORE {
+		return false
+	}
+	delete(this.scores, key)
+	this.ban(&BanEntry{ID: id, Reason: reason, Until: this.now().Add(common.BAN_DURATION * time.Second).Unix()})
+	return true
+}
+
+// GetScore returns the current misbehavior score of the authenticated id
+func (this *Reputation) GetScore(id uint64) int {
+	if id == 0 {
+		return 0
+	}
+	this.Lock()
+	defer this.Unlock()
+	if s := this.decay(reputationKey(id, "")); s != nil {
+		return s.score
+	}
+	return 0
+}
+
+// decay applies the decay of the score since the last update, and drops it once it reaches zero
+func (this *Reputation) decay(key string) *peerScore {
+	s, ok := this.scores[key]
+	if !ok {
+		return nil
+	}
+	now := this.now()
+	steps := int(now.Sub(s.updated) / (common.SCORE_DECAY_INTERVAL * time.Second))
+	if steps <= 0 {
+		return s
+	}
+	s.score -= steps
+	s.updated = s.updated.Add(time.Duration(steps) * common.SCORE_DECAY_INTERVAL * time.Second)
+	if s.score <= 0 {
+		delete(this.scores, key)
+		return nil
+	}
+	return s
+}
+
+// IsBanned returns true if either the id or the ip of the peer is banned
+func (this *Reputation) IsBanned(id uint64, ip string) bool {
+	this.Lock()
+	defer this.Unlock()
+	if id != 0 && this.banned(reputationKey(id, "")) {
+		return true
+	}
+	return ip != "" && this.banned(reputationKey(0, ip))
+}
+
+func (this *Reputation) banned(key string) bool {
+	entry, ok := this.bans[key]
+	if !ok {
+		return false
+	}
+	if entry.Until <= this.now().Unix() {
+		delete(this.bans, key)
+		this.save()
+		return false
+	}
+	return true
+}
+
+// Ban bans the peer by id, or by ip if id is 0, for duration
+func (this *Reputation) Ban(id uint64, ip string, duration time.Duration, reason string) error {
+	if id == 0 && ip == "" {
+		return fmt.Errorf("neither peer id nor ip given")
+	}
+	if duration <= 0 {
+		duration = common.BAN_DURATION * time.Second
+	}
+	this.Lock()
+	defer this.Unlock()
+	this.ban(&BanEntry{
+		ID:     id,
+		IP:     ip,
+		Reason: reason,
+		Until:  this.now().Add(duration).Unix(),
+	})
+	return nil
+}
+
+func (this *Reputation) ban(entry *BanEntry) {
+	log.Warnf("[p2p]ban peer %d %s until %s: %s", entry.ID, entry.IP,
+		time.Unix(entry.Until, 0).Format(time.RFC3339), entry.Reason)
+	this.bans[entry.key()] = entry
+	this.save()
+}
+
+// Unban lifts the bans of the id and the ip of the peer, it returns false if
+// neither is banned
+func (this *Reputation) Unban(id uint64, ip string) bool {
+	this.Lock()
+	defer this.Unlock()
+	found := false
+	for _, key := range reputationKeys(id, ip) {
+		if _, ok := this.bans[key]; ok {
+			delete(this.bans, key)
+			found = true
+		}
+		delete(this.scores, key)
+	}
+	if found {
+		this.save()
+	}
+	return found
+}
+
+// GetBans returns the unexpired bans ordered by expiry
+func (this *Reputation) GetBans() []BanEntry {
+	this.Lock()
+	defer this.Unlock()
+	now := this.now().Unix()
+	bans := make([]BanEntry, 0, len(this.bans))
+	for _, entry := range this.bans {
+		if entry.Until > now {
+			bans = append(bans, *entry)
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].Until < bans[j].Until
+	})
+	return bans
+}
+
+// load reads the ban list from the file, dropping the expired bans
+func (this *Reputation) load() {
+	if this.path == "" || !comm.FileExisted(this.path) {
+		return
+	}
+	buf, err := ioutil.ReadFile(this.path)
+	if err != nil {
+		log.Warnf("[p2p]read %s fail: %s", this.path, err)
+		return
+	}
+	var bans []*BanEntry
+	if err := json.Unmarshal(buf, &bans); err != nil {
+		log.Warnf("[p2p]parse ban list fail: %s", err)
+		return
+	}
+	now := this.now().Unix()
+	for _, entry := range bans {
+		if entry.Until > now {
+			this.bans[entry.key()] = entry
+		}
+	}
+}
+
+// save persists the ban list, the caller must hold the lock
+func (this *Reputation) save() {
+	if this.path == "" {
+		return
+	}
+	bans := make([]*BanEntry, 0, len(this.bans))
+	for _, entry := range this.bans {
+		bans = append(bans, entry)
+	}
+	buf, err := json.Marshal(bans)
+	if err != nil {
+		log.Warnf("[p2p]package ban list fail: %s", err)
+		return
+	}
+	if err := ioutil.WriteFile(this.path, buf, 0600); err != nil {
+		log.Warnf("[p2p]write ban list fail: %s", err)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package peer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReputationPenalize(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	r := NewReputation("")
+	r.now = func() time.Time { return now }
+
+	assert.False(t, r.Penalize(1, common.BAN_SCORE-10, "test"))
+	assert.Equal(t, common.BAN_SCORE-10, r.GetScore(1))
+
+	now = now.Add(5 * common.SCORE_DECAY_INTERVAL * time.Second)
+	assert.Equal(t, common.BAN_SCORE-15, r.GetScore(1))
+
+	assert.False(t, r.Penalize(1, 10, "test"))
+	assert.True(t, r.Penalize(1, 5, "test"))
+	assert.True(t, r.IsBanned(1, "10.0.0.1"))
+	assert.False(t, r.IsBanned(2, "10.0.0.1"))
+	assert.Equal(t, 0, r.GetScore(1))
+	assert.Equal(t, 1, len(r.GetBans()))
+
+	now = now.Add(common.BAN_DURATION * time.Second)
+	assert.False(t, r.IsBanned(1, "10.0.0.1"))
+	assert.Empty(t, r.GetBans())
+}
+
+func TestReputationSharedIp(t *testing.T) {
+	r := NewReputation("")
+
+	// peers behind a NAT or on a devnet host share the ip, only the misbehaving id is banned
+	assert.False(t, r.Penalize(1, common.BAN_SCORE/2, "test"))
+	assert.True(t, r.Penalize(1, common.BAN_SCORE/2, "test"))
+	assert.True(t, r.IsBanned(1, "127.0.0.1"))
+	assert.False(t, r.IsBanned(2, "127.0.0.1"))
+	assert.Equal(t, 0, r.GetScore(2))
+
+	// unauthenticated peers are not scored
+	assert.False(t, r.Penalize(0, common.BAN_SCORE, "test"))
+	assert.Equal(t, 0, r.GetScore(0))
+	assert.False(t, r.IsBanned(0, "127.0.0.1"))
+
+	assert.True(t, r.Unban(1, ""))
+	assert.False(t, r.IsBanned(1, "127.0.0.1"))
+}
+
+func TestReputationBanList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reputation")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, common.BAN_FILE_NAME)
+
+	r := NewReputation(path)
+	assert.NotNil(t, r.Ban(0, "", time.Hour, "test"))
+	assert.Nil(t, r.Ban(0, "10.0.0.1", time.Hour, "by ip"))
+	assert.Nil(t, r.Ban(3, "", 2*time.Hour, "by id"))
+	assert.True(t, r.IsBanned(0, "10.0.0.1"))
+	assert.True(t, r.IsBanned(4, "10.0.0.1"))
+	assert.True(t, r.IsBanned(3, ""))
+
+	r = NewReputation(path)
+	bans := r.GetBans()
+	assert.Equal(t, 2, len(bans))
+	assert.Equal(t, "by ip", bans[0].Reason)
+	assert.Equal(t, uint64(3), bans[1].ID)
+
+	assert.True(t, r.Unban(0, "10.0.0.1"))
+	assert.False(t, r.Unban(0, "10.0.0.1"))
+	r = NewReputation(path)
+	assert.False(t, r.IsBanned(0, "10.0.0.1"))
+	assert.True(t, r.IsBanned(3, "10.0.0.1"))
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package peer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	comm "github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/message/types"
+)
+
+// seedFile is the persistent form of the seed store
+type seedFile struct {
+	Trusted []string                  `json:"trusted"`
+	Seeds   []*types.SeedAnnouncement `json:"seeds"`
+}
+
+// SeedStore caches the latest announcement of each trusted seed. A seed is
+// trusted by its node key, either configured or learned from the handshake
+// with a configured seed address.
+type SeedStore struct {
+	sync.RWMutex
+	path    string
+	trusted map[string]bool
+	seeds   map[string]*types.SeedAnnouncement
+	now     func() time.Time
+}
+
+// NewSeedStore returns the seed store loaded from path, trusting the hex encoded node keys
+func NewSeedStore(path string, trusted []string) *SeedStore {
+	this := &SeedStore{
+		path:    path,
+		trusted: make(map[string]bool),
+		seeds:   make(map[string]*types.SeedAnnouncement),
+		now:     time.Now,
+	}
+	for _, key := range trusted {
+		if !this.trust(key) {
+			log.Warnf("[p2p]invalid seed key %s", key)
+		}
+	}
+	this.load()
+	return this
+}
+
+// trust adds the hex encoded node key to the trusted keys
+func (this *SeedStore) trust(key string) bool {
+	buf, err := hex.DecodeString(key)
+	if err != nil {
+		return false
+	}
+	if _, err := keypair.DeserializePublicKey(buf); err != nil {
+		return false
+	}
+	this.trusted[key] = true
+	return true
+}
+
+// Trust adds the node key of a seed to the trusted keys
+func (this *SeedStore) Trust(pubKey keypair.PublicKey) {
+	key := hex.EncodeToString(keypair.SerializePublicKey(pubKey))
+	this.Lock()
+	defer this.Unlock()
+	if this.trusted[key] {
+		return
+	}
+	log.Infof("[p2p]trust seed key %s", key)
+	this.trusted[key] = true
+	this.save()
+}
+
+// IsTrusted returns whether the node key is a trusted seed key
+func (this *SeedStore) IsTrusted(pubKey keypair.PublicKey) bool {
+	this.RLock()
+	defer this.RUnlock()
+	return this.trusted[hex.EncodeToString(keypair.SerializePublicKey(pubKey))]
+}
+
+// Update caches the announcement if it is valid, trusted and newer than the
+// cached one. It returns true if the announcement is cached.
+func (this *SeedStore) Update(seed *types.SeedAnnouncement) (bool, error) {
+	now := this.now()
+	if _, err := seed.Verify(now); err != nil {
+		return false, err
+	}
+	key := hex.EncodeToString(seed.PubKey)
+	this.Lock()
+	defer this.Unlock()
+	if !this.trusted[key] {
+		return false, errors.New("untrusted seed key")
+	}
+	if old, ok := this.seeds[key]; ok && old.Time >= seed.Time {
+		return false, nil
+	}
+	this.seeds[key] = seed
+	this.prune(now)
+	this.save()
+	return true, nil
+}
+
+// prune drops the expired announcements and the oldest beyond MAX_SEED_CNT
+func (this *SeedStore) prune(now time.Time) {
+	for key, seed := range this.seeds {
+		if seed.IsExpired(now) {
+			delete(this.seeds, key)
+		}
+	}
+	for len(this.seeds) > common.MAX_SEED_CNT {
+		var oldest string
+		for key, seed := range this.seeds {
+			if oldest == "" || seed.Time < this.seeds[oldest].Time {
+				oldest = key
+			}
+		}
+		delete(this.seeds, oldest)
+	}
+}
+
+// GetSeeds returns the unexpired announcements, the latest first
+func (this *SeedStore) GetSeeds() []*types.SeedAnnouncement {
+	now := this.now()
+	this.RLock()
+	defer this.RUnlock()
+	seeds := make([]*types.SeedAnnouncement, 0, len(this.seeds))
+	for _, seed := range this.seeds {
+		if !seed.IsExpired(now) {
+			seeds = append(seeds, seed)
+		}
+	}
+	sort.Slice(seeds, func(i, j int) bool {
+		return seeds[i].Time > seeds[j].Time
+	})
+	return seeds
+}
+
+// load reads the trusted keys and announcements from the file
+func (this *SeedStore) load() {
+	if this.path == "" || !comm.FileExisted(this.path) {
+		return
+	}
+	buf, err := ioutil.ReadFile(this.path)
+	if err != nil {
+		log.Warnf("[p2p]read %s fail: %s", this.path, err)
+		return
+	}
+	var file seedFile
+	if err := json.Unmarshal(buf, &file); err != nil {
+		log.Warnf("[p2p]parse seed file fail: %s", err)
+		return
+	}
+	for _, key := range file.Trusted {
+		this.trust(key)
+	}
+	now := this.now()
+	for _, seed := range file.Seeds {
+		key := hex.EncodeToString(seed.PubKey)
+		if _, err := seed.Verify(now); err != nil || !this.trusted[key] {
+			continue
+		}
+		if old, ok := this.seeds[key]; !ok || old.Time < seed.Time {
+			this.seeds[key] = seed
+		}
+	}
+	this.prune(now)
+}
+
+// save persists the trusted keys and announcements, the caller must hold the lock
+func (this *SeedStore) save() {
+	if this.path == "" {
+		return
+	}
+	file := seedFile{
+		Trusted: make([]string, 0, len(this.trusted)),
+		Seeds:   make([]*types.SeedAnnouncement, 0, len(this.seeds)),
+	}
+	for key := range this.trusted {
+		file.Trusted = append(file.Trusted, key)
+	}
+	sort.Strings(file.Trusted)
+	for _, seed := range this.seeds {
+		file.Seeds = append(file.Seeds, seed)
+	}
+	buf, err := json.Marshal(file)
+	if err != nil {
+		log.Warnf("[p2p]package seed file fail: %s", err)
+		return
+	}
+	if err := ioutil.WriteFile(this.path, buf, 0600); err != nil {
+		log.Warnf("[p2p]write seed file fail: %s", err)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package peer
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/message/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSeed(t *testing.T, priKey keypair.PrivateKey, addr string, time int64) *types.SeedAnnouncement {
+	seed := &types.SeedAnnouncement{
+		PubKey: keypair.SerializePublicKey(priKey.Public()),
+		Addr:   addr,
+		Time:   time,
+	}
+	sig, err := common.SignWithNodeKey(priKey, seed.SignData())
+	assert.Nil(t, err)
+	seed.Signature = sig
+	return seed
+}
+
+func TestSeedStoreUpdate(t *testing.T) {
+	configured, _, _ := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	learned, _, _ := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	untrusted, _, _ := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+
+	now := time.Now()
+	s := NewSeedStore("", []string{hex.EncodeToString(keypair.SerializePublicKey(configured.Public())), "invalid"})
+	s.now = func() time.Time { return now }
+
+	updated, err := s.Update(newTestSeed(t, configured, "10.0.0.1:20338", now.Unix()-10))
+	assert.Nil(t, err)
+	assert.True(t, updated)
+
+	// stale announcements are ignored
+	updated, err = s.Update(newTestSeed(t, configured, "10.0.0.2:20338", now.Unix()-20))
+	assert.Nil(t, err)
+	assert.False(t, updated)
+
+	_, err = s.Update(newTestSeed(t, untrusted, "10.0.0.3:20338", now.Unix()))
+	assert.NotNil(t, err)
+
+	_, err = s.Update(newTestSeed(t, learned, "10.0.0.4:20338", now.Unix()))
+	assert.NotNil(t, err)
+	s.Trust(learned.Public())
+	updated, err = s.Update(newTestSeed(t, learned, "10.0.0.4:20338", now.Unix()))
+	assert.Nil(t, err)
+	assert.True(t, updated)
+
+	seeds := s.GetSeeds()
+	assert.Equal(t, 2, len(seeds))
+	assert.Equal(t, "10.0.0.4:20338", seeds[0].Addr)
+	assert.Equal(t, "10.0.0.1:20338", seeds[1].Addr)
+
+	// the seed moved to a new address
+	updated, err = s.Update(newTestSeed(t, configured, "10.0.0.5:20338", now.Unix()))
+	assert.Nil(t, err)
+	assert.True(t, updated)
+
+	now = now.Add(common.SEED_ANNOUNCE_TTL * time.Second)
+	assert.Empty(t, s.GetSeeds())
+}
+
+func TestSeedStorePersist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "seeds")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, common.SEED_FILE_NAME)
+
+	priKey, _, _ := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	s := NewSeedStore(path, nil)
+	s.Trust(priKey.Public())
+	_, err = s.Update(newTestSeed(t, priKey, "seed1.poly.network:20338", time.Now().Unix()))
+	assert.Nil(t, err)
+
+	s = NewSeedStore(path, nil)
+	assert.True(t, s.IsTrusted(priKey.Public()))
+	seeds := s.GetSeeds()
+	assert.Equal(t, 1, len(seeds))
+	assert.Equal(t, "seed1.poly.network:20338", seeds[0].Addr)
+}
@@ -0,0 +1,273 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2pserver
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/log"
+	scom "github.com/polynetwork/poly/core/store/common"
+	p2pComm "github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/message/msg_pack"
+	msgtypes "github.com/polynetwork/poly/p2pserver/message/types"
+	"github.com/polynetwork/poly/p2pserver/peer"
+)
+
+const (
+	SNAPSHOT_REQUEST_TIMEOUT = 30                      //s, Request snapshot timeout time. If reply haven't received after SNAPSHOT_REQUEST_TIMEOUT second, retry
+	SNAPSHOT_MAX_FLIGHT_REQ  = 4                       //Number of snapshot requests on flight per sync node
+	SNAPSHOT_MAX_CHUNK_CNT   = 64 * 1024               //Max chunk count of a snapshot manifest
+	SNAPSHOT_MAX_CHUNK_SIZE  = p2pComm.MAX_PAYLOAD_LEN //Max size of a snapshot chunk
+)
+
+// snapshotFlight record an outstanding snapshot request
+type snapshotFlight struct {
+	nodeID  uint64
+	reqTime time.Time
+}
+
+// SnapshotSyncMgr download the snapshot of a trusted state hash from peers to the download dir.
+// Only the replies of outstanding requests are accepted, the manifest must carry the trusted
+// state hash and the chunks must match the hashes of the manifest
+type SnapshotSyncMgr struct {
+	server    *P2PServer
+	height    uint32
+	stateHash common.Uint256
+	dir       string
+	manifest  *scom.SnapshotManifest
+	saved     []bool                     //Chunks saved to dir
+	remain    int                        //Count of chunks not saved
+	flights   map[uint32]*snapshotFlight //Index of manifest or chunk => outstanding request
+	lock      sync.Mutex
+	exitCh    chan interface{}
+}
+
+// NewSnapshotSyncMgr return a SnapshotSyncMgr instance
+func NewSnapshotSyncMgr(server *P2PServer, height uint32, stateHash common.Uint256, dir string) *SnapshotSyncMgr {
+	return &SnapshotSyncMgr{
+		server:    server,
+		height:    height,
+		stateHash: stateHash,
+		dir:       dir,
+		flights:   make(map[uint32]*snapshotFlight),
+		exitCh:    make(chan interface{}, 1),
+	}
+}
+
+// Start to download snapshot, a download interrupted before is resumed
+func (this *SnapshotSyncMgr) Start() {
+	if err := this.resume(); err != nil {
+		log.Errorf("[p2p]snapshot sync resume error: %s", err)
+		return
+	}
+	if this.isDone() {
+		return
+	}
+	log.Infof("[p2p]snapshot sync start at height:%d state hash:%s", this.height, this.stateHash.ToHexString())
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-this.exitCh:
+			return
+		case <-ticker.C:
+			if this.isDone() {
+				return
+			}
+			this.sync()
+		}
+	}
+}
+
+// Close stop to download snapshot
+func (this *SnapshotSyncMgr) Close() {
+	close(this.exitCh)
+}
+
+// resume load the manifest and the chunks already saved in dir
+func (this *SnapshotSyncMgr) resume() error {
+	manifest, err := scom.LoadSnapshotManifest(this.dir)
+	if err == scom.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if manifest.StateHash != this.stateHash {
+		return fmt.Errorf("manifest of state hash %s already in %s", manifest.StateHash.ToHexString(), this.dir)
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.setManifest(manifest)
+	for i, hash := range manifest.ChunkHashes {
+		data, err := scom.LoadSnapshotChunk(this.dir, i)
+		if err == nil && common.Uint256(sha256.Sum256(data)) == hash {
+			this.markSaved(uint32(i))
+		}
+	}
+	return nil
+}
+
+func (this *SnapshotSyncMgr) isDone() bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.manifest != nil && this.remain == 0
+}
+
+// sync request the manifest or the missing chunks from peers at snapshot height
+func (this *SnapshotSyncMgr) sync() {
+	peers := make([]*peer.Peer, 0)
+	for _, p := range this.server.network.GetNeighbors() {
+		if p.GetHeight() >= uint64(this.height) {
+			peers = append(peers, p)
+		}
+	}
+	if len(peers) == 0 {
+		return
+	}
+
+	this.lock.Lock()
+	now := time.Now()
+	loads := make(map[uint64]int)
+	for index, flight := range this.flights {
+		if now.Sub(flight.reqTime) > SNAPSHOT_REQUEST_TIMEOUT*time.Second {
+			delete(this.flights, index)
+			continue
+		}
+		loads[flight.nodeID]++
+	}
+	indexes := make([]uint32, 0)
+	if this.manifest == nil {
+		if _, ok := this.flights[msgtypes.SNAPSHOT_MANIFEST_INDEX]; !ok {
+			indexes = append(indexes, msgtypes.SNAPSHOT_MANIFEST_INDEX)
+		}
+	} else {
+		for i, saved := range this.saved {
+			if _, ok := this.flights[uint32(i)]; !saved && !ok {
+				indexes = append(indexes, uint32(i))
+			}
+		}
+	}
+	reqs := make(map[uint32]*peer.Peer)
+	for _, index := range indexes {
+		p := leastLoadedPeer(peers, loads)
+		if p == nil {
+			break
+		}
+		loads[p.GetID()]++
+		this.flights[index] = &snapshotFlight{nodeID: p.GetID(), reqTime: now}
+		reqs[index] = p
+	}
+	this.lock.Unlock()
+
+	for index, p := range reqs {
+		msg := msgpack.NewSnapshotReq(this.height, index)
+		if err := this.server.Send(p, msg, false); err != nil {
+			log.Warnf("[p2p]send snapshot request error: %s, index: %d, peer id: %d", err, index, p.GetID())
+		}
+	}
+}
+
+// leastLoadedPeer return the peer with fewest outstanding requests, nil if all peers are busy
+func leastLoadedPeer(peers []*peer.Peer, loads map[uint64]int) *peer.Peer {
+	var result *peer.Peer
+	for _, p := range peers {
+		if loads[p.GetID()] >= SNAPSHOT_MAX_FLIGHT_REQ {
+			continue
+		}
+		if result == nil || loads[p.GetID()] < loads[result.GetID()] {
+			result = p
+		}
+	}
+	return result
+}
+
+// OnSnapshotReceive check the reply of an outstanding request and save it to dir
+func (this *SnapshotSyncMgr) OnSnapshotReceive(fromID uint64, height uint32, index uint32, data []byte) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	flight, ok := this.flights[index]
+	if !ok || flight.nodeID != fromID || height != this.height {
+		log.Debugf("[p2p]unsolicited snapshot, height: %d, index: %d, peer id: %d", height, index, fromID)
+		return
+	}
+	delete(this.flights, index)
+
+	if index == msgtypes.SNAPSHOT_MANIFEST_INDEX {
+		if this.manifest != nil {
+			return
+		}
+		manifest := new(scom.SnapshotManifest)
+		if err := manifest.Deserialization(common.NewZeroCopySource(data)); err != nil {
+			log.Warnf("[p2p]deserialize snapshot manifest error: %s, peer id: %d", err, fromID)
+			return
+		}
+		if manifest.Version != scom.SNAPSHOT_VERSION || manifest.Height != this.height ||
+			manifest.StateHash != this.stateHash || len(manifest.ChunkHashes) > SNAPSHOT_MAX_CHUNK_CNT {
+			log.Warnf("[p2p]untrusted snapshot manifest, height: %d, state hash: %s, chunks: %d, peer id: %d",
+				manifest.Height, manifest.StateHash.ToHexString(), len(manifest.ChunkHashes), fromID)
+			return
+		}
+		if err := os.MkdirAll(this.dir, 0755); err != nil {
+			log.Errorf("[p2p]create snapshot dir error: %s", err)
+			return
+		}
+		if err := scom.SaveSnapshotManifest(this.dir, manifest); err != nil {
+			log.Errorf("[p2p]save snapshot manifest error: %s", err)
+			return
+		}
+		this.setManifest(manifest)
+		log.Infof("[p2p]snapshot manifest received, height: %d, chunks: %d", manifest.Height, len(manifest.ChunkHashes))
+		return
+	}
+
+	if this.manifest == nil || index >= uint32(len(this.saved)) || this.saved[index] {
+		return
+	}
+	if len(data) > SNAPSHOT_MAX_CHUNK_SIZE || common.Uint256(sha256.Sum256(data)) != this.manifest.ChunkHashes[index] {
+		log.Warnf("[p2p]invalid snapshot chunk, index: %d, peer id: %d", index, fromID)
+		return
+	}
+	if err := scom.SaveSnapshotChunk(this.dir, int(index), data); err != nil {
+		log.Errorf("[p2p]save snapshot chunk error: %s", err)
+		return
+	}
+	this.markSaved(index)
+	if this.remain == 0 {
+		log.Infof("[p2p]snapshot at height %d is downloaded to %s, stop the node and import it", this.height, this.dir)
+	}
+}
+
+func (this *SnapshotSyncMgr) setManifest(manifest *scom.SnapshotManifest) {
+	this.manifest = manifest
+	this.saved = make([]bool, len(manifest.ChunkHashes))
+	this.remain = len(manifest.ChunkHashes)
+}
+
+func (this *SnapshotSyncMgr) markSaved(index uint32) {
+	if !this.saved[index] {
+		this.saved[index] = true
+		this.remain--
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2pserver
+
+import (
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/polynetwork/poly/common"
+	scom "github.com/polynetwork/poly/core/store/common"
+	msgtypes "github.com/polynetwork/poly/p2pserver/message/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotSyncReceive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot-sync")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	chunks := [][]byte{[]byte("chunk 0"), []byte("chunk 1")}
+	manifest := &scom.SnapshotManifest{
+		Version:   scom.SNAPSHOT_VERSION,
+		Height:    100,
+		BlockHash: common.Uint256{1},
+		StateHash: common.Uint256{2},
+	}
+	for _, chunk := range chunks {
+		manifest.ChunkHashes = append(manifest.ChunkHashes, sha256.Sum256(chunk))
+	}
+	sink := common.NewZeroCopySink(nil)
+	manifest.Serialization(sink)
+	forged := *manifest
+	forged.StateHash = common.Uint256{3}
+	forgedSink := common.NewZeroCopySink(nil)
+	forged.Serialization(forgedSink)
+
+	mgr := NewSnapshotSyncMgr(nil, 100, manifest.StateHash, dir)
+	request := func(nodeID uint64, index uint32) {
+		mgr.flights[index] = &snapshotFlight{nodeID: nodeID, reqTime: time.Now()}
+	}
+
+	mgr.OnSnapshotReceive(1, 100, msgtypes.SNAPSHOT_MANIFEST_INDEX, sink.Bytes())
+	assert.Nil(t, mgr.manifest, "unsolicited manifest should be dropped")
+
+	request(1, msgtypes.SNAPSHOT_MANIFEST_INDEX)
+	mgr.OnSnapshotReceive(2, 100, msgtypes.SNAPSHOT_MANIFEST_INDEX, sink.Bytes())
+	assert.Nil(t, mgr.manifest, "manifest from another peer should be dropped")
+	mgr.OnSnapshotReceive(1, 100, msgtypes.SNAPSHOT_MANIFEST_INDEX, forgedSink.Bytes())
+	assert.Nil(t, mgr.manifest, "manifest of untrusted state hash should be dropped")
+
+	request(1, msgtypes.SNAPSHOT_MANIFEST_INDEX)
+	mgr.OnSnapshotReceive(1, 100, msgtypes.SNAPSHOT_MANIFEST_INDEX, sink.Bytes())
+	assert.NotNil(t, mgr.manifest)
+	assert.Equal(t, 2, mgr.remain)
+
+	request(1, msgtypes.SNAPSHOT_MANIFEST_INDEX)
+	mgr.OnSnapshotReceive(1, 100, msgtypes.SNAPSHOT_MANIFEST_INDEX, forgedSink.Bytes())
+	loaded, err := scom.LoadSnapshotManifest(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, manifest.Hash(), loaded.Hash(), "accepted manifest should not be overwritten")
+
+	request(1, 0)
+	mgr.OnSnapshotReceive(1, 100, 0, chunks[1])
+	assert.Equal(t, 2, mgr.remain, "chunk of mismatched hash should be dropped")
+	mgr.OnSnapshotReceive(1, 100, 0, chunks[0])
+	assert.Equal(t, 2, mgr.remain, "reply of an answered request should be dropped")
+
+	request(1, 0)
+	request(2, 1)
+	mgr.OnSnapshotReceive(1, 100, 0, chunks[0])
+	mgr.OnSnapshotReceive(2, 100, 1, chunks[1])
+	assert.True(t, mgr.isDone())
+	for i, chunk := range chunks {
+		data, err := scom.LoadSnapshotChunk(dir, i)
+		assert.NoError(t, err)
+		assert.Equal(t, chunk, data)
+	}
+
+	resumed := NewSnapshotSyncMgr(nil, 100, manifest.StateHash, dir)
+	assert.NoError(t, resumed.resume())
+	assert.True(t, resumed.isDone())
+	other := NewSnapshotSyncMgr(nil, 100, forged.StateHash, dir)
+	assert.Error(t, other.resume(), "manifest of another state hash should not be overwritten")
+}
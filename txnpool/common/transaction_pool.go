@@ -20,13 +20,16 @@
 package common
 
 import (
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/errors"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
 	vt "github.com/polynetwork/poly/validator/types"
 )
 
@@ -37,17 +40,50 @@ type TXAttr struct {
 }
 
 type TXEntry struct {
-	Tx    *types.Transaction // transaction which has been verified
-	Attrs []*TXAttr          // the result from each validator
+	Tx      *types.Transaction // transaction which has been verified
+	Attrs   []*TXAttr          // the result from each validator
+	Relayer *RelayerTx         // the submissions of the relayer signing the transaction, nil if none
+}
+
+// RelayerTx is the headers and cross chain transactions a transaction of
+// a relayer submits, counted against the quota of the relayer.
+type RelayerTx struct {
+	Relayer common.Address
+	Quota   *relayer_manager.RelayerQuota // nil if the relayer is not limited
+	Headers uint64
+	Imports uint64
+}
+
+// txArrival records when a transaction entered the pool. It survives the
+// re-verification of the transaction so that its position is kept.
+type txArrival struct {
+	lane TxLane    // The lane of the transaction
+	seq  uint64    // The arrival order of the transaction
+	time time.Time // The arrival time of the transaction
 }
 
 // TXPool contains all currently valid transactions. Transactions
 // enter the pool when they are valid from the network,
 // consensus or submitted. They exit the pool when they are included
 // in the ledger.
+//
+// The pool is bounded both globally and per payer. Transactions are
+// ordered by lane, then by gas price and arrival. When the pool is full,
+// the lowest ranked transaction is evicted in favor of a higher ranked one,
+// and transactions older than the max age are evicted as well. The
+// transactions of a relayer are admitted as long as their submissions fit
+// in the quota of the relayer for one block.
 type TXPool struct {
 	sync.RWMutex
-	txList map[common.Uint256]*TXEntry // Transactions which have been verified
+	txList   map[common.Uint256]*TXEntry                      // Transactions which have been verified
+	arrivals map[common.Uint256]*txArrival                    // Arrival of the transactions, including the ones under re-verification
+	payers   map[common.Address]int                           // Transaction count of each payer
+	relayers map[common.Address]*relayer_manager.RelayerUsage // Submissions of each relayer
+	seq      uint64                                           // The arrival order of the next transaction
+
+	capacity      int           // The max number of transactions in the pool
+	payerCapacity int           // The max number of transactions of a payer in the pool
+	maxAge        time.Duration // The max time a transaction stays in the pool
 }
 
 // Init creates a new transaction pool to gather.
@@ -55,24 +91,193 @@ func (tp *TXPool) Init() {
 	tp.Lock()
 	defer tp.Unlock()
 	tp.txList = make(map[common.Uint256]*TXEntry)
+	tp.arrivals = make(map[common.Uint256]*txArrival)
+	tp.payers = make(map[common.Address]int)
+	tp.relayers = make(map[common.Address]*relayer_manager.RelayerUsage)
+	tp.capacity = MAX_CAPACITY
+	tp.payerCapacity = MAX_PAYER_CAPACITY
+	tp.maxAge = MAX_TX_AGE
 }
 
-// AddTxList adds a valid transaction to the transaction pool. If the
-// transaction is already in the pool, just return false. Parameter
-// txEntry includes transaction, fee, and verified information(height,
-// validator, error code).
-func (tp *TXPool) AddTxList(txEntry *TXEntry) bool {
+// ranksBefore returns whether transaction a is packed before transaction b.
+func ranksBefore(a *types.Transaction, aa *txArrival, b *types.Transaction, ba *txArrival) bool {
+	if aa.lane != ba.lane {
+		return aa.lane < ba.lane
+	}
+	if a.GasPrice != b.GasPrice {
+		return a.GasPrice > b.GasPrice
+	}
+	return aa.seq < ba.seq
+}
+
+// arrival returns the arrival of a transaction, a new one is created if
+// the transaction is unknown to the pool.
+func (tp *TXPool) arrival(tx *types.Transaction) *txArrival {
+	if arrival, ok := tp.arrivals[tx.Hash()]; ok {
+		return arrival
+	}
+	return &txArrival{lane: GetTxLane(tx), seq: tp.seq, time: time.Now()}
+}
+
+// lowest returns the lowest ranked transaction in the pool, if payer is
+// not nil, only the transactions of the payer are considered.
+func (tp *TXPool) lowest(payer *common.Address) *TXEntry {
+	var worst *TXEntry
+	for hash, txEntry := range tp.txList {
+		if payer != nil && txEntry.Tx.Payer != *payer {
+			continue
+		}
+		if worst == nil || ranksBefore(worst.Tx, tp.arrivals[worst.Tx.Hash()], txEntry.Tx, tp.arrivals[hash]) {
+			worst = txEntry
+		}
+	}
+	return worst
+}
+
+// victims returns the transactions to evict for a new transaction to enter
+// the pool, and false if the pool is full of higher ranked transactions.
+func (tp *TXPool) victims(tx *types.Transaction, arrival *txArrival) ([]*TXEntry, bool) {
+	victims := make([]*TXEntry, 0)
+	if tp.payers[tx.Payer] >= tp.payerCapacity {
+		worst := tp.lowest(&tx.Payer)
+		if worst == nil || !ranksBefore(tx, arrival, worst.Tx, tp.arrivals[worst.Tx.Hash()]) {
+			return nil, false
+		}
+		victims = append(victims, worst)
+	}
+	if len(tp.txList)-len(victims) >= tp.capacity {
+		worst := tp.lowest(nil)
+		if worst == nil || !ranksBefore(tx, arrival, worst.Tx, tp.arrivals[worst.Tx.Hash()]) {
+			return nil, false
+		}
+		if len(victims) == 0 || victims[0] != worst {
+			victims = append(victims, worst)
+		}
+	}
+	return victims, true
+}
+
+// removeTx removes a transaction from the pool, the arrival is kept if
+// the transaction is going to be re-verified.
+func (tp *TXPool) removeTx(txEntry *TXEntry, keepArrival bool) {
+	txHash := txEntry.Tx.Hash()
+	delete(tp.txList, txHash)
+	if !keepArrival {
+		delete(tp.arrivals, txHash)
+	}
+	payer := txEntry.Tx.Payer
+	if tp.payers[payer]--; tp.payers[payer] <= 0 {
+		delete(tp.payers, payer)
+	}
+	if r := txEntry.Relayer; r != nil {
+		usage := tp.relayers[r.Relayer]
+		usage.Headers -= r.Headers
+		usage.Imports -= r.Imports
+		if usage.Headers == 0 && usage.Imports == 0 {
+			delete(tp.relayers, r.Relayer)
+		}
+	}
+}
+
+// checkRelayerQuota returns an error if the submissions of r don't fit in
+// the quota of the relayer along with the ones already in the pool.
+func (tp *TXPool) checkRelayerQuota(r *RelayerTx) error {
+	if r == nil || r.Quota == nil {
+		return nil
+	}
+	usage, ok := tp.relayers[r.Relayer]
+	if !ok {
+		usage = &relayer_manager.RelayerUsage{}
+	}
+	return r.Quota.Check(usage, r.Headers, r.Imports)
+}
+
+// CheckRelayerQuota returns an error if the submissions of r don't fit in
+// the quota of the relayer along with the ones already in the pool.
+func (tp *TXPool) CheckRelayerQuota(r *RelayerTx) error {
+	tp.RLock()
+	defer tp.RUnlock()
+	return tp.checkRelayerQuota(r)
+}
+
+// GetRelayerUsage returns the headers and cross chain transactions the
+// transactions of relayer in the pool submit.
+func (tp *TXPool) GetRelayerUsage(relayer common.Address) (headers, imports uint64) {
+	tp.RLock()
+	defer tp.RUnlock()
+	if usage, ok := tp.relayers[relayer]; ok {
+		return usage.Headers, usage.Imports
+	}
+	return 0, 0
+}
+
+// CheckCapacity returns whether the transaction could enter the pool,
+// either with a free slot or by evicting a lower ranked transaction.
+func (tp *TXPool) CheckCapacity(tx *types.Transaction) bool {
+	tp.RLock()
+	defer tp.RUnlock()
+	if _, ok := tp.txList[tx.Hash()]; ok {
+		return true
+	}
+	_, ok := tp.victims(tx, tp.arrival(tx))
+	return ok
+}
+
+// AddTx adds a valid transaction to the transaction pool, evicting the
+// lowest ranked transaction if the pool or the payer's quota is full.
+// It returns ErrDuplicateInput if the transaction is already in the pool,
+// ErrTxPoolFull if it ranks below all the transactions to evict, and
+// ErrRelayerQuota if it exceeds the quota of its relayer.
+func (tp *TXPool) AddTx(txEntry *TXEntry) errors.ErrCode {
 	tp.Lock()
 	defer tp.Unlock()
 	txHash := txEntry.Tx.Hash()
 	if _, ok := tp.txList[txHash]; ok {
-		log.Infof("AddTxList: transaction %x is already in the pool",
+		log.Infof("AddTx: transaction %x is already in the pool",
 			txHash)
-		return false
+		return errors.ErrDuplicateInput
+	}
+	if err := tp.checkRelayerQuota(txEntry.Relayer); err != nil {
+		log.Debugf("AddTx: transaction %x of relayer %s: %s", txHash,
+			txEntry.Relayer.Relayer.ToBase58(), err)
+		return errors.ErrRelayerQuota
+	}
+
+	arrival := tp.arrival(txEntry.Tx)
+	victims, ok := tp.victims(txEntry.Tx, arrival)
+	if !ok {
+		log.Debugf("AddTx: transaction pool is full for tx %x", txHash)
+		return errors.ErrTxPoolFull
+	}
+	for _, victim := range victims {
+		log.Debugf("AddTx: transaction %x evicted by tx %x", victim.Tx.Hash(), txHash)
+		tp.removeTx(victim, false)
 	}
 
+	if _, ok := tp.arrivals[txHash]; !ok {
+		tp.arrivals[txHash] = arrival
+		tp.seq++
+	}
 	tp.txList[txHash] = txEntry
-	return true
+	tp.payers[txEntry.Tx.Payer]++
+	if r := txEntry.Relayer; r != nil {
+		usage, ok := tp.relayers[r.Relayer]
+		if !ok {
+			usage = &relayer_manager.RelayerUsage{}
+			tp.relayers[r.Relayer] = usage
+		}
+		usage.Headers += r.Headers
+		usage.Imports += r.Imports
+	}
+	return errors.ErrNoError
+}
+
+// AddTxList adds a valid transaction to the transaction pool. If the
+// transaction is already in the pool or the pool is full, just return
+// false. Parameter txEntry includes transaction, fee, and verified
+// information(height, validator, error code).
+func (tp *TXPool) AddTxList(txEntry *TXEntry) bool {
+	return tp.AddTx(txEntry) == errors.ErrNoError
 }
 
 // CleanTransactionList cleans the transaction list included in the ledger.
@@ -82,10 +287,18 @@ func (tp *TXPool) CleanTransactionList(txs []*types.Transaction) error {
 	tp.Lock()
 	defer tp.Unlock()
 	for _, tx := range txs {
-		if _, ok := tp.txList[tx.Hash()]; ok {
-			delete(tp.txList, tx.Hash())
+		if txEntry, ok := tp.txList[tx.Hash()]; ok {
+			tp.removeTx(txEntry, false)
 			cleaned++
 		}
+		delete(tp.arrivals, tx.Hash())
+	}
+
+	// Drop the arrivals of the transactions which failed the re-verification
+	for hash, arrival := range tp.arrivals {
+		if _, ok := tp.txList[hash]; !ok && time.Since(arrival.time) > tp.maxAge {
+			delete(tp.arrivals, hash)
+		}
 	}
 
 	log.Debugf("CleanTransactionList: transaction %d requested,%d cleaned, remains %d in TxPool",
@@ -93,15 +306,31 @@ func (tp *TXPool) CleanTransactionList(txs []*types.Transaction) error {
 	return nil
 }
 
+// RemoveExpiredTxs removes the transactions which can not be packed in
+// the block of the height or any later block, as their validity window is over.
+func (tp *TXPool) RemoveExpiredTxs(height uint32) []*types.Transaction {
+	tp.Lock()
+	defer tp.Unlock()
+	removed := make([]*types.Transaction, 0)
+	for hash, txEntry := range tp.txList {
+		if txEntry.Tx.IsExpired(height) {
+			log.Debugf("RemoveExpiredTxs: transaction %x is over its validity window", hash)
+			tp.removeTx(txEntry, false)
+			removed = append(removed, txEntry.Tx)
+		}
+	}
+	return removed
+}
+
 // DelTxList removes a single transaction from the pool.
 func (tp *TXPool) DelTxList(tx *types.Transaction) bool {
 	tp.Lock()
 	defer tp.Unlock()
-	txHash := tx.Hash()
-	if _, ok := tp.txList[txHash]; !ok {
+	txEntry, ok := tp.txList[tx.Hash()]
+	if !ok {
 		return false
 	}
-	delete(tp.txList, txHash)
+	tp.removeTx(txEntry, true)
 	return true
 }
 
@@ -121,15 +350,31 @@ func (tp *TXPool) compareTxHeight(txEntry *TXEntry, height uint32) bool {
 // GetTxPool gets the transaction lists from the pool for the consensus,
 // if the byCount is marked, return the configured number at most; if the
 // the byCount is not marked, return all of the current transaction pool.
+// The transactions are returned by lane, gas price and arrival, and the
+// ones older than the max age or over their validity window are evicted.
 func (tp *TXPool) GetTxPool(byCount bool, height uint32) ([]*TXEntry,
 	[]*types.Transaction) {
-	tp.RLock()
-	defer tp.RUnlock()
+	tp.Lock()
+	defer tp.Unlock()
 
-	orderByFee := make([]*TXEntry, 0, len(tp.txList))
-	for _, txEntry := range tp.txList {
-		orderByFee = append(orderByFee, txEntry)
+	ordered := make([]*TXEntry, 0, len(tp.txList))
+	for hash, txEntry := range tp.txList {
+		if time.Since(tp.arrivals[hash].time) > tp.maxAge {
+			log.Debugf("GetTxPool: transaction %x expired in the pool", hash)
+			tp.removeTx(txEntry, false)
+			continue
+		}
+		if txEntry.Tx.IsExpired(height + 1) {
+			log.Debugf("GetTxPool: transaction %x is over its validity window", hash)
+			tp.removeTx(txEntry, false)
+			continue
+		}
+		ordered = append(ordered, txEntry)
 	}
+	sort.Slice(ordered, func(i, j int) bool {
+		a, b := ordered[i].Tx, ordered[j].Tx
+		return ranksBefore(a, tp.arrivals[a.Hash()], b, tp.arrivals[b.Hash()])
+	})
 
 	count := int(config.DefConfig.Consensus.MaxTxInBlock)
 	if count <= 0 {
@@ -142,7 +387,7 @@ func (tp *TXPool) GetTxPool(byCount bool, height uint32) ([]*TXEntry,
 	var num int
 	txList := make([]*TXEntry, 0, count)
 	oldTxList := make([]*types.Transaction, 0)
-	for _, txEntry := range orderByFee {
+	for _, txEntry := range ordered {
 		if !tp.compareTxHeight(txEntry, height) {
 			oldTxList = append(oldTxList, txEntry.Tx)
 			continue
@@ -212,7 +457,7 @@ func (tp *TXPool) GetUnverifiedTxs(txs []*types.Transaction,
 		}
 
 		if !tp.compareTxHeight(txEntry, height) {
-			delete(tp.txList, tx.Hash())
+			tp.removeTx(txEntry, true)
 			res.OldTxs = append(res.OldTxs, txEntry.Tx)
 			continue
 		}
@@ -234,7 +479,8 @@ func (tp *TXPool) GetUnverifiedTxs(txs []*types.Transaction,
 	return res
 }
 
-// Remain returns the remaining tx list to cleanup
+// Remain returns the remaining tx list to cleanup, the arrivals are kept
+// so that the re-verified transactions keep their positions.
 func (tp *TXPool) Remain() []*types.Transaction {
 	tp.Lock()
 	defer tp.Unlock()
@@ -242,7 +488,7 @@ func (tp *TXPool) Remain() []*types.Transaction {
 	txList := make([]*types.Transaction, 0, len(tp.txList))
 	for _, txEntry := range tp.txList {
 		txList = append(txList, txEntry.Tx)
-		delete(tp.txList, txEntry.Tx.Hash())
+		tp.removeTx(txEntry, true)
 	}
 
 	return txList
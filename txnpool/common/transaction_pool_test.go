@@ -19,12 +19,21 @@
 package common
 
 import (
+	"testing"
+	"time"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/payload"
 	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/errors"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/header_sync"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
 	"github.com/stretchr/testify/assert"
-	"testing"
-	"time"
 )
 
 var (
@@ -34,13 +43,38 @@ var (
 func init() {
 	log.Init(log.PATH, log.Stdout)
 
-	mutable := &types.MutableTransaction{
+	txn = newTestTx(&types.Transaction{
 		TxType:  types.Invoke,
 		Nonce:   uint32(time.Now().Unix()),
 		Payload: &payload.InvokeCode{Code: []byte{}},
+	})
+}
+
+// newTestTx returns the transaction with its hash computed
+func newTestTx(tx *types.Transaction) *types.Transaction {
+	sink := common.NewZeroCopySink(nil)
+	if err := tx.Serialization(sink); err != nil {
+		panic(err)
 	}
+	ret, err := types.TransactionFromRawBytes(sink.Bytes())
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
 
-	txn, _ = mutable.IntoImmutable()
+// newInvokeTx returns a transaction invoking the method of a native contract
+func newInvokeTx(contract common.Address, method string, gasPrice uint64, payer common.Address, nonce uint32) *types.Transaction {
+	param := &states.ContractInvokeParam{Address: contract, Method: method}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	return newTestTx(&types.Transaction{
+		TxType:   types.Invoke,
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Payer:    payer,
+		Payload:  &payload.InvokeCode{Code: sink.Bytes()},
+	})
 }
 
 func TestTxPool(t *testing.T) {
@@ -98,3 +132,172 @@ func TestTxPool(t *testing.T) {
 		return
 	}
 }
+
+func TestGetTxLane(t *testing.T) {
+	payer := common.Address{1}
+	assert.Equal(t, GovernanceLane, GetTxLane(newInvokeTx(utils.RelayerManagerContractAddress,
+		relayer_manager.APPROVE_REGISTER_RELAYER, 0, payer, 0)))
+	assert.Equal(t, DefaultLane, GetTxLane(newInvokeTx(utils.RelayerManagerContractAddress,
+		relayer_manager.REGISTER_RELAYER, 0, payer, 0)))
+	assert.Equal(t, HeaderSyncLane, GetTxLane(newInvokeTx(utils.HeaderSyncContractAddress,
+		header_sync.SYNC_BLOCK_HEADER, 0, payer, 0)))
+	assert.Equal(t, CrossChainLane, GetTxLane(newInvokeTx(utils.CrossChainManagerContractAddress,
+		cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME, 0, payer, 0)))
+	assert.Equal(t, DefaultLane, GetTxLane(txn))
+}
+
+func TestTxPoolOrder(t *testing.T) {
+	txPool := &TXPool{}
+	txPool.Init()
+
+	payer := common.Address{1}
+	imported := newInvokeTx(utils.CrossChainManagerContractAddress, cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME, 500, payer, 1)
+	header1 := newInvokeTx(utils.HeaderSyncContractAddress, header_sync.SYNC_BLOCK_HEADER, 0, payer, 2)
+	header2 := newInvokeTx(utils.HeaderSyncContractAddress, header_sync.SYNC_BLOCK_HEADER, 0, payer, 3)
+	header3 := newInvokeTx(utils.HeaderSyncContractAddress, header_sync.SYNC_BLOCK_HEADER, 10, payer, 4)
+	approval := newInvokeTx(utils.RelayerManagerContractAddress, relayer_manager.APPROVE_REGISTER_RELAYER, 0, payer, 5)
+	for _, tx := range []*types.Transaction{imported, header1, header2, header3, approval, txn} {
+		assert.Equal(t, errors.ErrNoError, txPool.AddTx(&TXEntry{Tx: tx}))
+	}
+
+	expected := []*types.Transaction{approval, header3, header1, header2, imported, txn}
+	txList, _ := txPool.GetTxPool(false, 0)
+	assert.Equal(t, len(expected), len(txList))
+	for i, txEntry := range txList {
+		assert.Equal(t, expected[i].Hash(), txEntry.Tx.Hash())
+	}
+
+	// Re-verified transactions keep their arrival order
+	remain := txPool.Remain()
+	assert.Equal(t, 0, txPool.GetTransactionCount())
+	for _, tx := range []*types.Transaction{header2, header1} {
+		assert.Equal(t, errors.ErrNoError, txPool.AddTx(&TXEntry{Tx: tx}))
+	}
+	assert.Equal(t, len(expected), len(remain))
+	txList, _ = txPool.GetTxPool(false, 0)
+	assert.Equal(t, 2, len(txList))
+	assert.Equal(t, header1.Hash(), txList[0].Tx.Hash())
+	assert.Equal(t, header2.Hash(), txList[1].Tx.Hash())
+}
+
+func TestTxPoolEviction(t *testing.T) {
+	txPool := &TXPool{}
+	txPool.Init()
+	txPool.capacity = 3
+	txPool.payerCapacity = 2
+
+	payer1, payer2 := common.Address{1}, common.Address{2}
+	tx1 := newInvokeTx(utils.CrossChainManagerContractAddress, cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME, 0, payer1, 1)
+	tx2 := newInvokeTx(utils.CrossChainManagerContractAddress, cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME, 0, payer1, 2)
+	assert.Equal(t, errors.ErrNoError, txPool.AddTx(&TXEntry{Tx: tx1}))
+	assert.Equal(t, errors.ErrNoError, txPool.AddTx(&TXEntry{Tx: tx2}))
+	assert.Equal(t, errors.ErrDuplicateInput, txPool.AddTx(&TXEntry{Tx: tx2}))
+
+	// The payer's quota is full of transactions ranked higher
+	tx3 := newInvokeTx(utils.CrossChainManagerContractAddress, cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME, 0, payer1, 3)
+	assert.False(t, txPool.CheckCapacity(tx3))
+	assert.Equal(t, errors.ErrTxPoolFull, txPool.AddTx(&TXEntry{Tx: tx3}))
+
+	// A header sync of the payer evicts the payer's latest import
+	tx4 := newInvokeTx(utils.HeaderSyncContractAddress, header_sync.SYNC_BLOCK_HEADER, 0, payer1, 4)
+	assert.True(t, txPool.CheckCapacity(tx4))
+	assert.Equal(t, errors.ErrNoError, txPool.AddTx(&TXEntry{Tx: tx4}))
+	assert.Nil(t, txPool.GetTransaction(tx2.Hash()))
+	assert.Equal(t, 2, txPool.GetTransactionCount())
+
+	tx5 := newInvokeTx(utils.CrossChainManagerContractAddress, cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME, 0, payer2, 5)
+	assert.Equal(t, errors.ErrNoError, txPool.AddTx(&TXEntry{Tx: tx5}))
+
+	// The pool is full, the lowest ranked transaction is evicted by a higher gas price
+	tx6 := newInvokeTx(utils.CrossChainManagerContractAddress, cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME, 0, payer2, 6)
+	assert.Equal(t, errors.ErrTxPoolFull, txPool.AddTx(&TXEntry{Tx: tx6}))
+	tx7 := newInvokeTx(utils.CrossChainManagerContractAddress, cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME, 10, payer2, 7)
+	assert.Equal(t, errors.ErrNoError, txPool.AddTx(&TXEntry{Tx: tx7}))
+	assert.Nil(t, txPool.GetTransaction(tx5.Hash()))
+	assert.Equal(t, 3, txPool.GetTransactionCount())
+
+	// Transactions older than the max age are evicted
+	txPool.arrivals[tx1.Hash()].time = time.Now().Add(-2 * txPool.maxAge)
+	txList, _ := txPool.GetTxPool(false, 0)
+	assert.Equal(t, 2, len(txList))
+	assert.Nil(t, txPool.GetTransaction(tx1.Hash()))
+	assert.Equal(t, errors.ErrNoError, txPool.AddTx(&TXEntry{Tx: tx6}))
+}
+
+func TestTxPoolExpiry(t *testing.T) {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	defer func() { config.DefConfig.P2PNode.NetworkId = networkId }()
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+
+	txPool := &TXPool{}
+	txPool.Init()
+
+	newTx := func(validity *types.TxValidity, nonce uint32) *types.Transaction {
+		return newTestTx(&types.Transaction{
+			Version:    types.TX_VERSION_VALIDITY,
+			TxType:     types.Invoke,
+			Nonce:      nonce,
+			Payload:    &payload.InvokeCode{Code: []byte{}},
+			Attributes: validity.Attributes(),
+		})
+	}
+	tx1 := newTx(&types.TxValidity{ValidUntil: 10}, 1)
+	tx2 := newTx(&types.TxValidity{ValidUntil: 20}, 2)
+	tx3 := newTx(&types.TxValidity{ValidFrom: 15, ValidUntil: 30}, 3)
+	for _, tx := range []*types.Transaction{tx1, tx2, tx3, txn} {
+		assert.Equal(t, errors.ErrNoError, txPool.AddTx(&TXEntry{Tx: tx}))
+	}
+
+	// Transactions not valid yet are kept
+	txList, _ := txPool.GetTxPool(false, 10)
+	assert.Equal(t, 3, len(txList))
+	assert.Nil(t, txPool.GetTransaction(tx1.Hash()))
+
+	removed := txPool.RemoveExpiredTxs(21)
+	assert.Equal(t, 1, len(removed))
+	assert.Equal(t, tx2.Hash(), removed[0].Hash())
+	assert.NotNil(t, txPool.GetTransaction(tx3.Hash()))
+	assert.NotNil(t, txPool.GetTransaction(txn.Hash()))
+}
+
+func TestTxPoolRelayerQuota(t *testing.T) {
+	txPool := &TXPool{}
+	txPool.Init()
+
+	relayer := common.Address{1}
+	quota := &relayer_manager.RelayerQuota{HeadersPerBlock: 10, ImportsPerBlock: 2}
+	newEntry := func(nonce uint32, headers, imports uint64) *TXEntry {
+		return &TXEntry{
+			Tx:      newInvokeTx(utils.HeaderSyncContractAddress, header_sync.SYNC_BLOCK_HEADER, 0, relayer, nonce),
+			Relayer: &RelayerTx{Relayer: relayer, Quota: quota, Headers: headers, Imports: imports},
+		}
+	}
+	tx1 := newEntry(1, 6, 0)
+	tx2 := newEntry(2, 6, 0)
+	tx3 := newEntry(3, 4, 1)
+	assert.Equal(t, errors.ErrNoError, txPool.AddTx(tx1))
+	assert.NotNil(t, txPool.CheckRelayerQuota(tx2.Relayer))
+	assert.Equal(t, errors.ErrRelayerQuota, txPool.AddTx(tx2))
+	assert.Equal(t, errors.ErrNoError, txPool.AddTx(tx3))
+	headers, imports := txPool.GetRelayerUsage(relayer)
+	assert.Equal(t, uint64(10), headers)
+	assert.Equal(t, uint64(1), imports)
+
+	// Submissions leave the counters along with the transactions
+	assert.Nil(t, txPool.CleanTransactionList([]*types.Transaction{tx1.Tx}))
+	headers, _ = txPool.GetRelayerUsage(relayer)
+	assert.Equal(t, uint64(4), headers)
+	assert.Equal(t, errors.ErrNoError, txPool.AddTx(tx2))
+	assert.True(t, txPool.DelTxList(tx2.Tx))
+	assert.True(t, txPool.DelTxList(tx3.Tx))
+	headers, imports = txPool.GetRelayerUsage(relayer)
+	assert.Equal(t, uint64(0), headers)
+	assert.Equal(t, uint64(0), imports)
+
+	// A relayer without quota is counted but not limited
+	free := newEntry(4, 100, 100)
+	free.Relayer.Quota = nil
+	assert.Equal(t, errors.ErrNoError, txPool.AddTx(free))
+	headers, _ = txPool.GetRelayerUsage(relayer)
+	assert.Equal(t, uint64(100), headers)
+}
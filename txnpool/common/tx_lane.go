@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2018 The ontology Authors
+ * This file is part of The ontology library.
+ *
+ * The ontology is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The ontology is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The ontology.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+	"github.com/polynetwork/poly/native/service/governance/neo3_state_manager"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
+)
+
+// TxLane enumerates the classes of transactions, the ones in a lower lane are packed first
+type TxLane uint8
+
+const (
+	GovernanceLane TxLane = iota // Governance calls approved by consensus
+	HeaderSyncLane               // Header syncs, packed before the cross chain imports depending on them
+	CrossChainLane               // Cross chain imports
+	DefaultLane                  // All other transactions
+)
+
+func (lane TxLane) String() string {
+	switch lane {
+	case GovernanceLane:
+		return "governance"
+	case HeaderSyncLane:
+		return "header sync"
+	case CrossChainLane:
+		return "cross chain"
+	default:
+		return "default"
+	}
+}
+
+// governanceMethods are the methods of governance contracts approved by consensus signatures,
+// along with the ones called by consensus nodes
+var governanceMethods = map[common.Address]map[string]bool{
+	utils.NodeManagerContractAddress: {
+		node_manager.APPROVE_CANDIDATE: true,
+		node_manager.BLACK_NODE:        true,
+		node_manager.WHITE_NODE:        true,
+		node_manager.COMMIT_DPOS:       true,
+		node_manager.UPDATE_CONFIG:     true,
+	},
+	utils.SideChainManagerContractAddress: {
+		side_chain_manager.APPROVE_REGISTER_SIDE_CHAIN: true,
+		side_chain_manager.APPROVE_UPDATE_SIDE_CHAIN:   true,
+		side_chain_manager.APPROVE_QUIT_SIDE_CHAIN:     true,
+	},
+	utils.RelayerManagerContractAddress: {
+		relayer_manager.APPROVE_REGISTER_RELAYER: true,
+		relayer_manager.APPROVE_REMOVE_RELAYER:   true,
+		relayer_manager.SET_RELAYER_QUOTA:        true,
+		relayer_manager.SET_RELAYER_FEE:          true,
+	},
+	utils.Neo3StateManagerContractAddress: {
+		neo3_state_manager.APPROVE_REGISTER_STATE_VALIDATOR: true,
+		neo3_state_manager.APPROVE_REMOVE_STATE_VALIDATOR:   true,
+	},
+}
+
+// GetTxLane returns the lane of a transaction by the native contract method it invokes
+func GetTxLane(tx *types.Transaction) TxLane {
+	invokeCode, ok := tx.Payload.(*payload.InvokeCode)
+	if !ok {
+		return DefaultLane
+	}
+	param := new(states.ContractInvokeParam)
+	if err := param.Deserialization(common.NewZeroCopySource(invokeCode.Code)); err != nil {
+		return DefaultLane
+	}
+	switch param.Address {
+	case utils.HeaderSyncContractAddress:
+		return HeaderSyncLane
+	case utils.CrossChainManagerContractAddress:
+		if param.Method == cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME ||
+			param.Method == cross_chain_manager.IMPORT_OUTER_TRANSFER_BATCH_NAME {
+			return CrossChainLane
+		}
+	default:
+		if governanceMethods[param.Address][param.Method] {
+			return GovernanceLane
+		}
+	}
+	return DefaultLane
+}
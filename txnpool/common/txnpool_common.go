@@ -19,6 +19,8 @@
 package common
 
 import (
+	"time"
+
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/errors"
@@ -39,6 +41,11 @@ const (
 	MAX_TX_SIZE      = 1024 * 1024                      // The max size of a transaction to prevent DOS attacks
 )
 
+const (
+	MAX_PAYER_CAPACITY = 1024             // The max number of verified txs of a payer in the tx pool
+	MAX_TX_AGE         = 30 * time.Minute // The max time a verified tx stays in the tx pool
+)
+
 // ActorType enumerates the kind of actor
 type ActorType uint8
 
@@ -25,6 +25,7 @@ import (
 	"github.com/ontio/ontology-eventbus/actor"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	tx "github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/errors"
@@ -69,6 +70,11 @@ func replyTxResult(txResultCh chan *tc.TxResult, hash common.Uint256,
 	}
 }
 
+// gasMetering checks whether the transactions of block at height are limited by gas
+func gasMetering(height uint32) bool {
+	return height >= config.GetActivationHeight(config.FEATURE_GAS_METERING, config.DefConfig.P2PNode.NetworkId)
+}
+
 // TxnActor: Handle the low priority msg from P2P and API
 type TxActor struct {
 	server *TXPoolServer
@@ -95,7 +101,23 @@ func (ta *TxActor) handleTransaction(sender tc.SenderType, self *actor.PID,
 			replyTxResult(txResultCh, txn.Hash(), errors.ErrDuplicateInput,
 				fmt.Sprintf("transaction %x is already in the tx pool", txn.Hash()))
 		}
-	} else if ta.server.getTransactionCount() >= tc.MAX_CAPACITY {
+	} else if gasMetering(ta.server.getHeight()+1) && txn.GasPrice < ta.server.getGasPrice() {
+		log.Debugf("handleTransaction: invalid gasPrice %d of tx %x", txn.GasPrice, txn.Hash())
+
+		ta.server.increaseStats(tc.FailureStats)
+		if sender == tc.HttpSender && txResultCh != nil {
+			replyTxResult(txResultCh, txn.Hash(), errors.ErrGasPrice,
+				fmt.Sprintf("gas price %d is lower than %d", txn.GasPrice, ta.server.getGasPrice()))
+		}
+	} else if gasMetering(ta.server.getHeight()+1) && txn.GasLimit < config.DefConfig.Common.GasLimit {
+		log.Debugf("handleTransaction: invalid gasLimit %d of tx %x", txn.GasLimit, txn.Hash())
+
+		ta.server.increaseStats(tc.FailureStats)
+		if sender == tc.HttpSender && txResultCh != nil {
+			replyTxResult(txResultCh, txn.Hash(), errors.ErrGasLimit,
+				fmt.Sprintf("gas limit %d is lower than %d", txn.GasLimit, config.DefConfig.Common.GasLimit))
+		}
+	} else if !ta.server.checkCapacity(txn) {
 		log.Debugf("handleTransaction: transaction pool is full for tx %x",
 			txn.Hash())
 
@@ -104,6 +126,13 @@ func (ta *TxActor) handleTransaction(sender tc.SenderType, self *actor.PID,
 			replyTxResult(txResultCh, txn.Hash(), errors.ErrTxPoolFull,
 				"transaction pool is full")
 		}
+	} else if err := ta.server.checkRelayerQuota(txn); err != nil {
+		log.Debugf("handleTransaction: tx %x exceeds the relayer quota: %s", txn.Hash(), err)
+
+		ta.server.increaseStats(tc.FailureStats)
+		if sender == tc.HttpSender && txResultCh != nil {
+			replyTxResult(txResultCh, txn.Hash(), errors.ErrRelayerQuota, err.Error())
+		}
 	} else {
 		<-ta.server.slots
 		ta.server.assignTxToWorker(txn, sender, txResultCh)
@@ -23,9 +23,20 @@ package proc
 import (
 	"github.com/ontio/ontology-eventbus/actor"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/core/ledger"
+	"github.com/polynetwork/poly/core/payload"
+	scommon "github.com/polynetwork/poly/core/store/common"
 	tx "github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/errors"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager"
+	ccmcom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/header_sync"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
 	tc "github.com/polynetwork/poly/txnpool/common"
 	"github.com/polynetwork/poly/validator/types"
 	"sort"
@@ -114,6 +125,7 @@ func (s *TXPoolServer) init(num uint8, disablePreExec, disableBroadcastNetTx boo
 		s.slots <- struct{}{}
 	}
 
+	s.gasPrice = config.DefConfig.Common.GasPrice
 	s.disablePreExec = disablePreExec
 	s.disableBroadcastNetTx = disableBroadcastNetTx
 	// Create the given concurrent workers
@@ -464,6 +476,7 @@ func (s *TXPoolServer) getPendingTxs(byCount bool) []*tx.Transaction {
 // cleanTransactionList cleans the txs in the block from the ledger
 func (s *TXPoolServer) cleanTransactionList(txs []*tx.Transaction, height uint32) {
 	s.txPool.CleanTransactionList(txs)
+	s.txPool.RemoveExpiredTxs(height + 1)
 
 	// Cleanup tx pool
 	if !s.disablePreExec {
@@ -480,14 +493,117 @@ func (s *TXPoolServer) delTransaction(t *tx.Transaction) {
 }
 
 // addTxList adds a valid transaction to the tx pool.
-func (s *TXPoolServer) addTxList(txEntry *tc.TXEntry) bool {
-	ret := s.txPool.AddTxList(txEntry)
-	if !ret {
+func (s *TXPoolServer) addTxList(txEntry *tc.TXEntry) errors.ErrCode {
+	ret := s.txPool.AddTx(txEntry)
+	switch ret {
+	case errors.ErrDuplicateInput:
 		s.increaseStats(tc.DuplicateStats)
+	case errors.ErrTxPoolFull, errors.ErrRelayerQuota:
+		s.increaseStats(tc.FailureStats)
 	}
 	return ret
 }
 
+// checkCapacity returns whether the tx pool has room for a transaction.
+func (s *TXPoolServer) checkCapacity(t *tx.Transaction) bool {
+	return s.txPool.CheckCapacity(t)
+}
+
+// checkRelayerQuota returns an error if a transaction of a relayer doesn't
+// fit in the quota of the relayer along with its transactions in the pool.
+func (s *TXPoolServer) checkRelayerQuota(t *tx.Transaction) error {
+	return s.txPool.CheckRelayerQuota(s.relayerTx(t))
+}
+
+// relayerTx returns the submissions of a transaction counted against the
+// quota of the relayer signing it, or nil if the transaction doesn't submit
+// headers or cross chain transactions of a registered relayer.
+func (s *TXPoolServer) relayerTx(t *tx.Transaction) *tc.RelayerTx {
+	if ledger.DefLedger == nil || s.getHeight()+1 < config.GetActivationHeight(config.FEATURE_RELAYER_QUOTA,
+		config.DefConfig.P2PNode.NetworkId) {
+		return nil
+	}
+	headers, imports, ok := relayerTxCost(t)
+	if !ok {
+		return nil
+	}
+	signers, err := t.GetSignatureAddresses()
+	if err != nil {
+		return nil
+	}
+	for _, signer := range signers {
+		value, err := ledger.DefLedger.GetStorageItem(utils.RelayerManagerContractAddress,
+			append([]byte(relayer_manager.RELAYER), signer[:]...))
+		if err != nil || value == nil {
+			continue
+		}
+		quota, err := getRelayerQuota(signer)
+		if err != nil {
+			log.Warnf("relayerTx: get quota of relayer %s error: %s", signer.ToBase58(), err)
+		}
+		return &tc.RelayerTx{Relayer: signer, Quota: quota, Headers: headers, Imports: imports}
+	}
+	return nil
+}
+
+// relayerTxCost returns the headers and cross chain transactions submitted
+// by a transaction, ok is false if it doesn't submit any.
+func relayerTxCost(t *tx.Transaction) (headers, imports uint64, ok bool) {
+	invokeCode, isInvoke := t.Payload.(*payload.InvokeCode)
+	if !isInvoke {
+		return 0, 0, false
+	}
+	param := new(states.ContractInvokeParam)
+	if err := param.Deserialization(common.NewZeroCopySource(invokeCode.Code)); err != nil {
+		return 0, 0, false
+	}
+	source := common.NewZeroCopySource(param.Args)
+	switch {
+	case param.Address == utils.HeaderSyncContractAddress && param.Method == header_sync.SYNC_BLOCK_HEADER:
+		params := new(hscommon.SyncBlockHeaderParam)
+		if err := params.Deserialization(source); err != nil {
+			return 0, 0, false
+		}
+		return uint64(len(params.Headers)), 0, true
+	case param.Address == utils.HeaderSyncContractAddress && param.Method == header_sync.SYNC_CROSS_CHAIN_MSG:
+		params := new(hscommon.SyncCrossChainMsgParam)
+		if err := params.Deserialization(source); err != nil {
+			return 0, 0, false
+		}
+		return uint64(len(params.CrossChainMsgs)), 0, true
+	case param.Address == utils.CrossChainManagerContractAddress && param.Method == cross_chain_manager.IMPORT_OUTER_TRANSFER_NAME:
+		return 0, 1, true
+	case param.Address == utils.CrossChainManagerContractAddress && param.Method == cross_chain_manager.IMPORT_OUTER_TRANSFER_BATCH_NAME:
+		params := new(ccmcom.EntranceBatchParam)
+		if err := params.Deserialization(source); err != nil {
+			return 0, 0, false
+		}
+		return 0, uint64(len(params.Params)), true
+	}
+	return 0, 0, false
+}
+
+// getRelayerQuota reads the quota of a relayer from the ledger, falling back
+// to the default quota, it returns nil if neither is set.
+func getRelayerQuota(relayer common.Address) (*relayer_manager.RelayerQuota, error) {
+	keys := [][]byte{append([]byte(relayer_manager.RELAYER_QUOTA), relayer[:]...), []byte(relayer_manager.RELAYER_QUOTA)}
+	for _, key := range keys {
+		value, err := ledger.DefLedger.GetStorageItem(utils.RelayerManagerContractAddress, key)
+		if err != nil {
+			if err == scommon.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		quota := new(relayer_manager.RelayerQuota)
+		if err := quota.Deserialization(common.NewZeroCopySource(value)); err != nil {
+			return nil, err
+		}
+		return quota, nil
+	}
+	return nil, nil
+}
+
 // increaseStats increases the count with the stats type
 func (s *TXPoolServer) increaseStats(v tc.TxnStatsType) {
 	s.stats.Lock()
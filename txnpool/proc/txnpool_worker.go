@@ -164,10 +164,14 @@ func (worker *txPoolWorker) handleTimeoutEvent() {
 // the pending list.
 func (worker *txPoolWorker) putTxPool(pt *pendingTx) bool {
 	txEntry := &tc.TXEntry{
-		Tx:    pt.tx,
-		Attrs: pt.ret,
+		Tx:      pt.tx,
+		Attrs:   pt.ret,
+		Relayer: worker.server.relayerTx(pt.tx),
+	}
+	if ret := worker.server.addTxList(txEntry); ret == errors.ErrTxPoolFull || ret == errors.ErrRelayerQuota {
+		worker.server.removePendingTx(pt.tx.Hash(), ret)
+		return false
 	}
-	worker.server.addTxList(txEntry)
 	worker.server.removePendingTx(pt.tx.Hash(), errors.ErrNoError)
 	return true
 }
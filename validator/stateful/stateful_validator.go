@@ -77,6 +77,9 @@ func (self *validator) Receive(context actor.Context) {
 			errCode = errors.ErrUnknown
 		} else if exist {
 			errCode = errors.ErrDuplicatedTx
+		} else if err := msg.Tx.VerifyValidity(height + 1); err != nil {
+			log.Debugf("stateful-validator: tx %x, %s", hash, err)
+			errCode = errors.ErrTxValidity
 		}
 
 		response := &vatypes.CheckResponse{